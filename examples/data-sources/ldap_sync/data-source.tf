@@ -0,0 +1,18 @@
+variable "sync_cookie" {
+  type    = string
+  default = null
+}
+
+data "ldap_sync" "example" {
+  base_dn = "ou=people,dc=example,dc=com"
+  filter  = "(objectClass=person)"
+  cookie  = var.sync_cookie
+}
+
+output "changed_people" {
+  value = [for change in data.ldap_sync.example.changes : change.dn if change.state != "delete"]
+}
+
+output "sync_cookie" {
+  value = data.ldap_sync.example.sync_cookie
+}
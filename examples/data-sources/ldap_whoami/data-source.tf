@@ -0,0 +1 @@
+data "ldap_whoami" "example" {}
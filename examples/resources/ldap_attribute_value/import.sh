@@ -0,0 +1 @@
+terraform import ldap_attribute_value.deploy_key 'uid=alice,ou=people,dc=example,dc=com|sshPublicKey|ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIH6nJvW3WnM1S2n4bVkB4d8S7Lp4yJ4x0fMZ2Cq9a7kD deploy'
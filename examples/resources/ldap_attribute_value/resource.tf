@@ -0,0 +1,5 @@
+resource "ldap_attribute_value" "deploy_key" {
+  dn        = "uid=alice,ou=people,dc=example,dc=com"
+  attribute = "sshPublicKey"
+  value     = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIH6nJvW3WnM1S2n4bVkB4d8S7Lp4yJ4x0fMZ2Cq9a7kD deploy"
+}
@@ -0,0 +1,7 @@
+resource "ldap_attributes" "alice" {
+  dn = "uid=alice,ou=people,dc=example,dc=com"
+  attributes = {
+    "employeeType"     = ["contractor"]
+    "departmentNumber" = ["4711"]
+  }
+}
@@ -0,0 +1,17 @@
+resource "ldap_group" "developers" {
+  name        = "developers"
+  parent_dn   = "ou=groups,dc=example,dc=com"
+  description = "Developers"
+  members = [
+    "cn=alice,ou=people,dc=example,dc=com",
+    "cn=bob,ou=people,dc=example,dc=com",
+  ]
+}
+
+resource "ldap_group" "operators" {
+  name       = "operators"
+  parent_dn  = "ou=groups,dc=example,dc=com"
+  group_type = "posixGroup"
+  gid_number = 5000
+  members    = ["alice", "bob"]
+}
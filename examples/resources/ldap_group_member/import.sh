@@ -0,0 +1 @@
+terraform import ldap_group_member.backup 'cn=operators,ou=groups,dc=example,dc=com|cn=backup,ou=services,dc=example,dc=com'
@@ -0,0 +1,4 @@
+resource "ldap_group_member" "backup" {
+  group_dn  = "cn=operators,ou=groups,dc=example,dc=com"
+  member_dn = "cn=backup,ou=services,dc=example,dc=com"
+}
@@ -0,0 +1,8 @@
+resource "ldap_group_members" "admins" {
+  group_dn = "cn=admins,ou=groups,dc=example,dc=com"
+  members = [
+    "cn=alice,ou=people,dc=example,dc=com",
+    "cn=bob,ou=people,dc=example,dc=com",
+  ]
+  ignore_members = ["cn=*,ou=emergency,dc=example,dc=com"]
+}
@@ -0,0 +1,9 @@
+resource "ldap_ldif_entry" "example" {
+  ldif = <<-EOT
+    dn: cn=alice,ou=people,dc=example,dc=com
+    objectClass: inetOrgPerson
+    cn: alice
+    sn: Liddell
+    description:: QWxpY2Ugd2FzIGhlcmUg
+  EOT
+}
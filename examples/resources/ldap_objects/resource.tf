@@ -0,0 +1,19 @@
+resource "ldap_objects" "example" {
+  base_dn = "ou=people,dc=example,dc=com"
+  objects = {
+    "cn=alice" = {
+      object_classes = ["person"]
+      attributes = {
+        cn = ["alice"]
+        sn = ["Alice"]
+      }
+    }
+    "cn=bob" = {
+      object_classes = ["person"]
+      attributes = {
+        cn = ["bob"]
+        sn = ["Bob"]
+      }
+    }
+  }
+}
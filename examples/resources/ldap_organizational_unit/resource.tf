@@ -0,0 +1,11 @@
+resource "ldap_organizational_unit" "people" {
+  name        = "people"
+  parent_dn   = "dc=example,dc=com"
+  description = "Users of the company"
+}
+
+resource "ldap_organizational_unit" "contractors" {
+  name                = "contractors"
+  parent_dn           = ldap_organizational_unit.people.dn
+  deletion_protection = true
+}
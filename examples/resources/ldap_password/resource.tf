@@ -0,0 +1,7 @@
+resource "ldap_password" "example" {
+  user_dn      = "cn=alice,ou=people,dc=example,dc=com"
+  new_password = var.alice_password
+  rotation_trigger = {
+    "rotated_at" = "2024-01-01"
+  }
+}
@@ -0,0 +1,5 @@
+resource "ldap_password_policy_state" "example" {
+  dn             = "cn=alice,ou=people,dc=example,dc=com"
+  unlock         = true
+  reset_failures = true
+}
@@ -0,0 +1,18 @@
+resource "ldap_user" "alice" {
+  uid        = "alice"
+  parent_dn  = "ou=people,dc=example,dc=com"
+  given_name = "Alice"
+  surname    = "Liddell"
+  mail       = "alice@example.com"
+  password   = var.alice_password
+
+  posix = {
+    uid_number  = 10001
+    gid_number  = 10001
+    login_shell = "/bin/bash"
+  }
+
+  attributes = {
+    telephoneNumber = ["+1 555 0100"]
+  }
+}
@@ -0,0 +1,7 @@
+resource "ldap_user_groups" "alice" {
+  user_dn = "cn=alice,ou=people,dc=example,dc=com"
+  group_dns = [
+    "cn=developers,ou=groups,dc=example,dc=com",
+    "cn=vpn,ou=groups,dc=example,dc=com",
+  ]
+}
@@ -0,0 +1,217 @@
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"net"
+	"net/url"
+	"sync"
+)
+
+// Modes to authenticate connections to servers returned in referrals.
+const (
+	referralBindSame      = "same"
+	referralBindAnonymous = "anonymous"
+	referralBindExplicit  = "explicit"
+)
+
+// ldapClient is handed to the resources and data sources by the provider. Besides the connection to the configured
+// server it keeps the settings needed to open connections to the servers returned in referrals.
+type ldapClient struct {
+	conn           *ldap.Conn
+	locks          *dnLocks
+	subschema      *subschemaCache
+	controlSupport *controlSupport
+	// authzID is the authorization identity returned by the bind if ldap_authzid_on_bind is set and the server
+	// supports the authorization identity controls, nil otherwise.
+	authzID *string
+
+	url               string
+	dialer            *net.Dialer
+	tlsConfig         *tls.Config
+	tlsUseStartTLS    bool
+	allowInsecureBind bool
+	bindDN            string
+	bindPassword      string
+	authzIDOnBind     bool
+	credentialCache   string
+	krb5Config        string
+	servicePrincipal  string
+
+	referralBind         string
+	referralBindDN       string
+	referralBindPassword string
+}
+
+// dialReferral opens a connection to the server of the given referral URL and authenticates it as configured by
+// ldap_referral_bind.
+func (c *ldapClient) dialReferral(referral string) (*ldap.Conn, error) {
+	u, err := url.Parse(referral)
+	if err != nil {
+		return nil, err
+	}
+
+	var password string
+	switch {
+	case c.referralBind == referralBindExplicit:
+		password = c.referralBindPassword
+	case c.referralBind != referralBindAnonymous && c.credentialCache == "":
+		password = c.bindPassword
+	}
+	if !c.allowInsecureBind && sendsPasswordUnencrypted(referral, c.tlsUseStartTLS, password) {
+		return nil, fmt.Errorf("refusing to send the bind password unencrypted to %s, set ldap_allow_insecure_bind to bind anyway", u.Host)
+	}
+
+	conn, err := ldap.DialURL(fmt.Sprintf("%s://%s", u.Scheme, u.Host), ldap.DialWithDialer(c.dialer), ldap.DialWithTLSConfig(c.tlsConfig))
+	if err != nil {
+		return nil, err
+	}
+
+	if c.tlsUseStartTLS && u.Scheme != "ldaps" {
+		if err := conn.StartTLS(c.tlsConfig); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	switch c.referralBind {
+	case referralBindAnonymous:
+		// LDAPv3 allows operations without a bind, which are treated as anonymous
+		err = nil
+	case referralBindExplicit:
+		_, _, err = BindWithPasswordPolicy(conn, c.referralBindDN, c.referralBindPassword)
+	default:
+		_, _, err = c.bind(conn, referral)
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// bind authenticates a connection with the credentials of the provider, so every connection binds the same way. A
+// GSSAPI bind is used if a credential cache is configured, a simple bind with the bind DN and password otherwise. The
+// configured service principal only applies to the server of ldap_url, other servers default to ldap/<host>. The
+// password policy control of a simple bind is returned, as well as the authorization identity if ldap_authzid_on_bind
+// is set and the server returned it.
+func (c *ldapClient) bind(conn *ldap.Conn, serverURL string) (*ldap.ControlBeheraPasswordPolicy, *string, error) {
+	if c.credentialCache != "" {
+		servicePrincipal := ""
+		if serverURL == c.url {
+			servicePrincipal = c.servicePrincipal
+		}
+		if err := bindGSSAPI(conn, serverURL, c.credentialCache, c.krb5Config, servicePrincipal); err != nil {
+			return nil, nil, fmt.Errorf("using GSSAPI: %w", err)
+		}
+		return nil, nil, nil
+	}
+
+	var controls []ldap.Control
+	if c.authzIDOnBind {
+		controls = append(controls, NewControlAuthzIDRequest())
+	}
+	policy, responseControls, err := BindWithPasswordPolicy(conn, c.bindDN, c.bindPassword, controls...)
+	if err != nil {
+		return policy, nil, err
+	}
+	if identity, ok := FindAuthzID(responseControls); c.authzIDOnBind && ok {
+		return policy, &identity, nil
+	}
+	return policy, nil, nil
+}
+
+// referralBaseDN returns the base DN of a search continuation reference, which defaults to the base DN of the
+// original search.
+func referralBaseDN(referral string, baseDN string) (string, error) {
+	u, err := url.Parse(referral)
+	if err != nil {
+		return "", err
+	}
+	if dn := u.Path; len(dn) > 1 {
+		return dn[1:], nil
+	}
+	return baseDN, nil
+}
+
+// dnLocks serializes the writes of all resources to the same entry, since concurrent modifications of e.g. the members
+// of a group interleave on the server. Writes to different entries still run concurrently. As every write passes
+// through it, it refuses all writes if the provider is configured read_only.
+type dnLocks struct {
+	mu       sync.Mutex
+	locks    map[string]*dnLock
+	readOnly bool
+}
+
+// ErrReadOnly is returned by dnLocks.write if the provider is configured read_only, the operation isn't sent.
+var ErrReadOnly = errors.New("the provider is configured read_only")
+
+// dnLock is held while writing an entry. It is removed from dnLocks once nobody holds or waits for it anymore.
+type dnLock struct {
+	held  chan struct{}
+	users int
+}
+
+// lock waits until no other write to the entry is running and returns the function releasing the lock. Waiting is
+// cancelled when the context is done. Locking a nil dnLocks does nothing, e.g. for resources which weren't configured.
+func (l *dnLocks) lock(ctx context.Context, dn string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	key := normalizeDN(dn)
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = map[string]*dnLock{}
+	}
+	entry, exists := l.locks[key]
+	if !exists {
+		entry = &dnLock{held: make(chan struct{}, 1)}
+		l.locks[key] = entry
+	}
+	entry.users++
+	l.mu.Unlock()
+
+	release := func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		entry.users--
+		if entry.users == 0 {
+			delete(l.locks, key)
+		}
+	}
+
+	select {
+	case entry.held <- struct{}{}:
+	default:
+		tflog.Debug(ctx, "Waiting for another write to the same entry", map[string]interface{}{"dn": dn})
+		select {
+		case entry.held <- struct{}{}:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+
+	return func() {
+		<-entry.held
+		release()
+	}, nil
+}
+
+// write runs the write operation on the entry while holding its lock.
+func (l *dnLocks) write(ctx context.Context, dn string, operation func() error) error {
+	if l != nil && l.readOnly {
+		return ErrReadOnly
+	}
+	unlock, err := l.lock(ctx, dn)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return operation()
+}
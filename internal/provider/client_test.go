@@ -0,0 +1,216 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestReferralBaseDN(t *testing.T) {
+	baseDN, err := referralBaseDN("ldap://other.example.com/ou=people,dc=example,dc=com??sub", "dc=example,dc=com")
+	assert.NoError(t, err)
+	assert.Equal(t, "ou=people,dc=example,dc=com", baseDN)
+
+	baseDN, err = referralBaseDN("ldap://other.example.com/ou=M%C3%BCller,dc=example,dc=com", "dc=example,dc=com")
+	assert.NoError(t, err)
+	assert.Equal(t, "ou=Müller,dc=example,dc=com", baseDN)
+
+	baseDN, err = referralBaseDN("ldap://other.example.com", "dc=example,dc=com")
+	assert.NoError(t, err)
+	assert.Equal(t, "dc=example,dc=com", baseDN)
+}
+
+func TestDialReferralAnonymous(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	// the server never answers, so a bind request would block until it times out
+	received := make(chan int, 1)
+	go func() {
+		serverConn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer serverConn.Close()
+		_ = serverConn.SetReadDeadline(time.Now().Add(time.Second))
+		n, _ := serverConn.Read(make([]byte, 1))
+		received <- n
+	}()
+
+	client := &ldapClient{referralBind: referralBindAnonymous, bindDN: "cn=admin,dc=example,dc=com", bindPassword: "admin"}
+	conn, err := client.dialReferral(fmt.Sprintf("ldap://%s/dc=example,dc=com", listener.Addr()))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, <-received, "no bind request is sent to the referred server")
+	_ = conn.Close()
+}
+
+func TestDialReferralInsecureBind(t *testing.T) {
+	client := &ldapClient{referralBind: referralBindSame, bindDN: "cn=admin,dc=example,dc=com", bindPassword: "admin"}
+	_, err := client.dialReferral("ldap://127.0.0.1:1/dc=example,dc=com")
+	assert.ErrorContains(t, err, "refusing to send the bind password unencrypted")
+
+	client.allowInsecureBind = true
+	_, err = client.dialReferral("ldap://127.0.0.1:1/dc=example,dc=com")
+	assert.ErrorContains(t, err, "connection refused")
+}
+
+func TestDialReferralUnauthenticatedPlaintext(t *testing.T) {
+	// an explicit bind without a password sends no password, so the plaintext connection is allowed
+	client := &ldapClient{referralBind: referralBindExplicit, referralBindDN: "cn=reader,dc=example,dc=com"}
+	_, err := client.dialReferral("ldap://127.0.0.1:1/dc=example,dc=com")
+	assert.ErrorContains(t, err, "connection refused")
+}
+
+// testBindRequest is a bind request received by testBindServer.
+type testBindRequest struct {
+	dn       string
+	controls []string
+}
+
+// testBindServer accepts connections and answers every bind request successfully. The received bind requests are sent
+// to the returned channel.
+func testBindServer(t *testing.T) (net.Listener, <-chan testBindRequest) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	requests := make(chan testBindRequest, 10)
+	go func() {
+		for {
+			serverConn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer serverConn.Close()
+				for {
+					packet, err := ber.ReadPacket(serverConn)
+					if err != nil || len(packet.Children) < 2 || packet.Children[1].Tag != ldap.ApplicationBindRequest {
+						return
+					}
+					request := testBindRequest{dn: packet.Children[1].Children[1].Value.(string)}
+					if len(packet.Children) > 2 {
+						for _, control := range packet.Children[2].Children {
+							request.controls = append(request.controls, control.Children[0].Value.(string))
+						}
+					}
+					requests <- request
+
+					response := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+					response.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, packet.Children[0].Value, "MessageID"))
+					bindResponse := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationBindResponse, nil, "Bind Response")
+					bindResponse.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, 0, "resultCode"))
+					bindResponse.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+					bindResponse.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "diagnosticMessage"))
+					response.AppendChild(bindResponse)
+					if _, err := serverConn.Write(response.Bytes()); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return listener, requests
+}
+
+func TestBindReferralSameAsProvider(t *testing.T) {
+	listener, requests := testBindServer(t)
+	client := &ldapClient{
+		url:               fmt.Sprintf("ldap://%s", listener.Addr()),
+		allowInsecureBind: true,
+		bindDN:            "cn=admin,dc=example,dc=com",
+		bindPassword:      "admin",
+		authzIDOnBind:     true,
+		referralBind:      referralBindSame,
+	}
+
+	conn, err := ldap.DialURL(client.url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, authzID, err := client.bind(conn, client.url)
+	assert.NoError(t, err)
+	assert.Nil(t, authzID, "the server didn't return an authorization identity")
+	primary := <-requests
+	assert.Equal(t, "cn=admin,dc=example,dc=com", primary.dn)
+	assert.ElementsMatch(t, []string{ldap.ControlTypeBeheraPasswordPolicy, ControlTypeAuthzIDRequest}, primary.controls)
+
+	// connections to referred servers bind the same way
+	referralConn, err := client.dialReferral(fmt.Sprintf("ldap://%s/ou=people,dc=example,dc=com", listener.Addr()))
+	assert.NoError(t, err)
+	defer referralConn.Close()
+	assert.Equal(t, primary, <-requests)
+}
+
+func TestDNLocks(t *testing.T) {
+	locks := &dnLocks{}
+	ctx := context.Background()
+
+	unlock, err := locks.lock(ctx, "cn=group,dc=example,dc=com")
+	assert.NoError(t, err)
+
+	// writes to other entries don't wait
+	other, err := locks.lock(ctx, "cn=other,dc=example,dc=com")
+	assert.NoError(t, err)
+	other()
+
+	// writes to the same entry wait, regardless of the spelling of its DN
+	acquired := make(chan struct{})
+	go func() {
+		unlock, err := locks.lock(ctx, "CN=Group, dc=example,dc=com")
+		assert.NoError(t, err)
+		close(acquired)
+		unlock()
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("lock acquired while it was held")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("lock not acquired after it was released")
+	}
+
+	// waiting is cancelled with the context
+	unlock, err = locks.lock(ctx, "cn=group,dc=example,dc=com")
+	assert.NoError(t, err)
+	cancelled, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = locks.lock(cancelled, "cn=group,dc=example,dc=com")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	unlock()
+
+	// unused locks are removed
+	locks.mu.Lock()
+	assert.Empty(t, locks.locks)
+	locks.mu.Unlock()
+
+	// unconfigured resources don't lock
+	var unconfigured *dnLocks
+	assert.NoError(t, unconfigured.write(ctx, "cn=group,dc=example,dc=com", func() error { return nil }))
+}
+
+func TestDNLocksReadOnly(t *testing.T) {
+	locks := &dnLocks{readOnly: true}
+	called := false
+	err := locks.write(context.Background(), "cn=group,dc=example,dc=com", func() error {
+		called = true
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrReadOnly)
+	assert.False(t, called)
+}
@@ -0,0 +1,425 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	datasourceschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	resourceschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/thoas/go-funk"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LDAPControlModel describes a control configured in the controls attribute of resources and data sources.
+type LDAPControlModel struct {
+	OID         types.String `tfsdk:"oid"`
+	Value       types.String `tfsdk:"value"`
+	Criticality types.Bool   `tfsdk:"criticality"`
+}
+
+// ControlTypePermissiveModify is the OID of the Active Directory permissive modify control, which makes adding
+// existing values and deleting missing values succeed.
+const ControlTypePermissiveModify = "1.2.840.113556.1.4.1413"
+
+// NewControlPermissiveModify creates a permissive modify control.
+func NewControlPermissiveModify() ldap.Control {
+	return ldap.NewControlString(ControlTypePermissiveModify, false, "")
+}
+
+// ControlTypeTreeDelete is the OID of the tree delete control, which deletes an entry together with all entries below it.
+const ControlTypeTreeDelete = "1.2.840.113556.1.4.805"
+
+// NewControlTreeDelete creates a tree delete control.
+func NewControlTreeDelete() ldap.Control {
+	return ldap.NewControlString(ControlTypeTreeDelete, true, "")
+}
+
+// SupportsControl checks whether the server advertises the given control in the supportedControl attribute of the
+// root DSE.
+func SupportsControl(conn *ldap.Conn, controlType string) (bool, error) {
+	rootDSE, err := GetEntry(conn, "", "supportedControl")
+	if err != nil {
+		return false, err
+	}
+	for _, supported := range rootDSE.GetAttributeValues("supportedControl") {
+		if supported == controlType {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ErrControlNotSupported is returned by controlSupport.check for controls the server doesn't advertise.
+var ErrControlNotSupported = errors.New("control not supported")
+
+// ControlTypeVLV is the OID of the virtual list view control (draft-ietf-ldapext-ldapv3-vlv).
+const ControlTypeVLV = "2.16.840.1.113730.3.4.9"
+
+// controlNames names controls which are commonly requested in the errors of controlSupport.check.
+var controlNames = map[string]string{
+	ldap.ControlTypePaging:               "paged results",
+	ldap.ControlTypeServerSideSorting:    "server side sorting",
+	ldap.ControlTypeManageDsaIT:          "manage DSA IT",
+	ControlTypeVLV:                       "virtual list view",
+	ControlTypeTreeDelete:                "tree delete",
+	ControlTypePermissiveModify:          "permissive modify",
+	ControlTypeRelax:                     "relax rules",
+	ldap.ControlTypeBeheraPasswordPolicy: "password policy",
+}
+
+// controlSupport checks controls against the supportedControl attribute of the root DSE before they are sent, if
+// ldap_check_control_support is set. The root DSE is read once and shared between all resources.
+type controlSupport struct {
+	enabled bool
+	// ignored lists controls which are treated as unsupported even if the server advertises them, so tests can use
+	// the fallbacks of optional controls.
+	ignored   []string
+	once      sync.Once
+	supported []string
+	err       error
+}
+
+// load reads the supported controls from the root DSE once.
+func (c *controlSupport) load(conn *ldap.Conn) {
+	c.once.Do(func() {
+		var rootDSE ldap.Entry
+		rootDSE, c.err = GetEntry(conn, "", "supportedControl")
+		for _, controlType := range rootDSE.GetAttributeValues("supportedControl") {
+			if !funk.ContainsString(c.ignored, controlType) {
+				c.supported = append(c.supported, controlType)
+			}
+		}
+	})
+}
+
+// supports checks whether the server advertises the control, regardless of ldap_check_control_support. It is used to
+// decide whether optional controls like the tree delete control can be sent.
+func (c *controlSupport) supports(conn *ldap.Conn, controlType string) (bool, error) {
+	if c == nil {
+		return SupportsControl(conn, controlType)
+	}
+	c.load(conn)
+	if c.err != nil {
+		return false, c.err
+	}
+	return funk.ContainsString(c.supported, controlType), nil
+}
+
+// check returns ErrControlNotSupported if the server doesn't advertise one of the controls. Nothing is checked if the
+// checks aren't enabled.
+func (c *controlSupport) check(conn *ldap.Conn, controls []ldap.Control) error {
+	if c == nil || !c.enabled || len(controls) == 0 {
+		return nil
+	}
+	c.load(conn)
+	if c.err != nil {
+		return fmt.Errorf("can not read the supported controls from the root DSE: %w", c.err)
+	}
+
+	var unsupported []string
+	for _, control := range controls {
+		if controlType := control.GetControlType(); !funk.ContainsString(c.supported, controlType) {
+			if name, ok := controlNames[controlType]; ok {
+				controlType = fmt.Sprintf("%s (%s)", controlType, name)
+			}
+			unsupported = append(unsupported, controlType)
+		}
+	}
+	if len(unsupported) > 0 {
+		return fmt.Errorf("%w: the server doesn't advertise %s in the supportedControl attribute of its root DSE", ErrControlNotSupported, strings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
+// addControlSupportError reports an error of controlSupport.check.
+func addControlSupportError(diagnostics *diag.Diagnostics, err error) {
+	diagnostics.AddError(
+		"Control not supported",
+		fmt.Sprintf("%s. Remove the control or unset ldap_check_control_support to send it anyway", err),
+	)
+}
+
+// PermissiveModify makes adding existing values and deleting missing values of the modify request succeed. The
+// permissive modify control is used if the server supports it, otherwise these values are removed from the request
+// after reading the current values of the entry. Unlike the control, this isn't atomic.
+func PermissiveModify(ctx context.Context, conn *ldap.Conn, r *ldap.ModifyRequest, rules map[string]string) error {
+	if supported, err := SupportsControl(conn, ControlTypePermissiveModify); err != nil {
+		return err
+	} else if supported {
+		r.Controls = append(r.Controls, NewControlPermissiveModify())
+		return nil
+	}
+
+	var attributeTypes []string
+	for _, change := range r.Changes {
+		attributeTypes = append(attributeTypes, change.Modification.Type)
+	}
+	start := time.Now()
+	entry, err := GetEntry(conn, r.DN, attributeTypes...)
+	LogOperation(ctx, "search", r.DN, start)
+	if err != nil {
+		return err
+	}
+	r.Changes = permissiveChanges(r.Changes, entry, rules)
+	return nil
+}
+
+// permissiveChanges removes the values from the changes, which are already present in the entry when they are added
+// or missing when they are deleted. Changes left without values are dropped.
+func permissiveChanges(changes []ldap.Change, entry ldap.Entry, rules map[string]string) []ldap.Change {
+	var result []ldap.Change
+	for _, change := range changes {
+		attributeType := change.Modification.Type
+		current := entry.GetEqualFoldAttributeValues(attributeType)
+		rule := lookupMatchingRule(attributeType, rules)
+		switch change.Operation {
+		case ldap.AddAttribute:
+			if values := subtractValues(rule, change.Modification.Vals, current); len(values) > 0 {
+				result = append(result, ldap.Change{Operation: change.Operation, Modification: ldap.PartialAttribute{Type: attributeType, Vals: values}})
+			}
+		case ldap.DeleteAttribute:
+			if len(current) == 0 {
+				continue
+			}
+			if len(change.Modification.Vals) == 0 {
+				result = append(result, change)
+			} else if values := subtractValues(rule, change.Modification.Vals, subtractValues(rule, change.Modification.Vals, current)); len(values) > 0 {
+				result = append(result, ldap.Change{Operation: change.Operation, Modification: ldap.PartialAttribute{Type: attributeType, Vals: values}})
+			}
+		default:
+			result = append(result, change)
+		}
+	}
+	return result
+}
+
+// controlsDescriptions holds the descriptions of the controls attribute and its nested attributes, which is shared by
+// resources and data sources.
+var controlsDescriptions = map[string]string{
+	"controls":    "Additional controls sent with every request, given by their OID, an optional base64 encoded value and their criticality",
+	"oid":         "OID of the control",
+	"value":       "Base64 encoded value of the control",
+	"criticality": "Whether the server has to reject the request if it doesn't support the control",
+}
+
+// controlsResourceAttribute returns the schema of the controls attribute of resources, read by BuildControls.
+func controlsResourceAttribute() resourceschema.ListNestedAttribute {
+	return resourceschema.ListNestedAttribute{
+		MarkdownDescription: controlsDescriptions["controls"],
+		Optional:            true,
+		NestedObject: resourceschema.NestedAttributeObject{
+			Attributes: map[string]resourceschema.Attribute{
+				"oid": resourceschema.StringAttribute{
+					MarkdownDescription: controlsDescriptions["oid"],
+					Required:            true,
+				},
+				"value": resourceschema.StringAttribute{
+					MarkdownDescription: controlsDescriptions["value"],
+					Optional:            true,
+				},
+				"criticality": resourceschema.BoolAttribute{
+					MarkdownDescription: controlsDescriptions["criticality"],
+					Optional:            true,
+				},
+			},
+		},
+	}
+}
+
+// controlsDataSourceAttribute returns the schema of the controls attribute of data sources, read by BuildControls.
+func controlsDataSourceAttribute() datasourceschema.ListNestedAttribute {
+	return datasourceschema.ListNestedAttribute{
+		MarkdownDescription: controlsDescriptions["controls"],
+		Optional:            true,
+		NestedObject: datasourceschema.NestedAttributeObject{
+			Attributes: map[string]datasourceschema.Attribute{
+				"oid": datasourceschema.StringAttribute{
+					MarkdownDescription: controlsDescriptions["oid"],
+					Required:            true,
+				},
+				"value": datasourceschema.StringAttribute{
+					MarkdownDescription: controlsDescriptions["value"],
+					Optional:            true,
+				},
+				"criticality": datasourceschema.BoolAttribute{
+					MarkdownDescription: controlsDescriptions["criticality"],
+					Optional:            true,
+				},
+			},
+		},
+	}
+}
+
+// BuildControls converts the configured controls to LDAP controls. The values of the controls are base64 encoded.
+func BuildControls(ctx context.Context, controls types.List, diagnostics *diag.Diagnostics) []ldap.Control {
+	var models []LDAPControlModel
+	diagnostics.Append(controls.ElementsAs(ctx, &models, false)...)
+
+	var built []ldap.Control
+	for i, model := range models {
+		value, err := base64.StdEncoding.DecodeString(model.Value.ValueString())
+		if err != nil {
+			diagnostics.AddAttributeError(
+				path.Root("controls").AtListIndex(i).AtName("value"),
+				"Invalid base64 value",
+				fmt.Sprintf("Can not decode value of control %s: %s", model.OID.ValueString(), err),
+			)
+			continue
+		}
+		built = append(built, ldap.NewControlString(model.OID.ValueString(), model.Criticality.ValueBool(), string(value)))
+	}
+	return built
+}
+
+// ControlTypeRelax is the OID of the relax rules control, which allows setting attributes that are usually
+// maintained by the server.
+const ControlTypeRelax = "1.3.6.1.4.1.4203.666.5.12"
+
+// NewControlRelax creates a relax rules control.
+func NewControlRelax() ldap.Control {
+	return ldap.NewControlString(ControlTypeRelax, true, "")
+}
+
+// ControlTypeDontUseCopy is the OID of the don't use copy control (RFC 6171), which makes the server answer from the
+// original data instead of a possibly outdated copy, or return a referral or an error.
+const ControlTypeDontUseCopy = "1.3.6.1.1.22"
+
+// NewControlDontUseCopy creates a don't use copy control, which has to be critical.
+func NewControlDontUseCopy() ldap.Control {
+	return ldap.NewControlString(ControlTypeDontUseCopy, true, "")
+}
+
+// ControlTypeAssertion is the OID of the assertion control (RFC 4528), which makes the server only perform an
+// operation if the entry matches the filter.
+const ControlTypeAssertion = "1.3.6.1.1.12"
+
+// NewControlAssertion creates a critical assertion control with the BER encoded filter as its value.
+func NewControlAssertion(filter string) (ldap.Control, error) {
+	packet, err := ldap.CompileFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	return ldap.NewControlString(ControlTypeAssertion, true, string(packet.Bytes())), nil
+}
+
+// ControlTypePreRead and ControlTypePostRead are the OIDs of the read entry controls (RFC 4527), which make the server
+// return the entry as it was before or after the operation in the response.
+const (
+	ControlTypePreRead  = "1.3.6.1.1.13.1"
+	ControlTypePostRead = "1.3.6.1.1.13.2"
+)
+
+// NewControlReadEntry creates a non-critical pre-read or post-read control requesting the given attributes. Servers
+// which don't support the control ignore it.
+func NewControlReadEntry(controlType string, attributes []string) ldap.Control {
+	selection := ber.NewSequence("AttributeSelection")
+	for _, attribute := range attributes {
+		selection.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, attribute, "Attribute"))
+	}
+	return ldap.NewControlString(controlType, false, string(selection.Bytes()))
+}
+
+// DecodeReadEntryControl decodes the entry returned in a pre-read or post-read response control, which is encoded
+// like a search result entry.
+func DecodeReadEntryControl(control ldap.Control) (map[string][]string, error) {
+	controlString, ok := control.(*ldap.ControlString)
+	if !ok {
+		return nil, fmt.Errorf("unexpected control %s", control.GetControlType())
+	}
+	packet, err := ber.DecodePacketErr([]byte(controlString.ControlValue))
+	if err != nil {
+		return nil, err
+	}
+	if len(packet.Children) != 2 {
+		return nil, errors.New("the control doesn't contain an entry")
+	}
+
+	attributes := map[string][]string{}
+	for _, attribute := range packet.Children[1].Children {
+		if len(attribute.Children) != 2 {
+			return nil, errors.New("the entry contains an invalid attribute")
+		}
+		attributeType := attribute.Children[0].Data.String()
+		attributes[attributeType] = []string{}
+		for _, value := range attribute.Children[1].Children {
+			attributes[attributeType] = append(attributes[attributeType], value.Data.String())
+		}
+	}
+	return attributes, nil
+}
+
+// BindWithPasswordPolicy binds like conn.Bind, but requests the password policy control. If the bind is refused by the
+// password policy, e.g. because the password expired or the account is locked, the reason is added to the error. The
+// returned control contains warnings like the time until the password expires, it is nil if the server didn't send it.
+// Additional controls are sent with the bind request, the response controls are returned for them.
+func BindWithPasswordPolicy(conn *ldap.Conn, dn string, password string, controls ...ldap.Control) (*ldap.ControlBeheraPasswordPolicy, []ldap.Control, error) {
+	result, err := conn.SimpleBind(&ldap.SimpleBindRequest{
+		Username: dn,
+		Password: password,
+		Controls: append([]ldap.Control{ldap.NewControlBeheraPasswordPolicy()}, controls...),
+	})
+
+	var policy *ldap.ControlBeheraPasswordPolicy
+	var responseControls []ldap.Control
+	if result != nil {
+		policy, _ = ldap.FindControl(result.Controls, ldap.ControlTypeBeheraPasswordPolicy).(*ldap.ControlBeheraPasswordPolicy)
+		responseControls = result.Controls
+	}
+	return policy, responseControls, passwordPolicyError(err, policy)
+}
+
+// passwordPolicyError adds the reason of the password policy response control to the error of an operation.
+func passwordPolicyError(err error, policy *ldap.ControlBeheraPasswordPolicy) error {
+	if err == nil || policy == nil || policy.Error < 0 {
+		return err
+	}
+	return fmt.Errorf("%w (password policy: %s)", err, strings.ToLower(policy.ErrorString))
+}
+
+// passwordPolicyWarnings describes the warnings of a password policy response control returned for a successful bind.
+func passwordPolicyWarnings(policy *ldap.ControlBeheraPasswordPolicy) []string {
+	if policy == nil {
+		return nil
+	}
+	var warnings []string
+	if policy.Expire >= 0 {
+		warnings = append(warnings, fmt.Sprintf("the password expires in %s", time.Duration(policy.Expire)*time.Second))
+	}
+	if policy.Grace >= 0 {
+		warnings = append(warnings, fmt.Sprintf("the password expired, %d grace logins remaining", policy.Grace))
+	}
+	if policy.Error >= 0 {
+		warnings = append(warnings, strings.ToLower(policy.ErrorString))
+	}
+	return warnings
+}
+
+// ControlTypeAuthzIDRequest and ControlTypeAuthzIDResponse are the OIDs of the authorization identity controls
+// (RFC 3829), which make the server return the authorization identity established by a bind in its response.
+const (
+	ControlTypeAuthzIDRequest  = "2.16.840.1.113730.3.4.16"
+	ControlTypeAuthzIDResponse = "2.16.840.1.113730.3.4.15"
+)
+
+// NewControlAuthzIDRequest creates a non-critical authorization identity request control, which has no value.
+func NewControlAuthzIDRequest() ldap.Control {
+	return ldap.NewControlString(ControlTypeAuthzIDRequest, false, "")
+}
+
+// FindAuthzID returns the authorization identity of an authorization identity response control, e.g.
+// "dn:cn=admin,dc=example,dc=com". The identity is empty for anonymous binds, ok is false if the control is missing.
+func FindAuthzID(controls []ldap.Control) (authzID string, ok bool) {
+	control, ok := ldap.FindControl(controls, ControlTypeAuthzIDResponse).(*ldap.ControlString)
+	if !ok {
+		return "", false
+	}
+	return control.ControlValue, true
+}
@@ -0,0 +1,212 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func testControlsList(controls ...LDAPControlModel) types.List {
+	controlType := types.ObjectType{AttrTypes: map[string]attr.Type{
+		"oid":         types.StringType,
+		"value":       types.StringType,
+		"criticality": types.BoolType,
+	}}
+	list, _ := types.ListValueFrom(context.Background(), controlType, controls)
+	return list
+}
+
+func TestBuildControls(t *testing.T) {
+	var diagnostics diag.Diagnostics
+	controls := BuildControls(context.Background(), testControlsList(
+		LDAPControlModel{OID: types.StringValue("1.2.3.4"), Value: types.StringValue("dmFsdWU="), Criticality: types.BoolValue(true)},
+		LDAPControlModel{OID: types.StringValue("1.2.3.5"), Value: types.StringNull(), Criticality: types.BoolNull()},
+	), &diagnostics)
+	assert.False(t, diagnostics.HasError())
+	assert.Len(t, controls, 2)
+
+	// the control is encoded as it is sent to the server
+	decoded, err := ldap.DecodeControl(controls[0].Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3.4", decoded.GetControlType())
+	assert.Equal(t, "value", decoded.(*ldap.ControlString).ControlValue)
+	assert.True(t, decoded.(*ldap.ControlString).Criticality)
+
+	decoded, err = ldap.DecodeControl(controls[1].Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3.5", decoded.GetControlType())
+	assert.False(t, decoded.(*ldap.ControlString).Criticality)
+
+	diagnostics = diag.Diagnostics{}
+	BuildControls(context.Background(), testControlsList(
+		LDAPControlModel{OID: types.StringValue("1.2.3.4"), Value: types.StringValue("not base64!"), Criticality: types.BoolNull()},
+	), &diagnostics)
+	assert.True(t, diagnostics.HasError())
+}
+
+func TestPermissiveChanges(t *testing.T) {
+	entry := ldap.NewEntry("cn=test,dc=example,dc=com", map[string][]string{
+		"description": {"first", "second"},
+		"mail":        {"Test@example.com"},
+	})
+	changes := []ldap.Change{
+		{Operation: ldap.AddAttribute, Modification: ldap.PartialAttribute{Type: "description", Vals: []string{"second", "third"}}},
+		{Operation: ldap.AddAttribute, Modification: ldap.PartialAttribute{Type: "mail", Vals: []string{"test@example.com"}}},
+		{Operation: ldap.DeleteAttribute, Modification: ldap.PartialAttribute{Type: "description", Vals: []string{"first", "missing"}}},
+		{Operation: ldap.DeleteAttribute, Modification: ldap.PartialAttribute{Type: "description", Vals: []string{"missing"}}},
+		{Operation: ldap.DeleteAttribute, Modification: ldap.PartialAttribute{Type: "telephoneNumber", Vals: []string{}}},
+		{Operation: ldap.DeleteAttribute, Modification: ldap.PartialAttribute{Type: "Mail", Vals: []string{}}},
+		{Operation: ldap.ReplaceAttribute, Modification: ldap.PartialAttribute{Type: "sn", Vals: []string{"test"}}},
+	}
+
+	assert.Equal(t, []ldap.Change{
+		{Operation: ldap.AddAttribute, Modification: ldap.PartialAttribute{Type: "description", Vals: []string{"third"}}},
+		{Operation: ldap.DeleteAttribute, Modification: ldap.PartialAttribute{Type: "description", Vals: []string{"first"}}},
+		{Operation: ldap.DeleteAttribute, Modification: ldap.PartialAttribute{Type: "Mail", Vals: []string{}}},
+		{Operation: ldap.ReplaceAttribute, Modification: ldap.PartialAttribute{Type: "sn", Vals: []string{"test"}}},
+	}, permissiveChanges(changes, *entry, nil))
+}
+
+func TestNewControlDontUseCopy(t *testing.T) {
+	decoded, err := ldap.DecodeControl(NewControlDontUseCopy().Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, ControlTypeDontUseCopy, decoded.GetControlType())
+	assert.True(t, decoded.(*ldap.ControlString).Criticality)
+}
+
+func TestNewControlAssertion(t *testing.T) {
+	control, err := NewControlAssertion("(entryCSN=20240101000000.000000Z#000000#000#000000)")
+	assert.NoError(t, err)
+	decoded, err := ldap.DecodeControl(control.Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, ControlTypeAssertion, decoded.GetControlType())
+	assert.True(t, decoded.(*ldap.ControlString).Criticality)
+
+	// the value is the BER encoded filter
+	filter, err := ldap.CompileFilter("(entryCSN=20240101000000.000000Z#000000#000#000000)")
+	assert.NoError(t, err)
+	assert.Equal(t, string(filter.Bytes()), decoded.(*ldap.ControlString).ControlValue)
+
+	_, err = NewControlAssertion("(entryCSN=")
+	assert.Error(t, err)
+}
+
+func TestReadEntryControl(t *testing.T) {
+	control := NewControlReadEntry(ControlTypePostRead, []string{"sn", "entryCSN"})
+	decoded, err := ldap.DecodeControl(control.Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, ControlTypePostRead, decoded.GetControlType())
+	assert.False(t, decoded.(*ldap.ControlString).Criticality)
+	selection := ber.DecodePacket([]byte(decoded.(*ldap.ControlString).ControlValue))
+	if assert.Len(t, selection.Children, 2) {
+		assert.Equal(t, "sn", selection.Children[0].Value)
+		assert.Equal(t, "entryCSN", selection.Children[1].Value)
+	}
+
+	// the response contains the entry like a search result entry
+	entry := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Entry")
+	entry.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "cn=test,dc=example,dc=com", "DN"))
+	attributes := ber.NewSequence("Attributes")
+	attribute := ber.NewSequence("Attribute")
+	attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "sn", "Type"))
+	values := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+	values.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "first", "Value"))
+	values.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "second", "Value"))
+	attribute.AppendChild(values)
+	attributes.AppendChild(attribute)
+	entry.AppendChild(attributes)
+
+	read, err := DecodeReadEntryControl(ldap.NewControlString(ControlTypePostRead, false, string(entry.Bytes())))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{"sn": {"first", "second"}}, read)
+
+	_, err = DecodeReadEntryControl(ldap.NewControlString(ControlTypePostRead, false, "invalid"))
+	assert.Error(t, err)
+}
+
+func TestPasswordPolicyError(t *testing.T) {
+	bindErr := ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+	expired := ldap.NewControlBeheraPasswordPolicy()
+	expired.Error = 0
+	expired.ErrorString = ldap.BeheraPasswordPolicyErrorMap[0]
+
+	err := passwordPolicyError(bindErr, expired)
+	assert.ErrorContains(t, err, "(password policy: password expired)")
+	assert.True(t, ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials))
+
+	assert.Equal(t, bindErr, passwordPolicyError(bindErr, ldap.NewControlBeheraPasswordPolicy()))
+	assert.Equal(t, bindErr, passwordPolicyError(bindErr, nil))
+	assert.NoError(t, passwordPolicyError(nil, expired))
+}
+
+func TestPasswordPolicyWarnings(t *testing.T) {
+	assert.Empty(t, passwordPolicyWarnings(nil))
+	assert.Empty(t, passwordPolicyWarnings(ldap.NewControlBeheraPasswordPolicy()))
+
+	policy := ldap.NewControlBeheraPasswordPolicy()
+	policy.Expire = 3600
+	assert.Equal(t, []string{"the password expires in 1h0m0s"}, passwordPolicyWarnings(policy))
+
+	policy = ldap.NewControlBeheraPasswordPolicy()
+	policy.Grace = 2
+	policy.Error = 2
+	policy.ErrorString = ldap.BeheraPasswordPolicyErrorMap[2]
+	assert.Equal(t, []string{"the password expired, 2 grace logins remaining", "password must be changed"}, passwordPolicyWarnings(policy))
+}
+
+func TestControlSupport(t *testing.T) {
+	c := &controlSupport{enabled: true}
+	c.once.Do(func() {
+		c.supported = []string{ldap.ControlTypePaging, ControlTypeTreeDelete}
+	})
+	assert.NoError(t, c.check(nil, []ldap.Control{ldap.NewControlPaging(10), NewControlTreeDelete()}))
+	err := c.check(nil, []ldap.Control{ldap.NewControlPaging(10), ldap.NewControlString(ControlTypeVLV, false, ""), ldap.NewControlString("1.2.3.4", false, "")})
+	assert.ErrorIs(t, err, ErrControlNotSupported)
+	assert.ErrorContains(t, err, "2.16.840.1.113730.3.4.9 (virtual list view), 1.2.3.4")
+
+	// nothing is checked unless enabled
+	assert.NoError(t, (&controlSupport{}).check(nil, []ldap.Control{NewControlTreeDelete()}))
+	var unconfigured *controlSupport
+	assert.NoError(t, unconfigured.check(nil, []ldap.Control{NewControlTreeDelete()}))
+
+	// optional controls are looked up even if the checks aren't enabled
+	c = &controlSupport{}
+	c.once.Do(func() {
+		c.supported = []string{ControlTypeTreeDelete}
+	})
+	supported, err := c.supports(nil, ControlTypeTreeDelete)
+	assert.NoError(t, err)
+	assert.True(t, supported)
+	supported, err = c.supports(nil, ControlTypeRelax)
+	assert.NoError(t, err)
+	assert.False(t, supported)
+}
+
+func TestFindAuthzID(t *testing.T) {
+	request, err := ldap.DecodeControl(NewControlAuthzIDRequest().Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, ControlTypeAuthzIDRequest, request.GetControlType())
+	assert.False(t, request.(*ldap.ControlString).Criticality)
+
+	// the response control contains the identity as its value, which is missing for anonymous binds
+	response, err := ldap.DecodeControl(ldap.NewControlString(ControlTypeAuthzIDResponse, false, "dn:cn=admin,dc=example,dc=com").Encode())
+	assert.NoError(t, err)
+	authzID, ok := FindAuthzID([]ldap.Control{ldap.NewControlBeheraPasswordPolicy(), response})
+	assert.True(t, ok)
+	assert.Equal(t, "dn:cn=admin,dc=example,dc=com", authzID)
+
+	authzID, ok = FindAuthzID([]ldap.Control{ldap.NewControlString(ControlTypeAuthzIDResponse, false, "")})
+	assert.True(t, ok)
+	assert.Empty(t, authzID)
+
+	_, ok = FindAuthzID([]ldap.Control{ldap.NewControlBeheraPasswordPolicy()})
+	assert.False(t, ok)
+	_, ok = FindAuthzID(nil)
+	assert.False(t, ok)
+}
@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"strings"
+)
+
+// addOperationError adds a diagnostic for a failed LDAP operation. If the operation was aborted because the timeout
+// was exceeded or the provider is read-only, the diagnostic names the phase and DN instead. Common result codes are explained in Terraform terms,
+// together with the given hints.
+func addOperationError(diagnostics *diag.Diagnostics, err error, phase string, dn string, summary string, detail string, hints ...string) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		diagnostics.AddError(
+			"Timeout exceeded",
+			fmt.Sprintf("The %s timeout was exceeded while processing %s", phase, dn),
+		)
+	} else if errors.Is(err, ErrReadOnly) {
+		diagnostics.AddError(
+			"Provider is configured read_only",
+			fmt.Sprintf("Writing %s during %s was refused without contacting the server, since ldap_read_only is set. Unset it to allow changes", dn, phase),
+		)
+	} else if friendlySummary, explanation, ok := explainLDAPError(err, dn); ok {
+		parts := []string{explanation}
+		for _, hint := range hints {
+			if hint != "" {
+				parts = append(parts, hint)
+			}
+		}
+		parts = append(parts, fmt.Sprintf("LDAP server reported: %s", err))
+		diagnostics.AddError(fmt.Sprintf("%s: %s", summary, friendlySummary), strings.Join(parts, "\n\n"))
+	} else {
+		diagnostics.AddError(summary, detail)
+	}
+}
+
+// explainLDAPError returns a summary and an explanation of the likely cause for common LDAP result codes.
+func explainLDAPError(err error, dn string) (string, string, bool) {
+	var ldapError *ldap.Error
+	if !errors.As(err, &ldapError) {
+		return "", "", false
+	}
+
+	switch ldapError.ResultCode {
+	case ldap.LDAPResultEntryAlreadyExists:
+		return "Entry already exists",
+			fmt.Sprintf("An entry with the DN %s already exists on the server. Import it into the state or choose another DN", dn),
+			true
+	case ldap.LDAPResultObjectClassViolation:
+		return "Object class violation",
+			"The attributes of the entry don't match its object classes. Check that all attributes required by the object classes are set, that all attributes are allowed by one of them and that the entry has a single structural object class",
+			true
+	case ldap.LDAPResultConstraintViolation:
+		return "Constraint violation",
+			"A value was rejected by a constraint of the server, e.g. because a single-valued attribute has more than one value, a value is too long or a password policy rejected it",
+			true
+	case ldap.LDAPResultInsufficientAccessRights:
+		return "Insufficient access rights",
+			fmt.Sprintf("The bind DN of the provider isn't allowed to perform this operation on %s. Check the access control configuration of the server", dn),
+			true
+	case ldap.LDAPResultAssertionFailed:
+		return "Entry was changed concurrently",
+			fmt.Sprintf("The entry %s changed since the plan or doesn't match modify_assertion anymore, so the changes weren't applied. Re-run terraform plan to apply the changes on top of the current entry", dn),
+			true
+	case ldap.LDAPResultInvalidDNSyntax:
+		return "Invalid DN syntax",
+			fmt.Sprintf("The DN %s is not valid. Check its syntax and escape special characters like commas with a backslash", dn),
+			true
+	}
+	return "", "", false
+}
@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAddOperationError(t *testing.T) {
+	var diagnostics diag.Diagnostics
+	addOperationError(&diagnostics, fmt.Errorf("wrapped: %w", context.DeadlineExceeded), "create", "cn=test,dc=example,dc=com", "Can not add resource", "detail")
+	assert.Equal(t, "Timeout exceeded", diagnostics[0].Summary())
+	assert.Contains(t, diagnostics[0].Detail(), "The create timeout was exceeded while processing cn=test,dc=example,dc=com")
+
+	diagnostics = diag.Diagnostics{}
+	err := ldap.NewError(ldap.LDAPResultObjectClassViolation, errors.New("object class 'person' requires attribute 'sn'"))
+	addOperationError(&diagnostics, err, "create", "cn=test,dc=example,dc=com", "Can not add resource", "detail", "", "These attributes are required by the object classes, but are not set: sn")
+	assert.Equal(t, "Can not add resource: Object class violation", diagnostics[0].Summary())
+	assert.Contains(t, diagnostics[0].Detail(), "These attributes are required by the object classes, but are not set: sn")
+	assert.Contains(t, diagnostics[0].Detail(), "LDAP server reported:")
+
+	diagnostics = diag.Diagnostics{}
+	addOperationError(&diagnostics, ErrReadOnly, "update", "cn=test,dc=example,dc=com", "Can not modify resource", "detail")
+	assert.Equal(t, "Provider is configured read_only", diagnostics[0].Summary())
+	assert.Contains(t, diagnostics[0].Detail(), "Writing cn=test,dc=example,dc=com during update was refused")
+
+	diagnostics = diag.Diagnostics{}
+	addOperationError(&diagnostics, errors.New("connection closed"), "create", "cn=test,dc=example,dc=com", "Can not add resource", "detail")
+	assert.Equal(t, "Can not add resource", diagnostics[0].Summary())
+	assert.Equal(t, "detail", diagnostics[0].Detail())
+}
+
+func TestExplainLDAPError(t *testing.T) {
+	for resultCode, summary := range map[uint16]string{
+		ldap.LDAPResultEntryAlreadyExists:       "Entry already exists",
+		ldap.LDAPResultObjectClassViolation:     "Object class violation",
+		ldap.LDAPResultConstraintViolation:      "Constraint violation",
+		ldap.LDAPResultInsufficientAccessRights: "Insufficient access rights",
+		ldap.LDAPResultInvalidDNSyntax:          "Invalid DN syntax",
+	} {
+		explainedSummary, explanation, ok := explainLDAPError(ldap.NewError(resultCode, errors.New("error")), "cn=test,dc=example,dc=com")
+		assert.True(t, ok)
+		assert.Equal(t, summary, explainedSummary)
+		assert.NotEmpty(t, explanation)
+	}
+
+	_, _, ok := explainLDAPError(ldap.NewError(ldap.LDAPResultBusy, errors.New("busy")), "cn=test,dc=example,dc=com")
+	assert.False(t, ok)
+}
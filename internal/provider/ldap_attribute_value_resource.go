@@ -0,0 +1,228 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"strings"
+	"time"
+)
+
+var _ resource.Resource = &LDAPAttributeValueResource{}
+var _ resource.ResourceWithConfigure = &LDAPAttributeValueResource{}
+var _ resource.ResourceWithImportState = &LDAPAttributeValueResource{}
+
+func NewLDAPAttributeValueResource() resource.Resource {
+	return &LDAPAttributeValueResource{}
+}
+
+type LDAPAttributeValueResource struct {
+	conn  *ldap.Conn
+	locks *dnLocks
+}
+
+type LDAPAttributeValueResourceModel struct {
+	ID            types.String `tfsdk:"id"`
+	DN            types.String `tfsdk:"dn"`
+	Attribute     types.String `tfsdk:"attribute"`
+	Value         types.String `tfsdk:"value"`
+	AllowExisting types.Bool   `tfsdk:"allow_existing"`
+}
+
+// attributeValueIDSeparator separates the DN, the attribute and the value in the id of ldap_attribute_value.
+const attributeValueIDSeparator = "|"
+
+func (L *LDAPAttributeValueResource) Configure(_ context.Context, request resource.ConfigureRequest, response *resource.ConfigureResponse) {
+	if request.ProviderData == nil {
+		return
+	}
+
+	if client, ok := request.ProviderData.(*ldapClient); !ok {
+		response.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ldapClient, got: %T. Please report this issue to the provider developers.", request.ProviderData),
+		)
+
+		return
+	} else {
+		L.conn = client.conn
+		L.locks = client.locks
+	}
+}
+
+func (L *LDAPAttributeValueResource) Metadata(_ context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_attribute_value"
+}
+
+func (L *LDAPAttributeValueResource) Schema(_ context.Context, _ resource.SchemaRequest, response *resource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		MarkdownDescription: "Manages a single value of an attribute of an entry, which is otherwise managed elsewhere, e.g. an " +
+			"`sshPublicKey` of a user shared by several configurations. Other values of the attribute are left untouched",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Resource identifier, the DN, the attribute and the value separated by `|`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"dn": schema.StringAttribute{
+				MarkdownDescription: "DN of the entry",
+				Required:            true,
+				Validators: []validator.String{
+					IsValidDN(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"attribute": schema.StringAttribute{
+				MarkdownDescription: "Type of the attribute",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"value": schema.StringAttribute{
+				MarkdownDescription: "The value added to the attribute",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"allow_existing": schema.BoolAttribute{
+				MarkdownDescription: "Whether creating the resource succeeds if the attribute already contains the value. The value is still removed when the resource is destroyed",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+func (L *LDAPAttributeValueResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	var data *LDAPAttributeValueResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	dn := data.DN.ValueString()
+	data.ID = types.StringValue(strings.Join([]string{dn, data.Attribute.ValueString(), data.Value.ValueString()}, attributeValueIDSeparator))
+
+	r := ldap.NewModifyRequest(dn, []ldap.Control{})
+	r.Add(data.Attribute.ValueString(), []string{data.Value.ValueString()})
+	start := time.Now()
+	err := WithContext(ctx, func() error {
+		return L.locks.write(ctx, dn, func() error {
+			return L.conn.Modify(r)
+		})
+	})
+	LogOperation(ctx, "modify", dn, start)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultAttributeOrValueExists) && data.AllowExisting.ValueBool() {
+		tflog.Info(ctx, "Attribute already contains the value", map[string]interface{}{"dn": dn, "attribute": data.Attribute.ValueString()})
+	} else if ldap.IsErrorWithCode(err, ldap.LDAPResultAttributeOrValueExists) {
+		response.Diagnostics.AddError(
+			"Value already exists",
+			fmt.Sprintf("The attribute %s of %s already contains the value. Set allow_existing to manage the existing value", data.Attribute.ValueString(), dn),
+		)
+		return
+	} else if err != nil {
+		addOperationError(&response.Diagnostics, err, "create", dn,
+			"Can not add value",
+			fmt.Sprintf("Trying to add a value to the attribute %s of %s returned: %s", data.Attribute.ValueString(), dn, err),
+		)
+		return
+	}
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+// Read checks the value with a compare operation, so the server applies the equality matching rule of the attribute.
+func (L *LDAPAttributeValueResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	var data *LDAPAttributeValueResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	dn := data.DN.ValueString()
+	var exists bool
+	start := time.Now()
+	err := WithContext(ctx, func() (err error) {
+		exists, err = L.conn.Compare(dn, data.Attribute.ValueString(), data.Value.ValueString())
+		return
+	})
+	LogOperation(ctx, "compare", dn, start)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		tflog.Warn(ctx, "Entry was deleted outside of Terraform, removing the value from the state", map[string]interface{}{"dn": dn})
+		response.State.RemoveResource(ctx)
+		return
+	} else if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchAttribute) {
+		addOperationError(&response.Diagnostics, err, "read", dn,
+			"Can not compare value",
+			fmt.Sprintf("Trying to check whether the attribute %s of %s contains the value returned: %s", data.Attribute.ValueString(), dn, err),
+		)
+		return
+	}
+	if !exists {
+		tflog.Warn(ctx, "Value was removed outside of Terraform, removing it from the state", map[string]interface{}{"dn": dn, "attribute": data.Attribute.ValueString()})
+		response.State.RemoveResource(ctx)
+		return
+	}
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPAttributeValueResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
+	// only allow_existing can change, which is only used on create
+	var data *LDAPAttributeValueResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &data)...)
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPAttributeValueResource) Delete(ctx context.Context, request resource.DeleteRequest, response *resource.DeleteResponse) {
+	var data *LDAPAttributeValueResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	dn := data.DN.ValueString()
+	r := ldap.NewModifyRequest(dn, []ldap.Control{})
+	r.Delete(data.Attribute.ValueString(), []string{data.Value.ValueString()})
+	start := time.Now()
+	err := WithContext(ctx, func() error {
+		return L.locks.write(ctx, dn, func() error {
+			return L.conn.Modify(r)
+		})
+	})
+	LogOperation(ctx, "modify", dn, start)
+	if err != nil && !ldap.IsErrorAnyOf(err, ldap.LDAPResultNoSuchObject, ldap.LDAPResultNoSuchAttribute) {
+		addOperationError(&response.Diagnostics, err, "delete", dn,
+			"Can not remove value",
+			fmt.Sprintf("Trying to remove a value from the attribute %s of %s returned: %s", data.Attribute.ValueString(), dn, err),
+		)
+	}
+}
+
+// ImportState imports a value by the DN, the attribute and the value separated by |. The value may contain | itself.
+func (L *LDAPAttributeValueResource) ImportState(ctx context.Context, request resource.ImportStateRequest, response *resource.ImportStateResponse) {
+	parts := strings.SplitN(request.ID, attributeValueIDSeparator, 3)
+	if len(parts) != 3 {
+		response.Diagnostics.AddError(
+			"Invalid import id",
+			fmt.Sprintf("Expected the DN, the attribute and the value separated by %q, got: %s", attributeValueIDSeparator, request.ID),
+		)
+		return
+	}
+
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("id"), request.ID)...)
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("dn"), parts[0])...)
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("attribute"), parts[1])...)
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("value"), parts[2])...)
+}
@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"regexp"
+	"testing"
+)
+
+func TestLDAPAttributeValueResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAttributeValueConfig(`
+resource "ldap_attribute_value" "work" {
+	dn = ldap_object.carol.dn
+	attribute = "mail"
+	value = "carol@work.example.com"
+}`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_attribute_value.work", "id", "cn=carol,dc=example,dc=com|mail|carol@work.example.com"),
+					testCheckServerValues("cn=carol,dc=example,dc=com", "mail", []string{"carol@example.com", "carol@work.example.com"}),
+				),
+			},
+			{
+				Config: testAttributeValueConfig(`
+resource "ldap_attribute_value" "work" {
+	dn = ldap_object.carol.dn
+	attribute = "mail"
+	value = "carol@work.example.com"
+}`),
+				ResourceName:      "ldap_attribute_value.work",
+				ImportState:       true,
+				ImportStateId:     "cn=carol,dc=example,dc=com|mail|carol@work.example.com",
+				ImportStateVerify: true,
+			},
+			// A value removed by another writer is added again
+			{
+				PreConfig: testRemoveMemberExternally(t, "cn=carol,dc=example,dc=com", "mail", "carol@work.example.com"),
+				Config: testAttributeValueConfig(`
+resource "ldap_attribute_value" "work" {
+	dn = ldap_object.carol.dn
+	attribute = "mail"
+	value = "carol@work.example.com"
+}`),
+				Check: testCheckServerValues("cn=carol,dc=example,dc=com", "mail", []string{"carol@example.com", "carol@work.example.com"}),
+			},
+			// Existing values are only managed if allowed
+			{
+				Config: testAttributeValueConfig(`
+resource "ldap_attribute_value" "home" {
+	dn = ldap_object.carol.dn
+	attribute = "mail"
+	value = "carol@example.com"
+}`),
+				ExpectError: regexp.MustCompile("Value already exists"),
+			},
+			{
+				Config: testAttributeValueConfig(`
+resource "ldap_attribute_value" "home" {
+	dn = ldap_object.carol.dn
+	attribute = "mail"
+	value = "carol@example.com"
+	allow_existing = true
+}`),
+				Check: testCheckServerValues("cn=carol,dc=example,dc=com", "mail", []string{"carol@example.com"}),
+			},
+			// The value is removed on destroy, even though it existed before
+			{
+				Config: testAttributeValueConfig(""),
+				Check:  testCheckServerValues("cn=carol,dc=example,dc=com", "mail", []string{}),
+			},
+		},
+	})
+}
+
+// testAttributeValueConfig creates a person with a mail address, whose other addresses are managed elsewhere, together
+// with the given values.
+func testAttributeValueConfig(values string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "carol" {
+	dn = "cn=carol,dc=example,dc=com"
+	object_classes = ["inetOrgPerson"]
+	attributes = {
+		"cn" = ["carol"]
+		"sn" = ["carol"]
+		"mail" = ["carol@example.com"]
+	}
+	ignore_changes = ["mail"]
+}
+%s
+`, values)
+}
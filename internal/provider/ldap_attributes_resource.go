@@ -0,0 +1,243 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"time"
+)
+
+var _ resource.Resource = &LDAPAttributesResource{}
+var _ resource.ResourceWithConfigure = &LDAPAttributesResource{}
+
+func NewLDAPAttributesResource() resource.Resource {
+	return &LDAPAttributesResource{}
+}
+
+type LDAPAttributesResource struct {
+	conn  *ldap.Conn
+	locks *dnLocks
+}
+
+type LDAPAttributesResourceModel struct {
+	ID            types.String `tfsdk:"id"`
+	DN            types.String `tfsdk:"dn"`
+	Attributes    types.Map    `tfsdk:"attributes"`
+	KeepOnDestroy types.Bool   `tfsdk:"keep_on_destroy"`
+}
+
+func (L *LDAPAttributesResource) Configure(_ context.Context, request resource.ConfigureRequest, response *resource.ConfigureResponse) {
+	if request.ProviderData == nil {
+		return
+	}
+
+	if client, ok := request.ProviderData.(*ldapClient); !ok {
+		response.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ldapClient, got: %T. Please report this issue to the provider developers.", request.ProviderData),
+		)
+
+		return
+	} else {
+		L.conn = client.conn
+		L.locks = client.locks
+	}
+}
+
+func (L *LDAPAttributesResource) Metadata(_ context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_attributes"
+}
+
+func (L *LDAPAttributesResource) Schema(_ context.Context, _ resource.SchemaRequest, response *resource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		MarkdownDescription: "Manages some attributes of an existing entry, which is otherwise managed elsewhere. The values of the " +
+			"attributes are replaced, all other attributes of the entry are left untouched. The entry isn't created if it doesn't exist",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Resource identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"dn": schema.StringAttribute{
+				MarkdownDescription: "DN of the entry",
+				Required:            true,
+				Validators: []validator.String{
+					IsValidDN(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"attributes": schema.MapAttribute{
+				MarkdownDescription: "The managed attributes, the name defines the type of the attribute. An empty list deletes the attribute",
+				Required:            true,
+				ElementType:         types.ListType{ElemType: types.StringType},
+			},
+			"keep_on_destroy": schema.BoolAttribute{
+				MarkdownDescription: "Whether the attributes keep their values when the resource is destroyed or an attribute is removed from `attributes`. By default, they are deleted",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+func (L *LDAPAttributesResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	var data *LDAPAttributesResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &data)...)
+	var attributes map[string][]string
+	response.Diagnostics.Append(data.Attributes.ElementsAs(ctx, &attributes, false)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	dn := data.DN.ValueString()
+	data.ID = data.DN
+
+	// the entry is checked first, so a missing entry isn't mistaken for a missing parent by the error of the modify
+	start := time.Now()
+	_, err := GetEntry(L.conn, dn, "1.1")
+	LogOperation(ctx, "search", dn, start)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) || errors.Is(err, ErrEntryNotFound) {
+		response.Diagnostics.AddError(
+			"Entry not found",
+			fmt.Sprintf("The entry %s doesn't exist. ldap_attributes only manages attributes of existing entries, use ldap_object to create it", dn),
+		)
+		return
+	} else if err != nil {
+		addOperationError(&response.Diagnostics, err, "create", dn,
+			"Can not read entry",
+			err.Error(),
+		)
+		return
+	}
+
+	if err := L.replace(ctx, dn, attributes, nil, false); err != nil {
+		addOperationError(&response.Diagnostics, err, "create", dn,
+			"Can not modify entry",
+			fmt.Sprintf("Trying to replace the attributes of %s returned: %s", dn, err),
+		)
+		return
+	}
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+// Read only refreshes the managed attributes, values which only differ in their spelling keep the spelling of the state.
+func (L *LDAPAttributesResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	var data *LDAPAttributesResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	var stateAttributes map[string][]string
+	response.Diagnostics.Append(data.Attributes.ElementsAs(ctx, &stateAttributes, false)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	dn := data.DN.ValueString()
+	start := time.Now()
+	entry, err := GetEntry(L.conn, dn, append(sortedKeys(stateAttributes), "1.1")...)
+	LogOperation(ctx, "search", dn, start)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) || errors.Is(err, ErrEntryNotFound) {
+		tflog.Warn(ctx, "Entry was deleted outside of Terraform, removing the attributes from the state", map[string]interface{}{"dn": dn})
+		response.State.RemoveResource(ctx)
+		return
+	} else if err != nil {
+		addOperationError(&response.Diagnostics, err, "read", dn,
+			"Can not read entry",
+			err.Error(),
+		)
+		return
+	}
+
+	attributes := map[string][]string{}
+	for attributeType, stateValues := range stateAttributes {
+		attributes[attributeType] = preferStateValues(lookupMatchingRule(attributeType, nil), entry.GetEqualFoldAttributeValues(attributeType), stateValues)
+	}
+	var d diag.Diagnostics
+	data.Attributes, d = types.MapValueFrom(ctx, types.ListType{ElemType: types.StringType}, attributes)
+	response.Diagnostics.Append(d...)
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPAttributesResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
+	var stateData *LDAPAttributesResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &stateData)...)
+	var planData *LDAPAttributesResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &planData)...)
+	var stateAttributes map[string][]string
+	response.Diagnostics.Append(stateData.Attributes.ElementsAs(ctx, &stateAttributes, false)...)
+	var planAttributes map[string][]string
+	response.Diagnostics.Append(planData.Attributes.ElementsAs(ctx, &planAttributes, false)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	dn := planData.DN.ValueString()
+	if err := L.replace(ctx, dn, planAttributes, stateAttributes, planData.KeepOnDestroy.ValueBool()); err != nil {
+		addOperationError(&response.Diagnostics, err, "update", dn,
+			"Can not modify entry",
+			fmt.Sprintf("Trying to replace the attributes of %s returned: %s", dn, err),
+		)
+		return
+	}
+	planData.ID = stateData.ID
+	response.Diagnostics.Append(response.State.Set(ctx, &planData)...)
+}
+
+func (L *LDAPAttributesResource) Delete(ctx context.Context, request resource.DeleteRequest, response *resource.DeleteResponse) {
+	var data *LDAPAttributesResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	var stateAttributes map[string][]string
+	response.Diagnostics.Append(data.Attributes.ElementsAs(ctx, &stateAttributes, false)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	dn := data.DN.ValueString()
+	if data.KeepOnDestroy.ValueBool() {
+		tflog.Debug(ctx, "Leaving the attributes of the entry untouched", map[string]interface{}{"dn": dn})
+		return
+	}
+	if err := L.replace(ctx, dn, nil, stateAttributes, false); err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		addOperationError(&response.Diagnostics, err, "delete", dn,
+			"Can not modify entry",
+			fmt.Sprintf("Trying to delete the attributes of %s returned: %s", dn, err),
+		)
+	}
+}
+
+// replace replaces the values of the planned attributes which differ from the state. Attributes only in the state are
+// deleted, unless they are kept. Nothing is sent if nothing changed.
+func (L *LDAPAttributesResource) replace(ctx context.Context, dn string, planAttributes map[string][]string, stateAttributes map[string][]string, keep bool) error {
+	r := ldap.NewModifyRequest(dn, []ldap.Control{})
+	for _, attributeType := range sortedKeys(planAttributes) {
+		if stateValues, exists := stateAttributes[attributeType]; !exists || !sameValues(lookupMatchingRule(attributeType, nil), stateValues, planAttributes[attributeType]) {
+			r.Replace(attributeType, planAttributes[attributeType])
+		}
+	}
+	for _, attributeType := range sortedKeys(stateAttributes) {
+		if _, exists := planAttributes[attributeType]; !exists && !keep {
+			r.Replace(attributeType, []string{})
+		}
+	}
+	if len(r.Changes) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	defer LogOperation(ctx, "modify", dn, start)
+	return WithContext(ctx, func() error {
+		return L.locks.write(ctx, dn, func() error {
+			return L.conn.Modify(r)
+		})
+	})
+}
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"regexp"
+	"testing"
+)
+
+func TestLDAPAttributesResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			t.Cleanup(testDeleteEntryExternally(t, "cn=hr,dc=example,dc=com"))
+			testAddEntryExternally(t, "cn=hr,dc=example,dc=com", map[string][]string{"sn": {"external"}, "description": {"external"}})()
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Missing entries aren't created
+			{
+				Config:      testAttributesConfig("cn=missing,dc=example,dc=com", `"description" = ["managed"]`, false),
+				ExpectError: regexp.MustCompile("Entry not found"),
+			},
+			{
+				Config: testAttributesConfig("cn=hr,dc=example,dc=com", `"description" = ["managed"], "telephoneNumber" = ["123"]`, false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckServerValues("cn=hr,dc=example,dc=com", "description", []string{"managed"}),
+					testCheckServerValues("cn=hr,dc=example,dc=com", "telephoneNumber", []string{"123"}),
+					testCheckServerValues("cn=hr,dc=example,dc=com", "sn", []string{"external"}),
+				),
+			},
+			// Values changed outside of Terraform are replaced again
+			{
+				PreConfig: testAddValueExternally(t, "cn=hr,dc=example,dc=com", "description", "external"),
+				Config:    testAttributesConfig("cn=hr,dc=example,dc=com", `"description" = ["managed"], "telephoneNumber" = ["123"]`, false),
+				Check:     testCheckServerValues("cn=hr,dc=example,dc=com", "description", []string{"managed"}),
+			},
+			// Attributes removed from the map are deleted
+			{
+				Config: testAttributesConfig("cn=hr,dc=example,dc=com", `"description" = ["changed"]`, false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckServerValues("cn=hr,dc=example,dc=com", "description", []string{"changed"}),
+					testCheckServerValues("cn=hr,dc=example,dc=com", "telephoneNumber", []string{}),
+				),
+			},
+			{
+				Config: testAttributesConfig("cn=hr,dc=example,dc=com", `"description" = ["changed"]`, true),
+			},
+			// The entry and the attributes are kept when the resource is destroyed
+			{
+				Config: `
+data "ldap_object" "hr" {
+	dn = "cn=hr,dc=example,dc=com"
+}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckServerValues("cn=hr,dc=example,dc=com", "description", []string{"changed"}),
+					testCheckServerValues("cn=hr,dc=example,dc=com", "sn", []string{"external"}),
+				),
+			},
+		},
+	})
+}
+
+func testAttributesConfig(dn string, attributes string, keepOnDestroy bool) string {
+	return fmt.Sprintf(`
+resource "ldap_attributes" "test" {
+	dn = %q
+	attributes = { %s }
+	keep_on_destroy = %t
+}
+`, dn, attributes, keepOnDestroy)
+}
@@ -0,0 +1,278 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"strings"
+	"time"
+)
+
+var _ resource.Resource = &LDAPGroupMemberResource{}
+var _ resource.ResourceWithConfigure = &LDAPGroupMemberResource{}
+var _ resource.ResourceWithImportState = &LDAPGroupMemberResource{}
+
+func NewLDAPGroupMemberResource() resource.Resource {
+	return &LDAPGroupMemberResource{}
+}
+
+type LDAPGroupMemberResource struct {
+	conn  *ldap.Conn
+	locks *dnLocks
+}
+
+type LDAPGroupMemberResourceModel struct {
+	ID              types.String `tfsdk:"id"`
+	GroupDN         types.String `tfsdk:"group_dn"`
+	MemberDN        types.String `tfsdk:"member_dn"`
+	MemberAttribute types.String `tfsdk:"member_attribute"`
+	AllowExisting   types.Bool   `tfsdk:"allow_existing"`
+}
+
+// groupMemberIDSeparator separates the group and the member DN in the id of ldap_group_member.
+const groupMemberIDSeparator = "|"
+
+func (L *LDAPGroupMemberResource) Configure(_ context.Context, request resource.ConfigureRequest, response *resource.ConfigureResponse) {
+	if request.ProviderData == nil {
+		return
+	}
+
+	if client, ok := request.ProviderData.(*ldapClient); !ok {
+		response.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ldapClient, got: %T. Please report this issue to the provider developers.", request.ProviderData),
+		)
+
+		return
+	} else {
+		L.conn = client.conn
+		L.locks = client.locks
+	}
+}
+
+func (L *LDAPGroupMemberResource) Metadata(_ context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_group_member"
+}
+
+func (L *LDAPGroupMemberResource) Schema(_ context.Context, _ resource.SchemaRequest, response *resource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		MarkdownDescription: "Manages a single member of a group, which is otherwise managed elsewhere. Other members of the group are left untouched. " +
+			"Groups storing user names in `memberUid` get the `uid` of `member_dn` as member",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Resource identifier, the group DN and the member DN separated by `|`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"group_dn": schema.StringAttribute{
+				MarkdownDescription: "DN of the group",
+				Required:            true,
+				Validators: []validator.String{
+					IsValidDN(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"member_dn": schema.StringAttribute{
+				MarkdownDescription: "DN of the member",
+				Required:            true,
+				Validators: []validator.String{
+					IsValidDN(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"member_attribute": schema.StringAttribute{
+				MarkdownDescription: "Attribute storing the members of the group. Detected from the object classes of the group if not set: `member` for groupOfNames and Active Directory groups, `uniqueMember` for groupOfUniqueNames and `memberUid` for posixGroups. Has to be set if the object classes are ambiguous, e.g. for a groupOfNames which is a posixGroup as well",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("member", "uniqueMember", "memberUid"),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"allow_existing": schema.BoolAttribute{
+				MarkdownDescription: "Whether creating the resource succeeds if the group already contains the member. The member is still removed from the group when the resource is destroyed",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+func (L *LDAPGroupMemberResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	var data *LDAPGroupMemberResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	groupDN := data.GroupDN.ValueString()
+	if data.MemberAttribute.IsUnknown() || data.MemberAttribute.IsNull() {
+		memberAttribute, err := readMemberAttribute(ctx, L.conn, groupDN)
+		if err != nil {
+			addMemberAttributeError(&response.Diagnostics, err, "create", groupDN)
+			return
+		}
+		data.MemberAttribute = types.StringValue(memberAttribute)
+	}
+	data.ID = types.StringValue(groupDN + groupMemberIDSeparator + data.MemberDN.ValueString())
+	value, err := memberValue(data.MemberAttribute.ValueString(), data.MemberDN.ValueString())
+	if err != nil {
+		response.Diagnostics.AddAttributeError(
+			path.Root("member_dn"),
+			"Invalid member",
+			err.Error(),
+		)
+		return
+	}
+
+	r := ldap.NewModifyRequest(groupDN, []ldap.Control{})
+	r.Add(data.MemberAttribute.ValueString(), []string{value})
+	start := time.Now()
+	err = WithContext(ctx, func() error {
+		return L.locks.write(ctx, groupDN, func() error {
+			return L.conn.Modify(r)
+		})
+	})
+	LogOperation(ctx, "modify", groupDN, start)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultAttributeOrValueExists) && data.AllowExisting.ValueBool() {
+		tflog.Info(ctx, "Group already contains the member", map[string]interface{}{"dn": groupDN, "member": data.MemberDN.ValueString()})
+	} else if ldap.IsErrorWithCode(err, ldap.LDAPResultAttributeOrValueExists) {
+		response.Diagnostics.AddError(
+			"Member already exists",
+			fmt.Sprintf("The group %s already contains %s. Set allow_existing to manage the existing membership", groupDN, data.MemberDN.ValueString()),
+		)
+		return
+	} else if err != nil {
+		addOperationError(&response.Diagnostics, err, "create", groupDN,
+			"Can not add member",
+			fmt.Sprintf("Trying to add %s to group %s returned: %s", data.MemberDN.ValueString(), groupDN, err),
+		)
+		return
+	}
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+// Read checks the membership with a compare operation, so the members of large groups don't have to be fetched.
+func (L *LDAPGroupMemberResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	var data *LDAPGroupMemberResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	groupDN := data.GroupDN.ValueString()
+	value, err := memberValue(data.MemberAttribute.ValueString(), data.MemberDN.ValueString())
+	if err != nil {
+		response.Diagnostics.AddAttributeError(
+			path.Root("member_dn"),
+			"Invalid member",
+			err.Error(),
+		)
+		return
+	}
+	var isMember bool
+	start := time.Now()
+	err = WithContext(ctx, func() (err error) {
+		isMember, err = L.conn.Compare(groupDN, data.MemberAttribute.ValueString(), value)
+		return
+	})
+	LogOperation(ctx, "compare", groupDN, start)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		tflog.Warn(ctx, "Group was deleted outside of Terraform, removing the member from the state", map[string]interface{}{"dn": groupDN})
+		response.State.RemoveResource(ctx)
+		return
+	} else if err != nil {
+		addOperationError(&response.Diagnostics, err, "read", groupDN,
+			"Can not compare member",
+			fmt.Sprintf("Trying to check whether %s is a member of group %s returned: %s", data.MemberDN.ValueString(), groupDN, err),
+		)
+		return
+	}
+	if !isMember {
+		tflog.Warn(ctx, "Member was removed outside of Terraform, removing it from the state", map[string]interface{}{"dn": groupDN, "member": data.MemberDN.ValueString()})
+		response.State.RemoveResource(ctx)
+		return
+	}
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPGroupMemberResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
+	// only allow_existing can change, which is only used on create
+	var data *LDAPGroupMemberResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &data)...)
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPGroupMemberResource) Delete(ctx context.Context, request resource.DeleteRequest, response *resource.DeleteResponse) {
+	var data *LDAPGroupMemberResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	groupDN := data.GroupDN.ValueString()
+	value, err := memberValue(data.MemberAttribute.ValueString(), data.MemberDN.ValueString())
+	if err != nil {
+		response.Diagnostics.AddAttributeError(
+			path.Root("member_dn"),
+			"Invalid member",
+			err.Error(),
+		)
+		return
+	}
+	r := ldap.NewModifyRequest(groupDN, []ldap.Control{})
+	r.Delete(data.MemberAttribute.ValueString(), []string{value})
+	start := time.Now()
+	err = WithContext(ctx, func() error {
+		return L.locks.write(ctx, groupDN, func() error {
+			return L.conn.Modify(r)
+		})
+	})
+	LogOperation(ctx, "modify", groupDN, start)
+	if err != nil && !ldap.IsErrorAnyOf(err, ldap.LDAPResultNoSuchObject, ldap.LDAPResultNoSuchAttribute) {
+		addOperationError(&response.Diagnostics, err, "delete", groupDN,
+			"Can not remove member",
+			fmt.Sprintf("Trying to remove %s from group %s returned: %s", data.MemberDN.ValueString(), groupDN, err),
+		)
+	}
+}
+
+// ImportState imports a membership by the group DN and the member DN separated by |. The member attribute is detected
+// from the group.
+func (L *LDAPGroupMemberResource) ImportState(ctx context.Context, request resource.ImportStateRequest, response *resource.ImportStateResponse) {
+	groupDN, memberDN, found := strings.Cut(request.ID, groupMemberIDSeparator)
+	if !found {
+		response.Diagnostics.AddError(
+			"Invalid import id",
+			fmt.Sprintf("Expected the group DN and the member DN separated by %q, got: %s", groupMemberIDSeparator, request.ID),
+		)
+		return
+	}
+
+	memberAttribute, err := readMemberAttribute(ctx, L.conn, groupDN)
+	if err != nil {
+		addMemberAttributeError(&response.Diagnostics, err, "import", groupDN)
+		return
+	}
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("id"), request.ID)...)
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("group_dn"), groupDN)...)
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("member_dn"), memberDN)...)
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("member_attribute"), memberAttribute)...)
+}
@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"regexp"
+	"testing"
+)
+
+func TestLDAPGroupMemberResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testGroupMemberConfig(`
+resource "ldap_group_member" "bob" {
+	group_dn = ldap_object.admins.dn
+	member_dn = ldap_object.bob.dn
+}`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_group_member.bob", "member_attribute", "member"),
+					testCheckServerValues("cn=admins,dc=example,dc=com", "member", []string{"cn=alice,dc=example,dc=com", "cn=placeholder,dc=example,dc=com", "cn=bob,dc=example,dc=com"}),
+				),
+			},
+			{
+				Config: testGroupMemberConfig(`
+resource "ldap_group_member" "bob" {
+	group_dn = ldap_object.admins.dn
+	member_dn = ldap_object.bob.dn
+}`),
+				ResourceName:      "ldap_group_member.bob",
+				ImportState:       true,
+				ImportStateId:     "cn=admins,dc=example,dc=com|cn=bob,dc=example,dc=com",
+				ImportStateVerify: true,
+			},
+			// Existing members are only managed if allowed
+			{
+				Config: testGroupMemberConfig(`
+resource "ldap_group_member" "alice" {
+	group_dn = ldap_object.admins.dn
+	member_dn = ldap_object.alice.dn
+}`),
+				ExpectError: regexp.MustCompile("Member already exists"),
+			},
+			{
+				Config: testGroupMemberConfig(`
+resource "ldap_group_member" "alice" {
+	group_dn = ldap_object.admins.dn
+	member_dn = ldap_object.alice.dn
+	allow_existing = true
+}`),
+				Check: testCheckServerValues("cn=admins,dc=example,dc=com", "member", []string{"cn=alice,dc=example,dc=com", "cn=placeholder,dc=example,dc=com"}),
+			},
+		},
+	})
+}
+
+// testGroupMemberConfig creates a group with the member alice, which is otherwise managed elsewhere, together with
+// the given membership. The placeholder member keeps the group valid when alice is removed on destroy.
+func testGroupMemberConfig(membership string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "alice" {
+	dn = "cn=alice,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["alice"]
+		"sn" = ["alice"]
+	}
+}
+
+resource "ldap_object" "bob" {
+	dn = "cn=bob,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["bob"]
+		"sn" = ["bob"]
+	}
+}
+
+resource "ldap_object" "admins" {
+	dn = "cn=admins,dc=example,dc=com"
+	object_classes = ["groupOfNames"]
+	attributes = {
+		"cn" = ["admins"]
+		"member" = [ldap_object.alice.dn, "cn=placeholder,dc=example,dc=com"]
+	}
+	ignore_changes = ["member"]
+}
+%s
+`, membership)
+}
+
+func TestLDAPGroupMemberResourceMemberUid(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// the uid of the member DN is stored, other members are compared case-sensitively
+			{
+				Config: testMemberUidConfig(`
+resource "ldap_group_member" "alice" {
+	group_dn = ldap_object.nisusers.dn
+	member_dn = "uid=alice,ou=people,dc=example,dc=com"
+}`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_group_member.alice", "member_attribute", "memberUid"),
+					testCheckServerValues("cn=nisusers,dc=example,dc=com", "memberUid", []string{"Alice", "alice"}),
+				),
+			},
+			{
+				Config: testMemberUidConfig(""),
+				Check:  testCheckServerValues("cn=nisusers,dc=example,dc=com", "memberUid", []string{"Alice"}),
+			},
+		},
+	})
+}
+
+// testMemberUidConfig creates a posixGroup with the member Alice, which is otherwise managed elsewhere, together with
+// the given membership.
+func testMemberUidConfig(membership string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "nisusers" {
+	dn = "cn=nisusers,dc=example,dc=com"
+	object_classes = ["posixGroup"]
+	attributes = {
+		"cn" = ["nisusers"]
+		"gidNumber" = ["5001"]
+		"memberUid" = ["Alice"]
+	}
+	ignore_changes = ["memberUid"]
+}
+%s
+`, membership)
+}
+
+func TestLDAPGroupMemberResourceUnknownGroup(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "ldap_object" "people" {
+	dn = "ou=people,dc=example,dc=com"
+	object_classes = ["organizationalUnit"]
+}
+
+resource "ldap_group_member" "alice" {
+	group_dn = ldap_object.people.dn
+	member_dn = "cn=alice,dc=example,dc=com"
+}
+`,
+				ExpectError: regexp.MustCompile("Member attribute not detected"),
+			},
+		},
+	})
+}
@@ -0,0 +1,323 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var _ resource.Resource = &LDAPGroupMembersResource{}
+var _ resource.ResourceWithConfigure = &LDAPGroupMembersResource{}
+var _ resource.ResourceWithImportState = &LDAPGroupMembersResource{}
+
+func NewLDAPGroupMembersResource() resource.Resource {
+	return &LDAPGroupMembersResource{}
+}
+
+type LDAPGroupMembersResource struct {
+	conn  *ldap.Conn
+	locks *dnLocks
+}
+
+type LDAPGroupMembersResourceModel struct {
+	ID              types.String `tfsdk:"id"`
+	GroupDN         types.String `tfsdk:"group_dn"`
+	Members         types.Set    `tfsdk:"members"`
+	IgnoreMembers   types.Set    `tfsdk:"ignore_members"`
+	MemberAttribute types.String `tfsdk:"member_attribute"`
+}
+
+func (L *LDAPGroupMembersResource) Configure(_ context.Context, request resource.ConfigureRequest, response *resource.ConfigureResponse) {
+	if request.ProviderData == nil {
+		return
+	}
+
+	if client, ok := request.ProviderData.(*ldapClient); !ok {
+		response.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ldapClient, got: %T. Please report this issue to the provider developers.", request.ProviderData),
+		)
+
+		return
+	} else {
+		L.conn = client.conn
+		L.locks = client.locks
+	}
+}
+
+func (L *LDAPGroupMembersResource) Metadata(_ context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_group_members"
+}
+
+func (L *LDAPGroupMembersResource) Schema(_ context.Context, _ resource.SchemaRequest, response *resource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		MarkdownDescription: "Manages all members of a group, which is otherwise managed elsewhere. Members added outside of Terraform are removed, " +
+			"unless they match `ignore_members`. Destroying the resource removes the members listed in `members` from the group",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Resource identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"group_dn": schema.StringAttribute{
+				MarkdownDescription: "DN of the group",
+				Required:            true,
+				Validators: []validator.String{
+					IsValidDN(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"members": schema.SetAttribute{
+				MarkdownDescription: "DNs of all members of the group, except the ignored ones. User names if the members are stored in `memberUid`",
+				Required:            true,
+				ElementType:         types.StringType,
+			},
+			"ignore_members": schema.SetAttribute{
+				MarkdownDescription: "Members which may exist without being managed, e.g. break-glass accounts. Given as DNs or as patterns, in which `*` matches any characters, like `cn=*,ou=emergency,dc=example,dc=com`. User names in `memberUid` are matched case-sensitively. Ignored members are never added, removed or reported as drift",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"member_attribute": schema.StringAttribute{
+				MarkdownDescription: "Attribute storing the members of the group. Detected from the object classes of the group if not set: `member` for groupOfNames and Active Directory groups, `uniqueMember` for groupOfUniqueNames and `memberUid` for posixGroups. Has to be set if the object classes are ambiguous, e.g. for a groupOfNames which is a posixGroup as well. Only this attribute is modified, so groups combining `member` and `memberUid` can be managed by one resource per attribute",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("member", "uniqueMember", "memberUid"),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+	}
+}
+
+func (L *LDAPGroupMembersResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	var data *LDAPGroupMembersResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = data.GroupDN
+	if data.MemberAttribute.IsUnknown() || data.MemberAttribute.IsNull() {
+		memberAttribute, err := readMemberAttribute(ctx, L.conn, data.GroupDN.ValueString())
+		if err != nil {
+			addMemberAttributeError(&response.Diagnostics, err, "create", data.GroupDN.ValueString())
+			return
+		}
+		data.MemberAttribute = types.StringValue(memberAttribute)
+	}
+	if err := L.reconcile(ctx, data, &response.Diagnostics); err != nil {
+		addOperationError(&response.Diagnostics, err, "create", data.GroupDN.ValueString(),
+			"Can not modify members",
+			fmt.Sprintf("Trying to modify the members of group %s returned: %s", data.GroupDN.ValueString(), err),
+		)
+		return
+	}
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+// Read reads the members of the group. Ignored members aren't part of the state, so they are never reported as drift.
+func (L *LDAPGroupMembersResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	var data *LDAPGroupMembersResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	var stateMembers []string
+	response.Diagnostics.Append(data.Members.ElementsAs(ctx, &stateMembers, false)...)
+	var ignorePatterns []string
+	response.Diagnostics.Append(data.IgnoreMembers.ElementsAs(ctx, &ignorePatterns, false)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	members, err := L.readMembers(ctx, data)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) || errors.Is(err, ErrEntryNotFound) {
+		tflog.Warn(ctx, "Group was deleted outside of Terraform, removing it from the state", map[string]interface{}{"dn": data.GroupDN.ValueString()})
+		response.State.RemoveResource(ctx)
+		return
+	} else if err != nil {
+		addOperationError(&response.Diagnostics, err, "read", data.GroupDN.ValueString(),
+			"Can not read group",
+			err.Error(),
+		)
+		return
+	}
+
+	rule := memberMatchingRule(data.MemberAttribute.ValueString())
+	managed := unignoredMembers(rule, members, ignorePatterns)
+	set, d := types.SetValueFrom(ctx, types.StringType, preferStateValues(rule, managed, stateMembers))
+	response.Diagnostics.Append(d...)
+	data.Members = set
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPGroupMembersResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
+	var data *LDAPGroupMembersResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	if err := L.reconcile(ctx, data, &response.Diagnostics); err != nil {
+		addOperationError(&response.Diagnostics, err, "update", data.GroupDN.ValueString(),
+			"Can not modify members",
+			fmt.Sprintf("Trying to modify the members of group %s returned: %s", data.GroupDN.ValueString(), err),
+		)
+		return
+	}
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPGroupMembersResource) Delete(ctx context.Context, request resource.DeleteRequest, response *resource.DeleteResponse) {
+	var data *LDAPGroupMembersResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	var members []string
+	response.Diagnostics.Append(data.Members.ElementsAs(ctx, &members, false)...)
+	if response.Diagnostics.HasError() || len(members) == 0 {
+		return
+	}
+
+	groupDN := data.GroupDN.ValueString()
+	current, err := L.readMembers(ctx, data)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) || errors.Is(err, ErrEntryNotFound) {
+		return
+	} else if err != nil {
+		addOperationError(&response.Diagnostics, err, "delete", groupDN,
+			"Can not read group",
+			err.Error(),
+		)
+		return
+	}
+
+	// only members which are still present can be deleted
+	rule := memberMatchingRule(data.MemberAttribute.ValueString())
+	deleted := subtractValues(rule, members, subtractValues(rule, members, current))
+	if len(deleted) == 0 {
+		return
+	}
+	r := ldap.NewModifyRequest(groupDN, []ldap.Control{})
+	r.Delete(data.MemberAttribute.ValueString(), deleted)
+	if err := L.modify(ctx, r); err != nil {
+		addOperationError(&response.Diagnostics, err, "delete", groupDN,
+			"Can not remove members",
+			fmt.Sprintf("Trying to remove the members of group %s returned: %s", groupDN, err),
+		)
+	}
+}
+
+func (L *LDAPGroupMembersResource) ImportState(ctx context.Context, request resource.ImportStateRequest, response *resource.ImportStateResponse) {
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("id"), request.ID)...)
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("group_dn"), request.ID)...)
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("members"), []string{})...)
+
+	memberAttribute, err := readMemberAttribute(ctx, L.conn, request.ID)
+	if err != nil {
+		addMemberAttributeError(&response.Diagnostics, err, "import", request.ID)
+		return
+	}
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("member_attribute"), memberAttribute)...)
+}
+
+// reconcile reads the current members of the group and adds the missing members and deletes the members, which are
+// neither configured nor ignored, in a single modification.
+func (L *LDAPGroupMembersResource) reconcile(ctx context.Context, data *LDAPGroupMembersResourceModel, diagnostics *diag.Diagnostics) error {
+	var members []string
+	diagnostics.Append(data.Members.ElementsAs(ctx, &members, false)...)
+	var ignorePatterns []string
+	diagnostics.Append(data.IgnoreMembers.ElementsAs(ctx, &ignorePatterns, false)...)
+	if diagnostics.HasError() {
+		return errors.New("error converting data")
+	}
+
+	current, err := L.readMembers(ctx, data)
+	if err != nil {
+		return err
+	}
+
+	rule := memberMatchingRule(data.MemberAttribute.ValueString())
+	r := ldap.NewModifyRequest(data.GroupDN.ValueString(), []ldap.Control{})
+	if added := subtractValues(rule, members, current); len(added) > 0 {
+		r.Add(data.MemberAttribute.ValueString(), added)
+	}
+	if deleted := subtractValues(rule, unignoredMembers(rule, current, ignorePatterns), members); len(deleted) > 0 {
+		r.Delete(data.MemberAttribute.ValueString(), deleted)
+	}
+	if len(r.Changes) == 0 {
+		return nil
+	}
+	return L.modify(ctx, r)
+}
+
+// readMembers reads the current members of the group.
+func (L *LDAPGroupMembersResource) readMembers(ctx context.Context, data *LDAPGroupMembersResourceModel) ([]string, error) {
+	start := time.Now()
+	entry, err := GetEntry(L.conn, data.GroupDN.ValueString(), data.MemberAttribute.ValueString())
+	LogOperation(ctx, "search", data.GroupDN.ValueString(), start)
+	if err != nil {
+		return nil, err
+	}
+	return entry.GetEqualFoldAttributeValues(data.MemberAttribute.ValueString()), nil
+}
+
+// modify modifies the members of the group while holding its lock.
+func (L *LDAPGroupMembersResource) modify(ctx context.Context, r *ldap.ModifyRequest) error {
+	start := time.Now()
+	defer LogOperation(ctx, "modify", r.DN, start)
+	return WithContext(ctx, func() error {
+		return L.locks.write(ctx, r.DN, func() error {
+			return L.conn.Modify(r)
+		})
+	})
+}
+
+// unignoredMembers returns the members which don't match any of the ignore patterns, comparing them using the
+// matching rule of the member attribute.
+func unignoredMembers(rule string, members []string, ignorePatterns []string) []string {
+	var result []string
+	for _, member := range members {
+		ignored := false
+		for _, pattern := range ignorePatterns {
+			if matchesMemberPattern(rule, pattern, member) {
+				ignored = true
+				break
+			}
+		}
+		if !ignored {
+			result = append(result, member)
+		}
+	}
+	return result
+}
+
+// matchesMemberPattern checks whether the member matches the pattern. Patterns without wildcards are compared using the
+// matching rule, otherwise `*` matches any characters and the comparison ignores case, unless the rule is case-sensitive.
+func matchesMemberPattern(rule string, pattern string, member string) bool {
+	if !strings.Contains(pattern, "*") {
+		return equalValues(rule, pattern, member)
+	}
+	expression := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	if rule != matchingRuleCaseExact {
+		expression = "(?i)" + expression
+	}
+	matched, err := regexp.MatchString(expression, member)
+	return err == nil && matched
+}
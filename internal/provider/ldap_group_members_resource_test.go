@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLDAPGroupMembersResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testGroupMembersConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_group_members.staff", "member_attribute", "member"),
+					testCheckServerValues("cn=staff,dc=example,dc=com", "member", []string{"cn=placeholder,dc=example,dc=com", "cn=alice,dc=example,dc=com"}),
+				),
+			},
+			// Ignored members added outside of Terraform aren't drift
+			{
+				PreConfig: testAddMemberExternally(t, "cn=staff,dc=example,dc=com", "cn=breakglass1,dc=example,dc=com"),
+				Config:    testGroupMembersConfig,
+				PlanOnly:  true,
+			},
+			// Other members added outside of Terraform are removed again
+			{
+				PreConfig:          testAddMemberExternally(t, "cn=staff,dc=example,dc=com", "cn=mallory,dc=example,dc=com"),
+				Config:             testGroupMembersConfig,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testGroupMembersConfig,
+				Check:  testCheckServerValues("cn=staff,dc=example,dc=com", "member", []string{"cn=placeholder,dc=example,dc=com", "cn=alice,dc=example,dc=com", "cn=breakglass1,dc=example,dc=com"}),
+			},
+		},
+	})
+}
+
+// testGroupMembersConfig manages the members of a group, which keeps a placeholder member, so it isn't empty after the
+// managed members were removed on destroy.
+const testGroupMembersConfig = `
+resource "ldap_object" "alice" {
+	dn = "cn=alice,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["alice"]
+		"sn" = ["alice"]
+	}
+}
+
+resource "ldap_object" "staff" {
+	dn = "cn=staff,dc=example,dc=com"
+	object_classes = ["groupOfNames"]
+	attributes = {
+		"cn" = ["staff"]
+		"member" = ["cn=placeholder,dc=example,dc=com"]
+	}
+	ignore_changes = ["member"]
+}
+
+resource "ldap_group_members" "staff" {
+	group_dn = ldap_object.staff.dn
+	members = [ldap_object.alice.dn]
+	ignore_members = ["cn=placeholder,dc=example,dc=com", "cn=breakglass*,dc=example,dc=com"]
+}
+`
+
+func testAddMemberExternally(t *testing.T, groupDN string, memberDN string) func() {
+	return testExternally(t, func(conn *ldap.Conn) error {
+		r := ldap.NewModifyRequest(groupDN, []ldap.Control{})
+		r.Add("member", []string{memberDN})
+		return conn.Modify(r)
+	})
+}
+
+func TestUnignoredMembers(t *testing.T) {
+	members := []string{
+		"cn=alice,dc=example,dc=com",
+		"CN=Emergency1,ou=emergency,dc=example,dc=com",
+		"cn=bob, dc=example,dc=com",
+	}
+	assert.Equal(t, []string{"cn=alice,dc=example,dc=com"}, unignoredMembers(matchingRuleDistinguishedName, members, []string{
+		"cn=*,ou=emergency,dc=example,dc=com",
+		"cn=Bob,dc=example,dc=com",
+	}))
+	assert.Equal(t, members, unignoredMembers(matchingRuleDistinguishedName, members, nil))
+
+	assert.True(t, matchesMemberPattern(matchingRuleDistinguishedName, "cn=breakglass*,dc=example,dc=com", "cn=BreakGlass2,dc=example,dc=com"))
+	assert.False(t, matchesMemberPattern(matchingRuleDistinguishedName, "cn=breakglass*,dc=example,dc=com", "cn=breakglass2,ou=other,dc=example,dc=org"))
+	assert.False(t, matchesMemberPattern(matchingRuleDistinguishedName, "cn=a.b,dc=example,dc=com", "cn=axb,dc=example,dc=com"))
+
+	// user names in memberUid are case-sensitive
+	assert.Equal(t, []string{"Alice", "bob"}, unignoredMembers(matchingRuleCaseExact, []string{"alice", "Alice", "bob", "svc-backup"}, []string{"alice", "svc-*"}))
+	assert.False(t, matchesMemberPattern(matchingRuleCaseExact, "svc-*", "SVC-backup"))
+}
@@ -0,0 +1,460 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/thoas/go-funk"
+	"strconv"
+	"time"
+)
+
+var _ resource.Resource = &LDAPGroupResource{}
+var _ resource.ResourceWithConfigure = &LDAPGroupResource{}
+var _ resource.ResourceWithValidateConfig = &LDAPGroupResource{}
+var _ resource.ResourceWithImportState = &LDAPGroupResource{}
+
+func NewLDAPGroupResource() resource.Resource {
+	return &LDAPGroupResource{}
+}
+
+type LDAPGroupResource struct {
+	conn  *ldap.Conn
+	locks *dnLocks
+}
+
+type LDAPGroupResourceModel struct {
+	ID                types.String `tfsdk:"id"`
+	DN                types.String `tfsdk:"dn"`
+	Name              types.String `tfsdk:"name"`
+	ParentDN          types.String `tfsdk:"parent_dn"`
+	Description       types.String `tfsdk:"description"`
+	Members           types.Set    `tfsdk:"members"`
+	GroupType         types.String `tfsdk:"group_type"`
+	GIDNumber         types.Int64  `tfsdk:"gid_number"`
+	PlaceholderMember types.String `tfsdk:"placeholder_member"`
+}
+
+// The values of group_type.
+const (
+	groupTypeGroupOfNames       = "groupOfNames"
+	groupTypeGroupOfUniqueNames = "groupOfUniqueNames"
+	groupTypePosixGroup         = "posixGroup"
+	groupTypeActiveDirectory    = "activeDirectory"
+)
+
+// groupKind describes how groups of a group_type are stored.
+type groupKind struct {
+	objectClasses   []string
+	memberAttribute string
+	// requiresMember is set if the object class requires at least one member, which is a placeholder in empty groups
+	requiresMember bool
+}
+
+var groupKinds = map[string]groupKind{
+	groupTypeGroupOfNames:       {objectClasses: []string{"groupOfNames"}, memberAttribute: "member", requiresMember: true},
+	groupTypeGroupOfUniqueNames: {objectClasses: []string{"groupOfUniqueNames"}, memberAttribute: "uniqueMember", requiresMember: true},
+	groupTypePosixGroup:         {objectClasses: []string{"posixGroup"}, memberAttribute: "memberUid"},
+	groupTypeActiveDirectory:    {objectClasses: []string{"group"}, memberAttribute: "member"},
+}
+
+func (L *LDAPGroupResource) Configure(_ context.Context, request resource.ConfigureRequest, response *resource.ConfigureResponse) {
+	if request.ProviderData == nil {
+		return
+	}
+
+	if client, ok := request.ProviderData.(*ldapClient); !ok {
+		response.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ldapClient, got: %T. Please report this issue to the provider developers.", request.ProviderData),
+		)
+
+		return
+	} else {
+		L.conn = client.conn
+		L.locks = client.locks
+	}
+}
+
+func (L *LDAPGroupResource) Metadata(_ context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_group"
+}
+
+func (L *LDAPGroupResource) Schema(_ context.Context, _ resource.SchemaRequest, response *resource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		MarkdownDescription: "Manages a group. The object classes and the member attribute are chosen by the group type. " +
+			"Members are added and deleted individually instead of replacing all members of the group",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Resource identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"dn": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "DN of the group, which is named by its `cn` below `parent_dn`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Name of the group, stored in `cn` and for Active Directory groups also in `sAMAccountName`",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"parent_dn": schema.StringAttribute{
+				MarkdownDescription: "DN of the entry the group is created in",
+				Required:            true,
+				Validators: []validator.String{
+					IsValidDN(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"description": schema.StringAttribute{
+				MarkdownDescription: "Description of the group",
+				Optional:            true,
+			},
+			"members": schema.SetAttribute{
+				MarkdownDescription: "The members of the group. These are DNs, except for posixGroup, whose members are user names",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"group_type": schema.StringAttribute{
+				MarkdownDescription: "The type of the group: `groupOfNames` (default) and `groupOfUniqueNames` store DNs in `member` respectively `uniqueMember`, `posixGroup` stores user names in `memberUid` and `activeDirectory` creates an Active Directory group",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(groupTypeGroupOfNames, groupTypeGroupOfUniqueNames, groupTypePosixGroup, groupTypeActiveDirectory),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"gid_number": schema.Int64Attribute{
+				MarkdownDescription: "The numeric group id, required for posixGroup",
+				Optional:            true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplaceIf(
+						func(_ context.Context, request planmodifier.Int64Request, response *int64planmodifier.RequiresReplaceIfFuncResponse) {
+							response.RequiresReplace = request.StateValue.IsNull() != request.PlanValue.IsNull()
+						},
+						"Adding or removing the group id requires replacement",
+						"Adding or removing the group id requires replacement",
+					),
+				},
+			},
+			"placeholder_member": schema.StringAttribute{
+				MarkdownDescription: "Member stored in empty groupOfNames and groupOfUniqueNames groups, since these need at least one member. Defaults to the DN of the group itself. The placeholder isn't listed in `members`",
+				Optional:            true,
+				Validators: []validator.String{
+					IsValidDN(),
+				},
+			},
+		},
+	}
+}
+
+func (L *LDAPGroupResource) ValidateConfig(ctx context.Context, request resource.ValidateConfigRequest, response *resource.ValidateConfigResponse) {
+	var data *LDAPGroupResourceModel
+	response.Diagnostics.Append(request.Config.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() || data.GroupType.IsUnknown() {
+		return
+	}
+
+	groupType := groupTypeOf(data)
+	if groupType == groupTypePosixGroup && data.GIDNumber.IsNull() {
+		response.Diagnostics.AddAttributeError(
+			path.Root("gid_number"),
+			"Missing group id",
+			"posixGroup groups require a gid_number",
+		)
+	}
+	if !groupKinds[groupType].requiresMember && !data.PlaceholderMember.IsNull() {
+		response.Diagnostics.AddAttributeWarning(
+			path.Root("placeholder_member"),
+			"Placeholder member is ignored",
+			fmt.Sprintf("Groups of the type %s can be empty and don't use a placeholder member", groupType),
+		)
+	}
+}
+
+func (L *LDAPGroupResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	var data *LDAPGroupResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &data)...)
+	members := L.members(ctx, data, &response.Diagnostics)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	data.DN = types.StringValue(groupDN(data))
+	data.ID = data.DN
+	kind := groupKinds[groupTypeOf(data)]
+	a := ldap.NewAddRequest(data.DN.ValueString(), []ldap.Control{})
+	a.Attribute("objectClass", kind.objectClasses)
+	a.Attribute("cn", []string{data.Name.ValueString()})
+	if !data.Description.IsNull() {
+		a.Attribute("description", []string{data.Description.ValueString()})
+	}
+	if !data.GIDNumber.IsNull() {
+		a.Attribute("gidNumber", []string{strconv.FormatInt(data.GIDNumber.ValueInt64(), 10)})
+	}
+	if groupTypeOf(data) == groupTypeActiveDirectory {
+		a.Attribute("sAMAccountName", []string{data.Name.ValueString()})
+	}
+	if len(members) > 0 {
+		a.Attribute(kind.memberAttribute, members)
+	} else if kind.requiresMember {
+		a.Attribute(kind.memberAttribute, []string{placeholderMember(data)})
+	}
+
+	start := time.Now()
+	err := WithContext(ctx, func() error {
+		return L.locks.write(ctx, a.DN, func() error {
+			return L.conn.Add(a)
+		})
+	})
+	LogOperation(ctx, "add", a.DN, start)
+	if err != nil {
+		addOperationError(&response.Diagnostics, err, "create", a.DN,
+			"Can not create group",
+			fmt.Sprintf("Trying to add group %s returned: %s", a.DN, err),
+		)
+		return
+	}
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPGroupResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	var data *LDAPGroupResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	stateMembers := L.members(ctx, data, &response.Diagnostics)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	kind := groupKinds[groupTypeOf(data)]
+	start := time.Now()
+	entry, err := GetEntry(L.conn, data.DN.ValueString(), "description", "gidNumber", kind.memberAttribute)
+	LogOperation(ctx, "search", data.DN.ValueString(), start)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) || errors.Is(err, ErrEntryNotFound) {
+		tflog.Warn(ctx, "Group was deleted outside of Terraform, removing it from the state", map[string]interface{}{"dn": data.DN.ValueString()})
+		response.State.RemoveResource(ctx)
+		return
+	} else if err != nil {
+		addOperationError(&response.Diagnostics, err, "read", data.DN.ValueString(),
+			"Can not read group",
+			err.Error(),
+		)
+		return
+	}
+
+	if description := entry.GetAttributeValues("description"); len(description) > 0 {
+		data.Description = types.StringValue(description[0])
+	} else {
+		data.Description = types.StringNull()
+	}
+	if gidNumber, err := strconv.ParseInt(entry.GetAttributeValue("gidNumber"), 10, 64); err == nil {
+		data.GIDNumber = types.Int64Value(gidNumber)
+	} else {
+		data.GIDNumber = types.Int64Null()
+	}
+
+	rule := lookupMatchingRule(kind.memberAttribute, nil)
+	members := entry.GetEqualFoldAttributeValues(kind.memberAttribute)
+	if kind.requiresMember {
+		members = subtractValues(rule, members, []string{placeholderMember(data)})
+	}
+	members = preferStateValues(rule, members, stateMembers)
+	if len(members) == 0 && data.Members.IsNull() {
+		data.Members = types.SetNull(types.StringType)
+	} else {
+		set, d := types.SetValueFrom(ctx, types.StringType, members)
+		response.Diagnostics.Append(d...)
+		data.Members = set
+	}
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPGroupResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
+	var stateData *LDAPGroupResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &stateData)...)
+	var planData *LDAPGroupResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &planData)...)
+	stateMembers := L.members(ctx, stateData, &response.Diagnostics)
+	planMembers := L.members(ctx, planData, &response.Diagnostics)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	r := ldap.NewModifyRequest(stateData.DN.ValueString(), []ldap.Control{})
+	if planData.Description.IsNull() && !stateData.Description.IsNull() {
+		r.Delete("description", []string{})
+	} else if !planData.Description.Equal(stateData.Description) {
+		r.Replace("description", []string{planData.Description.ValueString()})
+	}
+	if !planData.GIDNumber.Equal(stateData.GIDNumber) {
+		r.Replace("gidNumber", []string{strconv.FormatInt(planData.GIDNumber.ValueInt64(), 10)})
+	}
+	r.Changes = append(r.Changes, memberChanges(groupKinds[groupTypeOf(planData)], stateMembers, planMembers, placeholderMember(stateData), placeholderMember(planData))...)
+
+	if len(r.Changes) > 0 {
+		start := time.Now()
+		err := WithContext(ctx, func() error {
+			return L.locks.write(ctx, r.DN, func() error {
+				return L.conn.Modify(r)
+			})
+		})
+		LogOperation(ctx, "modify", r.DN, start)
+		if err != nil {
+			addOperationError(&response.Diagnostics, err, "update", r.DN,
+				"Can not modify group",
+				fmt.Sprintf("Trying to modify group %s returned: %s", r.DN, err),
+			)
+			return
+		}
+	}
+	response.Diagnostics.Append(response.State.Set(ctx, &planData)...)
+}
+
+func (L *LDAPGroupResource) Delete(ctx context.Context, request resource.DeleteRequest, response *resource.DeleteResponse) {
+	var data *LDAPGroupResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	start := time.Now()
+	err := WithContext(ctx, func() error {
+		return L.locks.write(ctx, data.DN.ValueString(), func() error {
+			return L.conn.Del(ldap.NewDelRequest(data.DN.ValueString(), []ldap.Control{}))
+		})
+	})
+	LogOperation(ctx, "delete", data.DN.ValueString(), start)
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		addOperationError(&response.Diagnostics, err, "delete", data.DN.ValueString(),
+			"Can not delete group",
+			fmt.Sprintf("Trying to delete group %s returned: %s", data.DN.ValueString(), err),
+		)
+	}
+}
+
+// ImportState imports a group by its DN. The group type is detected from the object classes of the entry.
+func (L *LDAPGroupResource) ImportState(ctx context.Context, request resource.ImportStateRequest, response *resource.ImportStateResponse) {
+	entry, err := GetEntry(L.conn, request.ID, "objectClass", "cn")
+	if err != nil {
+		addOperationError(&response.Diagnostics, err, "import", request.ID,
+			"Can not read group",
+			err.Error(),
+		)
+		return
+	}
+	rdn, parentDN, err := SplitRDN(entry.DN)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Invalid DN",
+			fmt.Sprintf("Can not split the DN %s: %s", entry.DN, err),
+		)
+		return
+	}
+
+	groupType := ""
+	objectClasses := entry.GetAttributeValues("objectClass")
+	for _, candidate := range []string{groupTypeGroupOfNames, groupTypeGroupOfUniqueNames, groupTypePosixGroup, groupTypeActiveDirectory} {
+		if funk.Contains(objectClasses, func(objectClass string) bool {
+			return equalValues(matchingRuleCaseIgnore, objectClass, groupKinds[candidate].objectClasses[0])
+		}) {
+			groupType = candidate
+			break
+		}
+	}
+	if groupType == "" {
+		response.Diagnostics.AddError(
+			"Not a group",
+			fmt.Sprintf("The entry %s has none of the object classes of the supported group types", entry.DN),
+		)
+		return
+	}
+
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("id"), entry.DN)...)
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("dn"), entry.DN)...)
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("name"), rdn.Attributes[0].Value)...)
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("parent_dn"), parentDN)...)
+	if groupType != groupTypeGroupOfNames {
+		response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("group_type"), groupType)...)
+	}
+}
+
+// members returns the members of the group configured in the model.
+func (L *LDAPGroupResource) members(ctx context.Context, data *LDAPGroupResourceModel, diagnostics *diag.Diagnostics) []string {
+	var members []string
+	if data != nil {
+		diagnostics.Append(data.Members.ElementsAs(ctx, &members, false)...)
+	}
+	return members
+}
+
+// groupTypeOf returns the configured group type, which defaults to groupOfNames.
+func groupTypeOf(data *LDAPGroupResourceModel) string {
+	if data.GroupType.IsNull() {
+		return groupTypeGroupOfNames
+	}
+	return data.GroupType.ValueString()
+}
+
+// groupDN returns the DN of the group named by its name below its parent.
+func groupDN(data *LDAPGroupResourceModel) string {
+	return fmt.Sprintf("cn=%s,%s", ldap.EscapeDN(data.Name.ValueString()), data.ParentDN.ValueString())
+}
+
+// placeholderMember returns the member stored in empty groups which require at least one member.
+func placeholderMember(data *LDAPGroupResourceModel) string {
+	if !data.PlaceholderMember.IsNull() {
+		return data.PlaceholderMember.ValueString()
+	}
+	return groupDN(data)
+}
+
+// memberChanges returns the changes adding and deleting single members to get from the state members to the plan
+// members. The placeholder is added before the last member is deleted and deleted once the first member was added, so
+// groups which require a member are never empty.
+func memberChanges(kind groupKind, stateMembers []string, planMembers []string, statePlaceholder string, planPlaceholder string) []ldap.Change {
+	rule := lookupMatchingRule(kind.memberAttribute, nil)
+	var changes []ldap.Change
+	placeholderChange := func(operation uint, placeholder string) {
+		changes = append(changes, ldap.Change{Operation: operation, Modification: ldap.PartialAttribute{Type: kind.memberAttribute, Vals: []string{placeholder}}})
+	}
+
+	if kind.requiresMember && len(planMembers) == 0 && (len(stateMembers) > 0 || !equalValues(rule, statePlaceholder, planPlaceholder)) {
+		placeholderChange(ldap.AddAttribute, planPlaceholder)
+	}
+	if added := subtractValues(rule, planMembers, stateMembers); len(added) > 0 {
+		changes = append(changes, ldap.Change{Operation: ldap.AddAttribute, Modification: ldap.PartialAttribute{Type: kind.memberAttribute, Vals: added}})
+	}
+	if deleted := subtractValues(rule, stateMembers, planMembers); len(deleted) > 0 {
+		changes = append(changes, ldap.Change{Operation: ldap.DeleteAttribute, Modification: ldap.PartialAttribute{Type: kind.memberAttribute, Vals: deleted}})
+	}
+	if kind.requiresMember && len(stateMembers) == 0 && (len(planMembers) > 0 || !equalValues(rule, statePlaceholder, planPlaceholder)) {
+		placeholderChange(ldap.DeleteAttribute, statePlaceholder)
+	}
+	return changes
+}
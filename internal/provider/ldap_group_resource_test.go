@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestLDAPGroupResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testCheckEntryMissing("cn=developers,dc=example,dc=com"),
+		Steps: []resource.TestStep{
+			{
+				Config: testGroupConfig("alice", "bob"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_group.developers", "dn", "cn=developers,dc=example,dc=com"),
+					resource.TestCheckResourceAttr("ldap_group.developers", "members.#", "2"),
+					testCheckServerValues("cn=developers,dc=example,dc=com", "member", []string{"cn=alice,dc=example,dc=com", "cn=bob,dc=example,dc=com"}),
+				),
+			},
+			{
+				Config: testGroupConfig("bob"),
+				Check:  testCheckServerValues("cn=developers,dc=example,dc=com", "member", []string{"cn=bob,dc=example,dc=com"}),
+			},
+			// Empty groups keep the placeholder member, which isn't listed in members
+			{
+				Config: testGroupConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_group.developers", "members.#", "0"),
+					testCheckServerValues("cn=developers,dc=example,dc=com", "member", []string{"cn=developers,dc=example,dc=com"}),
+				),
+			},
+			{
+				Config: testGroupConfig("alice"),
+				Check:  testCheckServerValues("cn=developers,dc=example,dc=com", "member", []string{"cn=alice,dc=example,dc=com"}),
+			},
+			{
+				Config:            testGroupConfig("alice"),
+				ResourceName:      "ldap_group.developers",
+				ImportState:       true,
+				ImportStateId:     "cn=developers,dc=example,dc=com",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+// testGroupConfig creates a groupOfNames with the given persons as members.
+func testGroupConfig(members ...string) string {
+	var memberDNs []string
+	for _, member := range members {
+		memberDNs = append(memberDNs, fmt.Sprintf("ldap_object.%s.dn", member))
+	}
+	return fmt.Sprintf(`
+resource "ldap_object" "alice" {
+	dn = "cn=alice,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["alice"]
+		"sn" = ["alice"]
+	}
+}
+
+resource "ldap_object" "bob" {
+	dn = "cn=bob,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["bob"]
+		"sn" = ["bob"]
+	}
+}
+
+resource "ldap_group" "developers" {
+	name = "developers"
+	parent_dn = "dc=example,dc=com"
+	description = "Developers"
+	members = [%s]
+}
+`, strings.Join(memberDNs, ", "))
+}
+
+func TestLDAPGroupResourcePosixGroup(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testPosixGroupConfig(""),
+				ExpectError: regexp.MustCompile("Missing group id"),
+			},
+			{
+				Config: testPosixGroupConfig("gid_number = 5000"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckServerValues("cn=operators,dc=example,dc=com", "memberUid", []string{"alice", "bob"}),
+					testCheckServerValues("cn=operators,dc=example,dc=com", "gidNumber", []string{"5000"}),
+				),
+			},
+		},
+	})
+}
+
+func testPosixGroupConfig(gidNumber string) string {
+	return fmt.Sprintf(`
+resource "ldap_group" "operators" {
+	name = "operators"
+	parent_dn = "dc=example,dc=com"
+	group_type = "posixGroup"
+	members = ["alice", "bob"]
+	%s
+}
+`, gidNumber)
+}
+
+func TestMemberChanges(t *testing.T) {
+	groupOfNames := groupKinds[groupTypeGroupOfNames]
+	placeholder := "cn=group,dc=example,dc=com"
+
+	// the placeholder is added before the last member is deleted
+	assert.Equal(t, []ldap.Change{
+		{Operation: ldap.AddAttribute, Modification: ldap.PartialAttribute{Type: "member", Vals: []string{placeholder}}},
+		{Operation: ldap.DeleteAttribute, Modification: ldap.PartialAttribute{Type: "member", Vals: []string{"cn=alice,dc=example,dc=com"}}},
+	}, memberChanges(groupOfNames, []string{"cn=alice,dc=example,dc=com"}, nil, placeholder, placeholder))
+
+	// and deleted after the first member was added
+	assert.Equal(t, []ldap.Change{
+		{Operation: ldap.AddAttribute, Modification: ldap.PartialAttribute{Type: "member", Vals: []string{"cn=alice,dc=example,dc=com"}}},
+		{Operation: ldap.DeleteAttribute, Modification: ldap.PartialAttribute{Type: "member", Vals: []string{placeholder}}},
+	}, memberChanges(groupOfNames, nil, []string{"cn=alice,dc=example,dc=com"}, placeholder, placeholder))
+
+	// members are compared as DNs
+	assert.Equal(t, []ldap.Change{
+		{Operation: ldap.AddAttribute, Modification: ldap.PartialAttribute{Type: "member", Vals: []string{"cn=carol,dc=example,dc=com"}}},
+		{Operation: ldap.DeleteAttribute, Modification: ldap.PartialAttribute{Type: "member", Vals: []string{"cn=bob,dc=example,dc=com"}}},
+	}, memberChanges(groupOfNames,
+		[]string{"cn=alice,dc=example,dc=com", "cn=bob,dc=example,dc=com"},
+		[]string{"CN=Alice, dc=example,dc=com", "cn=carol,dc=example,dc=com"},
+		placeholder, placeholder,
+	))
+
+	// a changed placeholder of an empty group is replaced
+	assert.Equal(t, []ldap.Change{
+		{Operation: ldap.AddAttribute, Modification: ldap.PartialAttribute{Type: "member", Vals: []string{"cn=nobody"}}},
+		{Operation: ldap.DeleteAttribute, Modification: ldap.PartialAttribute{Type: "member", Vals: []string{placeholder}}},
+	}, memberChanges(groupOfNames, nil, nil, placeholder, "cn=nobody"))
+	assert.Empty(t, memberChanges(groupOfNames, nil, nil, placeholder, placeholder))
+
+	// posix groups can be empty
+	assert.Equal(t, []ldap.Change{
+		{Operation: ldap.DeleteAttribute, Modification: ldap.PartialAttribute{Type: "memberUid", Vals: []string{"alice"}}},
+	}, memberChanges(groupKinds[groupTypePosixGroup], []string{"alice"}, nil, "", ""))
+}
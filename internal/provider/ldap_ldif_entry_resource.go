@@ -0,0 +1,356 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"strings"
+	"time"
+)
+
+var _ resource.Resource = &LDAPLDIFEntryResource{}
+var _ resource.ResourceWithConfigure = &LDAPLDIFEntryResource{}
+var _ resource.ResourceWithModifyPlan = &LDAPLDIFEntryResource{}
+var _ resource.ResourceWithValidateConfig = &LDAPLDIFEntryResource{}
+
+func NewLDAPLDIFEntryResource() resource.Resource {
+	return &LDAPLDIFEntryResource{}
+}
+
+type LDAPLDIFEntryResource struct {
+	conn  *ldap.Conn
+	locks *dnLocks
+}
+
+type LDAPLDIFEntryResourceModel struct {
+	ID         types.String `tfsdk:"id"`
+	DN         types.String `tfsdk:"dn"`
+	LDIF       types.String `tfsdk:"ldif"`
+	Attributes types.Map    `tfsdk:"attributes"`
+}
+
+func (L *LDAPLDIFEntryResource) Configure(_ context.Context, request resource.ConfigureRequest, response *resource.ConfigureResponse) {
+	if request.ProviderData == nil {
+		return
+	}
+
+	if client, ok := request.ProviderData.(*ldapClient); !ok {
+		response.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ldapClient, got: %T. Please report this issue to the provider developers.", request.ProviderData),
+		)
+
+		return
+	} else {
+		L.conn = client.conn
+		L.locks = client.locks
+	}
+}
+
+func (L *LDAPLDIFEntryResource) Metadata(_ context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_ldif_entry"
+}
+
+func (L *LDAPLDIFEntryResource) Schema(_ context.Context, _ resource.SchemaRequest, response *resource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		MarkdownDescription: "Manages an entry described by a single LDIF content record (RFC 2849). Changes of the LDIF " +
+			"replace the changed attributes of the entry, only a changed DN recreates it",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Resource identifier",
+			},
+			"dn": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "DN of the entry as given in the LDIF",
+			},
+			"ldif": schema.StringAttribute{
+				MarkdownDescription: "The LDIF record of the entry, starting with its `dn`. Folded lines, comments and base64 encoded values are supported, change records and values referenced by URL are not",
+				Required:            true,
+			},
+			"attributes": schema.MapAttribute{
+				MarkdownDescription: "The attributes of the entry on the server, limited to the attribute types of the LDIF. Values which aren't valid UTF-8 are base64 encoded",
+				Computed:            true,
+				ElementType:         types.ListType{ElemType: types.StringType},
+			},
+		},
+	}
+}
+
+func (L *LDAPLDIFEntryResource) ValidateConfig(ctx context.Context, request resource.ValidateConfigRequest, response *resource.ValidateConfigResponse) {
+	var data *LDAPLDIFEntryResourceModel
+	response.Diagnostics.Append(request.Config.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() || data.LDIF.IsUnknown() {
+		return
+	}
+
+	if _, err := ParseLDIFRecord(data.LDIF.ValueString()); err != nil {
+		response.Diagnostics.AddAttributeError(
+			path.Root("ldif"),
+			"Invalid LDIF",
+			err.Error(),
+		)
+	}
+}
+
+// ModifyPlan plans the DN and the attributes of the LDIF, so changed attributes show up in the plan. Since the entry
+// isn't renamed, a changed DN requires a replacement.
+func (L *LDAPLDIFEntryResource) ModifyPlan(ctx context.Context, request resource.ModifyPlanRequest, response *resource.ModifyPlanResponse) {
+	var stateData *LDAPLDIFEntryResourceModel
+	var planData *LDAPLDIFEntryResourceModel
+
+	response.Diagnostics.Append(request.State.Get(ctx, &stateData)...)
+	response.Diagnostics.Append(request.Plan.Get(ctx, &planData)...)
+	if response.Diagnostics.HasError() || planData == nil {
+		return
+	}
+
+	if planData.LDIF.IsUnknown() {
+		planData.DN = types.StringUnknown()
+		planData.Attributes = types.MapUnknown(types.ListType{ElemType: types.StringType})
+	} else if record, err := ParseLDIFRecord(planData.LDIF.ValueString()); err != nil {
+		// reported by ValidateConfig
+		return
+	} else {
+		planData.DN = types.StringValue(record.DN)
+		if stateData != nil && sameDN(stateData.DN.ValueString(), record.DN) {
+			planData.DN = stateData.DN
+		} else if stateData != nil {
+			response.RequiresReplace = append(response.RequiresReplace, path.Root("ldif"))
+		}
+		attributes, d := types.MapValueFrom(ctx, types.ListType{ElemType: types.StringType}, ldifAttributes(record))
+		response.Diagnostics.Append(d...)
+		planData.Attributes = attributes
+	}
+	planData.ID = planData.DN
+	response.Diagnostics.Append(response.Plan.Set(ctx, &planData)...)
+}
+
+func (L *LDAPLDIFEntryResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	var data *LDAPLDIFEntryResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &data)...)
+	record := parseLDIF(data, &response.Diagnostics)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	data.DN = types.StringValue(record.DN)
+	data.ID = data.DN
+	a := ldap.NewAddRequest(record.DN, []ldap.Control{})
+	for _, attributeType := range record.Types {
+		a.Attribute(attributeType, rawValues(record.Attributes[attributeType]))
+	}
+
+	start := time.Now()
+	err := WithContext(ctx, func() error {
+		return L.locks.write(ctx, a.DN, func() error {
+			return L.conn.Add(a)
+		})
+	})
+	LogOperation(ctx, "add", a.DN, start)
+	if err != nil {
+		addOperationError(&response.Diagnostics, err, "create", a.DN,
+			"Can not create entry",
+			fmt.Sprintf("Trying to add entry %s returned: %s", a.DN, err),
+		)
+		return
+	}
+
+	attributes, d := types.MapValueFrom(ctx, types.ListType{ElemType: types.StringType}, ldifAttributes(record))
+	response.Diagnostics.Append(d...)
+	data.Attributes = attributes
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPLDIFEntryResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	var data *LDAPLDIFEntryResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	var stateAttributes map[string][]string
+	response.Diagnostics.Append(data.Attributes.ElementsAs(ctx, &stateAttributes, false)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	start := time.Now()
+	entry, err := GetEntry(L.conn, data.DN.ValueString(), sortedKeys(stateAttributes)...)
+	LogOperation(ctx, "search", data.DN.ValueString(), start)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) || errors.Is(err, ErrEntryNotFound) {
+		tflog.Warn(ctx, "Entry was deleted outside of Terraform, removing it from the state", map[string]interface{}{"dn": data.DN.ValueString()})
+		response.State.RemoveResource(ctx)
+		return
+	} else if err != nil {
+		addOperationError(&response.Diagnostics, err, "read", data.DN.ValueString(),
+			"Can not read entry",
+			err.Error(),
+		)
+		return
+	}
+
+	for attributeType, stateValues := range stateAttributes {
+		serverValues := representValues(entry.GetEqualFoldRawAttributeValues(attributeType))
+		rule := ldifMatchingRule(attributeType)
+		if sameValues(rule, serverValues, stateValues) {
+			// keeps the order of the LDIF, which the server doesn't have to preserve
+			continue
+		}
+		stateAttributes[attributeType] = preferStateValues(rule, serverValues, stateValues)
+	}
+	attributes, d := types.MapValueFrom(ctx, types.ListType{ElemType: types.StringType}, stateAttributes)
+	response.Diagnostics.Append(d...)
+	data.Attributes = attributes
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPLDIFEntryResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
+	var stateData *LDAPLDIFEntryResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &stateData)...)
+	var planData *LDAPLDIFEntryResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &planData)...)
+	var stateAttributes map[string][]string
+	response.Diagnostics.Append(stateData.Attributes.ElementsAs(ctx, &stateAttributes, false)...)
+	record := parseLDIF(planData, &response.Diagnostics)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	r := ldap.NewModifyRequest(stateData.DN.ValueString(), []ldap.Control{})
+	r.Changes = ldifChanges(record, stateAttributes)
+	if len(r.Changes) > 0 {
+		start := time.Now()
+		err := WithContext(ctx, func() error {
+			return L.locks.write(ctx, r.DN, func() error {
+				return L.conn.Modify(r)
+			})
+		})
+		LogOperation(ctx, "modify", r.DN, start)
+		if err != nil {
+			addOperationError(&response.Diagnostics, err, "update", r.DN,
+				"Can not modify entry",
+				fmt.Sprintf("Trying to modify entry %s returned: %s", r.DN, err),
+			)
+			return
+		}
+	}
+
+	planData.DN = stateData.DN
+	planData.ID = stateData.ID
+	attributes, d := types.MapValueFrom(ctx, types.ListType{ElemType: types.StringType}, ldifAttributes(record))
+	response.Diagnostics.Append(d...)
+	planData.Attributes = attributes
+	response.Diagnostics.Append(response.State.Set(ctx, &planData)...)
+}
+
+func (L *LDAPLDIFEntryResource) Delete(ctx context.Context, request resource.DeleteRequest, response *resource.DeleteResponse) {
+	var data *LDAPLDIFEntryResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	start := time.Now()
+	err := WithContext(ctx, func() error {
+		return L.locks.write(ctx, data.DN.ValueString(), func() error {
+			return L.conn.Del(ldap.NewDelRequest(data.DN.ValueString(), []ldap.Control{}))
+		})
+	})
+	LogOperation(ctx, "delete", data.DN.ValueString(), start)
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		addOperationError(&response.Diagnostics, err, "delete", data.DN.ValueString(),
+			"Can not delete entry",
+			fmt.Sprintf("Trying to delete entry %s returned: %s", data.DN.ValueString(), err),
+		)
+	}
+}
+
+// parseLDIF parses the LDIF of the model, which was already validated.
+func parseLDIF(data *LDAPLDIFEntryResourceModel, diagnostics *diag.Diagnostics) *LDIFRecord {
+	if data == nil {
+		return nil
+	}
+	record, err := ParseLDIFRecord(data.LDIF.ValueString())
+	if err != nil {
+		diagnostics.AddAttributeError(
+			path.Root("ldif"),
+			"Invalid LDIF",
+			err.Error(),
+		)
+	}
+	return record
+}
+
+// ldifChanges returns the changes replacing the attributes of the state, which differ from the LDIF record, and
+// deleting the attributes, which were removed from it.
+func ldifChanges(record *LDIFRecord, stateAttributes map[string][]string) []ldap.Change {
+	var changes []ldap.Change
+	planAttributes := ldifAttributes(record)
+	for _, attributeType := range record.Types {
+		var stateValues []string
+		for stateType, values := range stateAttributes {
+			if strings.EqualFold(stateType, attributeType) {
+				stateValues = values
+			}
+		}
+		if !sameValues(ldifMatchingRule(attributeType), stateValues, planAttributes[attributeType]) {
+			changes = append(changes, ldap.Change{
+				Operation:    ldap.ReplaceAttribute,
+				Modification: ldap.PartialAttribute{Type: attributeType, Vals: rawValues(record.Attributes[attributeType])},
+			})
+		}
+	}
+	for _, attributeType := range sortedKeys(stateAttributes) {
+		if !containsFold(record.Types, attributeType) && len(stateAttributes[attributeType]) > 0 {
+			changes = append(changes, ldap.Change{
+				Operation:    ldap.DeleteAttribute,
+				Modification: ldap.PartialAttribute{Type: attributeType, Vals: []string{}},
+			})
+		}
+	}
+	return changes
+}
+
+// ldifAttributes returns the attributes of the record as they are represented in the attributes of the resource.
+func ldifAttributes(record *LDIFRecord) map[string][]string {
+	attributes := map[string][]string{}
+	for attributeType, values := range record.Attributes {
+		attributes[attributeType] = representValues(values)
+	}
+	return attributes
+}
+
+// ldifMatchingRule returns the matching rule of the attribute type, treating object classes as case-insensitive.
+func ldifMatchingRule(attributeType string) string {
+	if strings.EqualFold(attributeType, "objectClass") {
+		return matchingRuleCaseIgnore
+	}
+	return lookupMatchingRule(attributeType, nil)
+}
+
+// representValues returns the values as strings, which are base64 encoded unless all of them are valid UTF-8.
+func representValues(values [][]byte) []string {
+	if !isText(values) {
+		return encodeBinaryValues(values)
+	}
+	return rawValues(values)
+}
+
+// rawValues converts the values to strings without encoding them, as expected by add and modify requests.
+func rawValues(values [][]byte) []string {
+	converted := make([]string, len(values))
+	for i, value := range values {
+		converted[i] = string(value)
+	}
+	return converted
+}
+
+// sameValues checks whether both lists contain the same values according to the matching rule, regardless of their
+// order.
+func sameValues(rule string, a []string, b []string) bool {
+	return len(a) == len(b) && len(subtractValues(rule, a, b)) == 0 && len(subtractValues(rule, b, a)) == 0
+}
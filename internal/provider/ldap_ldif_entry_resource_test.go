@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"regexp"
+	"testing"
+)
+
+func TestLDAPLDIFEntryResource(t *testing.T) {
+	var entryUUID string
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testCheckEntryMissing("cn=ldif,dc=example,dc=com"),
+		Steps: []resource.TestStep{
+			{
+				Config: testLDIFEntryConfig("description:: RMO2ZQ=="),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_ldif_entry.test", "dn", "cn=ldif,dc=example,dc=com"),
+					testCheckServerValues("cn=ldif,dc=example,dc=com", "description", []string{"Döe"}),
+					testCheckServerValues("cn=ldif,dc=example,dc=com", "sn", []string{"a folded surname"}),
+					testCaptureEntryUUID("cn=ldif,dc=example,dc=com", &entryUUID),
+				),
+			},
+			// Changing an attribute modifies the entry instead of replacing it
+			{
+				Config: testLDIFEntryConfig("description: changed"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckServerValues("cn=ldif,dc=example,dc=com", "description", []string{"changed"}),
+					testCheckEntryUUID("cn=ldif,dc=example,dc=com", &entryUUID, true),
+				),
+			},
+			// Values changed outside of Terraform are set again
+			{
+				PreConfig: testAddValueExternally(t, "cn=ldif,dc=example,dc=com", "description", "external"),
+				Config:    testLDIFEntryConfig("description: changed"),
+				Check:     testCheckServerValues("cn=ldif,dc=example,dc=com", "description", []string{"changed"}),
+			},
+			{
+				Config:      testLDIFEntryConfig("changetype: delete"),
+				ExpectError: regexp.MustCompile("Invalid LDIF"),
+			},
+		},
+	})
+}
+
+// testLDIFEntryConfig creates the entry cn=ldif with a folded surname and the given further lines.
+func testLDIFEntryConfig(lines string) string {
+	return fmt.Sprintf(`
+resource "ldap_ldif_entry" "test" {
+	ldif = <<-EOT
+		dn: cn=ldif,dc=example,dc=com
+		objectClass: person
+		cn: ldif
+		sn: a folded
+		  surname
+		%s
+	EOT
+}
+`, lines)
+}
@@ -4,10 +4,19 @@ import (
 	"context"
 	"fmt"
 	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/thoas/go-funk"
+	"strconv"
+	"strings"
+	"time"
 )
 
 var _ datasource.DataSource = &LDAPObjectDataSource{}
@@ -18,17 +27,45 @@ func NewLDAPObjectDataSource() datasource.DataSource {
 }
 
 type LDAPObjectDataSource struct {
-	conn *ldap.Conn
+	conn           *ldap.Conn
+	controlSupport *controlSupport
 }
 
 type LDAPObjectDatasourceModel struct {
 	Id                   types.String `tfsdk:"id"`
 	DN                   types.String `tfsdk:"dn"`
+	ParentDN             types.String `tfsdk:"parent_dn"`
+	NormalizedDN         types.String `tfsdk:"normalized_dn"`
+	UseEntryDN           types.Bool   `tfsdk:"use_entry_dn"`
 	ObjectClasses        types.List   `tfsdk:"object_classes"`
 	Attributes           types.Map    `tfsdk:"attributes"`
+	SingleAttributes     types.Map    `tfsdk:"single_attributes"`
+	LocalizedAttributes  types.Map    `tfsdk:"localized_attributes"`
 	AdditionalAttributes types.Set    `tfsdk:"additional_attributes"`
+	Controls             types.List   `tfsdk:"controls"`
+	DontUseCopy          types.Bool   `tfsdk:"dont_use_copy"`
+	ManageDsaIT          types.Bool   `tfsdk:"manage_dsa_it"`
+	DerefAliases         types.String `tfsdk:"deref_aliases"`
+	TypedAttributes      types.Map    `tfsdk:"typed_attributes"`
+	Typed                types.Object `tfsdk:"typed"`
+	HasSubordinates      types.Bool   `tfsdk:"has_subordinates"`
+	NumSubordinates      types.Int64  `tfsdk:"num_subordinates"`
+	ReadDurationMs       types.Int64  `tfsdk:"read_duration_ms"`
 }
 
+// LDAPTypedAttributesModel holds the values of the attributes configured in typed_attributes, grouped by their type.
+type LDAPTypedAttributesModel struct {
+	Int  types.Map `tfsdk:"int"`
+	Bool types.Map `tfsdk:"bool"`
+	Time types.Map `tfsdk:"time"`
+}
+
+const (
+	typedAttributeInt  = "int"
+	typedAttributeBool = "bool"
+	typedAttributeTime = "time"
+)
+
 func (L *LDAPObjectDataSource) Metadata(_ context.Context, request datasource.MetadataRequest, response *datasource.MetadataResponse) {
 	response.TypeName = request.ProviderTypeName + "_object"
 }
@@ -44,12 +81,43 @@ func (L *LDAPObjectDataSource) Schema(_ context.Context, _ datasource.SchemaRequ
 			"dn": schema.StringAttribute{
 				MarkdownDescription: "DN of this ldap object",
 				Required:            true,
+				Validators: []validator.String{
+					IsValidDN(),
+				},
+			},
+			"parent_dn": schema.StringAttribute{
+				MarkdownDescription: "DN of the parent of this ldap object. Empty if the object is the root of a naming context",
+				Computed:            true,
+			},
+			"normalized_dn": schema.StringAttribute{
+				MarkdownDescription: "DN of this ldap object as returned by the server, which is the value of the `entryDN` operational attribute if `use_entry_dn` is set and the server supports it",
+				Computed:            true,
+			},
+			"use_entry_dn": schema.BoolAttribute{
+				MarkdownDescription: "Whether to request the `entryDN` operational attribute (RFC 5020), which some servers return in its canonical form, and use it for `normalized_dn` and `parent_dn` instead of the DN of the search result",
+				Optional:            true,
 			},
 			"additional_attributes": schema.SetAttribute{
 				MarkdownDescription: "Any additional attributes to request, such as constructed attributes",
 				Optional:            true,
 				ElementType:         types.StringType,
 			},
+			"dont_use_copy": schema.BoolAttribute{
+				MarkdownDescription: "Whether to send the don't use copy control (RFC 6171), so the server doesn't answer from a possibly outdated copy of the data, but returns a referral or an error instead",
+				Optional:            true,
+			},
+			"manage_dsa_it": schema.BoolAttribute{
+				MarkdownDescription: "Whether to send the ManageDsaIT control (RFC 3296), so a referral object is read like an ordinary entry instead of being returned as a referral",
+				Optional:            true,
+			},
+			"deref_aliases": schema.StringAttribute{
+				MarkdownDescription: "How aliases are dereferenced: `never` (default), `searching` below the base DN, `finding` the base DN or `always`",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("never", "searching", "finding", "always"),
+				},
+			},
+			"controls": controlsDataSourceAttribute(),
 			"object_classes": schema.ListAttribute{
 				MarkdownDescription: "A list of classes this object implements",
 				ElementType:         types.StringType,
@@ -60,6 +128,59 @@ func (L *LDAPObjectDataSource) Schema(_ context.Context, _ datasource.SchemaRequ
 				Computed:            true,
 				ElementType:         types.ListType{ElemType: types.StringType},
 			},
+			"single_attributes": schema.MapAttribute{
+				MarkdownDescription: "The attributes of `attributes` with exactly one value, mapped to that value, e.g. `single_attributes[\"mail\"]` instead of `attributes[\"mail\"][0]`. Attributes with several values are left out",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+			"localized_attributes": schema.MapAttribute{
+				MarkdownDescription: "The attributes with language tags (e.g. `description;lang-en`), grouped by attribute type and language",
+				Computed:            true,
+				ElementType: types.MapType{
+					ElemType: types.ListType{ElemType: types.StringType},
+				},
+			},
+			"typed_attributes": schema.MapAttribute{
+				MarkdownDescription: "Single-valued attributes to convert to a type, given by the attribute type and one of `int`, `bool` or `time`. The converted values are available in `typed`",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.Map{
+					mapvalidator.ValueStringsAre(stringvalidator.OneOf(typedAttributeInt, typedAttributeBool, typedAttributeTime)),
+				},
+			},
+			"typed": schema.SingleNestedAttribute{
+				MarkdownDescription: "The values of the attributes configured in `typed_attributes`, grouped by their type",
+				Computed:            true,
+				Attributes: map[string]schema.Attribute{
+					"int": schema.MapAttribute{
+						MarkdownDescription: "Integer attributes",
+						Computed:            true,
+						ElementType:         types.Int64Type,
+					},
+					"bool": schema.MapAttribute{
+						MarkdownDescription: "Boolean attributes",
+						Computed:            true,
+						ElementType:         types.BoolType,
+					},
+					"time": schema.MapAttribute{
+						MarkdownDescription: "Generalized time attributes, converted to RFC 3339 timestamps",
+						Computed:            true,
+						ElementType:         types.StringType,
+					},
+				},
+			},
+			"has_subordinates": schema.BoolAttribute{
+				MarkdownDescription: "Whether the object has children. Only set if the server supports the `hasSubordinates` operational attribute",
+				Computed:            true,
+			},
+			"num_subordinates": schema.Int64Attribute{
+				MarkdownDescription: "The number of children of the object. Only set if the server supports the `numSubordinates` operational attribute",
+				Computed:            true,
+			},
+			"read_duration_ms": schema.Int64Attribute{
+				MarkdownDescription: "Time in milliseconds it took to read the object from the server",
+				Computed:            true,
+			},
 		},
 	}
 }
@@ -69,15 +190,16 @@ func (L *LDAPObjectDataSource) Configure(_ context.Context, request datasource.C
 		return
 	}
 
-	if conn, ok := request.ProviderData.(*ldap.Conn); !ok {
+	if client, ok := request.ProviderData.(*ldapClient); !ok {
 		response.Diagnostics.AddError(
 			"Unexpected Datasource Configure Type",
-			fmt.Sprintf("Expected *ldap.Conn, got: %T. Please report this issue to the provider developers.", request.ProviderData),
+			fmt.Sprintf("Expected *ldapClient, got: %T. Please report this issue to the provider developers.", request.ProviderData),
 		)
 
 		return
 	} else {
-		L.conn = conn
+		L.conn = client.conn
+		L.controlSupport = client.controlSupport
 	}
 }
 
@@ -100,19 +222,174 @@ func (L *LDAPObjectDataSource) Read(ctx context.Context, request datasource.Read
 	var additionalAttributes []string
 	response.Diagnostics.Append(data.AdditionalAttributes.ElementsAs(ctx, &additionalAttributes, false)...)
 
-	if entry, err := GetEntry(L.conn, data.DN.ValueString(), append(additionalAttributes, "*")...); err != nil {
-		response.Diagnostics.AddError(
+	var typedAttributes map[string]string
+	response.Diagnostics.Append(data.TypedAttributes.ElementsAs(ctx, &typedAttributes, false)...)
+
+	controls := BuildControls(ctx, data.Controls, &response.Diagnostics)
+	if data.DontUseCopy.ValueBool() {
+		controls = append(controls, NewControlDontUseCopy())
+	}
+	if data.ManageDsaIT.ValueBool() {
+		controls = append(controls, ldap.NewControlManageDsaIT(true))
+	}
+	if err := L.controlSupport.check(L.conn, controls); err != nil {
+		addControlSupportError(&response.Diagnostics, err)
+	}
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	requestedAttributes := append(additionalAttributes, "*", "hasSubordinates", "numSubordinates")
+	if data.UseEntryDN.ValueBool() {
+		requestedAttributes = append(requestedAttributes, "entryDN")
+	}
+	for attributeType := range typedAttributes {
+		requestedAttributes = append(requestedAttributes, attributeType)
+	}
+
+	start := time.Now()
+	entry, err := GetEntryWithDerefAliases(L.conn, data.DN.ValueString(), derefAliasesPolicies[data.DerefAliases.ValueString()], controls, requestedAttributes...)
+	response.State.SetAttribute(ctx, path.Root("read_duration_ms"), LogOperation(ctx, "search", data.DN.ValueString(), start).Milliseconds())
+	if err != nil {
+		addOperationError(&response.Diagnostics, err, "read", data.DN.ValueString(),
 			"Can not read entry",
 			err.Error(),
 		)
 	} else {
 		response.State.SetAttribute(ctx, path.Root("dn"), entry.DN)
+		normalizedDN := canonicalDN(entry, data.UseEntryDN.ValueBool())
+		response.State.SetAttribute(ctx, path.Root("normalized_dn"), normalizedDN)
+
+		parentDN, err := ParentDN(normalizedDN)
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can not parse DN",
+				err.Error(),
+			)
+			return
+		}
+		if isNamingContext, err := IsNamingContext(L.conn, normalizedDN); err != nil {
+			response.Diagnostics.AddWarning(
+				"Can not read naming contexts",
+				fmt.Sprintf("Unable to check whether %s is the root of a naming context: %s", normalizedDN, err),
+			)
+		} else if isNamingContext {
+			parentDN = ""
+		}
+		response.State.SetAttribute(ctx, path.Root("parent_dn"), parentDN)
+
+		hasSubordinates, numSubordinates := subordinates(entry)
+		response.State.SetAttribute(ctx, path.Root("has_subordinates"), hasSubordinates)
+		response.State.SetAttribute(ctx, path.Root("num_subordinates"), numSubordinates)
+
+		typed := typedValues(entry, typedAttributes, &response.Diagnostics)
+		response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("typed"), typed)...)
+
 		for _, attribute := range entry.Attributes {
-			if attribute.Name == "objectClass" {
+			if isSubordinatesAttribute(attribute.Name) && !funk.ContainsString(additionalAttributes, attribute.Name) && typedAttributes[attribute.Name] == "" {
+				// only requested for has_subordinates and num_subordinates
+				continue
+			} else if data.UseEntryDN.ValueBool() && strings.EqualFold(attribute.Name, "entryDN") && !funk.ContainsString(additionalAttributes, attribute.Name) {
+				// only requested for normalized_dn
+				continue
+			} else if attribute.Name == "objectClass" {
 				response.State.SetAttribute(ctx, path.Root("object_classes"), attribute.Values)
 			} else {
 				response.State.SetAttribute(ctx, path.Root("attributes").AtMapKey(attribute.Name), attribute.Values)
+				if len(attribute.Values) == 1 {
+					response.State.SetAttribute(ctx, path.Root("single_attributes").AtMapKey(attribute.Name), attribute.Values[0])
+				}
+				if attributeType, language, isLocalized := SplitLanguageTag(attribute.Name); isLocalized {
+					response.State.SetAttribute(ctx, path.Root("localized_attributes").AtMapKey(attributeType).AtMapKey(language), attribute.Values)
+				}
+			}
+		}
+	}
+}
+
+// canonicalDN returns the DN of the entry as stored by the server. The entryDN operational attribute is preferred if
+// requested, since the DN of the search result may reflect the spelling of the search base.
+func canonicalDN(entry ldap.Entry, useEntryDN bool) string {
+	if useEntryDN {
+		if entryDN := entry.GetEqualFoldAttributeValue("entryDN"); entryDN != "" {
+			return entryDN
+		}
+	}
+	return entry.DN
+}
+
+// subordinates reads the hasSubordinates and numSubordinates operational attributes of an entry. The values are null
+// if the server doesn't support them.
+func subordinates(entry ldap.Entry) (types.Bool, types.Int64) {
+	hasSubordinates := types.BoolNull()
+	numSubordinates := types.Int64Null()
+
+	if value := entry.GetAttributeValue("numSubordinates"); value != "" {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			numSubordinates = types.Int64Value(n)
+			hasSubordinates = types.BoolValue(n > 0)
+		}
+	}
+	if value := entry.GetAttributeValue("hasSubordinates"); value != "" {
+		hasSubordinates = types.BoolValue(strings.EqualFold(value, "TRUE"))
+	}
+	return hasSubordinates, numSubordinates
+}
+
+// isSubordinatesAttribute checks whether the attribute is one of the operational attributes read by subordinates.
+func isSubordinatesAttribute(name string) bool {
+	return strings.EqualFold(name, "hasSubordinates") || strings.EqualFold(name, "numSubordinates")
+}
+
+// typedValues converts the attributes configured in typed_attributes to their type. The attributes have to have
+// exactly one value.
+func typedValues(entry ldap.Entry, typedAttributes map[string]string, diagnostics *diag.Diagnostics) LDAPTypedAttributesModel {
+	ints := map[string]attr.Value{}
+	bools := map[string]attr.Value{}
+	times := map[string]attr.Value{}
+
+	for attributeType, kind := range typedAttributes {
+		values := entry.GetEqualFoldAttributeValues(attributeType)
+		if len(values) != 1 {
+			diagnostics.AddAttributeError(
+				path.Root("typed_attributes").AtMapKey(attributeType),
+				"Can not convert attribute",
+				fmt.Sprintf("Only single-valued attributes can be converted, but %s has %d values", attributeType, len(values)),
+			)
+			continue
+		}
+
+		var err error
+		switch kind {
+		case typedAttributeInt:
+			var n int64
+			if n, err = strconv.ParseInt(values[0], 10, 64); err == nil {
+				ints[attributeType] = types.Int64Value(n)
+			}
+		case typedAttributeBool:
+			if strings.EqualFold(values[0], "TRUE") || strings.EqualFold(values[0], "FALSE") {
+				bools[attributeType] = types.BoolValue(strings.EqualFold(values[0], "TRUE"))
+			} else {
+				err = fmt.Errorf("%s is not TRUE or FALSE", values[0])
 			}
+		case typedAttributeTime:
+			var t time.Time
+			if t, err = ParseGeneralizedTime(values[0]); err == nil {
+				times[attributeType] = types.StringValue(t.Format(time.RFC3339Nano))
+			}
+		}
+		if err != nil {
+			diagnostics.AddAttributeError(
+				path.Root("typed_attributes").AtMapKey(attributeType),
+				"Can not convert attribute",
+				fmt.Sprintf("Can not convert the value of %s to %s: %s", attributeType, kind, err),
+			)
 		}
 	}
+
+	return LDAPTypedAttributesModel{
+		Int:  types.MapValueMust(types.Int64Type, ints),
+		Bool: types.MapValueMust(types.BoolType, bools),
+		Time: types.MapValueMust(types.StringType, times),
+	}
 }
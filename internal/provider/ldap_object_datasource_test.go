@@ -1,7 +1,14 @@
 package provider
 
 import (
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/assert"
+	"regexp"
 	"testing"
 )
 
@@ -16,9 +23,11 @@ func TestLDAPObjectDatasource(t *testing.T) {
 				Config: testDataSource,
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("data.ldap_object.test", "dn", "dc=example,dc=com"),
+					resource.TestCheckResourceAttr("data.ldap_object.test", "parent_dn", ""),
 					resource.TestCheckResourceAttr("data.ldap_object.test", "object_classes.#", "3"),
 					resource.TestCheckResourceAttr("data.ldap_object.test", "attributes.dc.0", "example"),
 					resource.TestCheckResourceAttr("data.ldap_object.test", "attributes.creatorsName.0", "cn=admin,dc=example,dc=com"),
+					resource.TestCheckResourceAttrWith("data.ldap_object.test", "read_duration_ms", testCheckNonNegative),
 				),
 			},
 		},
@@ -30,3 +39,257 @@ data "ldap_object" "test" {
 	dn = "dc=example,dc=com"
 	additional_attributes = ["creatorsName"]
 }`
+
+func TestLDAPObjectDatasourceControls(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Unknown non-critical controls are ignored by the server
+			{
+				Config: testDataSourceControls(false),
+				Check:  resource.TestCheckResourceAttr("data.ldap_object.test", "attributes.dc.0", "example"),
+			},
+			// Unknown critical controls are rejected, which shows that the control was sent
+			{
+				Config:      testDataSourceControls(true),
+				ExpectError: regexp.MustCompile("Unavailable Critical Extension"),
+			},
+		},
+	})
+}
+
+func testDataSourceControls(criticality bool) string {
+	return fmt.Sprintf(`
+data "ldap_object" "test" {
+	dn = "dc=example,dc=com"
+	controls = [
+		{
+			oid = "1.3.6.1.4.1.99999.1"
+			value = "dGVzdA=="
+			criticality = %t
+		}
+	]
+}`, criticality)
+}
+
+func TestLDAPObjectDatasourceSubordinates(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testDataSource,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.ldap_object.test", "has_subordinates", "true"),
+					resource.TestCheckNoResourceAttr("data.ldap_object.test", "attributes.hasSubordinates"),
+				),
+			},
+		},
+	})
+}
+
+func TestSubordinates(t *testing.T) {
+	hasSubordinates, numSubordinates := subordinates(*ldap.NewEntry("ou=people,dc=example,dc=com", map[string][]string{
+		"numSubordinates": {"42"},
+	}))
+	assert.True(t, hasSubordinates.ValueBool())
+	assert.Equal(t, int64(42), numSubordinates.ValueInt64())
+
+	hasSubordinates, numSubordinates = subordinates(*ldap.NewEntry("cn=leaf,dc=example,dc=com", map[string][]string{
+		"hasSubordinates": {"FALSE"},
+		"numSubordinates": {"0"},
+	}))
+	assert.False(t, hasSubordinates.ValueBool())
+	assert.Equal(t, int64(0), numSubordinates.ValueInt64())
+
+	hasSubordinates, numSubordinates = subordinates(*ldap.NewEntry("cn=test,dc=example,dc=com", map[string][]string{
+		"hasSubordinates": {"TRUE"},
+	}))
+	assert.True(t, hasSubordinates.ValueBool())
+	assert.True(t, numSubordinates.IsNull())
+
+	hasSubordinates, numSubordinates = subordinates(*ldap.NewEntry("cn=test,dc=example,dc=com", map[string][]string{}))
+	assert.True(t, hasSubordinates.IsNull())
+	assert.True(t, numSubordinates.IsNull())
+}
+
+func TestLDAPObjectDatasourceEntryDN(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "ldap_object" "test" {
+	dn = "dc=example,dc=com"
+	use_entry_dn = true
+}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.ldap_object.test", "normalized_dn", "dc=example,dc=com"),
+					resource.TestCheckNoResourceAttr("data.ldap_object.test", "attributes.entryDN"),
+				),
+			},
+		},
+	})
+}
+
+func TestCanonicalDN(t *testing.T) {
+	entry := *ldap.NewEntry("CN=Alice,OU=People,DC=example,DC=com", map[string][]string{
+		"entryDN": {"cn=alice,ou=people,dc=example,dc=com"},
+	})
+	assert.Equal(t, "cn=alice,ou=people,dc=example,dc=com", canonicalDN(entry, true))
+	assert.Equal(t, "CN=Alice,OU=People,DC=example,DC=com", canonicalDN(entry, false))
+
+	// servers without entryDN
+	entry = *ldap.NewEntry("CN=Alice,OU=People,DC=example,DC=com", map[string][]string{})
+	assert.Equal(t, "CN=Alice,OU=People,DC=example,DC=com", canonicalDN(entry, true))
+}
+
+func TestLDAPObjectDatasourceSingleAttributes(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "ldap_object" "single" {
+	dn = "cn=single,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["single"]
+		"sn" = ["single"]
+		"description" = ["first", "second"]
+	}
+}
+
+data "ldap_object" "single" {
+	dn = ldap_object.single.dn
+}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.ldap_object.single", "single_attributes.sn", "single"),
+					resource.TestCheckResourceAttr("data.ldap_object.single", "attributes.description.#", "2"),
+					resource.TestCheckNoResourceAttr("data.ldap_object.single", "single_attributes.description"),
+					resource.TestCheckNoResourceAttr("data.ldap_object.single", "single_attributes.objectClass"),
+				),
+			},
+		},
+	})
+}
+
+func TestLDAPObjectDatasourceTypedAttributes(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testDataSourceTypedAttributes(`uidNumber = "int", hasSubordinates = "bool", createTimestamp = "time"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.ldap_object.typed", "typed.int.uidNumber", "1234"),
+					resource.TestCheckResourceAttr("data.ldap_object.typed", "typed.bool.hasSubordinates", "false"),
+					resource.TestMatchResourceAttr("data.ldap_object.typed", "typed.time.createTimestamp", regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z$`)),
+				),
+			},
+			{
+				Config:      testDataSourceTypedAttributes(`objectClass = "int"`),
+				ExpectError: regexp.MustCompile("Only single-valued attributes can be converted"),
+			},
+			{
+				Config:      testDataSourceTypedAttributes(`cn = "int"`),
+				ExpectError: regexp.MustCompile("Can not convert the value of cn to int"),
+			},
+		},
+	})
+}
+
+func testDataSourceTypedAttributes(typedAttributes string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "typed" {
+	dn = "cn=typed,dc=example,dc=com"
+	object_classes = ["account", "posixAccount"]
+	attributes = {
+		"cn" = ["typed"]
+		"uid" = ["typed"]
+		"uidNumber" = ["1234"]
+		"gidNumber" = ["1234"]
+		"homeDirectory" = ["/home/typed"]
+	}
+}
+
+data "ldap_object" "typed" {
+	dn = ldap_object.typed.dn
+	typed_attributes = { %s }
+}`, typedAttributes)
+}
+
+func TestTypedValues(t *testing.T) {
+	entry := ldap.NewEntry("cn=test,dc=example,dc=com", map[string][]string{
+		"uidNumber":       {"1234"},
+		"hasSubordinates": {"TRUE"},
+		"pwdChangedTime":  {"20230102150405Z"},
+		"mail":            {"a@example.com", "b@example.com"},
+	})
+
+	var diagnostics diag.Diagnostics
+	typed := typedValues(*entry, map[string]string{
+		"uidnumber":       typedAttributeInt,
+		"hasSubordinates": typedAttributeBool,
+		"pwdChangedTime":  typedAttributeTime,
+	}, &diagnostics)
+	assert.False(t, diagnostics.HasError())
+	assert.Equal(t, types.MapValueMust(types.Int64Type, map[string]attr.Value{"uidnumber": types.Int64Value(1234)}), typed.Int)
+	assert.Equal(t, types.MapValueMust(types.BoolType, map[string]attr.Value{"hasSubordinates": types.BoolValue(true)}), typed.Bool)
+	assert.Equal(t, types.MapValueMust(types.StringType, map[string]attr.Value{"pwdChangedTime": types.StringValue("2023-01-02T15:04:05Z")}), typed.Time)
+
+	for attributeType, kind := range map[string]string{
+		"mail":            typedAttributeInt,
+		"description":     typedAttributeInt,
+		"hasSubordinates": typedAttributeInt,
+		"uidNumber":       typedAttributeBool,
+	} {
+		diagnostics = diag.Diagnostics{}
+		typedValues(*entry, map[string]string{attributeType: kind}, &diagnostics)
+		assert.True(t, diagnostics.HasError(), "%s can not be converted to %s", attributeType, kind)
+	}
+}
+
+func TestLDAPObjectDatasourceDontUseCopy(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !testServerSupportsControl(ControlTypeDontUseCopy) {
+				t.Skip("server does not support the don't use copy control")
+			}
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The critical control is accepted by the server holding the original data
+			{
+				Config: `
+data "ldap_object" "test" {
+	dn = "dc=example,dc=com"
+	dont_use_copy = true
+}
+
+data "ldap_search" "test" {
+	base_dn = "dc=example,dc=com"
+	dont_use_copy = true
+}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.ldap_object.test", "attributes.dc.0", "example"),
+					resource.TestCheckResourceAttr("data.ldap_search.test", "results.0.dc.0", "example"),
+				),
+			},
+		},
+	})
+}
@@ -2,38 +2,161 @@ package provider
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/thoas/go-funk"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	modifyStrategyIncremental = "incremental"
+	modifyStrategyReplace     = "replace"
+	modifyStrategyAddOnly     = "add_only"
+)
+
+const (
+	onExistingError     = "error"
+	onExistingAdopt     = "adopt"
+	onExistingOverwrite = "overwrite"
+)
+
+const (
+	renamePolicyModifyDN = "modify_dn"
+	renamePolicyReplace  = "replace"
+)
+
+const (
+	destroyActionDelete          = "delete"
+	destroyActionClearAttributes = "clear_attributes"
 )
 
 var _ resource.Resource = &LDAPObjectResource{}
 var _ resource.ResourceWithImportState = &LDAPObjectResource{}
 var _ resource.ResourceWithModifyPlan = &LDAPObjectResource{}
 var _ resource.ResourceWithConfigure = &LDAPObjectResource{}
+var _ resource.ResourceWithValidateConfig = &LDAPObjectResource{}
 
 func NewLDAPObjectResource() resource.Resource {
 	return &LDAPObjectResource{}
 }
 
 type LDAPObjectResource struct {
-	conn *ldap.Conn
+	conn           *ldap.Conn
+	locks          *dnLocks
+	subschema      *subschemaCache
+	controlSupport *controlSupport
 }
 
 type LDAPObjectResourceModel struct {
-	ID            types.String `tfsdk:"id"`
-	DN            types.String `tfsdk:"dn"`
-	ObjectClasses types.List   `tfsdk:"object_classes"`
-	Attributes    types.Map    `tfsdk:"attributes"`
-	IgnoreChanges types.List   `tfsdk:"ignore_changes"`
+	ID                          types.String                `tfsdk:"id"`
+	DN                          types.String                `tfsdk:"dn"`
+	RDNAttribute                types.String                `tfsdk:"rdn_attribute"`
+	RDNValue                    types.String                `tfsdk:"rdn_value"`
+	ParentDN                    types.String                `tfsdk:"parent_dn"`
+	ObjectClasses               types.List                  `tfsdk:"object_classes"`
+	Attributes                  types.Map                   `tfsdk:"attributes"`
+	BinaryAttributes            types.Map                   `tfsdk:"binary_attributes"`
+	SensitiveAttributes         types.Map                   `tfsdk:"sensitive_attributes"`
+	PostCreateAttributes        types.Map                   `tfsdk:"post_create_attributes"`
+	LocalizedAttributes         types.Map                   `tfsdk:"localized_attributes"`
+	AttributeAliases            types.Map                   `tfsdk:"attribute_aliases"`
+	ModifyStrategy              types.Map                   `tfsdk:"modify_strategy"`
+	MatchingRules               types.Map                   `tfsdk:"matching_rules"`
+	IgnoreChanges               types.List                  `tfsdk:"ignore_changes"`
+	IgnoreAttributeChanges      types.List                  `tfsdk:"ignore_attribute_changes"`
+	OrderedAttributes           types.List                  `tfsdk:"ordered_attributes"`
+	ForceNewOnObjectClassChange types.Bool                  `tfsdk:"force_new_on_object_class_change"`
+	PermissiveModify            types.Bool                  `tfsdk:"permissive_modify"`
+	Relax                       types.Bool                  `tfsdk:"relax"`
+	ManageDsaIT                 types.Bool                  `tfsdk:"manage_dsa_it"`
+	CreateParents               types.Bool                  `tfsdk:"create_parents"`
+	ParentObjectClass           types.String                `tfsdk:"parent_object_class"`
+	ParentAttributes            types.Map                   `tfsdk:"parent_attributes"`
+	DeleteEmptyParents          types.Bool                  `tfsdk:"delete_empty_parents"`
+	CreatedParents              types.List                  `tfsdk:"created_parents"`
+	RecursiveDelete             types.Bool                  `tfsdk:"recursive_delete"`
+	DeletionProtection          types.Bool                  `tfsdk:"deletion_protection"`
+	DestroyAction               types.String                `tfsdk:"destroy_action"`
+	RenamePolicy                types.String                `tfsdk:"rename_policy"`
+	ValidateSchema              types.Bool                  `tfsdk:"validate_schema"`
+	OnExisting                  types.String                `tfsdk:"on_existing"`
+	LockAttribute               types.String                `tfsdk:"lock_attribute"`
+	ModifyAssertion             types.String                `tfsdk:"modify_assertion"`
+	Version                     types.String                `tfsdk:"version"`
+	CapturePreRead              types.List                  `tfsdk:"capture_pre_read"`
+	CapturePostRead             types.List                  `tfsdk:"capture_post_read"`
+	PreRead                     types.Map                   `tfsdk:"pre_read"`
+	PostRead                    types.Map                   `tfsdk:"post_read"`
+	LastChanges                 types.List                  `tfsdk:"last_changes"`
+	ConsistencyTimeout          types.String                `tfsdk:"consistency_timeout"`
+	UniqueFilter                types.String                `tfsdk:"unique_filter"`
+	UniqueBaseDN                types.String                `tfsdk:"unique_base_dn"`
+	GeneratePassword            types.Bool                  `tfsdk:"generate_password"`
+	GeneratedPassword           types.String                `tfsdk:"generated_password"`
+	Controls                    types.List                  `tfsdk:"controls"`
+	CloneFromDN                 types.String                `tfsdk:"clone_from_dn"`
+	CloneExclude                types.List                  `tfsdk:"clone_exclude"`
+	ClonedAttributes            types.Map                   `tfsdk:"cloned_attributes"`
+	Timeouts                    *LDAPObjectResourceTimeouts `tfsdk:"timeouts"`
+	Retry                       *LDAPObjectResourceRetry    `tfsdk:"retry"`
+}
+
+type LDAPObjectResourceTimeouts struct {
+	Create types.String `tfsdk:"create"`
+	Read   types.String `tfsdk:"read"`
+	Update types.String `tfsdk:"update"`
+	Delete types.String `tfsdk:"delete"`
+}
+
+type LDAPObjectResourceRetry struct {
+	MaxAttempts types.Int64  `tfsdk:"max_attempts"`
+	MinBackoff  types.String `tfsdk:"min_backoff"`
+	MaxBackoff  types.String `tfsdk:"max_backoff"`
+	RetryOn     types.List   `tfsdk:"retry_on"`
+}
+
+// LDAPObjectResourceChange describes a single change of the last modification in last_changes.
+type LDAPObjectResourceChange struct {
+	Operation  types.String `tfsdk:"operation"`
+	Attribute  types.String `tfsdk:"attribute"`
+	ValueCount types.Int64  `tfsdk:"value_count"`
+}
+
+// ldapObjectChangeType is the type of the entries of the last_changes attribute.
+var ldapObjectChangeType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"operation":   types.StringType,
+		"attribute":   types.StringType,
+		"value_count": types.Int64Type,
+	},
+}
+
+// changeOperations maps the operations of modify requests to their names in last_changes.
+var changeOperations = map[uint]string{
+	ldap.AddAttribute:       "add",
+	ldap.DeleteAttribute:    "delete",
+	ldap.ReplaceAttribute:   "replace",
+	ldap.IncrementAttribute: "increment",
 }
 
 func (L *LDAPObjectResource) Metadata(_ context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
@@ -52,24 +175,340 @@ func (L *LDAPObjectResource) Schema(_ context.Context, _ resource.SchemaRequest,
 				},
 			},
 			"dn": schema.StringAttribute{
-				MarkdownDescription: "DN of this ldap object",
-				Required:            true,
+				MarkdownDescription: "DN of this ldap object. The object is renamed if only its RDN changes, unless `rename_policy` is `replace`. The values of the RDN have to be part of the corresponding attributes. Either `dn` or `rdn_attribute`, `rdn_value` and `parent_dn` have to be set, the DN is computed from the latter",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("rdn_attribute")),
+					IsValidDN(),
+				},
+			},
+			"rename_policy": schema.StringAttribute{
+				MarkdownDescription: "How DN changes are applied: `modify_dn` (default) renames the object in place if only its RDN changes and otherwise deletes and adds it again within the update, `replace` plans a replacement of the object for every DN change. Changes of the case or spacing of the DN are never applied",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(renamePolicyModifyDN, renamePolicyReplace),
+				},
+			},
+			"rdn_attribute": schema.StringAttribute{
+				MarkdownDescription: "Attribute type of the RDN, used together with `rdn_value` and `parent_dn` instead of `dn`",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("rdn_value"), path.MatchRoot("parent_dn")),
+				},
+			},
+			"rdn_value": schema.StringAttribute{
+				MarkdownDescription: "Value of the RDN, which is escaped as needed",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("rdn_attribute")),
+				},
+			},
+			"parent_dn": schema.StringAttribute{
+				MarkdownDescription: "DN of the parent of this ldap object",
+				Optional:            true,
+				Validators: []validator.String{
+					IsValidDN(),
+					stringvalidator.AlsoRequires(path.MatchRoot("rdn_attribute")),
+				},
 			},
 			"object_classes": schema.ListAttribute{
-				MarkdownDescription: "A list of classes this object implements",
+				MarkdownDescription: "A list of classes this object implements. The object is replaced if its structural object classes change",
 				ElementType:         types.StringType,
 				Required:            true,
+				PlanModifiers: []planmodifier.List{
+					RequiresReplaceOnStructuralObjectClassChange(L),
+				},
+			},
+			"create_parents": schema.BoolAttribute{
+				MarkdownDescription: "Whether to create missing parent entries of the DN when adding the object",
+				Optional:            true,
+			},
+			"parent_object_class": schema.StringAttribute{
+				MarkdownDescription: "The object class of parent entries created by `create_parents`. Defaults to `organizationalUnit`",
+				Optional:            true,
+			},
+			"parent_attributes": schema.MapAttribute{
+				MarkdownDescription: "Additional attributes of parent entries created by `create_parents`. The attribute of the RDN is always set",
+				Optional:            true,
+				ElementType:         types.ListType{ElemType: types.StringType},
+			},
+			"controls": controlsResourceAttribute(),
+			"on_existing": schema.StringAttribute{
+				MarkdownDescription: "What to do if the entry already exists when it is created: `error` (default) fails, `adopt` takes over the entry and updates it to match the configuration and `overwrite` replaces all configured attributes of the entry",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(onExistingError, onExistingAdopt, onExistingOverwrite),
+				},
+			},
+			"lock_attribute": schema.StringAttribute{
+				MarkdownDescription: "Operational attribute which changes with every modification of the entry, like `entryCSN` (OpenLDAP), `modifyTimestamp` or `uSNChanged` (Active Directory). If set, modifications are only applied if the attribute still has the value read last, using the assertion control (RFC 4528), so concurrent changes aren't overwritten",
+				Optional:            true,
+			},
+			"modify_assertion": schema.StringAttribute{
+				MarkdownDescription: "LDAP filter the entry has to match for modifications to be applied, using the assertion control (RFC 4528). Combined with the condition of `lock_attribute` if both are set",
+				Optional:            true,
+				Validators: []validator.String{
+					IsValidFilter(),
+				},
+			},
+			"version": schema.StringAttribute{
+				MarkdownDescription: "Value of `lock_attribute` read last",
+				Computed:            true,
+			},
+			"consistency_timeout": schema.StringAttribute{
+				MarkdownDescription: "For eventually consistent directories: how long to re-read the entry after it was created or modified until the written values of `attributes` are visible, given as a duration like `30s`. A warning is shown if they aren't visible in time. Not waiting by default",
+				Optional:            true,
+				Validators:          []validator.String{IsDuration()},
+			},
+			"unique_filter": schema.StringAttribute{
+				MarkdownDescription: "LDAP filter which no other entry below `unique_base_dn` may match when the entry is created, e.g. `(uid=alice)` to prevent duplicate accounts with different DNs. Creating the entry fails if a matching entry exists",
+				Optional:            true,
+				Validators: []validator.String{
+					IsValidFilter(),
+					stringvalidator.AlsoRequires(path.MatchRoot("unique_base_dn")),
+				},
+			},
+			"unique_base_dn": schema.StringAttribute{
+				MarkdownDescription: "DN of the subtree searched for entries conflicting with `unique_filter`",
+				Optional:            true,
+				Validators: []validator.String{
+					IsValidDN(),
+					stringvalidator.AlsoRequires(path.MatchRoot("unique_filter")),
+				},
+			},
+			"generate_password": schema.BoolAttribute{
+				MarkdownDescription: "Whether to let the server generate a password for the entry after creating it, using the password modify extended operation (RFC 3062)",
+				Optional:            true,
+			},
+			"generated_password": schema.StringAttribute{
+				MarkdownDescription: "Password generated by the server if `generate_password` is set. It is only generated once when the entry is created",
+				Computed:            true,
+				Sensitive:           true,
+			},
+			"capture_pre_read": schema.ListAttribute{
+				MarkdownDescription: "Attributes to capture in `pre_read` as they were right before a modification, using the pre-read control (RFC 4527)",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"capture_post_read": schema.ListAttribute{
+				MarkdownDescription: "Attributes to capture in `post_read` as they were right after a modification, using the post-read control (RFC 4527)",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"pre_read": schema.MapAttribute{
+				MarkdownDescription: "The attributes listed in `capture_pre_read` as they were before the last modification. Empty if the server doesn't support the pre-read control",
+				Computed:            true,
+				ElementType:         types.ListType{ElemType: types.StringType},
+			},
+			"post_read": schema.MapAttribute{
+				MarkdownDescription: "The attributes listed in `capture_post_read` as they were after the last modification. Empty if the server doesn't support the post-read control",
+				Computed:            true,
+				ElementType:         types.ListType{ElemType: types.StringType},
+			},
+			"last_changes": schema.ListNestedAttribute{
+				MarkdownDescription: "The changes sent with the last modification of the entry, e.g. to keep an audit trail of the changes made by Terraform. Only the number of values is recorded, so no sensitive values end up in the state. Empty until the entry is modified after it was created",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"operation": schema.StringAttribute{
+							MarkdownDescription: "The modify operation: `add`, `delete`, `replace` or `increment`",
+							Computed:            true,
+						},
+						"attribute": schema.StringAttribute{
+							MarkdownDescription: "The modified attribute",
+							Computed:            true,
+						},
+						"value_count": schema.Int64Attribute{
+							MarkdownDescription: "The number of values added, deleted or replaced. A delete without values deletes the whole attribute",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"validate_schema": schema.BoolAttribute{
+				MarkdownDescription: "Whether to check the object classes and attributes against the subschema of the server while planning. Unknown object classes and attributes required by the object classes which aren't set are reported as errors. Missing or incompatible structural object classes and attributes which aren't allowed by any of the object classes are reported as warnings. The check is skipped while the object classes or attributes are unknown",
+				Optional:            true,
+			},
+			"deletion_protection": schema.BoolAttribute{
+				MarkdownDescription: "Whether to prevent the object from being deleted. To delete the object, set this to `false` and apply first",
+				Optional:            true,
+			},
+			"destroy_action": schema.StringAttribute{
+				MarkdownDescription: "What to do with the entry when the object is destroyed: `delete` (default) deletes it and `clear_attributes` only removes the managed attributes and keeps the entry with its object classes and RDN, e.g. to keep a tombstone of decommissioned accounts. This applies to replacements as well, so a replacement keeping the DN requires `on_existing`",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(destroyActionDelete, destroyActionClearAttributes),
+				},
+			},
+			"recursive_delete": schema.BoolAttribute{
+				MarkdownDescription: "Whether to delete all entries below the object before deleting the object itself",
+				Optional:            true,
+			},
+			"delete_empty_parents": schema.BoolAttribute{
+				MarkdownDescription: "Whether to delete the parent entries created by `create_parents` when the object is destroyed and they are empty",
+				Optional:            true,
+			},
+			"created_parents": schema.ListAttribute{
+				MarkdownDescription: "The DNs of the parent entries created by `create_parents`",
+				Computed:            true,
+				ElementType:         types.StringType,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"force_new_on_object_class_change": schema.BoolAttribute{
+				MarkdownDescription: "Whether to recreate the object when its structural object classes change, which most servers refuse to modify. Defaults to `true`, set it to `false` to try changing them in place. Auxiliary object classes are always changed in place",
+				Optional:            true,
+			},
+			"relax": schema.BoolAttribute{
+				MarkdownDescription: "Whether to send the relax rules control with additions and modifications, so operational attributes like `modifyTimestamp` can be set, e.g. to restore them after a migration (supported by OpenLDAP)",
+				Optional:            true,
+			},
+			"manage_dsa_it": schema.BoolAttribute{
+				MarkdownDescription: "Whether to send the ManageDsaIT control (RFC 3296) with every request, so referral objects are managed like ordinary entries instead of being returned as referrals",
+				Optional:            true,
+			},
+			"permissive_modify": schema.BoolAttribute{
+				MarkdownDescription: "Whether adding existing values and deleting missing values shouldn't fail modifications. The permissive modify control is sent if the server supports it, e.g. Active Directory and OpenLDAP, otherwise these values are removed from the modification after reading the entry",
+				Optional:            true,
 			},
 			"attributes": schema.MapAttribute{
 				MarkdownDescription: "The definition of an attribute, the name defines the type of the attribute",
 				Optional:            true,
 				ElementType:         types.ListType{ElemType: types.StringType},
 			},
+			"binary_attributes": schema.MapAttribute{
+				MarkdownDescription: "Attributes with binary values (like `jpegPhoto` or `userCertificate;binary`), given as base64 encoded strings",
+				Optional:            true,
+				ElementType:         types.ListType{ElemType: types.StringType},
+			},
+			"sensitive_attributes": schema.MapAttribute{
+				MarkdownDescription: "Attributes with secret values (like `userPassword`), which are hidden in plans and outputs",
+				Optional:            true,
+				Sensitive:           true,
+				ElementType:         types.ListType{ElemType: types.StringType},
+			},
+			"post_create_attributes": schema.MapAttribute{
+				MarkdownDescription: "Attributes which can only be set after the object was created (e.g. `userAccountControl` in Active Directory). They are written in a second modification right after the object was added, in the order of their names. Afterwards they are managed like all other attributes",
+				Optional:            true,
+				ElementType:         types.ListType{ElemType: types.StringType},
+			},
+			"localized_attributes": schema.MapAttribute{
+				MarkdownDescription: "Attributes with language tags, grouped by attribute type and language (e.g. `{description = {en = [\"...\"], fr = [\"...\"]}}`). They are written as tagged attributes like `description;lang-en`",
+				Optional:            true,
+				ElementType: types.MapType{
+					ElemType: types.ListType{ElemType: types.StringType},
+				},
+			},
+			"clone_from_dn": schema.StringAttribute{
+				MarkdownDescription: "DN of a template entry, whose object classes and attributes are copied when the object is created. The configured object classes and attributes are applied on top, the RDN attributes of the template, operational attributes and `clone_exclude` aren't copied. The template is only read on create, the copied values are kept in `cloned_attributes` afterwards",
+				Optional:            true,
+				Validators: []validator.String{
+					IsValidDN(),
+				},
+			},
+			"clone_exclude": schema.ListAttribute{
+				MarkdownDescription: "Attribute types which aren't copied from the template given by `clone_from_dn`",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"cloned_attributes": schema.MapAttribute{
+				MarkdownDescription: "The attributes copied from the template given by `clone_from_dn`, which aren't configured. Object classes of the template which aren't configured are listed as `objectClass`. They are refreshed like other attributes, but changes on the server aren't reverted. To manage a cloned attribute, add it to `attributes`",
+				Computed:            true,
+				ElementType:         types.ListType{ElemType: types.StringType},
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"attribute_aliases": schema.MapAttribute{
+				MarkdownDescription: "A map of attribute names used in the configuration to the attribute names used by the server (e.g. `username = \"sAMAccountName\"`)",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
 			"ignore_changes": schema.ListAttribute{
 				MarkdownDescription: "A list of types for which changes are ignored",
 				Optional:            true,
 				ElementType:         types.StringType,
 			},
+			"ignore_attribute_changes": schema.ListAttribute{
+				MarkdownDescription: "A list of types whose values are only set when the entry is created. Unlike `ignore_changes`, the values changed on the server are read into the state, but they are accepted without planning a modification",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"modify_strategy": schema.MapAttribute{
+				MarkdownDescription: "How changes to an attribute are written: `incremental` (default) adds and deletes only the changed values, `replace` always replaces all values and `add_only` never deletes values",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.Map{
+					mapvalidator.ValueStringsAre(stringvalidator.OneOf(modifyStrategyIncremental, modifyStrategyReplace, modifyStrategyAddOnly)),
+				},
+			},
+			"matching_rules": schema.MapAttribute{
+				MarkdownDescription: "Equality matching rules of attribute types, used to detect whether the values returned by the server are equal to the configured ones (e.g. `caseIgnoreMatch` or `telephoneNumberMatch`). Well-known attribute types like `member`, `cn` or `telephoneNumber` use their standard matching rule by default, other attribute types use the equality matching rule published in the subschema of the server, so values normalized by the server, e.g. by trimming spaces, don't show up as changes. Attribute types with unsupported matching rules are compared exactly, set `octetStringMatch` to compare an attribute type exactly regardless of its schema",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.Map{
+					mapvalidator.ValueStringsAre(stringvalidator.OneOf(matchingRules...)),
+				},
+			},
+			"ordered_attributes": schema.ListAttribute{
+				MarkdownDescription: "A list of types whose values are ordered (e.g. `olcAccess`). Changes to these are compared in order and written as a full replace",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": schema.SingleNestedBlock{
+				MarkdownDescription: "Timeouts for the LDAP operations of each phase, given as durations like `30s` or `5m`. No timeout is applied by default",
+				Attributes: map[string]schema.Attribute{
+					"create": schema.StringAttribute{
+						MarkdownDescription: "Timeout for adding the entry",
+						Optional:            true,
+						Validators:          []validator.String{IsDuration()},
+					},
+					"read": schema.StringAttribute{
+						MarkdownDescription: "Timeout for reading the entry",
+						Optional:            true,
+						Validators:          []validator.String{IsDuration()},
+					},
+					"update": schema.StringAttribute{
+						MarkdownDescription: "Timeout for modifying the entry",
+						Optional:            true,
+						Validators:          []validator.String{IsDuration()},
+					},
+					"delete": schema.StringAttribute{
+						MarkdownDescription: "Timeout for deleting the entry",
+						Optional:            true,
+						Validators:          []validator.String{IsDuration()},
+					},
+				},
+			},
+			"retry": schema.SingleNestedBlock{
+				MarkdownDescription: "Retries adding, modifying, renaming and deleting the entry if the server returns one of the given result codes. Operations are not retried by default",
+				Attributes: map[string]schema.Attribute{
+					"max_attempts": schema.Int64Attribute{
+						MarkdownDescription: "Maximum number of attempts of each operation. Defaults to 3",
+						Optional:            true,
+						Validators:          []validator.Int64{int64validator.AtLeast(1)},
+					},
+					"min_backoff": schema.StringAttribute{
+						MarkdownDescription: "Time to wait before the first retry, which doubles with every further retry. Defaults to `1s`",
+						Optional:            true,
+						Validators:          []validator.String{IsDuration()},
+					},
+					"max_backoff": schema.StringAttribute{
+						MarkdownDescription: "Maximum time to wait between retries. Defaults to `30s`",
+						Optional:            true,
+						Validators:          []validator.String{IsDuration()},
+					},
+					"retry_on": schema.ListAttribute{
+						MarkdownDescription: "LDAP result codes to retry. Defaults to busy (51), unavailable (52) and other (80)",
+						Optional:            true,
+						ElementType:         types.Int64Type,
+					},
+				},
+			},
 		},
 	}
 }
@@ -79,15 +518,84 @@ func (L *LDAPObjectResource) Configure(_ context.Context, request resource.Confi
 		return
 	}
 
-	if conn, ok := request.ProviderData.(*ldap.Conn); !ok {
+	if client, ok := request.ProviderData.(*ldapClient); !ok {
 		response.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *ldap.Conn, got: %T. Please report this issue to the provider developers.", request.ProviderData),
+			fmt.Sprintf("Expected *ldapClient, got: %T. Please report this issue to the provider developers.", request.ProviderData),
 		)
 
 		return
 	} else {
-		L.conn = conn
+		L.conn = client.conn
+		L.locks = client.locks
+		L.subschema = client.subschema
+		L.controlSupport = client.controlSupport
+	}
+}
+
+func (L *LDAPObjectResource) ValidateConfig(ctx context.Context, request resource.ValidateConfigRequest, response *resource.ValidateConfigResponse) {
+	var data *LDAPObjectResourceModel
+	response.Diagnostics.Append(request.Config.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// every attribute can only be defined in one of the attribute maps
+	definedIn := map[string]string{}
+	aliases := knownStrings(data.AttributeAliases)
+	for _, name := range []string{"attributes", "binary_attributes", "sensitive_attributes", "post_create_attributes"} {
+		var attributes types.Map
+		response.Diagnostics.Append(request.Config.GetAttribute(ctx, path.Root(name), &attributes)...)
+		for attributeType := range attributes.Elements() {
+			if !data.Relax.ValueBool() && !data.Relax.IsUnknown() && L.isOperationalAttribute(ctx, serverAttributeType(attributeType, aliases)) {
+				response.Diagnostics.AddAttributeError(
+					path.Root(name).AtMapKey(attributeType),
+					"Operational attribute requires relax",
+					fmt.Sprintf("The operational attribute %s is maintained by the server and can only be written with the relax rules control. Set relax to preserve its values, e.g. during a migration", attributeType),
+				)
+			}
+			if other, exists := definedIn[attributeType]; exists {
+				response.Diagnostics.AddAttributeError(
+					path.Root(name).AtMapKey(attributeType),
+					"Attribute is defined twice",
+					fmt.Sprintf("The attribute %s can be either defined in %s or %s, but not in both", attributeType, other, name),
+				)
+			} else {
+				definedIn[attributeType] = name
+			}
+		}
+	}
+
+	BuildControls(ctx, data.Controls, &response.Diagnostics)
+
+	// the values of the RDN have to be kept in sync with the attributes naming the entry
+	if dn := composeDN(data); !dn.IsUnknown() && !data.Attributes.IsUnknown() && !data.MatchingRules.IsUnknown() {
+		if rdn, _, err := SplitRDN(dn.ValueString()); err == nil {
+			rules := knownStrings(data.MatchingRules)
+			for _, conflict := range rdnConflicts(rdn, knownStringLists(data.Attributes), rules) {
+				response.Diagnostics.AddAttributeError(
+					path.Root("attributes").AtMapKey(conflict.Type),
+					"RDN does not match the attributes",
+					fmt.Sprintf("The DN names the entry by %s=%s, but the attribute %s doesn't contain this value. Change the DN together with the attribute to rename the entry.", conflict.Type, conflict.Value, conflict.Type),
+				)
+			}
+		}
+	}
+
+	for attributeType, value := range data.BinaryAttributes.Elements() {
+		if values, ok := value.(types.List); ok && !values.IsUnknown() {
+			for i, v := range values.Elements() {
+				if s, ok := v.(types.String); ok && !s.IsUnknown() {
+					if _, err := base64.StdEncoding.DecodeString(s.ValueString()); err != nil {
+						response.Diagnostics.AddAttributeError(
+							path.Root("binary_attributes").AtMapKey(attributeType).AtListIndex(i),
+							"Invalid base64 value",
+							fmt.Sprintf("Can not decode value of attribute %s: %s", attributeType, err),
+						)
+					}
+				}
+			}
+		}
 	}
 }
 
@@ -98,15 +606,81 @@ func (L *LDAPObjectResource) Create(ctx context.Context, request resource.Create
 		return
 	}
 
-	if err := L.addLdapEntry(ctx, data, &response.Diagnostics); err != nil {
-		response.Diagnostics.AddError(
+	ctx, cancel := L.timeoutContext(ctx, data, "create")
+	defer cancel()
+
+	if !L.checkControlSupport(L.writeControls(ctx, data, &response.Diagnostics), &response.Diagnostics) {
+		return
+	}
+
+	if !L.checkUnique(ctx, data, "create", &response.Diagnostics, data.DN.ValueString()) {
+		return
+	}
+
+	if !data.CloneFromDN.IsNull() {
+		if err := L.cloneTemplate(ctx, data, &response.Diagnostics); err != nil {
+			addOperationError(&response.Diagnostics, err, "create", data.CloneFromDN.ValueString(),
+				"Can not read template",
+				fmt.Sprintf("Trying to read the template %s returned: %s", data.CloneFromDN.ValueString(), err),
+			)
+			return
+		}
+	}
+
+	err := L.addLdapEntry(ctx, data, &response.Diagnostics)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultEntryAlreadyExists) {
+		switch data.OnExisting.ValueString() {
+		case onExistingAdopt:
+			err = L.adoptLdapEntry(ctx, data, &response.Diagnostics)
+		case onExistingOverwrite:
+			err = L.overwriteLdapEntry(ctx, data, &response.Diagnostics)
+		default:
+			response.Diagnostics.AddError(
+				"Entry already exists",
+				fmt.Sprintf("The entry %s already exists. Import it into the state with\n\n  terraform import <resource address> %q\n\nor set on_existing to adopt or overwrite it", data.DN.ValueString(), data.DN.ValueString()),
+			)
+			return
+		}
+	}
+	if err != nil {
+		addOperationError(&response.Diagnostics, err, "create", data.DN.ValueString(),
 			"Can not add resource",
 			fmt.Sprintf("LDAP server reported: %s", err),
+			L.missingAttributesHint(ctx, data, err),
+			L.relaxHint(data, err),
 		)
 		return
 	}
 	data.ID = data.DN
+	data.Version = types.StringNull()
+	data.PreRead = types.MapNull(types.ListType{ElemType: types.StringType})
+	data.PostRead = types.MapNull(types.ListType{ElemType: types.StringType})
+	data.LastChanges = types.ListNull(ldapObjectChangeType)
+	data.GeneratedPassword = types.StringNull()
 	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+
+	if err := L.setPostCreateAttributes(ctx, data, &response.Diagnostics); err != nil {
+		addOperationError(&response.Diagnostics, err, "create", data.DN.ValueString(),
+			"Can not set post-create attributes",
+			fmt.Sprintf("The entry was created, but setting the post-create attributes failed: %s", err),
+		)
+		return
+	}
+	if data.GeneratePassword.ValueBool() {
+		if err := L.generatePassword(ctx, data); err != nil {
+			addOperationError(&response.Diagnostics, err, "create", data.DN.ValueString(),
+				"Can not generate password",
+				fmt.Sprintf("The entry was created, but generating its password failed: %s", err),
+			)
+			return
+		}
+		response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+	}
+	L.waitForConsistency(ctx, data, &response.Diagnostics)
+	if !data.LockAttribute.IsNull() {
+		L.readVersion(ctx, data, &response.Diagnostics)
+		response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+	}
 }
 
 func (L *LDAPObjectResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
@@ -116,20 +690,25 @@ func (L *LDAPObjectResource) Read(ctx context.Context, request resource.ReadRequ
 		return
 	}
 
-	if entry, err := GetEntry(L.conn, data.DN.ValueString()); err != nil {
-		response.Diagnostics.AddError(
+	ctx, cancel := L.timeoutContext(ctx, data, "read")
+	defer cancel()
+
+	if !L.checkControlSupport(L.requestControls(ctx, data, &response.Diagnostics), &response.Diagnostics) {
+		return
+	}
+
+	entry, err := L.readLdapEntry(ctx, data, &response.Diagnostics)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) || errors.Is(err, ErrEntryNotFound) {
+		tflog.Warn(ctx, "Entry was deleted outside of Terraform, removing it from the state", map[string]interface{}{"dn": data.DN.ValueString()})
+		response.State.RemoveResource(ctx)
+	} else if err != nil {
+		addOperationError(&response.Diagnostics, err, "read", data.DN.ValueString(),
 			"Can not read entry",
 			err.Error(),
 		)
 	} else {
-		response.State.SetAttribute(ctx, path.Root("dn"), entry.DN)
-		for _, attribute := range entry.Attributes {
-			if attribute.Name == "objectClass" {
-				response.State.SetAttribute(ctx, path.Root("object_classes"), attribute.Values)
-			} else if !L.isIgnored(ctx, attribute.Name, data, response.Diagnostics) {
-				response.State.SetAttribute(ctx, path.Root("attributes").AtMapKey(attribute.Name), attribute.Values)
-			}
-		}
+		L.refreshFromEntry(ctx, data, entry, &response.Diagnostics)
+		response.Diagnostics.Append(response.State.Set(ctx, &data)...)
 	}
 }
 
@@ -143,68 +722,90 @@ func (L *LDAPObjectResource) Update(ctx context.Context, request resource.Update
 		return
 	}
 
-	// Recreate object if DN changed
-	if stateData.DN.ValueString() != planData.DN.ValueString() {
-		if err := L.conn.Del(ldap.NewDelRequest(stateData.DN.ValueString(), []ldap.Control{})); err != nil {
-			response.Diagnostics.AddError(
+	ctx, cancel := L.timeoutContext(ctx, planData, "update")
+	defer cancel()
+
+	if !L.checkControlSupport(L.writeControls(ctx, planData, &response.Diagnostics), &response.Diagnostics) {
+		return
+	}
+	releaseClonedAttributes(ctx, stateData, planData, &response.Diagnostics)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// the entries captured by the read entry controls are only known if the entry is modified
+	planData.PreRead = types.MapNull(types.ListType{ElemType: types.StringType})
+	planData.PostRead = types.MapNull(types.ListType{ElemType: types.StringType})
+	// the last changes are kept unless the entry is modified
+	planData.LastChanges = stateData.LastChanges
+
+	// Rename the entry if only its RDN changed, the remaining attribute changes are applied afterwards
+	if !sameDN(stateData.DN.ValueString(), planData.DN.ValueString()) && isRename(stateData.DN.ValueString(), planData.DN.ValueString()) {
+		if err := L.renameLdapEntry(ctx, stateData, planData, &response.Diagnostics); err != nil {
+			addOperationError(&response.Diagnostics, err, "update", stateData.DN.ValueString(),
+				"Can not rename entry",
+				fmt.Sprintf("Renaming the entry to %s returned: %s", planData.DN.ValueString(), err),
+			)
+			return
+		}
+		planData.CreatedParents = stateData.CreatedParents
+	}
+
+	// Recreate object if DN changed, changes to its case or spacing are kept in the state only
+	if !sameDN(stateData.DN.ValueString(), planData.DN.ValueString()) {
+		if stateData.DeletionProtection.ValueBool() {
+			addDeletionProtectionError(&response.Diagnostics, stateData.DN.ValueString())
+			return
+		}
+		if !L.checkUnique(ctx, planData, "update", &response.Diagnostics, stateData.DN.ValueString(), planData.DN.ValueString()) {
+			return
+		}
+		d := ldap.NewDelRequest(stateData.DN.ValueString(), L.requestControls(ctx, stateData, &response.Diagnostics))
+		start := time.Now()
+		err := L.retryPolicy(ctx, planData, &response.Diagnostics).run(ctx, func(attempt int) error {
+			return L.locks.write(ctx, d.DN, func() error {
+				return deleteTolerantly(L.conn, d, attempt)
+			})
+		})
+		LogOperation(ctx, "delete", stateData.DN.ValueString(), start)
+		if err != nil {
+			addOperationError(&response.Diagnostics, err, "update", stateData.DN.ValueString(),
 				"Can not delete old DN entry",
 				fmt.Sprintf("Trying to delete entry of old DN returned: %s", err),
 			)
 			return
 		}
+		// keep the parents of the old DN, so they can still be cleaned up
+		planData.CreatedParents = stateData.CreatedParents
+		planData.LastChanges = types.ListNull(ldapObjectChangeType)
 		if err := L.addLdapEntry(ctx, planData, &response.Diagnostics); err != nil {
-			response.Diagnostics.AddError(
+			addOperationError(&response.Diagnostics, err, "update", planData.DN.ValueString(),
 				"Can not add resource",
 				fmt.Sprintf("LDAP server reported: %s", err),
+				L.missingAttributesHint(ctx, planData, err),
+				L.relaxHint(planData, err),
 			)
 			return
 		}
-	} else {
-		var stateAttributes map[string][]string
-		response.Diagnostics.Append(stateData.Attributes.ElementsAs(ctx, &stateAttributes, false)...)
-		var planAttributes map[string][]string
-		response.Diagnostics.Append(planData.Attributes.ElementsAs(ctx, &planAttributes, false)...)
-		r := ldap.NewModifyRequest(planData.DN.ValueString(), []ldap.Control{})
-
-		for attributeType, stateValues := range stateAttributes {
-			if L.isIgnored(ctx, attributeType, stateData, response.Diagnostics) {
-				continue
-			}
-			// state attribute is in the plan, compare the values
-			if planValues, exists := planAttributes[attributeType]; exists {
-				for _, stateValue := range stateValues {
-					if !funk.ContainsString(planValues, stateValue) {
-						r.Delete(attributeType, []string{stateValue})
-					}
-				}
-				for _, planValue := range planValues {
-					if !funk.ContainsString(stateValues, planValue) {
-						r.Add(attributeType, []string{planValue})
-					}
-				}
-			} else {
-				// state attribute is not in the plan, delete it
-				r.Delete(attributeType, []string{})
-			}
-		}
-		for attributeType, values := range planAttributes {
-			if L.isIgnored(ctx, attributeType, planData, response.Diagnostics) {
-				continue
-			}
-			// plan value is not in the state, add it
-			if _, exists := stateAttributes[attributeType]; !exists {
-				r.Add(attributeType, values)
-			}
-		}
-		if err := L.conn.Modify(r); err != nil {
-			response.Diagnostics.AddError(
-				"Can not modify entry",
-				fmt.Sprintf("LDAP server reported: %s", err),
+		if err := L.setPostCreateAttributes(ctx, planData, &response.Diagnostics); err != nil {
+			addOperationError(&response.Diagnostics, err, "update", planData.DN.ValueString(),
+				"Can not set post-create attributes",
+				fmt.Sprintf("The entry was created, but setting the post-create attributes failed: %s", err),
 			)
 			return
 		}
+	} else if err := L.modifyLdapEntry(ctx, stateData, planData, &response.Diagnostics); err != nil {
+		addOperationError(&response.Diagnostics, err, "update", planData.DN.ValueString(),
+			"Can not modify entry",
+			fmt.Sprintf("LDAP server reported: %s", err),
+			L.missingAttributesHint(ctx, planData, err),
+			L.relaxHint(planData, err),
+		)
+		return
 	}
 	planData.ID = planData.DN
+	L.waitForConsistency(ctx, planData, &response.Diagnostics)
+	L.readVersion(ctx, planData, &response.Diagnostics)
 	response.Diagnostics.Append(response.State.Set(ctx, &planData)...)
 }
 
@@ -215,19 +816,271 @@ func (L *LDAPObjectResource) Delete(ctx context.Context, request resource.Delete
 		return
 	}
 
-	if err := L.conn.Del(ldap.NewDelRequest(stateData.DN.ValueString(), []ldap.Control{})); err != nil {
-		response.Diagnostics.AddError(
-			"Can not delete entry",
-			fmt.Sprintf("Trying to delete entry returned: %s", err),
-		)
+	if stateData.DeletionProtection.ValueBool() {
+		addDeletionProtectionError(&response.Diagnostics, stateData.DN.ValueString())
 		return
 	}
-}
 
-func (L *LDAPObjectResource) ImportState(ctx context.Context, request resource.ImportStateRequest, response *resource.ImportStateResponse) {
-	if entry, err := GetEntry(L.conn, request.ID); err != nil {
-		response.Diagnostics.AddError(
-			"Can not read entry",
+	ctx, cancel := L.timeoutContext(ctx, stateData, "delete")
+	defer cancel()
+
+	if !L.checkControlSupport(L.requestControls(ctx, stateData, &response.Diagnostics), &response.Diagnostics) {
+		return
+	}
+
+	if stateData.DestroyAction.ValueString() == destroyActionClearAttributes {
+		if err := L.clearAttributes(ctx, stateData, &response.Diagnostics); err != nil {
+			addOperationError(&response.Diagnostics, err, "delete", stateData.DN.ValueString(),
+				"Can not clear attributes",
+				fmt.Sprintf("Trying to remove the managed attributes of %s returned: %s", stateData.DN.ValueString(), err),
+			)
+		}
+		return
+	}
+
+	controls := L.requestControls(ctx, stateData, &response.Diagnostics)
+	if stateData.RecursiveDelete.ValueBool() {
+		if supported, err := L.controlSupport.supports(L.conn, ControlTypeTreeDelete); err == nil && supported {
+			tflog.Info(ctx, "Deleting subtree using the tree delete control", map[string]interface{}{"dn": stateData.DN.ValueString()})
+			controls = append(controls, NewControlTreeDelete())
+		} else {
+			tflog.Info(ctx, "Deleting subtree entry by entry", map[string]interface{}{"dn": stateData.DN.ValueString()})
+			if err := L.deleteChildren(ctx, stateData, &response.Diagnostics); err != nil {
+				addOperationError(&response.Diagnostics, err, "delete", stateData.DN.ValueString(),
+					"Can not delete children",
+					fmt.Sprintf("Trying to delete the entries below %s returned: %s", stateData.DN.ValueString(), err),
+				)
+				return
+			}
+		}
+	}
+
+	d := ldap.NewDelRequest(stateData.DN.ValueString(), controls)
+	start := time.Now()
+	err := L.retryPolicy(ctx, stateData, &response.Diagnostics).run(ctx, func(attempt int) error {
+		return L.locks.write(ctx, d.DN, func() error {
+			return deleteTolerantly(L.conn, d, attempt)
+		})
+	})
+	LogOperation(ctx, "delete", stateData.DN.ValueString(), start)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNotAllowedOnNonLeaf) {
+		detail := fmt.Sprintf("Trying to delete entry returned: %s", err)
+		if children, err := searchChildren(ctx, L.conn, stateData.DN.ValueString(), ldap.ScopeSingleLevel); err == nil {
+			detail = fmt.Sprintf("The entry has %d children. Delete them first or set recursive_delete to delete them with the entry", len(children))
+		}
+		response.Diagnostics.AddError("Can not delete entry", detail)
+		return
+	} else if err != nil {
+		addOperationError(&response.Diagnostics, err, "delete", stateData.DN.ValueString(),
+			"Can not delete entry",
+			fmt.Sprintf("Trying to delete entry returned: %s", err),
+		)
+		return
+	}
+
+	if stateData.DeleteEmptyParents.ValueBool() {
+		var createdParents []string
+		response.Diagnostics.Append(stateData.CreatedParents.ElementsAs(ctx, &createdParents, false)...)
+		L.deleteEmptyParents(ctx, createdParents, &response.Diagnostics)
+	}
+}
+
+// clearAttributes removes the managed attributes of the entry except its RDN attributes, instead of deleting it.
+func (L *LDAPObjectResource) clearAttributes(ctx context.Context, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) error {
+	dn, err := ldap.ParseDN(data.DN.ValueString())
+	if err != nil {
+		return err
+	}
+	var rdnTypes []string
+	if len(dn.RDNs) > 0 {
+		for _, attribute := range dn.RDNs[0].Attributes {
+			rdnTypes = append(rdnTypes, attribute.Type)
+		}
+	}
+
+	aliases := L.attributeAliases(ctx, data, diagnostics)
+	var attributeTypes []string
+	for _, m := range []types.Map{data.Attributes, data.BinaryAttributes, data.SensitiveAttributes, data.PostCreateAttributes} {
+		for attributeType := range m.Elements() {
+			attributeTypes = append(attributeTypes, attributeType)
+		}
+	}
+	for attributeType := range flattenLocalizedAttributes(ctx, data.LocalizedAttributes, diagnostics) {
+		attributeTypes = append(attributeTypes, attributeType)
+	}
+	if diagnostics.HasError() {
+		return errors.New("error converting data")
+	}
+	sort.Strings(attributeTypes)
+
+	r := ldap.NewModifyRequest(data.DN.ValueString(), L.writeControls(ctx, data, diagnostics))
+	for _, attributeType := range attributeTypes {
+		serverType := serverAttributeType(attributeType, aliases)
+		if containsFold(rdnTypes, serverType) {
+			tflog.Debug(ctx, "Keeping RDN attribute", map[string]interface{}{"dn": data.DN.ValueString(), "attribute": serverType})
+			continue
+		}
+		// replacing without values removes the attribute and doesn't fail if it's missing already
+		r.Replace(serverType, []string{})
+	}
+	if len(r.Changes) == 0 {
+		return nil
+	}
+
+	tflog.Info(ctx, "Clearing managed attributes instead of deleting the entry", map[string]interface{}{"dn": data.DN.ValueString()})
+	start := time.Now()
+	defer LogOperation(ctx, "modify", r.DN, start)
+	return L.retryPolicy(ctx, data, diagnostics).run(ctx, func(_ int) error {
+		return L.locks.write(ctx, r.DN, func() error {
+			return L.conn.Modify(r)
+		})
+	})
+}
+
+// addDeletionProtectionError adds the diagnostic for deleting an object which is protected from deletion.
+func addDeletionProtectionError(diagnostics *diag.Diagnostics, dn string) {
+	diagnostics.AddError(
+		"Deletion protection is enabled",
+		fmt.Sprintf("The entry %s can not be deleted, because deletion_protection is set. Set deletion_protection to false and apply before deleting it", dn),
+	)
+}
+
+// searchChildren returns the DNs of the entries below the given DN using a paged search.
+func searchChildren(ctx context.Context, conn *ldap.Conn, dn string, scope int) ([]string, error) {
+	s := ldap.NewSearchRequest(dn, scope, ldap.NeverDerefAliases, 0, 0, false, "(objectClass=*)", []string{"1.1"}, []ldap.Control{})
+
+	var result *ldap.SearchResult
+	start := time.Now()
+	err := WithContext(ctx, func() (err error) {
+		result, err = conn.SearchWithPaging(s, 500)
+		return
+	})
+	LogOperation(ctx, "search", dn, start)
+	if err != nil {
+		return nil, err
+	}
+
+	var children []string
+	for _, entry := range result.Entries {
+		if !strings.EqualFold(entry.DN, dn) {
+			children = append(children, entry.DN)
+		}
+	}
+	return children, nil
+}
+
+// checkUnique searches below unique_base_dn for entries matching unique_filter. Entries with one of the given DNs are
+// the entry itself and don't conflict. It returns false and adds an error if conflicting entries exist.
+func (L *LDAPObjectResource) checkUnique(ctx context.Context, data *LDAPObjectResourceModel, phase string, diagnostics *diag.Diagnostics, ownDNs ...string) bool {
+	if data.UniqueFilter.IsNull() {
+		return true
+	}
+
+	s := ldap.NewSearchRequest(data.UniqueBaseDN.ValueString(), ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false, data.UniqueFilter.ValueString(), []string{"1.1"}, []ldap.Control{})
+	var result *ldap.SearchResult
+	start := time.Now()
+	err := WithContext(ctx, func() (err error) {
+		result, err = L.conn.SearchWithPaging(s, 500)
+		return
+	})
+	LogOperation(ctx, "search", s.BaseDN, start)
+	if err != nil {
+		addOperationError(diagnostics, err, phase, data.DN.ValueString(),
+			"Can not check uniqueness",
+			fmt.Sprintf("Searching below %s for entries matching %s returned: %s", s.BaseDN, s.Filter, err),
+		)
+		return false
+	}
+
+	var conflicting []string
+	for _, entry := range result.Entries {
+		if !funk.Contains(ownDNs, func(dn string) bool { return sameDN(dn, entry.DN) }) {
+			conflicting = append(conflicting, entry.DN)
+		}
+	}
+	if len(conflicting) > 0 {
+		diagnostics.AddAttributeError(
+			path.Root("unique_filter"),
+			"Conflicting entry exists",
+			fmt.Sprintf("The entry %s can't be created, since these entries below %s already match %s:\n\n  %s", data.DN.ValueString(), s.BaseDN, s.Filter, strings.Join(conflicting, "\n  ")),
+		)
+		return false
+	}
+	return true
+}
+
+// childrenDeepestFirst returns all entries below the given DN, sorted so that every entry comes before its parent.
+func childrenDeepestFirst(ctx context.Context, conn *ldap.Conn, dn string) ([]string, error) {
+	children, err := searchChildren(ctx, conn, dn, ldap.ScopeWholeSubtree)
+	if err != nil {
+		return nil, err
+	}
+
+	depths := map[string]int{}
+	for _, child := range children {
+		if parsed, err := ldap.ParseDN(child); err != nil {
+			return nil, err
+		} else {
+			depths[child] = len(parsed.RDNs)
+		}
+	}
+	sort.SliceStable(children, func(i, j int) bool {
+		return depths[children[i]] > depths[children[j]]
+	})
+	return children, nil
+}
+
+// deleteChildren deletes all entries below the entry, starting with the deepest ones. The children are deleted with
+// the retry policy and the controls of the entry.
+func (L *LDAPObjectResource) deleteChildren(ctx context.Context, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) error {
+	children, err := childrenDeepestFirst(ctx, L.conn, data.DN.ValueString())
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		d := ldap.NewDelRequest(child, L.requestControls(ctx, data, diagnostics))
+		start := time.Now()
+		err := L.retryPolicy(ctx, data, diagnostics).run(ctx, func(attempt int) error {
+			return L.locks.write(ctx, d.DN, func() error {
+				return deleteTolerantly(L.conn, d, attempt)
+			})
+		})
+		LogOperation(ctx, "delete", child, start)
+		if err != nil {
+			return fmt.Errorf("can not delete %s: %w", child, err)
+		}
+	}
+	return nil
+}
+
+// deleteEmptyParents deletes the given parent entries, starting with the last one. It stops at the first entry which
+// still has children.
+func (L *LDAPObjectResource) deleteEmptyParents(ctx context.Context, parents []string, diagnostics *diag.Diagnostics) {
+	for i := len(parents) - 1; i >= 0; i-- {
+		start := time.Now()
+		err := WithContext(ctx, func() error {
+			return L.locks.write(ctx, parents[i], func() error {
+				return L.conn.Del(ldap.NewDelRequest(parents[i], []ldap.Control{}))
+			})
+		})
+		LogOperation(ctx, "delete", parents[i], start)
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNotAllowedOnNonLeaf) {
+			return
+		} else if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			diagnostics.AddWarning(
+				"Can not delete parent entry",
+				fmt.Sprintf("Trying to delete the created parent %s returned: %s", parents[i], err),
+			)
+			return
+		}
+	}
+}
+
+func (L *LDAPObjectResource) ImportState(ctx context.Context, request resource.ImportStateRequest, response *resource.ImportStateResponse) {
+	if entry, err := GetEntry(L.conn, request.ID); err != nil {
+		addOperationError(&response.Diagnostics, err, "import", request.ID,
+			"Can not read entry",
 			err.Error(),
 		)
 	} else {
@@ -236,6 +1089,8 @@ func (L *LDAPObjectResource) ImportState(ctx context.Context, request resource.I
 		for _, attribute := range entry.Attributes {
 			if attribute.Name == "objectClass" {
 				response.State.SetAttribute(ctx, path.Root("object_classes"), attribute.Values)
+			} else if !isText(attribute.ByteValues) {
+				response.State.SetAttribute(ctx, path.Root("binary_attributes").AtMapKey(attribute.Name), encodeBinaryValues(attribute.ByteValues))
 			} else {
 				response.State.SetAttribute(ctx, path.Root("attributes").AtMapKey(attribute.Name), attribute.Values)
 			}
@@ -249,13 +1104,56 @@ func (L *LDAPObjectResource) ModifyPlan(ctx context.Context, request resource.Mo
 
 	response.Diagnostics.Append(request.State.Get(ctx, &stateData)...)
 	response.Diagnostics.Append(request.Plan.Get(ctx, &planData)...)
+	if planData != nil && !planData.RDNAttribute.IsNull() {
+		planData.DN = composeDN(planData)
+		response.Diagnostics.Append(response.Plan.SetAttribute(ctx, path.Root("dn"), planData.DN)...)
+	}
+	if planData != nil && planData.ValidateSchema.ValueBool() {
+		L.validateSchema(ctx, planData, stateData == nil, &response.Diagnostics)
+	}
+	if planData != nil && planData.LockAttribute.IsNull() {
+		response.Diagnostics.Append(response.Plan.SetAttribute(ctx, path.Root("version"), types.StringNull())...)
+	}
+	if planData != nil && stateData != nil {
+		// the password is only generated on create
+		response.Diagnostics.Append(response.Plan.SetAttribute(ctx, path.Root("generated_password"), stateData.GeneratedPassword)...)
+	} else if planData != nil && !planData.GeneratePassword.ValueBool() {
+		response.Diagnostics.Append(response.Plan.SetAttribute(ctx, path.Root("generated_password"), types.StringNull())...)
+	}
+	if planData != nil && planData.CapturePreRead.IsNull() {
+		response.Diagnostics.Append(response.Plan.SetAttribute(ctx, path.Root("pre_read"), types.MapNull(types.ListType{ElemType: types.StringType}))...)
+	}
+	if planData != nil && planData.CapturePostRead.IsNull() {
+		response.Diagnostics.Append(response.Plan.SetAttribute(ctx, path.Root("post_read"), types.MapNull(types.ListType{ElemType: types.StringType}))...)
+	}
+	if planData != nil && stateData == nil && planData.CloneFromDN.IsNull() {
+		response.Diagnostics.Append(response.Plan.SetAttribute(ctx, path.Root("cloned_attributes"), types.MapNull(types.ListType{ElemType: types.StringType}))...)
+	} else if planData != nil && stateData != nil && !stateData.ClonedAttributes.IsNull() {
+		// the template is only read on create, cloned attributes which are configured now are managed like all others
+		cloned := L.unconfiguredAttributes(ctx, knownStringLists(stateData.ClonedAttributes), planData, &response.Diagnostics)
+		value, d := types.MapValueFrom(ctx, types.ListType{ElemType: types.StringType}, cloned)
+		response.Diagnostics.Append(d...)
+		response.Diagnostics.Append(response.Plan.SetAttribute(ctx, path.Root("cloned_attributes"), value)...)
+	}
 	if stateData == nil || planData == nil {
 		// don't ignore any attributes on create and delete
 		return
 	}
 
-	if stateData != nil && planData != nil && stateData.DN != planData.DN {
+	if planData.RenamePolicy.ValueString() == renamePolicyReplace && (planData.DN.IsUnknown() || !sameDN(stateData.DN.ValueString(), planData.DN.ValueString())) {
+		response.RequiresReplace = append(response.RequiresReplace, path.Root("dn"))
+	} else if !planData.DN.IsUnknown() && !sameDN(stateData.DN.ValueString(), planData.DN.ValueString()) && !isRename(stateData.DN.ValueString(), planData.DN.ValueString()) {
+		response.Diagnostics.AddAttributeWarning(
+			path.Root("dn"),
+			"Entry will be recreated",
+			fmt.Sprintf("Only the RDN of an entry can be renamed, so %s will be deleted and added again as %s during the update. Set rename_policy to replace to plan this as a replacement.", stateData.DN.ValueString(), planData.DN.ValueString()),
+		)
+	}
+	if stateData.DN != planData.DN {
 		response.Diagnostics.Append(response.Plan.SetAttribute(ctx, path.Root("id"), types.StringUnknown())...)
+		if !sameDN(stateData.DN.ValueString(), planData.DN.ValueString()) {
+			response.Diagnostics.Append(response.Plan.SetAttribute(ctx, path.Root("created_parents"), types.ListUnknown(types.StringType))...)
+		}
 		if response.Diagnostics.HasError() {
 			return
 		}
@@ -269,42 +1167,1573 @@ func (L *LDAPObjectResource) ModifyPlan(ctx context.Context, request resource.Mo
 		return
 	}
 
-	for attributeType := range planAttributes {
-		if L.isIgnored(ctx, attributeType, planData, response.Diagnostics) {
+	for attributeType, planValues := range planAttributes {
+		if L.isIgnored(ctx, attributeType, planData, &response.Diagnostics) || L.isDriftAccepted(ctx, attributeType, planData, &response.Diagnostics) {
 			response.Plan.SetAttribute(ctx, path.Root("attributes").AtMapKey(attributeType), stateAttributes[attributeType])
+		} else if stateValues, exists := stateAttributes[attributeType]; exists && L.isOrdered(ctx, attributeType, planData, &response.Diagnostics) {
+			if !funk.Equal(stateValues, planValues) && isReordered(stateValues, planValues) {
+				response.Diagnostics.AddAttributeWarning(
+					path.Root("attributes").AtMapKey(attributeType),
+					"Only the order of values changed",
+					fmt.Sprintf("The values of the ordered attribute %s are unchanged, but their order differs. The attribute will be replaced to apply the new order.", attributeType),
+				)
+			}
 		}
 	}
 }
 
 func (L *LDAPObjectResource) addLdapEntry(ctx context.Context, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) error {
+	a, err := L.newAddRequest(ctx, data, diagnostics)
+	if err != nil {
+		return err
+	}
+
+	var createdParents []string
+	if !data.CreatedParents.IsUnknown() {
+		diagnostics.Append(data.CreatedParents.ElementsAs(ctx, &createdParents, false)...)
+	}
+	defer func() {
+		parents, d := types.ListValueFrom(ctx, types.StringType, createdParents)
+		diagnostics.Append(d...)
+		data.CreatedParents = parents
+	}()
+
+	retry := L.retryPolicy(ctx, data, diagnostics)
+	start := time.Now()
+	err = retry.run(ctx, func(attempt int) error {
+		return L.locks.write(ctx, a.DN, func() error {
+			return addTolerantly(L.conn, a, attempt)
+		})
+	})
+	LogOperation(ctx, "add", a.DN, start)
+
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) && data.CreateParents.ValueBool() {
+		created, parentErr := L.createParents(ctx, data, diagnostics)
+		createdParents = append(createdParents, created...)
+		if parentErr != nil {
+			return parentErr
+		}
+
+		start = time.Now()
+		err = retry.run(ctx, func(attempt int) error {
+			return L.locks.write(ctx, a.DN, func() error {
+				return addTolerantly(L.conn, a, attempt)
+			})
+		})
+		LogOperation(ctx, "add", a.DN, start)
+	}
+	return err
+}
+
+// newAddRequest builds the add request for the configured entry.
+func (L *LDAPObjectResource) newAddRequest(ctx context.Context, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) (*ldap.AddRequest, error) {
 	var objectClasses []string
 	diagnostics.Append(data.ObjectClasses.ElementsAs(ctx, &objectClasses, false)...)
 	if diagnostics.HasError() {
-		return errors.New("error converting data")
+		return nil, errors.New("error converting data")
 	}
 
 	var attributes map[string][]string
 	diagnostics.Append(data.Attributes.ElementsAs(ctx, &attributes, false)...)
 	if diagnostics.HasError() {
-		return errors.New("error converting data")
+		return nil, errors.New("error converting data")
 	}
 
-	a := ldap.NewAddRequest(data.DN.ValueString(), []ldap.Control{})
+	clonedAttributes := knownStringLists(data.ClonedAttributes)
+	objectClasses = append(objectClasses, clonedAttributes["objectClass"]...)
+	delete(clonedAttributes, "objectClass")
+
+	a := ldap.NewAddRequest(data.DN.ValueString(), L.writeControls(ctx, data, diagnostics))
 	a.Attribute("objectClass", objectClasses)
 
-	for attributeType, values := range attributes {
+	var sensitiveAttributes map[string][]string
+	diagnostics.Append(data.SensitiveAttributes.ElementsAs(ctx, &sensitiveAttributes, false)...)
+	if diagnostics.HasError() {
+		return nil, errors.New("error converting data")
+	}
+
+	localizedAttributes := flattenLocalizedAttributes(ctx, data.LocalizedAttributes, diagnostics)
+	if diagnostics.HasError() {
+		return nil, errors.New("error converting data")
+	}
+
+	for _, m := range []map[string][]string{attributes, sensitiveAttributes, localizedAttributes, clonedAttributes} {
+		for attributeType, values := range m {
+			// an empty list means that the attribute is not set
+			if len(values) > 0 {
+				a.Attribute(attributeType, values)
+			}
+		}
+	}
+
+	binaryAttributes, err := decodeBinaryAttributes(ctx, data.BinaryAttributes, diagnostics)
+	if err != nil {
+		return nil, err
+	}
+
+	for attributeType, values := range binaryAttributes {
 		a.Attribute(attributeType, values)
 	}
 
-	return L.conn.Add(a)
+	aliases := L.attributeAliases(ctx, data, diagnostics)
+	for i := range a.Attributes {
+		a.Attributes[i].Type = serverAttributeType(a.Attributes[i].Type, aliases)
+	}
+
+	return a, nil
 }
 
-func (L *LDAPObjectResource) isIgnored(ctx context.Context, attributeType string, data *LDAPObjectResourceModel, diagnostics diag.Diagnostics) bool {
-	var ignoredAttributes []string
-	diagnostics.Append(data.IgnoreChanges.ElementsAs(ctx, &ignoredAttributes, false)...)
+// cloneTemplate copies the object classes and attributes of the template entry, which are neither configured nor
+// excluded, to the cloned attributes.
+func (L *LDAPObjectResource) cloneTemplate(ctx context.Context, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) error {
+	// the controls are built outside of WithContext, whose goroutine must not append diagnostics
+	controls := L.requestControls(ctx, data, diagnostics)
+	var template ldap.Entry
+	start := time.Now()
+	err := WithContext(ctx, func() (err error) {
+		template, err = GetEntryWithControls(L.conn, data.CloneFromDN.ValueString(), controls, "*")
+		return
+	})
+	LogOperation(ctx, "search", data.CloneFromDN.ValueString(), start)
+	if err != nil {
+		return err
+	}
+
+	var excluded []string
+	diagnostics.Append(data.CloneExclude.ElementsAs(ctx, &excluded, false)...)
+	if diagnostics.HasError() {
+		return errors.New("error converting data")
+	}
+	templateDN, err := ldap.ParseDN(template.DN)
+	if err != nil {
+		return err
+	}
+	if len(templateDN.RDNs) > 0 {
+		for _, attribute := range templateDN.RDNs[0].Attributes {
+			excluded = append(excluded, attribute.Type)
+		}
+	}
+
+	cloned := map[string][]string{}
+	for _, attribute := range template.Attributes {
+		if containsFold(excluded, attribute.Name) || L.isOperationalAttribute(ctx, attribute.Name) {
+			continue
+		} else if !isText(attribute.ByteValues) {
+			tflog.Warn(ctx, "Not cloning binary attribute, configure it in binary_attributes instead", map[string]interface{}{"dn": template.DN, "attribute": attribute.Name})
+			continue
+		}
+		cloned[attribute.Name] = attribute.Values
+	}
+
+	value, d := types.MapValueFrom(ctx, types.ListType{ElemType: types.StringType}, L.unconfiguredAttributes(ctx, cloned, data, diagnostics))
+	diagnostics.Append(d...)
+	data.ClonedAttributes = value
+	return nil
+}
+
+// unconfiguredAttributes returns the given attributes without the attribute types and object classes configured for
+// the object.
+func (L *LDAPObjectResource) unconfiguredAttributes(ctx context.Context, attributes map[string][]string, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) map[string][]string {
+	var objectClasses []string
+	for _, element := range data.ObjectClasses.Elements() {
+		if objectClass, ok := element.(types.String); ok && !objectClass.IsUnknown() {
+			objectClasses = append(objectClasses, objectClass.ValueString())
+		}
+	}
+	aliases := L.attributeAliases(ctx, data, diagnostics)
+	var configured, localized []string
+	for _, m := range []types.Map{data.Attributes, data.BinaryAttributes, data.SensitiveAttributes, data.PostCreateAttributes} {
+		for attributeType := range m.Elements() {
+			configured = append(configured, attributeType, serverAttributeType(attributeType, aliases))
+		}
+	}
+	for attributeType := range data.LocalizedAttributes.Elements() {
+		localized = append(localized, attributeType)
+	}
+
+	result := map[string][]string{}
+	for attributeType, values := range attributes {
+		if attributeType == "objectClass" {
+			values = funk.FilterString(values, func(objectClass string) bool {
+				return !containsFold(objectClasses, objectClass)
+			})
+		} else if baseType, _, isLocalized := SplitLanguageTag(attributeType); containsFold(configured, attributeType) || isLocalized && containsFold(localized, baseType) {
+			continue
+		}
+		if len(values) > 0 {
+			result[attributeType] = values
+		}
+	}
+	return result
+}
+
+// releaseClonedAttributes moves the cloned values, which are configured now, to the attributes and object classes of
+// the state, so they are modified instead of being added again.
+func releaseClonedAttributes(ctx context.Context, stateData *LDAPObjectResourceModel, planData *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) {
+	stateCloned := knownStringLists(stateData.ClonedAttributes)
+	planCloned := knownStringLists(planData.ClonedAttributes)
+	if len(stateCloned) == 0 {
+		return
+	}
 
+	var stateAttributes map[string][]string
+	diagnostics.Append(stateData.Attributes.ElementsAs(ctx, &stateAttributes, false)...)
+	var stateObjectClasses []string
+	diagnostics.Append(stateData.ObjectClasses.ElementsAs(ctx, &stateObjectClasses, false)...)
 	if diagnostics.HasError() {
+		return
+	}
+	if stateAttributes == nil {
+		stateAttributes = map[string][]string{}
+	}
+
+	for attributeType, values := range stateCloned {
+		if attributeType == "objectClass" {
+			for _, objectClass := range values {
+				if !containsFold(planCloned[attributeType], objectClass) {
+					stateObjectClasses = append(stateObjectClasses, objectClass)
+				}
+			}
+		} else if _, cloned := planCloned[attributeType]; !cloned {
+			for planType := range planData.Attributes.Elements() {
+				if _, managed := stateAttributes[planType]; strings.EqualFold(planType, attributeType) && !managed {
+					stateAttributes[planType] = values
+				}
+			}
+		}
+	}
+
+	attributes, d := types.MapValueFrom(ctx, types.ListType{ElemType: types.StringType}, stateAttributes)
+	diagnostics.Append(d...)
+	stateData.Attributes = attributes
+	objectClasses, d := types.ListValueFrom(ctx, types.StringType, stateObjectClasses)
+	diagnostics.Append(d...)
+	stateData.ObjectClasses = objectClasses
+}
+
+// requestControls returns the controls sent with every request.
+func (L *LDAPObjectResource) requestControls(ctx context.Context, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) []ldap.Control {
+	controls := BuildControls(ctx, data.Controls, diagnostics)
+	if data.ManageDsaIT.ValueBool() {
+		controls = append(controls, ldap.NewControlManageDsaIT(true))
+	}
+	return controls
+}
+
+// checkControlSupport checks the controls of an operation before anything is sent, if ldap_check_control_support is
+// set. It returns false and adds an error if the server doesn't advertise one of them.
+func (L *LDAPObjectResource) checkControlSupport(controls []ldap.Control, diagnostics *diag.Diagnostics) bool {
+	if err := L.controlSupport.check(L.conn, controls); err != nil {
+		addControlSupportError(diagnostics, err)
 		return false
 	}
-	return funk.ContainsString(ignoredAttributes, attributeType)
+	return true
+}
+
+// writeControls returns the controls sent with add and modify requests.
+func (L *LDAPObjectResource) writeControls(ctx context.Context, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) []ldap.Control {
+	controls := L.requestControls(ctx, data, diagnostics)
+	if data.Relax.ValueBool() {
+		controls = append(controls, NewControlRelax())
+	}
+	return controls
+}
+
+// readLdapEntry reads the entry of the configured DN. Managed attributes are requested explicitly, so operational
+// attributes are returned as well.
+func (L *LDAPObjectResource) readLdapEntry(ctx context.Context, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) (ldap.Entry, error) {
+	attributes := []string{"*"}
+	aliases := L.attributeAliases(ctx, data, diagnostics)
+	for _, m := range []types.Map{data.Attributes, data.BinaryAttributes, data.SensitiveAttributes, data.PostCreateAttributes} {
+		for attributeType := range m.Elements() {
+			attributes = append(attributes, serverAttributeType(attributeType, aliases))
+		}
+	}
+
+	if !data.LockAttribute.IsNull() {
+		attributes = append(attributes, data.LockAttribute.ValueString())
+	}
+
+	controls := L.requestControls(ctx, data, diagnostics)
+	var entry ldap.Entry
+	start := time.Now()
+	err := WithContext(ctx, func() (err error) {
+		entry, err = GetEntryWithControls(L.conn, data.DN.ValueString(), controls, attributes...)
+		return
+	})
+	LogOperation(ctx, "search", data.DN.ValueString(), start)
+	return entry, err
+}
+
+// adoptLdapEntry takes over an existing entry and updates it to match the configuration.
+func (L *LDAPObjectResource) adoptLdapEntry(ctx context.Context, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) error {
+	entry, err := L.readLdapEntry(ctx, data, diagnostics)
+	if err != nil {
+		return err
+	}
+
+	existing := *data
+	L.refreshFromEntry(ctx, &existing, entry, diagnostics)
+	if diagnostics.HasError() {
+		return errors.New("error converting data")
+	}
+
+	tflog.Info(ctx, "Adopting existing entry", map[string]interface{}{"dn": entry.DN})
+	return L.modifyLdapEntry(ctx, &existing, data, diagnostics)
+}
+
+// overwriteLdapEntry takes over an existing entry and replaces all configured attributes. Missing object classes are
+// added, other attributes of the entry are kept.
+func (L *LDAPObjectResource) overwriteLdapEntry(ctx context.Context, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) error {
+	entry, err := L.readLdapEntry(ctx, data, diagnostics)
+	if err != nil {
+		return err
+	}
+
+	a, err := L.newAddRequest(ctx, data, diagnostics)
+	if err != nil {
+		return err
+	}
+
+	r := ldap.NewModifyRequest(a.DN, a.Controls)
+	for _, attribute := range a.Attributes {
+		if attribute.Type == "objectClass" {
+			for _, objectClass := range attribute.Vals {
+				if !funk.ContainsString(entry.GetAttributeValues("objectClass"), objectClass) {
+					r.Add("objectClass", []string{objectClass})
+				}
+			}
+		} else {
+			r.Replace(attribute.Type, attribute.Vals)
+		}
+	}
+
+	tflog.Info(ctx, "Overwriting existing entry", map[string]interface{}{"dn": entry.DN})
+	start := time.Now()
+	defer LogOperation(ctx, "modify", r.DN, start)
+	return L.retryPolicy(ctx, data, diagnostics).run(ctx, func(_ int) error {
+		return L.locks.write(ctx, r.DN, func() error {
+			return L.conn.Modify(r)
+		})
+	})
+}
+
+// createParents creates the missing parent entries of the object, starting with the top-most one. It returns the DNs
+// of the created entries in the order they were created.
+func (L *LDAPObjectResource) createParents(ctx context.Context, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) ([]string, error) {
+	dn, err := ldap.ParseDN(data.DN.ValueString())
+	if err != nil {
+		return nil, err
+	}
+
+	var parentAttributes map[string][]string
+	diagnostics.Append(data.ParentAttributes.ElementsAs(ctx, &parentAttributes, false)...)
+	if diagnostics.HasError() {
+		return nil, errors.New("error converting data")
+	}
+
+	parentObjectClass := "organizationalUnit"
+	if !data.ParentObjectClass.IsNull() {
+		parentObjectClass = data.ParentObjectClass.ValueString()
+	}
+
+	// walk up the DN until an existing entry is found
+	var missing []*ldap.DN
+	for i := 1; i < len(dn.RDNs); i++ {
+		parent := &ldap.DN{RDNs: dn.RDNs[i:]}
+		err := WithContext(ctx, func() error {
+			_, err := GetEntry(L.conn, parent.String(), "1.1")
+			return err
+		})
+		if err == nil {
+			break
+		} else if !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return nil, fmt.Errorf("can not read parent %s: %s", parent, err)
+		}
+		missing = append(missing, parent)
+	}
+
+	var created []string
+	for i := len(missing) - 1; i >= 0; i-- {
+		a := ldap.NewAddRequest(missing[i].String(), []ldap.Control{})
+		a.Attribute("objectClass", []string{parentObjectClass})
+		for _, rdn := range missing[i].RDNs[0].Attributes {
+			a.Attribute(rdn.Type, []string{rdn.Value})
+		}
+		for attributeType, values := range parentAttributes {
+			if len(values) > 0 {
+				a.Attribute(attributeType, values)
+			}
+		}
+
+		start := time.Now()
+		err := WithContext(ctx, func() error {
+			return L.locks.write(ctx, a.DN, func() error {
+				return L.conn.Add(a)
+			})
+		})
+		LogOperation(ctx, "add", a.DN, start)
+		if err != nil {
+			return created, fmt.Errorf("can not create parent %s: %s", a.DN, err)
+		}
+		created = append(created, a.DN)
+	}
+	return created, nil
+}
+
+// timeoutContext returns a context which is cancelled when the configured timeout of the given phase is exceeded.
+func (L *LDAPObjectResource) timeoutContext(ctx context.Context, data *LDAPObjectResourceModel, phase string) (context.Context, context.CancelFunc) {
+	if data.Timeouts != nil {
+		timeout := map[string]types.String{
+			"create": data.Timeouts.Create,
+			"read":   data.Timeouts.Read,
+			"update": data.Timeouts.Update,
+			"delete": data.Timeouts.Delete,
+		}[phase]
+		if duration, err := time.ParseDuration(timeout.ValueString()); err == nil {
+			return context.WithTimeout(ctx, duration)
+		}
+	}
+	return context.WithCancel(ctx)
+}
+
+// retryPolicy returns the policy used to retry write operations as configured by the retry block.
+func (L *LDAPObjectResource) retryPolicy(ctx context.Context, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) retryPolicy {
+	if data.Retry == nil {
+		return noRetry
+	}
+
+	policy := retryPolicy{maxAttempts: 3, minBackoff: time.Second, maxBackoff: 30 * time.Second, retryOn: defaultRetryOn}
+	if !data.Retry.MaxAttempts.IsNull() {
+		policy.maxAttempts = int(data.Retry.MaxAttempts.ValueInt64())
+	}
+	if duration, err := time.ParseDuration(data.Retry.MinBackoff.ValueString()); err == nil {
+		policy.minBackoff = duration
+	}
+	if duration, err := time.ParseDuration(data.Retry.MaxBackoff.ValueString()); err == nil {
+		policy.maxBackoff = duration
+	}
+	if !data.Retry.RetryOn.IsNull() {
+		var codes []int64
+		diagnostics.Append(data.Retry.RetryOn.ElementsAs(ctx, &codes, false)...)
+		policy.retryOn = nil
+		for _, code := range codes {
+			policy.retryOn = append(policy.retryOn, uint16(code))
+		}
+	}
+	return policy
+}
+
+// relaxHint explains that writing operational attributes with the relax rules control requires additional privileges,
+// if the entry is written with relax and the server denied the access.
+func (L *LDAPObjectResource) relaxHint(data *LDAPObjectResourceModel, err error) string {
+	if !data.Relax.ValueBool() || !ldap.IsErrorWithCode(err, ldap.LDAPResultInsufficientAccessRights) {
+		return ""
+	}
+	return "Writing operational attributes with the relax rules control requires the manage privilege, e.g. granted by \"by dn=... manage\" in the access control configuration of OpenLDAP"
+}
+
+// missingAttributesHint lists the attributes required by the object classes of the entry, which aren't configured, if
+// the error is an object class violation. It returns an empty string if the subschema can't be read or no attributes
+// are missing.
+func (L *LDAPObjectResource) missingAttributesHint(ctx context.Context, data *LDAPObjectResourceModel, err error) string {
+	if !ldap.IsErrorWithCode(err, ldap.LDAPResultObjectClassViolation) {
+		return ""
+	}
+
+	var diagnostics diag.Diagnostics
+	a, err := L.newAddRequest(ctx, data, &diagnostics)
+	if err != nil {
+		return ""
+	}
+
+	subschema, err := GetSubschema(L.conn)
+	if err != nil {
+		return ""
+	}
+
+	var objectClasses []string
+	var attributeTypes []string
+	for _, attribute := range a.Attributes {
+		if attribute.Type == "objectClass" {
+			objectClasses = attribute.Vals
+		}
+		attributeTypes = append(attributeTypes, attribute.Type)
+	}
+
+	if missing := subschema.MissingAttributes(objectClasses, attributeTypes); len(missing) > 0 {
+		return fmt.Sprintf("These attributes are required by the object classes, but are not set: %s", strings.Join(missing, ", "))
+	}
+	return ""
+}
+
+// validateSchema checks the object classes and attributes of the plan against the subschema of the server.
+// Post-create attributes are only set after the entry was created, so they are ignored when creating it.
+func (L *LDAPObjectResource) validateSchema(ctx context.Context, data *LDAPObjectResourceModel, create bool, diagnostics *diag.Diagnostics) {
+	var objectClasses []types.String
+	if !data.ObjectClasses.IsUnknown() {
+		diagnostics.Append(data.ObjectClasses.ElementsAs(ctx, &objectClasses, false)...)
+	}
+	objectClassesKnown := !data.ObjectClasses.IsUnknown()
+	for _, objectClass := range objectClasses {
+		objectClassesKnown = objectClassesKnown && !objectClass.IsUnknown()
+	}
+
+	var attributeTypes []string
+	attributesKnown := !data.AttributeAliases.IsUnknown()
+	maps := []types.Map{data.Attributes, data.SensitiveAttributes, data.BinaryAttributes, data.LocalizedAttributes}
+	if !create {
+		maps = append(maps, data.PostCreateAttributes)
+	}
+	for _, m := range maps {
+		if m.IsUnknown() {
+			attributesKnown = false
+			continue
+		}
+		for attributeType, values := range m.Elements() {
+			// an empty list means that the attribute is not set
+			if values.IsUnknown() || !isEmptyCollection(values) {
+				attributeTypes = append(attributeTypes, attributeType)
+			}
+		}
+	}
+	if !objectClassesKnown || L.conn == nil || diagnostics.HasError() {
+		tflog.Debug(ctx, "Skipping schema validation, because the object classes are unknown")
+		return
+	}
+
+	subschema, err := GetSubschema(L.conn)
+	if err != nil {
+		diagnostics.AddWarning("Can not validate schema", fmt.Sprintf("Reading the subschema of the server failed: %s", err))
+		return
+	}
+
+	var objectClassNames []string
+	for _, objectClass := range objectClasses {
+		objectClassNames = append(objectClassNames, objectClass.ValueString())
+	}
+	if !validateObjectClasses(subschema, objectClassNames, diagnostics) {
+		return
+	}
+	if !attributesKnown {
+		tflog.Debug(ctx, "Skipping schema validation of the attributes, because they are unknown")
+		return
+	}
+
+	aliases := L.attributeAliases(ctx, data, diagnostics)
+	for i, attributeType := range attributeTypes {
+		attributeTypes[i] = serverAttributeType(attributeType, aliases)
+	}
+
+	namedMaps := map[string]types.Map{
+		"attributes":             data.Attributes,
+		"sensitive_attributes":   data.SensitiveAttributes,
+		"binary_attributes":      data.BinaryAttributes,
+		"post_create_attributes": data.PostCreateAttributes,
+	}
+	for name, m := range namedMaps {
+		for attributeType, values := range m.Elements() {
+			list, ok := values.(types.List)
+			if !ok || len(list.Elements()) <= 1 {
+				continue
+			}
+			if definition, ok := subschema.AttributeType(serverAttributeType(attributeType, aliases)); ok && definition.SingleValue {
+				diagnostics.AddAttributeError(
+					path.Root(name).AtMapKey(attributeType),
+					"Single-valued attribute",
+					fmt.Sprintf("The attribute %s is single-valued according to the subschema of the server, but %d values are set", attributeType, len(list.Elements())),
+				)
+			}
+		}
+	}
+
+	if missing := subschema.MissingAttributes(objectClassNames, attributeTypes); len(missing) > 0 {
+		diagnostics.AddAttributeError(
+			path.Root("attributes"),
+			"Missing required attributes",
+			fmt.Sprintf("These attributes are required by the object classes %s, but are not set: %s", strings.Join(objectClassNames, ", "), strings.Join(missing, ", ")),
+		)
+	}
+	if disallowed := subschema.DisallowedAttributes(objectClassNames, attributeTypes); len(disallowed) > 0 {
+		diagnostics.AddAttributeWarning(
+			path.Root("attributes"),
+			"Attributes not allowed by the object classes",
+			fmt.Sprintf("These attributes are not allowed by the object classes %s and will likely be rejected by the server: %s", strings.Join(objectClassNames, ", "), strings.Join(disallowed, ", ")),
+		)
+	}
+}
+
+// validateObjectClasses reports object classes unknown to the subschema as errors and warns if the object classes
+// don't contain exactly one chain of structural object classes. It returns false if an object class is unknown.
+func validateObjectClasses(subschema *Subschema, objectClasses []string, diagnostics *diag.Diagnostics) bool {
+	valid := true
+	for i, objectClass := range objectClasses {
+		if _, ok := subschema.ObjectClass(objectClass); !ok {
+			detail := fmt.Sprintf("The object class %s is not defined in the subschema of the server", objectClass)
+			if similar := subschema.SimilarObjectClasses(objectClass); len(similar) > 0 {
+				detail = fmt.Sprintf("%s. Did you mean %s?", detail, strings.Join(similar, " or "))
+			}
+			diagnostics.AddAttributeError(path.Root("object_classes").AtListIndex(i), "Unknown object class", detail)
+			valid = false
+		}
+	}
+	if !valid {
+		return false
+	}
+
+	if structural := StructuralObjectClasses(subschema, objectClasses); len(structural) == 0 {
+		diagnostics.AddAttributeWarning(
+			path.Root("object_classes"),
+			"No structural object class",
+			"None of the object classes is structural, but every entry needs exactly one structural object class, e.g. person or organizationalUnit",
+		)
+	} else if a, b, conflict := subschema.StructuralConflict(objectClasses); conflict {
+		diagnostics.AddAttributeWarning(
+			path.Root("object_classes"),
+			"Incompatible structural object classes",
+			fmt.Sprintf("The structural object classes %s and %s are not derived from each other, but an entry can only belong to a single chain of structural object classes", a, b),
+		)
+	}
+	return true
+}
+
+// isEmptyCollection checks whether a list or map value has no elements.
+func isEmptyCollection(value attr.Value) bool {
+	switch v := value.(type) {
+	case types.List:
+		return len(v.Elements()) == 0
+	case types.Map:
+		return len(v.Elements()) == 0
+	}
+	return false
+}
+
+// modifyLdapEntry modifies the entry from the state to match the plan.
+func (L *LDAPObjectResource) modifyLdapEntry(ctx context.Context, stateData *LDAPObjectResourceModel, planData *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) error {
+	var stateAttributes map[string][]string
+	diagnostics.Append(stateData.Attributes.ElementsAs(ctx, &stateAttributes, false)...)
+	var planAttributes map[string][]string
+	diagnostics.Append(planData.Attributes.ElementsAs(ctx, &planAttributes, false)...)
+	var stateObjectClasses []string
+	diagnostics.Append(stateData.ObjectClasses.ElementsAs(ctx, &stateObjectClasses, false)...)
+	var planObjectClasses []string
+	diagnostics.Append(planData.ObjectClasses.ElementsAs(ctx, &planObjectClasses, false)...)
+	controls := L.writeControls(ctx, planData, diagnostics)
+	var assertions []string
+	if !planData.LockAttribute.IsNull() && !stateData.Version.IsNull() {
+		assertions = append(assertions, fmt.Sprintf("(%s=%s)", planData.LockAttribute.ValueString(), ldap.EscapeFilter(stateData.Version.ValueString())))
+	}
+	if !planData.ModifyAssertion.IsNull() {
+		assertions = append(assertions, planData.ModifyAssertion.ValueString())
+	}
+	if len(assertions) > 0 {
+		filter := assertions[0]
+		if len(assertions) > 1 {
+			filter = fmt.Sprintf("(&%s)", strings.Join(assertions, ""))
+		}
+		if control, err := NewControlAssertion(filter); err != nil {
+			return err
+		} else {
+			controls = append(controls, control)
+		}
+	}
+	var capturePreRead, capturePostRead []string
+	diagnostics.Append(planData.CapturePreRead.ElementsAs(ctx, &capturePreRead, false)...)
+	diagnostics.Append(planData.CapturePostRead.ElementsAs(ctx, &capturePostRead, false)...)
+	if !planData.CapturePreRead.IsNull() {
+		controls = append(controls, NewControlReadEntry(ControlTypePreRead, capturePreRead))
+	}
+	if !planData.CapturePostRead.IsNull() {
+		controls = append(controls, NewControlReadEntry(ControlTypePostRead, capturePostRead))
+	}
+	r := ldap.NewModifyRequest(planData.DN.ValueString(), controls)
+
+	subschema, err := L.subschema.get(L.conn)
+	if err != nil {
+		tflog.Debug(ctx, "Can not read subschema, using well-known structural object classes", map[string]interface{}{"error": err.Error()})
+	}
+	r.Changes = append(r.Changes, objectClassChanges(subschema, stateObjectClasses, planObjectClasses)...)
+
+	L.appendAttributeChanges(ctx, r, stateData, planData, stateAttributes, planAttributes, diagnostics)
+
+	var stateSensitiveAttributes map[string][]string
+	diagnostics.Append(stateData.SensitiveAttributes.ElementsAs(ctx, &stateSensitiveAttributes, false)...)
+	var planSensitiveAttributes map[string][]string
+	diagnostics.Append(planData.SensitiveAttributes.ElementsAs(ctx, &planSensitiveAttributes, false)...)
+	L.appendAttributeChanges(ctx, r, stateData, planData, stateSensitiveAttributes, planSensitiveAttributes, diagnostics)
+
+	var statePostCreateAttributes map[string][]string
+	diagnostics.Append(stateData.PostCreateAttributes.ElementsAs(ctx, &statePostCreateAttributes, false)...)
+	var planPostCreateAttributes map[string][]string
+	diagnostics.Append(planData.PostCreateAttributes.ElementsAs(ctx, &planPostCreateAttributes, false)...)
+	L.appendAttributeChanges(ctx, r, stateData, planData, statePostCreateAttributes, planPostCreateAttributes, diagnostics)
+
+	stateLocalizedAttributes := flattenLocalizedAttributes(ctx, stateData.LocalizedAttributes, diagnostics)
+	planLocalizedAttributes := flattenLocalizedAttributes(ctx, planData.LocalizedAttributes, diagnostics)
+	L.appendAttributeChanges(ctx, r, stateData, planData, stateLocalizedAttributes, planLocalizedAttributes, diagnostics)
+
+	stateBinaryAttributes, err := decodeBinaryAttributes(ctx, stateData.BinaryAttributes, diagnostics)
+	if err != nil {
+		return err
+	}
+	planBinaryAttributes, err := decodeBinaryAttributes(ctx, planData.BinaryAttributes, diagnostics)
+	if err != nil {
+		return err
+	}
+	L.appendAttributeChanges(ctx, r, stateData, planData, stateBinaryAttributes, planBinaryAttributes, diagnostics)
+
+	aliases := L.attributeAliases(ctx, planData, diagnostics)
+	for i := range r.Changes {
+		r.Changes[i].Modification.Type = serverAttributeType(r.Changes[i].Modification.Type, aliases)
+	}
+
+	if diagnostics.HasError() {
+		return errors.New("error converting data")
+	}
+	// only arguments of the provider changed, e.g. deletion_protection or timeouts
+	if len(r.Changes) == 0 {
+		return nil
+	}
+
+	if planData.PermissiveModify.ValueBool() {
+		rules := L.matchingRules(ctx, planData, diagnostics)
+		if err := PermissiveModify(ctx, L.conn, r, rules); err != nil {
+			return err
+		}
+		if len(r.Changes) == 0 {
+			return nil
+		}
+	}
+
+	var result *ldap.ModifyResult
+	modify := func() error {
+		start := time.Now()
+		defer LogOperation(ctx, "modify", r.DN, start)
+		return L.retryPolicy(ctx, planData, diagnostics).run(ctx, func(_ int) (err error) {
+			return L.locks.write(ctx, r.DN, func() (err error) {
+				result, err = L.conn.ModifyWithResult(r)
+				return
+			})
+		})
+	}
+	err = modify()
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultObjectClassModsProhibited) && removeObjectClassDeletions(r) {
+		// one of the deleted object classes is structural, although neither the subschema nor the well-known
+		// structural object classes said so
+		tflog.Warn(ctx, "The server refused to delete object classes, keeping them", map[string]interface{}{"dn": r.DN, "error": err.Error()})
+		if len(r.Changes) == 0 {
+			return nil
+		}
+		err = modify()
+	}
+	if err != nil {
+		return err
+	}
+	// only reached if the changes were sent, otherwise the last changes are kept
+	planData.LastChanges = lastChanges(ctx, r.Changes, diagnostics)
+	if !planData.CapturePreRead.IsNull() {
+		planData.PreRead = readEntryControlValue(ctx, result.Controls, ControlTypePreRead, diagnostics)
+	}
+	if !planData.CapturePostRead.IsNull() {
+		planData.PostRead = readEntryControlValue(ctx, result.Controls, ControlTypePostRead, diagnostics)
+	}
+	return nil
+}
+
+// lastChanges returns the value of last_changes for the changes of a modify request.
+func lastChanges(ctx context.Context, changes []ldap.Change, diagnostics *diag.Diagnostics) types.List {
+	result := make([]LDAPObjectResourceChange, len(changes))
+	for i, change := range changes {
+		result[i] = LDAPObjectResourceChange{
+			Operation:  types.StringValue(changeOperations[change.Operation]),
+			Attribute:  types.StringValue(change.Modification.Type),
+			ValueCount: types.Int64Value(int64(len(change.Modification.Vals))),
+		}
+	}
+	value, d := types.ListValueFrom(ctx, ldapObjectChangeType, result)
+	diagnostics.Append(d...)
+	return value
+}
+
+// readEntryControlValue returns the entry of a read entry response control. If the server didn't return the control,
+// a warning is added and the entry is empty.
+func readEntryControlValue(ctx context.Context, controls []ldap.Control, controlType string, diagnostics *diag.Diagnostics) types.Map {
+	attributes := map[string][]string{}
+	if control := ldap.FindControl(controls, controlType); control == nil {
+		diagnostics.AddWarning(
+			"Read entry control not supported",
+			fmt.Sprintf("The server didn't return the entry requested with the control %s, it probably doesn't support it. The modification was applied nevertheless", controlType),
+		)
+	} else if decoded, err := DecodeReadEntryControl(control); err != nil {
+		diagnostics.AddWarning("Can not decode read entry control", fmt.Sprintf("Decoding the entry returned with the control %s failed: %s", controlType, err))
+	} else {
+		attributes = decoded
+	}
+	value, d := types.MapValueFrom(ctx, types.ListType{ElemType: types.StringType}, attributes)
+	diagnostics.Append(d...)
+	return value
+}
+
+// renameLdapEntry changes the RDN of the entry using a modify DN operation, which removes the values of the old RDN.
+// The state is updated to the renamed entry, so modifyLdapEntry only applies the remaining changes.
+func (L *LDAPObjectResource) renameLdapEntry(ctx context.Context, stateData *LDAPObjectResourceModel, planData *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) error {
+	oldRDN, _, err := SplitRDN(stateData.DN.ValueString())
+	if err != nil {
+		return err
+	}
+	newRDN, _, err := SplitRDN(planData.DN.ValueString())
+	if err != nil {
+		return err
+	}
+	var stateAttributes map[string][]string
+	diagnostics.Append(stateData.Attributes.ElementsAs(ctx, &stateAttributes, false)...)
+	rules := L.matchingRules(ctx, planData, diagnostics)
+	controls := L.writeControls(ctx, planData, diagnostics)
+	if diagnostics.HasError() {
+		return errors.New("error converting data")
+	}
+
+	r := ldap.NewModifyDNWithControlsRequest(stateData.DN.ValueString(), newRDN.String(), true, "", controls)
+	start := time.Now()
+	err = L.retryPolicy(ctx, planData, diagnostics).run(ctx, func(_ int) error {
+		return L.locks.write(ctx, r.DN, func() error {
+			return L.conn.ModifyDN(r)
+		})
+	})
+	LogOperation(ctx, "modifydn", r.DN, start)
+	if err != nil {
+		return err
+	}
+
+	attributes, d := types.MapValueFrom(ctx, types.ListType{ElemType: types.StringType}, renameAttributes(stateAttributes, oldRDN, newRDN, rules))
+	diagnostics.Append(d...)
+	stateData.Attributes = attributes
+	stateData.DN = planData.DN
+	return nil
+}
+
+// consistencyPollInterval is the time between the reads of waitForConsistency.
+const consistencyPollInterval = time.Second
+
+// waitForConsistency re-reads the entry after it was written until the configured attribute values are visible or
+// the consistency timeout is exceeded. Exceeding the timeout only results in a warning, since the entry was written.
+func (L *LDAPObjectResource) waitForConsistency(ctx context.Context, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) {
+	timeout, err := time.ParseDuration(data.ConsistencyTimeout.ValueString())
+	if err != nil || timeout <= 0 {
+		return
+	}
+
+	var attributes map[string][]string
+	diagnostics.Append(data.Attributes.ElementsAs(ctx, &attributes, false)...)
+	rules := L.matchingRules(ctx, data, diagnostics)
+	for attributeType := range attributes {
+		if L.isIgnored(ctx, attributeType, data, diagnostics) || L.isDriftAccepted(ctx, attributeType, data, diagnostics) {
+			delete(attributes, attributeType)
+		}
+	}
+	aliases := L.attributeAliases(ctx, data, diagnostics)
+
+	deadline := time.Now().Add(timeout)
+	for attempt := 1; ; attempt++ {
+		var pending []string
+		entry, err := L.readLdapEntry(ctx, data, diagnostics)
+		if err == nil {
+			if pending = unobservedAttributes(attributes, entry, rules, aliases); len(pending) == 0 {
+				tflog.Debug(ctx, "Written entry is visible", map[string]interface{}{"dn": data.DN.ValueString(), "attempts": attempt})
+				return
+			}
+		}
+
+		if time.Now().After(deadline) {
+			detail := fmt.Sprintf("The entry %s was written, but reading it back didn't return the written values within %s", data.DN.ValueString(), timeout)
+			if err != nil {
+				detail = fmt.Sprintf("%s: %s", detail, err)
+			} else {
+				detail = fmt.Sprintf("%s. These attributes differ: %s", detail, strings.Join(pending, ", "))
+			}
+			diagnostics.AddWarning("Entry not consistent yet", detail)
+			return
+		}
+		tflog.Info(ctx, "Waiting for the written entry to become visible", map[string]interface{}{
+			"dn":      data.DN.ValueString(),
+			"attempt": attempt,
+			"pending": pending,
+			"error":   fmt.Sprint(err),
+		})
+		select {
+		case <-time.After(consistencyPollInterval):
+		case <-ctx.Done():
+			diagnostics.AddWarning("Entry not consistent yet", fmt.Sprintf("Waiting for the written values of %s to become visible was aborted: %s", data.DN.ValueString(), ctx.Err()))
+			return
+		}
+	}
+}
+
+// readVersion reads the lock attribute of the entry after it was changed. Failing to read it only results in a warning,
+// since the version is read again when the state is refreshed.
+func (L *LDAPObjectResource) readVersion(ctx context.Context, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) {
+	data.Version = types.StringNull()
+	if data.LockAttribute.IsNull() {
+		return
+	}
+
+	controls := L.requestControls(ctx, data, diagnostics)
+	var entry ldap.Entry
+	start := time.Now()
+	err := WithContext(ctx, func() (err error) {
+		entry, err = GetEntryWithControls(L.conn, data.DN.ValueString(), controls, data.LockAttribute.ValueString())
+		return
+	})
+	LogOperation(ctx, "search", data.DN.ValueString(), start)
+	if err != nil {
+		diagnostics.AddWarning("Can not read version", fmt.Sprintf("Reading %s of %s after the change failed: %s", data.LockAttribute.ValueString(), data.DN.ValueString(), err))
+		return
+	}
+	data.Version = types.StringValue(entry.GetEqualFoldAttributeValue(data.LockAttribute.ValueString()))
+}
+
+// generatePassword lets the server generate a new password for the entry using the password modify extended
+// operation and stores it in generated_password.
+func (L *LDAPObjectResource) generatePassword(ctx context.Context, data *LDAPObjectResourceModel) error {
+	var result *ldap.PasswordModifyResult
+	start := time.Now()
+	err := WithContext(ctx, func() (err error) {
+		return L.locks.write(ctx, data.DN.ValueString(), func() (err error) {
+			result, err = L.conn.PasswordModify(ldap.NewPasswordModifyRequest(data.DN.ValueString(), "", ""))
+			return
+		})
+	})
+	LogOperation(ctx, "passwordmodify", data.DN.ValueString(), start)
+	if err != nil {
+		return err
+	}
+	if result.GeneratedPassword == "" {
+		return errors.New("the server didn't return a generated password")
+	}
+	data.GeneratedPassword = types.StringValue(result.GeneratedPassword)
+	return nil
+}
+
+// setPostCreateAttributes writes the post-create attributes of a freshly added entry in a single modification.
+// The attributes are replaced in the order of their names.
+func (L *LDAPObjectResource) setPostCreateAttributes(ctx context.Context, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) error {
+	var postCreateAttributes map[string][]string
+	diagnostics.Append(data.PostCreateAttributes.ElementsAs(ctx, &postCreateAttributes, false)...)
+	if diagnostics.HasError() {
+		return errors.New("error converting data")
+	}
+
+	if len(postCreateAttributes) == 0 {
+		return nil
+	}
+
+	aliases := L.attributeAliases(ctx, data, diagnostics)
+	r := ldap.NewModifyRequest(data.DN.ValueString(), L.writeControls(ctx, data, diagnostics))
+	attributeTypes := funk.Keys(postCreateAttributes).([]string)
+	sort.Strings(attributeTypes)
+	for _, attributeType := range attributeTypes {
+		r.Replace(serverAttributeType(attributeType, aliases), postCreateAttributes[attributeType])
+	}
+
+	start := time.Now()
+	defer LogOperation(ctx, "modify", r.DN, start)
+	return L.retryPolicy(ctx, data, diagnostics).run(ctx, func(_ int) error {
+		return L.locks.write(ctx, r.DN, func() error {
+			return L.conn.Modify(r)
+		})
+	})
+}
+
+// appendAttributeChanges adds the changes needed to get from the state attributes to the plan attributes to the
+// modify request.
+func (L *LDAPObjectResource) appendAttributeChanges(ctx context.Context, r *ldap.ModifyRequest, stateData *LDAPObjectResourceModel, planData *LDAPObjectResourceModel, stateAttributes map[string][]string, planAttributes map[string][]string, diagnostics *diag.Diagnostics) {
+	for attributeType, stateValues := range stateAttributes {
+		if L.isIgnored(ctx, attributeType, stateData, diagnostics) || L.isDriftAccepted(ctx, attributeType, planData, diagnostics) {
+			continue
+		}
+		strategy := L.modifyStrategy(ctx, attributeType, planData, diagnostics)
+		// state attribute is in the plan, compare the values
+		if planValues, exists := planAttributes[attributeType]; exists {
+			rule := L.matchingRule(ctx, attributeType, planData, diagnostics)
+			r.Changes = append(r.Changes, diffValues(attributeType, rule, stateValues, planValues, L.isOrdered(ctx, attributeType, planData, diagnostics), strategy)...)
+		} else if strategy != modifyStrategyAddOnly {
+			// state attribute is not in the plan, delete it
+			r.Delete(attributeType, []string{})
+		}
+	}
+	for attributeType, values := range planAttributes {
+		if L.isIgnored(ctx, attributeType, planData, diagnostics) || L.isDriftAccepted(ctx, attributeType, planData, diagnostics) {
+			continue
+		}
+		// plan value is not in the state, add it
+		if _, exists := stateAttributes[attributeType]; !exists && len(values) > 0 {
+			if L.modifyStrategy(ctx, attributeType, planData, diagnostics) == modifyStrategyReplace {
+				r.Replace(attributeType, values)
+			} else {
+				r.Add(attributeType, values)
+			}
+		}
+	}
+}
+
+// modifyStrategy returns the configured modify strategy of an attribute type.
+func (L *LDAPObjectResource) modifyStrategy(ctx context.Context, attributeType string, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) string {
+	var strategies map[string]string
+	conversionDiagnostics := data.ModifyStrategy.ElementsAs(ctx, &strategies, false)
+	diagnostics.Append(conversionDiagnostics...)
+
+	if strategy, ok := strategies[attributeType]; ok && !conversionDiagnostics.HasError() {
+		return strategy
+	}
+	return modifyStrategyIncremental
+}
+
+// matchingRule returns the equality matching rule of an attribute type.
+func (L *LDAPObjectResource) matchingRule(ctx context.Context, attributeType string, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) string {
+	return lookupMatchingRule(attributeType, L.matchingRules(ctx, data, diagnostics))
+}
+
+// matchingRules returns the configured matching rules, completed by the equality matching rules of the subschema for
+// the managed attribute types without a configured or built-in matching rule. The server normalizes the values of
+// these, e.g. by trimming spaces, so comparing them exactly would show differences on every plan. If the subschema
+// can't be read, these attribute types are compared exactly.
+func (L *LDAPObjectResource) matchingRules(ctx context.Context, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) map[string]string {
+	rules := map[string]string{}
+	var configured map[string]string
+	diagnostics.Append(data.MatchingRules.ElementsAs(ctx, &configured, false)...)
+	for attributeType, rule := range configured {
+		rules[attributeType] = rule
+	}
+
+	var attributeTypes []string
+	for _, m := range []types.Map{data.Attributes, data.SensitiveAttributes, data.PostCreateAttributes, data.LocalizedAttributes} {
+		for attributeType := range m.Elements() {
+			if lookupMatchingRule(attributeType, rules) == "" {
+				attributeTypes = append(attributeTypes, attributeType)
+			}
+		}
+	}
+	if len(attributeTypes) == 0 {
+		return rules
+	}
+	subschema, err := L.subschema.get(L.conn)
+	if err != nil {
+		tflog.Debug(ctx, "Comparing attributes without a matching rule exactly", map[string]interface{}{"error": err.Error()})
+		return rules
+	}
+	aliases := L.attributeAliases(ctx, data, diagnostics)
+	for _, attributeType := range attributeTypes {
+		if rule := subschema.EqualityMatchingRule(serverAttributeType(attributeType, aliases)); rule != "" {
+			rules[attributeType] = rule
+		}
+	}
+	return rules
+}
+
+// attributeAliases returns the configured map of attribute names used in the configuration to attribute names used
+// by the server.
+func (L *LDAPObjectResource) attributeAliases(ctx context.Context, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) map[string]string {
+	var aliases map[string]string
+	diagnostics.Append(data.AttributeAliases.ElementsAs(ctx, &aliases, false)...)
+	return aliases
+}
+
+// configAttributeType translates an attribute type returned by the server to the name used in the configuration.
+func (L *LDAPObjectResource) configAttributeType(ctx context.Context, attributeType string, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) string {
+	for alias, serverType := range L.attributeAliases(ctx, data, diagnostics) {
+		if strings.EqualFold(serverType, attributeType) {
+			return alias
+		}
+	}
+	return attributeType
+}
+
+// operationalAttributes lists well-known operational attribute types maintained by the server, which can only be
+// written using the relax rules control. They are used if the subschema can't be read.
+var operationalAttributes = []string{
+	"createTimestamp",
+	"creatorsName",
+	"entryCSN",
+	"entryUUID",
+	"modifiersName",
+	"modifyTimestamp",
+}
+
+// unobservedAttributes returns the attribute types whose values in the entry differ from the given values, sorted by
+// name. Empty lists of values mean that the attribute must not be set.
+func unobservedAttributes(attributes map[string][]string, entry ldap.Entry, rules map[string]string, aliases map[string]string) []string {
+	var pending []string
+	for attributeType, values := range attributes {
+		rule := lookupMatchingRule(attributeType, rules)
+		entryValues := entry.GetEqualFoldAttributeValues(serverAttributeType(attributeType, aliases))
+		if len(entryValues) != len(values) || len(subtractValues(rule, values, entryValues)) > 0 {
+			pending = append(pending, attributeType)
+		}
+	}
+	sort.Strings(pending)
+	return pending
+}
+
+// isOperationalAttribute checks whether the attribute type is an operational attribute maintained by the server,
+// which is defined with an operational usage and NO-USER-MODIFICATION in the subschema. Attribute types the subschema
+// doesn't define are checked against the well-known operational attributes, as are all if it can't be read.
+func (L *LDAPObjectResource) isOperationalAttribute(ctx context.Context, attributeType string) bool {
+	if subschema, err := L.subschema.get(L.conn); err != nil {
+		tflog.Debug(ctx, "Can not read subschema, using well-known operational attributes", map[string]interface{}{"error": err.Error()})
+	} else if definition, ok := subschema.AttributeType(attributeType); ok {
+		return definition.Operational && definition.NoUserModification
+	}
+	return containsFold(operationalAttributes, attributeType)
+}
+
+// serverAttributeType translates an attribute type used in the configuration to the name used by the server.
+func serverAttributeType(attributeType string, aliases map[string]string) string {
+	if serverType, ok := aliases[attributeType]; ok {
+		return serverType
+	}
+	return attributeType
+}
+
+func (L *LDAPObjectResource) isIgnored(ctx context.Context, attributeType string, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) bool {
+	var ignoredAttributes []string
+	conversionDiagnostics := data.IgnoreChanges.ElementsAs(ctx, &ignoredAttributes, false)
+	diagnostics.Append(conversionDiagnostics...)
+
+	if conversionDiagnostics.HasError() {
+		return false
+	}
+	return funk.ContainsString(ignoredAttributes, attributeType)
+}
+
+// isDriftAccepted checks whether the attribute type is listed in ignore_attribute_changes, so its values are read,
+// but never modified after the entry was created.
+func (L *LDAPObjectResource) isDriftAccepted(ctx context.Context, attributeType string, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) bool {
+	var acceptedAttributes []string
+	conversionDiagnostics := data.IgnoreAttributeChanges.ElementsAs(ctx, &acceptedAttributes, false)
+	diagnostics.Append(conversionDiagnostics...)
+
+	if conversionDiagnostics.HasError() {
+		return false
+	}
+	return containsFold(acceptedAttributes, attributeType)
+}
+
+func (L *LDAPObjectResource) isOrdered(ctx context.Context, attributeType string, data *LDAPObjectResourceModel, diagnostics *diag.Diagnostics) bool {
+	var orderedAttributes []string
+	conversionDiagnostics := data.OrderedAttributes.ElementsAs(ctx, &orderedAttributes, false)
+	diagnostics.Append(conversionDiagnostics...)
+
+	if conversionDiagnostics.HasError() {
+		return false
+	}
+	return funk.ContainsString(orderedAttributes, attributeType)
+}
+
+// isReordered checks whether both lists contain the same values, regardless of their order.
+func isReordered(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, value := range a {
+		if !funk.ContainsString(b, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// refreshFromEntry updates the model with the values of the entry. Only attributes managed in the model are refreshed,
+// attributes missing on the server are read as empty.
+func (L *LDAPObjectResource) refreshFromEntry(ctx context.Context, data *LDAPObjectResourceModel, entry ldap.Entry, diagnostics *diag.Diagnostics) {
+	attributes := managedAttributes(ctx, data.Attributes, diagnostics)
+	binaryAttributes := managedAttributes(ctx, data.BinaryAttributes, diagnostics)
+	sensitiveAttributes := managedAttributes(ctx, data.SensitiveAttributes, diagnostics)
+	postCreateAttributes := managedAttributes(ctx, data.PostCreateAttributes, diagnostics)
+	var localizedAttributes map[string]map[string][]string
+	diagnostics.Append(data.LocalizedAttributes.ElementsAs(ctx, &localizedAttributes, false)...)
+	stateLocalizedAttributes := flattenLocalizedAttributes(ctx, data.LocalizedAttributes, diagnostics)
+	for _, languages := range localizedAttributes {
+		for language := range languages {
+			languages[language] = []string{}
+		}
+	}
+	stateClonedAttributes := knownStringLists(data.ClonedAttributes)
+	var stateAttributes, stateSensitiveAttributes, statePostCreateAttributes map[string][]string
+	diagnostics.Append(data.Attributes.ElementsAs(ctx, &stateAttributes, false)...)
+	diagnostics.Append(data.SensitiveAttributes.ElementsAs(ctx, &stateSensitiveAttributes, false)...)
+	diagnostics.Append(data.PostCreateAttributes.ElementsAs(ctx, &statePostCreateAttributes, false)...)
+	if diagnostics.HasError() {
+		return
+	}
+
+	if !sameDN(data.DN.ValueString(), entry.DN) {
+		data.DN = types.StringValue(entry.DN)
+	}
+	data.Version = types.StringNull()
+	if !data.LockAttribute.IsNull() {
+		data.Version = types.StringValue(entry.GetEqualFoldAttributeValue(data.LockAttribute.ValueString()))
+	}
+	for _, attribute := range entry.Attributes {
+		name := L.configAttributeType(ctx, attribute.Name, data, diagnostics)
+		if strings.EqualFold(attribute.Name, "objectClass") {
+			// object classes of the template are kept apart from the configured ones
+			objectClasses, d := types.ListValueFrom(ctx, types.StringType, funk.FilterString(attribute.Values, func(objectClass string) bool {
+				return !containsFold(stateClonedAttributes["objectClass"], objectClass)
+			}))
+			diagnostics.Append(d...)
+			data.ObjectClasses = objectClasses
+		} else if key, isBinary := configuredKey(binaryAttributes, name); isBinary {
+			binaryAttributes[key] = encodeBinaryValues(attribute.ByteValues)
+		} else if key, isSensitive := configuredKey(sensitiveAttributes, name); isSensitive {
+			sensitiveAttributes[key] = preferStateValues(L.matchingRule(ctx, key, data, diagnostics), attribute.Values, stateSensitiveAttributes[key])
+		} else if key, isPostCreate := configuredKey(postCreateAttributes, name); isPostCreate {
+			postCreateAttributes[key] = preferStateValues(L.matchingRule(ctx, key, data, diagnostics), attribute.Values, statePostCreateAttributes[key])
+		} else if attributeType, language, isLocalized := SplitLanguageTag(name); isLocalized && localizedKey(localizedAttributes, attributeType) != "" {
+			attributeType = localizedKey(localizedAttributes, attributeType)
+			localizedAttributes[attributeType][language] = preferStateValues(L.matchingRule(ctx, attributeType, data, diagnostics), attribute.Values, stateLocalizedAttributes[LanguageTaggedType(attributeType, language)])
+		} else if key, isManaged := configuredKey(attributes, name); isManaged {
+			attributes[key] = preferStateValues(L.matchingRule(ctx, key, data, diagnostics), attribute.Values, stateAttributes[key])
+		}
+	}
+
+	for name, values := range attributes {
+		if L.isIgnored(ctx, name, data, diagnostics) {
+			// keep the value of the state
+			attributes[name] = stateAttributes[name]
+		} else if L.modifyStrategy(ctx, name, data, diagnostics) == modifyStrategyAddOnly {
+			// values which weren't added by us are not managed
+			attributes[name] = funk.FilterString(values, func(value string) bool {
+				return containsValue(L.matchingRule(ctx, name, data, diagnostics), stateAttributes[name], value)
+			})
+		}
+	}
+
+	attributeMapType := types.ListType{ElemType: types.StringType}
+	for _, m := range []struct {
+		target *types.Map
+		values map[string][]string
+	}{
+		{&data.Attributes, attributes},
+		{&data.BinaryAttributes, binaryAttributes},
+		{&data.SensitiveAttributes, sensitiveAttributes},
+		{&data.PostCreateAttributes, postCreateAttributes},
+	} {
+		if !m.target.IsNull() {
+			value, d := types.MapValueFrom(ctx, attributeMapType, m.values)
+			diagnostics.Append(d...)
+			*m.target = value
+		}
+	}
+	if !data.LocalizedAttributes.IsNull() {
+		value, d := types.MapValueFrom(ctx, types.MapType{ElemType: attributeMapType}, localizedAttributes)
+		diagnostics.Append(d...)
+		data.LocalizedAttributes = value
+	}
+	if !data.ClonedAttributes.IsNull() && !data.ClonedAttributes.IsUnknown() {
+		clonedAttributes := map[string][]string{}
+		for attributeType, values := range stateClonedAttributes {
+			var entryValues []string
+			if attributeType == "objectClass" {
+				entryValues = funk.FilterString(values, func(objectClass string) bool {
+					return containsFold(entry.GetAttributeValues("objectClass"), objectClass)
+				})
+			} else {
+				entryValues = preferStateValues(L.matchingRule(ctx, attributeType, data, diagnostics), entry.GetEqualFoldAttributeValues(attributeType), values)
+			}
+			if len(entryValues) > 0 {
+				clonedAttributes[attributeType] = entryValues
+			}
+		}
+		value, d := types.MapValueFrom(ctx, attributeMapType, clonedAttributes)
+		diagnostics.Append(d...)
+		data.ClonedAttributes = value
+	}
+}
+
+// configuredKey returns the key of the attribute map naming the attribute type, which may be spelled differently by
+// the server, since attribute types are case-insensitive.
+func configuredKey(attributes map[string][]string, attributeType string) (string, bool) {
+	if _, ok := attributes[attributeType]; ok {
+		return attributeType, true
+	}
+	for key := range attributes {
+		if strings.EqualFold(key, attributeType) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// localizedKey returns the key of the localized attributes naming the attribute type regardless of its case, or an
+// empty string if it isn't configured.
+func localizedKey(localizedAttributes map[string]map[string][]string, attributeType string) string {
+	for key := range localizedAttributes {
+		if strings.EqualFold(key, attributeType) {
+			return key
+		}
+	}
+	return ""
+}
+
+// managedAttributes returns the attribute types of the given attribute map with empty values.
+func managedAttributes(ctx context.Context, attributes types.Map, diagnostics *diag.Diagnostics) map[string][]string {
+	var values map[string][]string
+	diagnostics.Append(attributes.ElementsAs(ctx, &values, false)...)
+
+	managed := map[string][]string{}
+	for attributeType := range values {
+		managed[attributeType] = []string{}
+	}
+	return managed
+}
+
+// flattenLocalizedAttributes converts the localized attributes to a map of language tagged attribute descriptions.
+func flattenLocalizedAttributes(ctx context.Context, localizedAttributes types.Map, diagnostics *diag.Diagnostics) map[string][]string {
+	var languages map[string]map[string][]string
+	diagnostics.Append(localizedAttributes.ElementsAs(ctx, &languages, false)...)
+
+	attributes := map[string][]string{}
+	for attributeType, values := range languages {
+		for language, languageValues := range values {
+			attributes[LanguageTaggedType(attributeType, language)] = languageValues
+		}
+	}
+	return attributes
+}
+
+// decodeBinaryAttributes converts a map of base64 encoded attribute values to a map of raw attribute values.
+func decodeBinaryAttributes(ctx context.Context, binaryAttributes types.Map, diagnostics *diag.Diagnostics) (map[string][]string, error) {
+	var encoded map[string][]string
+	diagnostics.Append(binaryAttributes.ElementsAs(ctx, &encoded, false)...)
+	if diagnostics.HasError() {
+		return nil, errors.New("error converting data")
+	}
+
+	decoded := make(map[string][]string, len(encoded))
+	for attributeType, values := range encoded {
+		for _, value := range values {
+			if v, err := base64.StdEncoding.DecodeString(value); err != nil {
+				return nil, fmt.Errorf("can not decode value of attribute %s: %s", attributeType, err)
+			} else {
+				decoded[attributeType] = append(decoded[attributeType], string(v))
+			}
+		}
+	}
+	return decoded, nil
+}
+
+// encodeBinaryValues converts raw attribute values to base64 encoded strings.
+func encodeBinaryValues(values [][]byte) []string {
+	var encoded []string
+	for _, value := range values {
+		encoded = append(encoded, base64.StdEncoding.EncodeToString(value))
+	}
+	return encoded
+}
+
+// isText checks whether all values are valid UTF-8 strings.
+func isText(values [][]byte) bool {
+	for _, value := range values {
+		if !utf8.Valid(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// diffValues computes the modify changes needed to get from the state values to the plan values of an attribute
+// using the given modify strategy. For the incremental strategy, only the values which changed are added or deleted,
+// unless sending all values with a replace is smaller than the delta. Ordered values can only be kept in order by
+// replacing all of them.
+func diffValues(attributeType string, rule string, stateValues []string, planValues []string, ordered bool, strategy string) []ldap.Change {
+	if ordered && strategy != modifyStrategyAddOnly && len(planValues) > 0 {
+		if funk.Equal(stateValues, planValues) {
+			return nil
+		}
+		return []ldap.Change{replaceChange(attributeType, planValues)}
+	}
+
+	deleted := subtractValues(rule, stateValues, planValues)
+	added := subtractValues(rule, planValues, stateValues)
+
+	if strategy == modifyStrategyAddOnly {
+		deleted = nil
+	} else if len(planValues) == 0 {
+		if len(stateValues) == 0 {
+			return nil
+		}
+		// an empty list removes the attribute
+		return []ldap.Change{{
+			Operation:    ldap.DeleteAttribute,
+			Modification: ldap.PartialAttribute{Type: attributeType, Vals: []string{}},
+		}}
+	}
+
+	if len(deleted)+len(added) == 0 {
+		return nil
+	}
+
+	if strategy == modifyStrategyReplace || (strategy == modifyStrategyIncremental && len(deleted)+len(added) > len(planValues)) {
+		return []ldap.Change{replaceChange(attributeType, planValues)}
+	}
+
+	var changes []ldap.Change
+	if len(deleted) > 0 {
+		changes = append(changes, ldap.Change{
+			Operation:    ldap.DeleteAttribute,
+			Modification: ldap.PartialAttribute{Type: attributeType, Vals: deleted},
+		})
+	}
+	if len(added) > 0 {
+		changes = append(changes, ldap.Change{
+			Operation:    ldap.AddAttribute,
+			Modification: ldap.PartialAttribute{Type: attributeType, Vals: added},
+		})
+	}
+	return changes
+}
+
+// objectClassChanges returns the changes adding the object classes which are only in the plan and deleting the
+// object classes which are only in the state. Structural object classes and top are never deleted, since servers
+// refuse to remove them from an entry, e.g. the superclasses of inetOrgPerson read from Active Directory. Entries
+// whose structural object class changes are replaced instead, unless force_new_on_object_class_change is false.
+func objectClassChanges(subschema *Subschema, stateObjectClasses []string, planObjectClasses []string) []ldap.Change {
+	var changes []ldap.Change
+	if added := subtractValues(matchingRuleCaseIgnore, planObjectClasses, stateObjectClasses); len(added) > 0 {
+		changes = append(changes, ldap.Change{
+			Operation:    ldap.AddAttribute,
+			Modification: ldap.PartialAttribute{Type: "objectClass", Vals: added},
+		})
+	}
+
+	structural := StructuralObjectClasses(subschema, stateObjectClasses)
+	var deleted []string
+	for _, objectClass := range subtractValues(matchingRuleCaseIgnore, stateObjectClasses, planObjectClasses) {
+		if !strings.EqualFold(objectClass, "top") && !funk.ContainsString(structural, strings.ToLower(objectClass)) {
+			deleted = append(deleted, objectClass)
+		}
+	}
+	if len(deleted) > 0 {
+		changes = append(changes, ldap.Change{
+			Operation:    ldap.DeleteAttribute,
+			Modification: ldap.PartialAttribute{Type: "objectClass", Vals: deleted},
+		})
+	}
+	return changes
+}
+
+// removeObjectClassDeletions removes the changes deleting object classes from the modify request. It returns false
+// if there were none.
+func removeObjectClassDeletions(r *ldap.ModifyRequest) bool {
+	var changes []ldap.Change
+	for _, change := range r.Changes {
+		if change.Operation != ldap.DeleteAttribute || !strings.EqualFold(change.Modification.Type, "objectClass") {
+			changes = append(changes, change)
+		}
+	}
+	removed := len(changes) < len(r.Changes)
+	r.Changes = changes
+	return removed
+}
+
+func replaceChange(attributeType string, values []string) ldap.Change {
+	return ldap.Change{
+		Operation:    ldap.ReplaceAttribute,
+		Modification: ldap.PartialAttribute{Type: attributeType, Vals: values},
+	}
+}
+
+// isRename checks whether the new DN only differs from the old DN by its RDN, so the entry can be renamed in place.
+func isRename(oldDN string, newDN string) bool {
+	_, oldParent, err := SplitRDN(oldDN)
+	if err != nil {
+		return false
+	}
+	_, newParent, err := SplitRDN(newDN)
+	if err != nil {
+		return false
+	}
+	return sameDN(oldParent, newParent)
+}
+
+// rdnConflicts returns the values of the RDN, which are missing in the attribute of the same type. RDN values of
+// attributes which aren't configured are added by the server.
+func rdnConflicts(rdn *ldap.RelativeDN, attributes map[string][]string, rules map[string]string) []*ldap.AttributeTypeAndValue {
+	var conflicts []*ldap.AttributeTypeAndValue
+	for _, ava := range rdn.Attributes {
+		for attributeType, values := range attributes {
+			if strings.EqualFold(attributeType, ava.Type) && !containsValue(lookupMatchingRule(attributeType, rules), values, ava.Value) {
+				conflicts = append(conflicts, &ldap.AttributeTypeAndValue{Type: attributeType, Value: ava.Value})
+			}
+		}
+	}
+	return conflicts
+}
+
+// renameAttributes applies the change of the RDN to the attributes, removing the values of the old RDN and adding the
+// values of the new one to the attributes of the same type.
+func renameAttributes(attributes map[string][]string, oldRDN *ldap.RelativeDN, newRDN *ldap.RelativeDN, rules map[string]string) map[string][]string {
+	renamed := make(map[string][]string, len(attributes))
+	for attributeType, values := range attributes {
+		rule := lookupMatchingRule(attributeType, rules)
+		for _, ava := range oldRDN.Attributes {
+			if strings.EqualFold(attributeType, ava.Type) {
+				values = subtractValues(rule, values, []string{ava.Value})
+			}
+		}
+		for _, ava := range newRDN.Attributes {
+			if strings.EqualFold(attributeType, ava.Type) && !containsValue(rule, values, ava.Value) {
+				values = append(values, ava.Value)
+			}
+		}
+		renamed[attributeType] = values
+	}
+	return renamed
+}
+
+// knownStrings returns the known elements of a map of strings.
+func knownStrings(m types.Map) map[string]string {
+	result := map[string]string{}
+	for key, value := range m.Elements() {
+		if s, ok := value.(types.String); ok && !s.IsUnknown() && !s.IsNull() {
+			result[key] = s.ValueString()
+		}
+	}
+	return result
+}
+
+// knownStringLists returns the elements of a map of string lists, whose values are all known.
+func knownStringLists(m types.Map) map[string][]string {
+	result := map[string][]string{}
+	for key, value := range m.Elements() {
+		list, ok := value.(types.List)
+		if !ok || list.IsUnknown() || list.IsNull() {
+			continue
+		}
+		var values []string
+		for _, element := range list.Elements() {
+			if s, ok := element.(types.String); ok && !s.IsUnknown() && !s.IsNull() {
+				values = append(values, s.ValueString())
+			} else {
+				values = nil
+				break
+			}
+		}
+		if values != nil {
+			result[key] = values
+		}
+	}
+	return result
+}
+
+// composeDN returns the configured DN, or composes it from the RDN and the parent DN. The DN is unknown as long as
+// one of its parts is unknown.
+func composeDN(data *LDAPObjectResourceModel) types.String {
+	if data.RDNAttribute.IsNull() {
+		return data.DN
+	}
+	if data.RDNAttribute.IsUnknown() || data.RDNValue.IsUnknown() || data.ParentDN.IsUnknown() {
+		return types.StringUnknown()
+	}
+	return types.StringValue(fmt.Sprintf("%s=%s,%s", data.RDNAttribute.ValueString(), ldap.EscapeDN(data.RDNValue.ValueString()), data.ParentDN.ValueString()))
 }
@@ -1,10 +1,28 @@
 package provider
 
 import (
+	"context"
+	"fmt"
 	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/thoas/go-funk"
+	"io"
+	"net"
 	"os"
+	"regexp"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestLDAPObjectResource(t *testing.T) {
@@ -172,3 +190,2240 @@ func testImportPreConfig() {
 		}
 	}
 }
+
+func TestLDAPObjectResourceOrderedAttributes(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testOrderedConfig(`["first", "second"]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_object.ordered", "attributes.description.0", "first"),
+					resource.TestCheckResourceAttr("ldap_object.ordered", "attributes.description.1", "second"),
+				),
+			},
+			// Reorder the values only
+			{
+				Config: testOrderedConfig(`["second", "first"]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_object.ordered", "attributes.description.0", "second"),
+					resource.TestCheckResourceAttr("ldap_object.ordered", "attributes.description.1", "first"),
+				),
+			},
+		},
+	})
+}
+
+func testOrderedConfig(descriptions string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "ordered" {
+	dn = "cn=ordered,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["ordered"]
+		"sn" = ["ordered"]
+		"description" = %s
+	}
+	ordered_attributes = ["description"]
+}
+`, descriptions)
+}
+
+func TestLDAPObjectResourceObjectClassChange(t *testing.T) {
+	var entryUUID string
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testObjectClassConfig(`["person"]`, `"sn" = ["classes"]`),
+				Check:  testCaptureEntryUUID("cn=classes,dc=example,dc=com", &entryUUID),
+			},
+			// Adding an auxiliary class is done in place
+			{
+				Config: testObjectClassConfig(`["person", "extensibleObject"]`, `"sn" = ["classes"]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_object.classes", "object_classes.#", "2"),
+					testCheckEntryUUID("cn=classes,dc=example,dc=com", &entryUUID, true),
+				),
+			},
+			// Changing the structural class replaces the entry
+			{
+				Config: testObjectClassConfig(`["organizationalRole"]`, ``),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_object.classes", "object_classes.0", "organizationalRole"),
+					testCheckEntryUUID("cn=classes,dc=example,dc=com", &entryUUID, false),
+				),
+			},
+		},
+	})
+}
+
+func testObjectClassConfig(objectClasses string, attributes string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "classes" {
+	dn = "cn=classes,dc=example,dc=com"
+	object_classes = %s
+	attributes = {
+		"cn" = ["classes"]
+		%s
+	}
+	force_new_on_object_class_change = true
+}
+`, objectClasses, attributes)
+}
+
+func TestLDAPObjectResourceAddAuxiliaryObjectClass(t *testing.T) {
+	var entryUUID string
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAuxiliaryObjectClassConfig(`["inetOrgPerson"]`, ""),
+				Check:  testCaptureEntryUUID("uid=carol,dc=example,dc=com", &entryUUID),
+			},
+			// posixAccount is added without touching the structural class
+			{
+				Config: testAuxiliaryObjectClassConfig(`["inetOrgPerson", "posixAccount"]`, `
+		"uidNumber" = ["10003"]
+		"gidNumber" = ["10003"]
+		"homeDirectory" = ["/home/carol"]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckEntryUUID("uid=carol,dc=example,dc=com", &entryUUID, true),
+					testCheckServerValues("uid=carol,dc=example,dc=com", "objectClass", []string{"inetOrgPerson", "posixAccount"}),
+				),
+			},
+		},
+	})
+}
+
+func testAuxiliaryObjectClassConfig(objectClasses string, attributes string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "carol" {
+	dn = "uid=carol,dc=example,dc=com"
+	object_classes = %s
+	attributes = {
+		"uid" = ["carol"]
+		"cn" = ["carol"]
+		"sn" = ["carol"]%s
+	}
+}
+`, objectClasses, attributes)
+}
+
+func TestObjectClassChanges(t *testing.T) {
+	// the structural object class and its superclasses are kept, only the auxiliary class is deleted
+	assert.Equal(t, []ldap.Change{
+		{Operation: ldap.AddAttribute, Modification: ldap.PartialAttribute{Type: "objectClass", Vals: []string{"posixAccount"}}},
+		{Operation: ldap.DeleteAttribute, Modification: ldap.PartialAttribute{Type: "objectClass", Vals: []string{"shadowAccount"}}},
+	}, objectClassChanges(nil,
+		[]string{"top", "person", "organizationalPerson", "inetOrgPerson", "shadowAccount"},
+		[]string{"inetOrgPerson", "posixAccount"},
+	))
+
+	// object classes are case-insensitive
+	assert.Empty(t, objectClassChanges(nil, []string{"inetorgperson", "posixaccount"}, []string{"inetOrgPerson", "posixAccount"}))
+
+	// the subschema decides which object classes are structural
+	subschema := &Subschema{ObjectClasses: map[string]ObjectClassDefinition{}}
+	for _, description := range []string{
+		"( 2.5.6.6 NAME 'person' SUP top STRUCTURAL MUST ( sn $ cn ) )",
+		"( 1.3.6.1.4.1.99999.2 NAME 'customPerson' SUP person STRUCTURAL )",
+		"( 1.3.6.1.4.1.99999.3 NAME 'customAux' SUP top AUXILIARY )",
+	} {
+		definition, err := ParseObjectClassDefinition(description)
+		assert.NoError(t, err)
+		subschema.ObjectClasses[strings.ToLower(definition.Names[0])] = definition
+	}
+	assert.Equal(t, []ldap.Change{
+		{Operation: ldap.DeleteAttribute, Modification: ldap.PartialAttribute{Type: "objectClass", Vals: []string{"customAux"}}},
+	}, objectClassChanges(subschema, []string{"customPerson", "person", "customAux"}, []string{"customPerson"}))
+}
+
+func TestRemoveObjectClassDeletions(t *testing.T) {
+	r := ldap.NewModifyRequest("cn=test,dc=example,dc=com", nil)
+	r.Add("objectClass", []string{"posixAccount"})
+	r.Delete("objectClass", []string{"customPerson"})
+	r.Replace("sn", []string{"test"})
+	assert.True(t, removeObjectClassDeletions(r))
+	assert.Equal(t, []ldap.Change{
+		{Operation: ldap.AddAttribute, Modification: ldap.PartialAttribute{Type: "objectClass", Vals: []string{"posixAccount"}}},
+		{Operation: ldap.ReplaceAttribute, Modification: ldap.PartialAttribute{Type: "sn", Vals: []string{"test"}}},
+	}, r.Changes)
+	assert.False(t, removeObjectClassDeletions(r))
+}
+
+func TestLDAPObjectResourceStructuralObjectClassChange(t *testing.T) {
+	var entryUUID string
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testStructuralObjectClassConfig("organizationalUnit", ""),
+				Check:  testCaptureEntryUUID("ou=structural,dc=example,dc=com", &entryUUID),
+			},
+			// The structural class is replaced by default
+			{
+				Config: testStructuralObjectClassConfig("organization", ""),
+				Check:  testCheckEntryUUID("ou=structural,dc=example,dc=com", &entryUUID, false),
+			},
+			// Changing it in place is refused by the server
+			{
+				Config:      testStructuralObjectClassConfig("organizationalUnit", "force_new_on_object_class_change = false"),
+				ExpectError: regexp.MustCompile("Can not modify entry"),
+			},
+		},
+	})
+}
+
+func testStructuralObjectClassConfig(objectClass string, options string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "structural" {
+	dn = "ou=structural,dc=example,dc=com"
+	object_classes = ["%s"]
+	attributes = {
+		"ou" = ["structural"]
+		"o" = ["structural"]
+	}
+	%s
+}
+`, objectClass, options)
+}
+
+func testGetEntryUUID(dn string) (string, error) {
+	conn, err := ldap.DialURL(os.Getenv("LDAP_URL"))
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	if err := conn.Bind(os.Getenv("LDAP_BIND_DN"), os.Getenv("LDAP_BIND_PASSWORD")); err != nil {
+		return "", err
+	}
+	entry, err := GetEntry(conn, dn, "entryUUID")
+	if err != nil {
+		return "", err
+	}
+	return entry.GetAttributeValue("entryUUID"), nil
+}
+
+func testCaptureEntryUUID(dn string, entryUUID *string) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		var err error
+		*entryUUID, err = testGetEntryUUID(dn)
+		return err
+	}
+}
+
+func testCheckEntryUUID(dn string, entryUUID *string, same bool) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		current, err := testGetEntryUUID(dn)
+		if err != nil {
+			return err
+		}
+		if same && current != *entryUUID {
+			return fmt.Errorf("entry %s was recreated", dn)
+		}
+		if !same && current == *entryUUID {
+			return fmt.Errorf("entry %s was not recreated", dn)
+		}
+		*entryUUID = current
+		return nil
+	}
+}
+
+func TestLDAPObjectResourceBinaryAttributes(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testBinaryConfig(`"jpegPhoto" = ["/9j/4AAQ"]`, `"jpegPhoto" = ["/9j/4AAQ"]`),
+				ExpectError: regexp.MustCompile("Attribute is defined twice"),
+			},
+			{
+				Config:      testBinaryConfig(`"jpegPhoto" = ["not base64"]`, ``),
+				ExpectError: regexp.MustCompile("Invalid base64 value"),
+			},
+			{
+				Config: testBinaryConfig(`"jpegPhoto" = ["/9j/4AAQ"]`, ``),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_object.binary", "binary_attributes.jpegPhoto.0", "/9j/4AAQ"),
+				),
+			},
+			{
+				Config: testBinaryConfig(`"jpegPhoto" = ["/9j/4AAQSkZJRg=="]`, ``),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_object.binary", "binary_attributes.jpegPhoto.0", "/9j/4AAQSkZJRg=="),
+				),
+			},
+		},
+	})
+}
+
+func testBinaryConfig(binaryAttributes string, attributes string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "binary" {
+	dn = "cn=binary,dc=example,dc=com"
+	object_classes = ["inetOrgPerson"]
+	attributes = {
+		"cn" = ["binary"]
+		"sn" = ["binary"]
+		%s
+	}
+	binary_attributes = {
+		%s
+	}
+}
+`, attributes, binaryAttributes)
+}
+
+func TestDiffValues(t *testing.T) {
+	assert.Empty(t, diffValues("member", "", []string{"a", "b"}, []string{"b", "a"}, false, modifyStrategyIncremental))
+
+	// only the delta is sent
+	changes := diffValues("member", "", []string{"a", "b", "c", "d"}, []string{"a", "b", "c", "e"}, false, modifyStrategyIncremental)
+	assert.Equal(t, []ldap.Change{
+		{Operation: ldap.DeleteAttribute, Modification: ldap.PartialAttribute{Type: "member", Vals: []string{"d"}}},
+		{Operation: ldap.AddAttribute, Modification: ldap.PartialAttribute{Type: "member", Vals: []string{"e"}}},
+	}, changes)
+
+	// replacing is smaller than the delta
+	changes = diffValues("member", "", []string{"a", "b", "c"}, []string{"d"}, false, modifyStrategyIncremental)
+	assert.Equal(t, []ldap.Change{
+		{Operation: ldap.ReplaceAttribute, Modification: ldap.PartialAttribute{Type: "member", Vals: []string{"d"}}},
+	}, changes)
+
+	// ordered values are always replaced
+	changes = diffValues("olcAccess", "", []string{"a", "b"}, []string{"b", "a"}, true, modifyStrategyIncremental)
+	assert.Equal(t, []ldap.Change{
+		{Operation: ldap.ReplaceAttribute, Modification: ldap.PartialAttribute{Type: "olcAccess", Vals: []string{"b", "a"}}},
+	}, changes)
+	assert.Empty(t, diffValues("olcAccess", "", []string{"a", "b"}, []string{"a", "b"}, true, modifyStrategyIncremental))
+
+	// replace strategy always replaces, but only if something changed
+	changes = diffValues("sn", "", []string{"a", "b"}, []string{"a", "c"}, false, modifyStrategyReplace)
+	assert.Equal(t, []ldap.Change{
+		{Operation: ldap.ReplaceAttribute, Modification: ldap.PartialAttribute{Type: "sn", Vals: []string{"a", "c"}}},
+	}, changes)
+	assert.Empty(t, diffValues("sn", "", []string{"a", "b"}, []string{"b", "a"}, false, modifyStrategyReplace))
+
+	// add only strategy never deletes
+	changes = diffValues("auditTrail", "", []string{"a", "b", "c"}, []string{"d"}, false, modifyStrategyAddOnly)
+	assert.Equal(t, []ldap.Change{
+		{Operation: ldap.AddAttribute, Modification: ldap.PartialAttribute{Type: "auditTrail", Vals: []string{"d"}}},
+	}, changes)
+	assert.Empty(t, diffValues("auditTrail", "", []string{"a", "b"}, []string{"a"}, false, modifyStrategyAddOnly))
+
+	// an empty list deletes the attribute
+	for _, strategy := range []string{modifyStrategyIncremental, modifyStrategyReplace} {
+		changes = diffValues("description", "", []string{"a", "b"}, []string{}, false, strategy)
+		assert.Equal(t, []ldap.Change{
+			{Operation: ldap.DeleteAttribute, Modification: ldap.PartialAttribute{Type: "description", Vals: []string{}}},
+		}, changes)
+	}
+	assert.Empty(t, diffValues("description", "", []string{}, []string{}, false, modifyStrategyIncremental))
+
+	// DNs naming the same entry are unchanged
+	assert.Empty(t, diffValues("member", matchingRuleDistinguishedName, []string{"cn=Admin,dc=example,dc=com"}, []string{"CN=admin, DC=example, DC=com"}, false, modifyStrategyIncremental))
+}
+
+func TestLDAPObjectResourceModifyStrategy(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testModifyStrategyConfig(`["first", "second"]`),
+			},
+			// Removing a value of an add_only attribute keeps it on the server
+			{
+				Config: testModifyStrategyConfig(`["first"]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_object.strategy", "attributes.description.#", "1"),
+					testCheckServerValues("cn=strategy,dc=example,dc=com", "description", []string{"first", "second"}),
+				),
+			},
+		},
+	})
+}
+
+func testModifyStrategyConfig(descriptions string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "strategy" {
+	dn = "cn=strategy,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["strategy"]
+		"sn" = ["strategy"]
+		"description" = %s
+	}
+	modify_strategy = {
+		"description" = "add_only"
+		"sn" = "replace"
+	}
+}
+`, descriptions)
+}
+
+// testCheckServerValues checks the values of an attribute directly on the server.
+func testCheckServerValues(dn string, attributeType string, expected []string) resource.TestCheckFunc {
+	return testCheckServerValuesWithControls(dn, attributeType, expected)
+}
+
+// testPreCheckServer runs a check against the server before the configuration of a step is applied, e.g. to verify
+// the result of a previous step which expected an error, since its checks aren't run.
+func testPreCheckServer(t *testing.T, check resource.TestCheckFunc) func() {
+	return func() {
+		if err := check(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// testCheckServerValuesWithControls checks the values of an attribute directly on the server, sending the given
+// controls with the search.
+func testCheckServerValuesWithControls(dn string, attributeType string, expected []string, controls ...ldap.Control) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		conn, err := ldap.DialURL(os.Getenv("LDAP_URL"))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		if err := conn.Bind(os.Getenv("LDAP_BIND_DN"), os.Getenv("LDAP_BIND_PASSWORD")); err != nil {
+			return err
+		}
+		entry, err := GetEntryWithControls(conn, dn, controls, attributeType)
+		if err != nil {
+			return err
+		}
+		values := entry.GetAttributeValues(attributeType)
+		if len(values) == 0 && len(expected) == 0 {
+			return nil
+		}
+		sort.Strings(values)
+		sort.Strings(expected)
+		if !funk.Equal(expected, values) {
+			return fmt.Errorf("expected %s of %s to be %v, got %v", attributeType, dn, expected, values)
+		}
+		return nil
+	}
+}
+
+func TestServerAttributeType(t *testing.T) {
+	aliases := map[string]string{"username": "sAMAccountName"}
+	assert.Equal(t, "sAMAccountName", serverAttributeType("username", aliases))
+	assert.Equal(t, "mail", serverAttributeType("mail", aliases))
+	assert.Equal(t, "mail", serverAttributeType("mail", nil))
+}
+
+func TestLDAPObjectResourceAttributeAliases(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAliasConfig("alias"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_object.alias", "attributes.username.0", "alias"),
+					testCheckServerValues("cn=alias,dc=example,dc=com", "uid", []string{"alias"}),
+				),
+			},
+			{
+				Config: testAliasConfig("alias2"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_object.alias", "attributes.username.0", "alias2"),
+					testCheckServerValues("cn=alias,dc=example,dc=com", "uid", []string{"alias2"}),
+				),
+			},
+		},
+	})
+}
+
+func testAliasConfig(username string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "alias" {
+	dn = "cn=alias,dc=example,dc=com"
+	object_classes = ["inetOrgPerson"]
+	attributes = {
+		"cn" = ["alias"]
+		"sn" = ["alias"]
+		"username" = [%q]
+	}
+	attribute_aliases = {
+		"username" = "uid"
+	}
+}
+`, username)
+}
+
+func TestLDAPObjectResourceRemoveAttribute(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testRemoveAttributeConfig(`"description" = ["removable"]`),
+				Check:  testCheckServerValues("cn=remove,dc=example,dc=com", "description", []string{"removable"}),
+			},
+			// Dropping the key deletes the attribute
+			{
+				Config: testRemoveAttributeConfig(``),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("ldap_object.remove", "attributes.description"),
+					testCheckServerValues("cn=remove,dc=example,dc=com", "description", nil),
+				),
+			},
+			// Re-adding the attribute
+			{
+				Config: testRemoveAttributeConfig(`"description" = ["removable"]`),
+				Check:  testCheckServerValues("cn=remove,dc=example,dc=com", "description", []string{"removable"}),
+			},
+			// An empty list deletes the attribute as well
+			{
+				Config: testRemoveAttributeConfig(`"description" = []`),
+				Check:  testCheckServerValues("cn=remove,dc=example,dc=com", "description", nil),
+			},
+			{
+				Config: testRemoveAttributeConfig(`"description" = ["removable"]`),
+				Check:  testCheckServerValues("cn=remove,dc=example,dc=com", "description", []string{"removable"}),
+			},
+		},
+	})
+}
+
+func testRemoveAttributeConfig(description string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "remove" {
+	dn = "cn=remove,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["remove"]
+		"sn" = ["remove"]
+		%s
+	}
+}
+`, description)
+}
+
+func TestLDAPObjectResourceSensitiveAttributes(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testSensitiveConfig("secret"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_object.sensitive", "sensitive_attributes.userPassword.0", "secret"),
+					resource.TestCheckNoResourceAttr("ldap_object.sensitive", "attributes.userPassword"),
+				),
+			},
+			{
+				Config: testSensitiveConfig("secret2"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_object.sensitive", "sensitive_attributes.userPassword.0", "secret2"),
+					testCheckServerValues("cn=sensitive,dc=example,dc=com", "userPassword", []string{"secret2"}),
+				),
+			},
+		},
+	})
+}
+
+func testSensitiveConfig(password string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "sensitive" {
+	dn = "cn=sensitive,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["sensitive"]
+		"sn" = ["sensitive"]
+	}
+	sensitive_attributes = {
+		"userPassword" = [%q]
+	}
+}
+`, password)
+}
+
+func TestLDAPObjectResourcePermissiveModify(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testPermissiveConfig(`["first"]`, false),
+			},
+			// Adding a value which already exists fails without the control
+			{
+				PreConfig:   testAddValueExternally(t, "cn=permissive,dc=example,dc=com", "description", "second"),
+				Config:      testPermissiveConfig(`["first", "second"]`, false),
+				ExpectError: regexp.MustCompile("Can not modify entry"),
+			},
+			{
+				Config: testPermissiveConfig(`["first", "second"]`, true),
+				Check:  testCheckServerValues("cn=permissive,dc=example,dc=com", "description", []string{"first", "second"}),
+			},
+		},
+	})
+}
+
+func testPermissiveConfig(descriptions string, permissive bool) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "permissive" {
+	dn = "cn=permissive,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["permissive"]
+		"sn" = ["permissive"]
+		"description" = %s
+	}
+	# values added externally are not refreshed into the state
+	modify_strategy = {
+		"description" = "add_only"
+	}
+	permissive_modify = %t
+}
+`, descriptions, permissive)
+}
+
+func testAddValueExternally(t *testing.T, dn string, attributeType string, value string) func() {
+	return testExternally(t, func(conn *ldap.Conn) error {
+		r := ldap.NewModifyRequest(dn, []ldap.Control{})
+		r.Add(attributeType, []string{value})
+		return conn.Modify(r)
+	})
+}
+
+func TestLDAPObjectResourceIgnoreAttributeChanges(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testIgnoreAttributeChangesConfig,
+				Check:  testCheckServerValues("cn=drifting,dc=example,dc=com", "description", []string{"original"}),
+			},
+			// The drifted value is read into the state and kept on the server
+			{
+				PreConfig: testAddValueExternally(t, "cn=drifting,dc=example,dc=com", "description", "drifted"),
+				Config:    testIgnoreAttributeChangesConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_object.drifting", "attributes.description.#", "2"),
+					testCheckServerValues("cn=drifting,dc=example,dc=com", "description", []string{"original", "drifted"}),
+				),
+			},
+			{
+				Config:   testIgnoreAttributeChangesConfig,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+const testIgnoreAttributeChangesConfig = `
+resource "ldap_object" "drifting" {
+	dn = "cn=drifting,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["drifting"]
+		"sn" = ["drifting"]
+		"description" = ["original"]
+	}
+	ignore_attribute_changes = ["description"]
+}
+`
+
+func TestLDAPObjectResourcePostCreateAttributes(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testPostCreateConfig("created"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckServerValues("cn=postcreate,dc=example,dc=com", "description", []string{"created"}),
+					resource.TestCheckResourceAttr("ldap_object.postcreate", "post_create_attributes.description.0", "created"),
+				),
+			},
+			{
+				Config: testPostCreateConfig("updated"),
+				Check:  testCheckServerValues("cn=postcreate,dc=example,dc=com", "description", []string{"updated"}),
+			},
+		},
+	})
+}
+
+func testPostCreateConfig(description string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "postcreate" {
+	dn = "cn=postcreate,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["postcreate"]
+		"sn" = ["postcreate"]
+	}
+	post_create_attributes = {
+		"description" = ["%s"]
+	}
+}
+`, description)
+}
+
+func TestLDAPObjectResourceTimeouts(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testTimeoutsConfig("five minutes"),
+				ExpectError: regexp.MustCompile("Invalid duration"),
+			},
+			{
+				Config: testTimeoutsConfig("1m"),
+				Check:  resource.TestCheckResourceAttr("ldap_object.timeouts", "timeouts.create", "1m"),
+			},
+		},
+	})
+}
+
+func testTimeoutsConfig(create string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "timeouts" {
+	dn = "cn=timeouts,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["timeouts"]
+		"sn" = ["timeouts"]
+	}
+	timeouts {
+		create = "%s"
+	}
+}
+`, create)
+}
+
+func TestLDAPObjectResourceDeleteTimeout(t *testing.T) {
+	ctx := context.Background()
+	conn := testSlowServer(t)
+
+	r := &LDAPObjectResource{conn: conn}
+	var schemaResponse fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResponse)
+
+	state := tfsdk.State{
+		Schema: schemaResponse.Schema,
+		Raw:    tftypes.NewValue(schemaResponse.Schema.Type().TerraformType(ctx), nil),
+	}
+	assert.False(t, state.SetAttribute(ctx, path.Root("dn"), "cn=slow,dc=example,dc=com").HasError())
+	assert.False(t, state.SetAttribute(ctx, path.Root("timeouts").AtName("delete"), "100ms").HasError())
+
+	response := fwresource.DeleteResponse{State: state}
+	start := time.Now()
+	r.Delete(ctx, fwresource.DeleteRequest{State: state}, &response)
+
+	assert.Less(t, time.Since(start), 10*time.Second)
+	if assert.True(t, response.Diagnostics.HasError()) {
+		assert.Equal(t, "Timeout exceeded", response.Diagnostics[0].Summary())
+		assert.Contains(t, response.Diagnostics[0].Detail(), "delete timeout")
+		assert.Contains(t, response.Diagnostics[0].Detail(), "cn=slow,dc=example,dc=com")
+	}
+}
+
+// testSlowServer returns a connection to a fake LDAP server, which accepts requests, but never answers them.
+func testSlowServer(t *testing.T) *ldap.Conn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			serverConn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { _ = serverConn.Close() })
+			go func() { _, _ = io.Copy(io.Discard, serverConn) }()
+		}
+	}()
+
+	conn, err := ldap.DialURL(fmt.Sprintf("ldap://%s", listener.Addr()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestLDAPObjectResourceCreateParents(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testCheckEntryMissing("ou=apps,dc=example,dc=com"),
+		Steps: []resource.TestStep{
+			{
+				Config: testCreateParentsConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckServerValues("ou=service-accounts,ou=apps,dc=example,dc=com", "description", []string{"created by terraform"}),
+					resource.TestCheckResourceAttr("ldap_object.parents", "created_parents.#", "2"),
+					resource.TestCheckResourceAttr("ldap_object.parents", "created_parents.0", "ou=apps,dc=example,dc=com"),
+					resource.TestCheckResourceAttr("ldap_object.parents", "created_parents.1", "ou=service-accounts,ou=apps,dc=example,dc=com"),
+				),
+			},
+		},
+	})
+}
+
+func testCreateParentsConfig() string {
+	return `
+resource "ldap_object" "parents" {
+	dn = "cn=app,ou=service-accounts,ou=apps,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["app"]
+		"sn" = ["app"]
+	}
+	create_parents = true
+	parent_attributes = {
+		"description" = ["created by terraform"]
+	}
+	delete_empty_parents = true
+}
+`
+}
+
+func testCheckEntryMissing(dn string) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		conn, err := ldap.DialURL(os.Getenv("LDAP_URL"))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		if err := conn.Bind(os.Getenv("LDAP_BIND_DN"), os.Getenv("LDAP_BIND_PASSWORD")); err != nil {
+			return err
+		}
+		if _, err := GetEntry(conn, dn); err == nil {
+			return fmt.Errorf("entry %s still exists", dn)
+		} else if !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return err
+		}
+		return nil
+	}
+}
+
+func TestLDAPObjectResourceLocalizedAttributes(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testLocalizedConfig("Hello", "Bonjour"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckServerValues("cn=localized,dc=example,dc=com", "description;lang-en", []string{"Hello"}),
+					testCheckServerValues("cn=localized,dc=example,dc=com", "description;lang-fr", []string{"Bonjour"}),
+					resource.TestCheckResourceAttr("data.ldap_object.localized", "localized_attributes.description.en.0", "Hello"),
+					resource.TestCheckResourceAttr("data.ldap_object.localized", "localized_attributes.description.fr.0", "Bonjour"),
+				),
+			},
+			{
+				Config: testLocalizedConfig("Hi", "Salut"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckServerValues("cn=localized,dc=example,dc=com", "description;lang-en", []string{"Hi"}),
+					testCheckServerValues("cn=localized,dc=example,dc=com", "description;lang-fr", []string{"Salut"}),
+				),
+			},
+		},
+	})
+}
+
+func testLocalizedConfig(en string, fr string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "localized" {
+	dn = "cn=localized,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["localized"]
+		"sn" = ["localized"]
+	}
+	localized_attributes = {
+		"description" = {
+			"en" = ["%s"]
+			"fr" = ["%s"]
+		}
+	}
+}
+
+data "ldap_object" "localized" {
+	dn = ldap_object.localized.dn
+	depends_on = [ldap_object.localized]
+}
+`, en, fr)
+}
+
+func TestLDAPObjectResourceDrift(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testDriftConfig,
+			},
+			// Changes to managed attributes are detected
+			{
+				PreConfig:          testAddValueExternally(t, "cn=drift,dc=example,dc=com", "sn", "changed"),
+				Config:             testDriftConfig,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testDriftConfig,
+				Check:  testCheckServerValues("cn=drift,dc=example,dc=com", "sn", []string{"drift"}),
+			},
+			// Unmanaged attributes are ignored
+			{
+				PreConfig: testAddValueExternally(t, "cn=drift,dc=example,dc=com", "description", "unmanaged"),
+				Config:    testDriftConfig,
+				PlanOnly:  true,
+			},
+		},
+	})
+}
+
+const testDriftConfig = `
+resource "ldap_object" "drift" {
+	dn = "cn=drift,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["drift"]
+		"sn" = ["drift"]
+	}
+}
+`
+
+func TestLDAPObjectResourceRecursiveDelete(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testCheckEntryMissing("ou=recursive,dc=example,dc=com"),
+		Steps: []resource.TestStep{
+			{
+				Config: testRecursiveDeleteConfig(false),
+			},
+			// Entries with children are not deleted by default
+			{
+				PreConfig:   testAddChildExternally(t, "cn=child,ou=recursive,dc=example,dc=com"),
+				Config:      testRecursiveDeleteConfig(false),
+				Destroy:     true,
+				ExpectError: regexp.MustCompile("The entry has 1 children"),
+			},
+			{
+				Config: testRecursiveDeleteConfig(true),
+			},
+		},
+	})
+}
+
+func testRecursiveDeleteConfig(recursive bool) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "recursive" {
+	dn = "ou=recursive,dc=example,dc=com"
+	object_classes = ["organizationalUnit"]
+	attributes = {
+		"ou" = ["recursive"]
+	}
+	recursive_delete = %t
+}
+`, recursive)
+}
+
+func testAddChildExternally(t *testing.T, dn string) func() {
+	return testExternally(t, func(conn *ldap.Conn) error {
+		r := ldap.NewAddRequest(dn, []ldap.Control{})
+		if strings.HasPrefix(dn, "ou=") {
+			r.Attribute("objectClass", []string{"organizationalUnit"})
+		} else {
+			r.Attribute("objectClass", []string{"person"})
+			r.Attribute("sn", []string{"child"})
+		}
+		if parsed, err := ldap.ParseDN(dn); err == nil {
+			r.Attribute(parsed.RDNs[0].Attributes[0].Type, []string{parsed.RDNs[0].Attributes[0].Value})
+		}
+		return conn.Add(r)
+	})
+}
+
+func TestLDAPObjectResourceRecursiveDeleteTreeDeleteControl(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !testServerSupportsControl(ControlTypeTreeDelete) {
+				t.Skip("server does not support the tree delete control")
+			}
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testCheckEntryMissing("ou=recursive,dc=example,dc=com"),
+		Steps: []resource.TestStep{
+			{
+				Config: testRecursiveDeleteConfig(true),
+			},
+			{
+				PreConfig: testAddChildExternally(t, "cn=child,ou=recursive,dc=example,dc=com"),
+				Config:    testRecursiveDeleteConfig(true),
+			},
+		},
+	})
+}
+
+func TestLDAPObjectResourceRecursiveDeleteClientSide(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testIgnoredControlsProviderFactories(ControlTypeTreeDelete),
+		CheckDestroy:             testCheckEntryMissing("ou=recursive,dc=example,dc=com"),
+		Steps: []resource.TestStep{
+			{
+				Config: testRecursiveDeleteConfig(true),
+			},
+			{
+				PreConfig: func() {
+					testAddChildExternally(t, "ou=nested,ou=recursive,dc=example,dc=com")()
+					testAddChildExternally(t, "cn=child,ou=nested,ou=recursive,dc=example,dc=com")()
+				},
+				Config: testRecursiveDeleteConfig(true),
+			},
+		},
+	})
+}
+
+func testServerSupportsControl(controlType string) bool {
+	conn, err := ldap.DialURL(os.Getenv("LDAP_URL"))
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+	if err := conn.Bind(os.Getenv("LDAP_BIND_DN"), os.Getenv("LDAP_BIND_PASSWORD")); err != nil {
+		return false
+	}
+	supported, err := SupportsControl(conn, controlType)
+	return err == nil && supported
+}
+
+func TestLDAPObjectResourceDeletionProtection(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testCheckEntryMissing("cn=protected,dc=example,dc=com"),
+		Steps: []resource.TestStep{
+			{
+				Config: testDeletionProtectionConfig(true),
+			},
+			{
+				Config:      testDeletionProtectionConfig(true),
+				Destroy:     true,
+				ExpectError: regexp.MustCompile("Deletion protection is enabled"),
+			},
+			{
+				Config: testDeletionProtectionConfig(false),
+			},
+		},
+	})
+}
+
+func testDeletionProtectionConfig(protected bool) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "protected" {
+	dn = "cn=protected,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["protected"]
+		"sn" = ["protected"]
+	}
+	deletion_protection = %t
+}
+`, protected)
+}
+
+func TestLDAPObjectResourceDestroyActionClearAttributes(t *testing.T) {
+	dn := "ou=tombstone,dc=example,dc=com"
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			t.Cleanup(testDeleteEntryExternally(t, dn))
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		// the entry survives with its RDN, but without the other managed attributes
+		CheckDestroy: resource.ComposeAggregateTestCheckFunc(
+			testCheckServerValues(dn, "ou", []string{"tombstone"}),
+			testCheckServerValues(dn, "objectClass", []string{"organizationalUnit"}),
+			testCheckServerValues(dn, "description", []string{}),
+			testCheckServerValues(dn, "telephoneNumber", []string{}),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "ldap_object" "tombstone" {
+	dn = %q
+	object_classes = ["organizationalUnit"]
+	attributes = {
+		"ou" = ["tombstone"]
+		"description" = ["decommissioned soon"]
+		"telephoneNumber" = ["123"]
+	}
+	destroy_action = "clear_attributes"
+}
+`, dn),
+				Check: testCheckServerValues(dn, "description", []string{"decommissioned soon"}),
+			},
+		},
+	})
+}
+
+func TestLDAPObjectResourceOnExisting(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() {
+					testAddEntryExternally(t, "cn=adopt,dc=example,dc=com", map[string][]string{"sn": {"external"}, "description": {"external"}})()
+					testAddEntryExternally(t, "cn=overwrite,dc=example,dc=com", map[string][]string{"sn": {"external"}, "description": {"external"}})()
+				},
+				Config:      testOnExistingConfig("error"),
+				ExpectError: regexp.MustCompile("terraform import <resource address> \"cn=adopt,dc=example,dc=com\""),
+			},
+			{
+				Config: testOnExistingConfig("adopt"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckServerValues("cn=adopt,dc=example,dc=com", "sn", []string{"adopted"}),
+					testCheckServerValues("cn=adopt,dc=example,dc=com", "description", []string{"external"}),
+					testCheckServerValues("cn=overwrite,dc=example,dc=com", "sn", []string{"overwritten"}),
+					testCheckServerValues("cn=overwrite,dc=example,dc=com", "description", []string{"external"}),
+				),
+			},
+		},
+	})
+}
+
+func testOnExistingConfig(onExisting string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "adopt" {
+	dn = "cn=adopt,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["adopt"]
+		"sn" = ["adopted"]
+	}
+	on_existing = %q
+}
+
+resource "ldap_object" "overwrite" {
+	dn = "cn=overwrite,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["overwrite"]
+		"sn" = ["overwritten"]
+	}
+	on_existing = %q
+}
+`, onExisting, strings.Replace(onExisting, "adopt", "overwrite", 1))
+}
+
+// testExternally returns a function running the operation on a connection bound like the provider, to change the
+// server outside of Terraform. The test fails if the connection or the operation fails.
+func testExternally(t *testing.T, operation func(conn *ldap.Conn) error) func() {
+	return func() {
+		t.Helper()
+		conn, err := ldap.DialURL(os.Getenv("LDAP_URL"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		if err := conn.Bind(os.Getenv("LDAP_BIND_DN"), os.Getenv("LDAP_BIND_PASSWORD")); err != nil {
+			t.Fatal(err)
+		}
+		if err := operation(conn); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func testAddEntryExternally(t *testing.T, dn string, attributes map[string][]string) func() {
+	return testExternally(t, func(conn *ldap.Conn) error {
+		r := ldap.NewAddRequest(dn, []ldap.Control{})
+		r.Attribute("objectClass", []string{"person"})
+		if parsed, err := ldap.ParseDN(dn); err == nil {
+			r.Attribute(parsed.RDNs[0].Attributes[0].Type, []string{parsed.RDNs[0].Attributes[0].Value})
+		}
+		for attributeType, values := range attributes {
+			r.Attribute(attributeType, values)
+		}
+		return conn.Add(r)
+	})
+}
+
+func TestLDAPObjectResourceRelax(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !testServerSupportsControl(ControlTypeRelax) {
+				t.Skip("server does not support the relax rules control")
+			}
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testRelaxConfig("20200101000000Z", false),
+				ExpectError: regexp.MustCompile("Operational attribute requires relax"),
+			},
+			{
+				Config: testRelaxConfig("20200101000000Z", true),
+				Check:  testCheckServerValues("cn=relax,dc=example,dc=com", "modifyTimestamp", []string{"20200101000000Z"}),
+			},
+		},
+	})
+}
+
+func testRelaxConfig(modifyTimestamp string, relax bool) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "relax" {
+	dn = "cn=relax,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["relax"]
+		"sn" = ["relax"]
+	}
+	post_create_attributes = {
+		"modifyTimestamp" = ["%s"]
+	}
+	relax = %t
+}
+`, modifyTimestamp, relax)
+}
+
+func TestLDAPObjectResourceDeletedExternally(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testDeletedExternallyConfig,
+			},
+			// The entry is removed from the state and planned to be created again
+			{
+				PreConfig:          testDeleteEntryExternally(t, "cn=vanished,dc=example,dc=com"),
+				Config:             testDeletedExternallyConfig,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testDeletedExternallyConfig,
+				Check:  testCheckServerValues("cn=vanished,dc=example,dc=com", "sn", []string{"vanished"}),
+			},
+		},
+	})
+}
+
+const testDeletedExternallyConfig = `
+resource "ldap_object" "vanished" {
+	dn = "cn=vanished,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["vanished"]
+		"sn" = ["vanished"]
+	}
+}
+`
+
+func testDeleteEntryExternally(t *testing.T, dn string) func() {
+	return testExternally(t, func(conn *ldap.Conn) error {
+		// the entry may already be gone, e.g. when cleaning up
+		if err := conn.Del(ldap.NewDelRequest(dn, []ldap.Control{})); !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return err
+		}
+		return nil
+	})
+}
+
+func TestLDAPObjectResourceMatchingRules(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testMatchingRulesConfig("soundexMatch"),
+				ExpectError: regexp.MustCompile("Invalid Attribute Value Match"),
+			},
+			{
+				Config: testMatchingRulesConfig("caseIgnoreMatch"),
+				Check:  resource.TestCheckResourceAttr("ldap_object.matching_rules", "attributes.description.0", "Managed By Terraform"),
+			},
+		},
+	})
+}
+
+func testMatchingRulesConfig(rule string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "matching_rules" {
+	dn = "cn=matching_rules,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["matching_rules"]
+		"sn" = ["matching_rules"]
+		"description" = ["Managed By Terraform"]
+	}
+	matching_rules = {
+		"description" = "%s"
+	}
+}
+`, rule)
+}
+
+func TestLDAPObjectResourceValidateSchema(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testValidateSchemaConfig(`"cn" = ["validate_schema"]`),
+				ExpectError: regexp.MustCompile("(?s)Missing required attributes.*sn"),
+				PlanOnly:    true,
+			},
+			{
+				Config:      testValidateSchemaConfig(`"cn" = ["validate_schema"], "sn" = ["validate_schema"], "preferredLanguage" = ["en", "de"]`),
+				ExpectError: regexp.MustCompile("(?s)Single-valued attribute.*preferredLanguage"),
+				PlanOnly:    true,
+			},
+			{
+				Config: testValidateSchemaConfig(`"cn" = ["validate_schema"], "sn" = ["validate_schema"]`),
+				Check:  testCheckServerValues("cn=validate_schema,dc=example,dc=com", "sn", []string{"validate_schema"}),
+			},
+		},
+	})
+}
+
+func testValidateSchemaConfig(attributes string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "validate_schema" {
+	dn = "cn=validate_schema,dc=example,dc=com"
+	object_classes = ["inetOrgPerson"]
+	attributes = { %s }
+	validate_schema = true
+}
+`, attributes)
+}
+
+func TestLDAPObjectResourceValidateObjectClasses(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testValidateObjectClassesConfig(`["inetOrgPersom"]`),
+				ExpectError: regexp.MustCompile("(?s)Unknown object class.*Did you mean inetOrgPerson"),
+				PlanOnly:    true,
+			},
+		},
+	})
+}
+
+func testValidateObjectClassesConfig(objectClasses string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "validate_object_classes" {
+	dn = "cn=validate_object_classes,dc=example,dc=com"
+	object_classes = %s
+	attributes = {
+		"cn" = ["validate_object_classes"]
+		"sn" = ["validate_object_classes"]
+	}
+	validate_schema = true
+}
+`, objectClasses)
+}
+
+func TestLDAPObjectResourceDNFormat(t *testing.T) {
+	var entryUUID string
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				PreConfig:          testAddEntryExternally(t, "cn=bob,dc=example,dc=com", map[string][]string{"sn": {"bob"}}),
+				Config:             testDNFormatConfig,
+				ImportState:        true,
+				ImportStateId:      "CN=Bob, DC=example,DC=com",
+				ImportStatePersist: true,
+				ResourceName:       "ldap_object.bob",
+				ImportStateCheck: func(_ []*terraform.InstanceState) error {
+					return testCaptureEntryUUID("cn=bob,dc=example,dc=com", &entryUUID)(nil)
+				},
+			},
+			// The entry is modified in place instead of being recreated
+			{
+				Config: testDNFormatConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_object.bob", "dn", "CN=Bob, DC=example,DC=com"),
+					testCheckEntryUUID("cn=bob,dc=example,dc=com", &entryUUID, true),
+				),
+			},
+			{
+				Config:   testDNFormatConfig,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+const testDNFormatConfig = `
+resource "ldap_object" "bob" {
+	dn = "CN=Bob, DC=example,DC=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["bob"]
+		"sn" = ["bob"]
+	}
+}
+`
+
+func TestLDAPObjectResourceRename(t *testing.T) {
+	var entryUUID string
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testRenameConfig("rename", "rename"),
+				Check:  testCaptureEntryUUID("cn=rename,dc=example,dc=com", &entryUUID),
+			},
+			// The RDN and the attribute have to change together
+			{
+				Config:      testRenameConfig("renamed", "rename"),
+				ExpectError: regexp.MustCompile("RDN does not match the attributes"),
+			},
+			{
+				Config: testRenameConfig("renamed", "renamed"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckEntryUUID("cn=renamed,dc=example,dc=com", &entryUUID, true),
+					testCheckServerValues("cn=renamed,dc=example,dc=com", "cn", []string{"renamed"}),
+					testCheckServerValues("cn=renamed,dc=example,dc=com", "description", []string{"renamed"}),
+				),
+			},
+		},
+	})
+}
+
+func testRenameConfig(rdn string, cn string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "rename" {
+	dn = "cn=%s,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["%s"]
+		"sn" = ["rename"]
+		"description" = ["%s"]
+	}
+}
+`, rdn, cn, cn)
+}
+
+func TestLDAPObjectResourceRenamePolicyReplace(t *testing.T) {
+	var entryUUID string
+	config := func(cn string) string {
+		return fmt.Sprintf(`
+resource "ldap_object" "replace" {
+	dn = "cn=%[1]s,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["%[1]s"]
+		"sn" = ["replace"]
+	}
+	rename_policy = "replace"
+}
+`, cn)
+	}
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testCheckEntryMissing("cn=replaced,dc=example,dc=com"),
+		Steps: []resource.TestStep{
+			{
+				Config: config("replace"),
+				Check:  testCaptureEntryUUID("cn=replace,dc=example,dc=com", &entryUUID),
+			},
+			// the entry is deleted and added instead of being renamed
+			{
+				Config: config("replaced"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckEntryUUID("cn=replaced,dc=example,dc=com", &entryUUID, false),
+					testCheckEntryMissing("cn=replace,dc=example,dc=com"),
+				),
+			},
+		},
+	})
+}
+
+func TestRDNConflicts(t *testing.T) {
+	rdn, _, err := SplitRDN("cn=Alice+uid=alice,dc=example,dc=com")
+	assert.NoError(t, err)
+
+	assert.Empty(t, rdnConflicts(rdn, map[string][]string{"CN": {"alice"}, "sn": {"Smith"}}, nil))
+	assert.Empty(t, rdnConflicts(rdn, map[string][]string{"sn": {"Smith"}}, nil))
+	conflicts := rdnConflicts(rdn, map[string][]string{"cn": {"Bob"}, "uid": {"alice", "bob"}}, nil)
+	assert.Len(t, conflicts, 1)
+	assert.Equal(t, "cn", conflicts[0].Type)
+	assert.Equal(t, "Alice", conflicts[0].Value)
+}
+
+func TestRenameAttributes(t *testing.T) {
+	oldRDN, _, err := SplitRDN("cn=Alice,dc=example,dc=com")
+	assert.NoError(t, err)
+	newRDN, _, err := SplitRDN("cn=Alicia,dc=example,dc=com")
+	assert.NoError(t, err)
+
+	assert.Equal(t,
+		map[string][]string{"cn": {"Smith", "Alicia"}, "sn": {"Alice"}},
+		renameAttributes(map[string][]string{"cn": {"alice", "Smith"}, "sn": {"Alice"}}, oldRDN, newRDN, nil),
+	)
+}
+
+func TestLDAPObjectResourceComposedDN(t *testing.T) {
+	var entryUUID string
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testComposedDNConfig("Doe, John"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_object.composed", "dn", `cn=Doe\, John,ou=composed,dc=example,dc=com`),
+					testCaptureEntryUUID(`cn=Doe\, John,ou=composed,dc=example,dc=com`, &entryUUID),
+				),
+			},
+			{
+				Config: testComposedDNConfig("Doe, Jane"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_object.composed", "dn", `cn=Doe\, Jane,ou=composed,dc=example,dc=com`),
+					testCheckEntryUUID(`cn=Doe\, Jane,ou=composed,dc=example,dc=com`, &entryUUID, true),
+				),
+			},
+			{
+				Config: `
+resource "ldap_object" "invalid" {
+	dn = "cn=invalid,dc=example,dc=com"
+	rdn_attribute = "cn"
+	rdn_value = "invalid"
+	parent_dn = "dc=example,dc=com"
+	object_classes = ["person"]
+}
+`,
+				ExpectError: regexp.MustCompile("Invalid Attribute Combination"),
+			},
+		},
+	})
+}
+
+func testComposedDNConfig(cn string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "parent" {
+	dn = "ou=composed,dc=example,dc=com"
+	object_classes = ["organizationalUnit"]
+}
+
+resource "ldap_object" "composed" {
+	rdn_attribute = "cn"
+	rdn_value = "%[1]s"
+	parent_dn = ldap_object.parent.dn
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["%[1]s"]
+		"sn" = ["Doe"]
+	}
+}
+`, cn)
+}
+
+func TestComposeDN(t *testing.T) {
+	assert.Equal(t, types.StringValue("cn=test,dc=example,dc=com"), composeDN(&LDAPObjectResourceModel{
+		DN:           types.StringValue("cn=test,dc=example,dc=com"),
+		RDNAttribute: types.StringNull(),
+	}))
+	assert.Equal(t, types.StringValue(`cn=Doe\, John\+1,ou=people,dc=example,dc=com`), composeDN(&LDAPObjectResourceModel{
+		RDNAttribute: types.StringValue("cn"),
+		RDNValue:     types.StringValue("Doe, John+1"),
+		ParentDN:     types.StringValue("ou=people,dc=example,dc=com"),
+	}))
+	assert.True(t, composeDN(&LDAPObjectResourceModel{
+		RDNAttribute: types.StringValue("cn"),
+		RDNValue:     types.StringValue("test"),
+		ParentDN:     types.StringUnknown(),
+	}).IsUnknown())
+}
+
+func TestLDAPObjectResourceOptimisticLocking(t *testing.T) {
+	if os.Getenv(resource.EnvTfAcc) == "" {
+		t.Skipf("Acceptance tests skipped unless env '%s' set", resource.EnvTfAcc)
+	}
+	testAccPreCheck(t)
+	ctx := context.Background()
+	dn := "cn=locked,dc=example,dc=com"
+
+	conn, err := ldap.DialURL(os.Getenv("LDAP_URL"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	if err := conn.Bind(os.Getenv("LDAP_BIND_DN"), os.Getenv("LDAP_BIND_PASSWORD")); err != nil {
+		t.Fatal(err)
+	}
+	testAddEntryExternally(t, dn, map[string][]string{"sn": {"locked"}})()
+	t.Cleanup(testDeleteEntryExternally(t, dn))
+
+	entry, err := GetEntry(conn, dn, "entryCSN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	version := entry.GetAttributeValue("entryCSN")
+
+	// the entry is changed after its version was read
+	modify := ldap.NewModifyRequest(dn, nil)
+	modify.Replace("description", []string{"concurrent"})
+	if err := conn.Modify(modify); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &LDAPObjectResource{conn: conn}
+	var schemaResponse fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResponse)
+	newState := func(sn string, version string) tfsdk.State {
+		state := tfsdk.State{
+			Schema: schemaResponse.Schema,
+			Raw:    tftypes.NewValue(schemaResponse.Schema.Type().TerraformType(ctx), nil),
+		}
+		assert.False(t, state.SetAttribute(ctx, path.Root("id"), dn).HasError())
+		assert.False(t, state.SetAttribute(ctx, path.Root("dn"), dn).HasError())
+		assert.False(t, state.SetAttribute(ctx, path.Root("object_classes"), []string{"person"}).HasError())
+		assert.False(t, state.SetAttribute(ctx, path.Root("attributes"), map[string][]string{"cn": {"locked"}, "sn": {sn}}).HasError())
+		assert.False(t, state.SetAttribute(ctx, path.Root("lock_attribute"), "entryCSN").HasError())
+		assert.False(t, state.SetAttribute(ctx, path.Root("version"), version).HasError())
+		return state
+	}
+	update := func(version string) fwresource.UpdateResponse {
+		plan := newState("changed", version)
+		response := fwresource.UpdateResponse{State: newState("locked", version)}
+		r.Update(ctx, fwresource.UpdateRequest{
+			State: newState("locked", version),
+			Plan:  tfsdk.Plan{Schema: plan.Schema, Raw: plan.Raw},
+		}, &response)
+		return response
+	}
+
+	response := update(version)
+	if assert.True(t, response.Diagnostics.HasError()) {
+		assert.Contains(t, response.Diagnostics[0].Summary(), "Entry was changed concurrently")
+	}
+	assert.NoError(t, testCheckServerValues(dn, "sn", []string{"locked"})(nil))
+
+	entry, err = GetEntry(conn, dn, "entryCSN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response = update(entry.GetAttributeValue("entryCSN"))
+	assert.False(t, response.Diagnostics.HasError(), "%v", response.Diagnostics)
+	assert.NoError(t, testCheckServerValues(dn, "sn", []string{"changed"})(nil))
+
+	var newVersion string
+	response.State.GetAttribute(ctx, path.Root("version"), &newVersion)
+	assert.NotEqual(t, entry.GetAttributeValue("entryCSN"), newVersion)
+}
+
+func TestLDAPObjectResourceReadEntryControls(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !testServerSupportsControl(ControlTypePreRead) || !testServerSupportsControl(ControlTypePostRead) {
+				t.Skip("server does not support the read entry controls")
+			}
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testReadEntryControlsConfig("first"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("ldap_object.snapshot", "pre_read.%"),
+					resource.TestCheckNoResourceAttr("ldap_object.snapshot", "post_read.%"),
+				),
+			},
+			{
+				Config: testReadEntryControlsConfig("second"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_object.snapshot", "pre_read.sn.0", "first"),
+					resource.TestCheckResourceAttr("ldap_object.snapshot", "post_read.sn.0", "second"),
+				),
+			},
+		},
+	})
+}
+
+func testReadEntryControlsConfig(sn string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "snapshot" {
+	dn = "cn=snapshot,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["snapshot"]
+		"sn" = ["%s"]
+	}
+	capture_pre_read = ["sn"]
+	capture_post_read = ["sn"]
+}
+`, sn)
+}
+
+func TestLDAPObjectResourceLastChanges(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testLastChangesConfig(`"description" = ["audited"]`, false),
+				Check:  resource.TestCheckNoResourceAttr("ldap_object.audited", "last_changes.#"),
+			},
+			{
+				Config: testLastChangesConfig(`"telephoneNumber" = ["1", "2"]`, false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_object.audited", "last_changes.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs("ldap_object.audited", "last_changes.*", map[string]string{
+						"operation":   "delete",
+						"attribute":   "description",
+						"value_count": "0",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs("ldap_object.audited", "last_changes.*", map[string]string{
+						"attribute":   "telephoneNumber",
+						"value_count": "2",
+					}),
+				),
+			},
+			// changing an argument of the provider doesn't modify the entry, so the last changes are kept
+			{
+				Config: testLastChangesConfig(`"telephoneNumber" = ["1", "2"]`, true),
+				Check:  resource.TestCheckResourceAttr("ldap_object.audited", "last_changes.#", "2"),
+			},
+		},
+	})
+}
+
+func testLastChangesConfig(attribute string, permissive bool) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "audited" {
+	dn = "cn=audited,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["audited"]
+		"sn" = ["audited"]
+		%s
+	}
+	permissive_modify = %t
+}
+`, attribute, permissive)
+}
+
+func TestLastChanges(t *testing.T) {
+	r := ldap.NewModifyRequest("cn=test,dc=example,dc=com", nil)
+	r.Replace("sn", []string{"secret"})
+	r.Delete("description", []string{})
+	r.Add("mail", []string{"a@example.com", "b@example.com"})
+
+	var diagnostics diag.Diagnostics
+	var changes []LDAPObjectResourceChange
+	diagnostics.Append(lastChanges(context.Background(), r.Changes, &diagnostics).ElementsAs(context.Background(), &changes, false)...)
+	assert.False(t, diagnostics.HasError())
+	assert.Equal(t, []LDAPObjectResourceChange{
+		{Operation: types.StringValue("replace"), Attribute: types.StringValue("sn"), ValueCount: types.Int64Value(1)},
+		{Operation: types.StringValue("delete"), Attribute: types.StringValue("description"), ValueCount: types.Int64Value(0)},
+		{Operation: types.StringValue("add"), Attribute: types.StringValue("mail"), ValueCount: types.Int64Value(2)},
+	}, changes)
+}
+
+func TestLDAPObjectResourceModifyAssertion(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testModifyAssertionConfig("first"),
+			},
+			{
+				Config: testModifyAssertionConfig("second"),
+				Check:  testCheckServerValues("cn=assertion,dc=example,dc=com", "sn", []string{"second"}),
+			},
+			// The entry doesn't match the assertion after it was changed externally
+			{
+				PreConfig:   testAddValueExternally(t, "cn=assertion,dc=example,dc=com", "title", "locked"),
+				Config:      testModifyAssertionConfig("third"),
+				ExpectError: regexp.MustCompile("Entry was changed concurrently"),
+			},
+		},
+	})
+}
+
+func testModifyAssertionConfig(sn string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "assertion" {
+	dn = "cn=assertion,dc=example,dc=com"
+	object_classes = ["person", "organizationalPerson"]
+	attributes = {
+		"cn" = ["assertion"]
+		"sn" = ["%s"]
+	}
+	modify_assertion = "(!(title=locked))"
+}
+`, sn)
+}
+
+func TestLDAPObjectResourceGeneratePassword(t *testing.T) {
+	var password string
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testGeneratePasswordConfig("first"),
+				Check: resource.TestCheckResourceAttrWith("ldap_object.generated", "generated_password", func(value string) error {
+					password = value
+					return testBindAs("cn=generated,dc=example,dc=com", value)
+				}),
+			},
+			// The password isn't generated again on update
+			{
+				Config: testGeneratePasswordConfig("second"),
+				Check: resource.TestCheckResourceAttrWith("ldap_object.generated", "generated_password", func(value string) error {
+					if value != password {
+						return fmt.Errorf("the password was generated again")
+					}
+					return testBindAs("cn=generated,dc=example,dc=com", value)
+				}),
+			},
+		},
+	})
+}
+
+func testGeneratePasswordConfig(description string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "generated" {
+	dn = "cn=generated,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["generated"]
+		"sn" = ["generated"]
+		"description" = [%q]
+	}
+	generate_password = true
+}
+`, description)
+}
+
+func testBindAs(dn string, password string) error {
+	conn, err := ldap.DialURL(os.Getenv("LDAP_URL"))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.Bind(dn, password)
+}
+
+func TestLDAPObjectResourceManageDsaIT(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testManageDsaITConfig("ldap://first.example.com/ou=referred,dc=example,dc=com"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.ldap_object.referral", "attributes.ref.0", "ldap://first.example.com/ou=referred,dc=example,dc=com"),
+					testCheckServerValuesWithControls("ou=referral,dc=example,dc=com", "ref", []string{"ldap://first.example.com/ou=referred,dc=example,dc=com"}, ldap.NewControlManageDsaIT(true)),
+				),
+			},
+			{
+				Config: testManageDsaITConfig("ldap://second.example.com/ou=referred,dc=example,dc=com"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.ldap_object.referral", "attributes.ref.0", "ldap://second.example.com/ou=referred,dc=example,dc=com"),
+					testCheckServerValuesWithControls("ou=referral,dc=example,dc=com", "ref", []string{"ldap://second.example.com/ou=referred,dc=example,dc=com"}, ldap.NewControlManageDsaIT(true)),
+				),
+			},
+		},
+	})
+}
+
+func testManageDsaITConfig(ref string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "referral" {
+	dn = "ou=referral,dc=example,dc=com"
+	object_classes = ["referral", "extensibleObject"]
+	attributes = {
+		"ou" = ["referral"]
+		"ref" = [%q]
+	}
+	manage_dsa_it = true
+}
+
+data "ldap_object" "referral" {
+	dn = ldap_object.referral.dn
+	manage_dsa_it = true
+	# read the entry after it was updated
+	depends_on = [ldap_object.referral]
+}
+`, ref)
+}
+
+func TestLDAPObjectResourceRetry(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testRetryConfig("first", "max_attempts = 0"),
+				ExpectError: regexp.MustCompile("max_attempts"),
+			},
+			{
+				Config: testRetryConfig("first", "max_attempts = 5\n\t\tmin_backoff = \"100ms\"\n\t\tretry_on = [51, 52]"),
+				Check:  testCheckServerValues("cn=retry,dc=example,dc=com", "sn", []string{"first"}),
+			},
+			{
+				Config: testRetryConfig("second", ""),
+				Check:  testCheckServerValues("cn=retry,dc=example,dc=com", "sn", []string{"second"}),
+			},
+		},
+	})
+}
+
+func testRetryConfig(sn string, retry string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "retry" {
+	dn = "cn=retry,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["retry"]
+		"sn" = [%q]
+	}
+	retry {
+		%s
+	}
+}
+`, sn, retry)
+}
+
+func TestLDAPObjectResourceConsistencyTimeout(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testConsistencyTimeoutConfig("first", "soon"),
+				ExpectError: regexp.MustCompile("Invalid duration"),
+			},
+			{
+				Config: testConsistencyTimeoutConfig("first", "10s"),
+				Check:  testCheckServerValues("cn=consistency,dc=example,dc=com", "sn", []string{"first"}),
+			},
+			{
+				Config: testConsistencyTimeoutConfig("second", "10s"),
+				Check:  testCheckServerValues("cn=consistency,dc=example,dc=com", "sn", []string{"second"}),
+			},
+		},
+	})
+}
+
+func testConsistencyTimeoutConfig(sn string, timeout string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "consistency" {
+	dn = "cn=consistency,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["consistency"]
+		"sn" = [%q]
+	}
+	consistency_timeout = %q
+}
+`, sn, timeout)
+}
+
+func TestUnobservedAttributes(t *testing.T) {
+	entry := *ldap.NewEntry("cn=test,dc=example,dc=com", map[string][]string{
+		"cn":          {"Test"},
+		"sn":          {"old"},
+		"mail":        {"test@example.com", "other@example.com"},
+		"description": {"stale"},
+	})
+
+	assert.Empty(t, unobservedAttributes(map[string][]string{
+		"cn":   {"test"},
+		"mail": {"other@example.com", "test@example.com"},
+	}, entry, nil, nil))
+	assert.Equal(t, []string{"description", "mail", "sn", "title"}, unobservedAttributes(map[string][]string{
+		"sn":          {"new"},
+		"mail":        {"test@example.com"},
+		"description": {},
+		"title":       {"missing"},
+	}, entry, nil, nil))
+	assert.Empty(t, unobservedAttributes(map[string][]string{"surname": {"old"}}, entry, nil, map[string]string{"surname": "sn"}))
+}
+
+func TestConfiguredKey(t *testing.T) {
+	attributes := map[string][]string{"givenname": {"Alice"}, "sn": {"Doe"}}
+
+	key, ok := configuredKey(attributes, "givenName")
+	assert.True(t, ok)
+	assert.Equal(t, "givenname", key)
+	key, ok = configuredKey(attributes, "sn")
+	assert.True(t, ok)
+	assert.Equal(t, "sn", key)
+	_, ok = configuredKey(attributes, "mail")
+	assert.False(t, ok)
+
+	localized := map[string]map[string][]string{"Description": {"de": {"Beschreibung"}}}
+	assert.Equal(t, "Description", localizedKey(localized, "description"))
+	assert.Equal(t, "", localizedKey(localized, "cn"))
+}
+
+func TestLDAPObjectResourceUniqueFilter(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testUniqueFilterConfig("alice"),
+				ExpectError: regexp.MustCompile("Conflicting entry exists"),
+			},
+			{
+				Config: testUniqueFilterConfig("alice2"),
+				Check:  testCheckServerValues("cn=alice,ou=duplicates,dc=example,dc=com", "uid", []string{"alice2"}),
+			},
+		},
+	})
+}
+
+// testUniqueFilterConfig creates an account with the uid alice and another account with the given uid in another
+// subtree, which has to be unique.
+func testUniqueFilterConfig(uid string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "existing" {
+	dn = "cn=alice,dc=example,dc=com"
+	object_classes = ["inetOrgPerson"]
+	attributes = {
+		"cn" = ["alice"]
+		"sn" = ["alice"]
+		"uid" = ["alice"]
+	}
+}
+
+resource "ldap_object" "duplicates" {
+	dn = "ou=duplicates,dc=example,dc=com"
+	object_classes = ["organizationalUnit"]
+}
+
+resource "ldap_object" "duplicate" {
+	dn = "cn=alice,${ldap_object.duplicates.dn}"
+	object_classes = ["inetOrgPerson"]
+	attributes = {
+		"cn" = ["alice"]
+		"sn" = ["alice"]
+		"uid" = [%[1]q]
+	}
+	unique_filter = "(uid=%[1]s)"
+	unique_base_dn = "dc=example,dc=com"
+	depends_on = [ldap_object.existing]
+}
+`, uid)
+}
+
+func TestLDAPObjectResourceIsOperationalAttribute(t *testing.T) {
+	ctx := context.Background()
+	cache := &subschemaCache{subschema: &Subschema{
+		AttributeTypes: map[string]AttributeTypeDefinition{
+			"contextcsn":        {Names: []string{"contextCSN"}, Operational: true, NoUserModification: true},
+			"pwdpolicysubentry": {Names: []string{"pwdPolicySubentry"}, Operational: true},
+			"entryuuid":         {Names: []string{"entryUUID"}},
+		},
+	}}
+	cache.once.Do(func() {})
+	L := &LDAPObjectResource{subschema: cache}
+
+	// the subschema decides for the attribute types it defines
+	assert.True(t, L.isOperationalAttribute(ctx, "contextCSN"))
+	assert.False(t, L.isOperationalAttribute(ctx, "pwdPolicySubentry"))
+	assert.False(t, L.isOperationalAttribute(ctx, "entryUUID"))
+	assert.True(t, L.isOperationalAttribute(ctx, "modifyTimestamp"))
+	assert.False(t, L.isOperationalAttribute(ctx, "description"))
+
+	// without a subschema, the well-known operational attributes are used
+	L = &LDAPObjectResource{}
+	assert.True(t, L.isOperationalAttribute(ctx, "entryUUID"))
+	assert.False(t, L.isOperationalAttribute(ctx, "contextCSN"))
+}
+
+func TestLDAPObjectResourceSchemaMatchingRules(t *testing.T) {
+	ctx := context.Background()
+	cache := &subschemaCache{subschema: &Subschema{
+		AttributeTypes: map[string]AttributeTypeDefinition{
+			"description": {Names: []string{"description"}, Equality: "caseIgnoreMatch"},
+			"info":        {Names: []string{"info"}, Equality: "caseIgnoreMatch"},
+		},
+	}}
+	cache.once.Do(func() {})
+	L := &LDAPObjectResource{subschema: cache}
+
+	attributes, _ := types.MapValueFrom(ctx, types.ListType{ElemType: types.StringType}, map[string][]string{
+		"cn":          {"test"},
+		"description": {"  hello  "},
+		"info":        {"  exact  "},
+		"photo":       {"..."},
+	})
+	matchingRules, _ := types.MapValueFrom(ctx, types.StringType, map[string]string{"info": matchingRuleOctetString})
+	data := &LDAPObjectResourceModel{
+		Attributes:           attributes,
+		SensitiveAttributes:  types.MapNull(types.ListType{ElemType: types.StringType}),
+		PostCreateAttributes: types.MapNull(types.ListType{ElemType: types.StringType}),
+		LocalizedAttributes:  types.MapNull(types.MapType{ElemType: types.ListType{ElemType: types.StringType}}),
+		AttributeAliases:     types.MapNull(types.StringType),
+		MatchingRules:        matchingRules,
+	}
+
+	var diagnostics diag.Diagnostics
+	rules := L.matchingRules(ctx, data, &diagnostics)
+	assert.False(t, diagnostics.HasError())
+	// values trimmed by the server are equal to the configured ones, unless the attribute is compared exactly
+	assert.Equal(t, []string{"  hello  "}, preferStateValues(lookupMatchingRule("description", rules), []string{"hello"}, []string{"  hello  "}))
+	assert.Equal(t, []string{"Hello World"}, preferStateValues(lookupMatchingRule("description", rules), []string{"hello  world"}, []string{"Hello World"}))
+	assert.Equal(t, []string{"exact"}, preferStateValues(lookupMatchingRule("info", rules), []string{"exact"}, []string{"  exact  "}))
+	assert.Equal(t, matchingRuleOctetString, lookupMatchingRule("info", rules))
+	assert.Equal(t, matchingRuleCaseIgnore, lookupMatchingRule("cn", rules))
+	assert.Equal(t, "", lookupMatchingRule("photo", rules))
+
+	// without a subschema, attribute types without a built-in matching rule are compared exactly, so the spelling of
+	// the server shows up as a change
+	L = &LDAPObjectResource{}
+	rules = L.matchingRules(ctx, data, &diagnostics)
+	assert.Equal(t, "", lookupMatchingRule("description", rules))
+	assert.Equal(t, []string{"hello"}, preferStateValues(lookupMatchingRule("description", rules), []string{"hello"}, []string{"  hello  "}))
+}
+
+func TestLDAPObjectResourceTrimmedValue(t *testing.T) {
+	config := `
+resource "ldap_object" "trimmed" {
+	dn = "cn=trimmed,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["trimmed"]
+		"sn" = ["trimmed"]
+		"description" = ["  padded   value  "]
+	}
+}
+`
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  resource.TestCheckResourceAttr("ldap_object.trimmed", "attributes.description.0", "  padded   value  "),
+			},
+			// the value is normalized by the server, but equal according to the matching rule of the schema
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestLDAPObjectResourceCloneFromDN(t *testing.T) {
+	template := "cn=template,dc=example,dc=com"
+	config := func(attributes string) string {
+		return fmt.Sprintf(`
+resource "ldap_object" "clone" {
+	dn = "cn=clone,dc=example,dc=com"
+	object_classes = ["person"]
+	clone_from_dn = %q
+	clone_exclude = ["seeAlso"]
+	attributes = {
+		"cn" = ["clone"]
+		"sn" = ["Clone"]
+		%s
+	}
+}
+`, template, attributes)
+	}
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			t.Cleanup(testDeleteEntryExternally(t, template))
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: testAddEntryExternally(t, template, map[string][]string{
+					"sn":              {"Template"},
+					"description":     {"from template"},
+					"telephoneNumber": {"123"},
+					"seeAlso":         {"cn=other,dc=example,dc=com"},
+				}),
+				Config: config(""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckServerValues("cn=clone,dc=example,dc=com", "sn", []string{"Clone"}),
+					testCheckServerValues("cn=clone,dc=example,dc=com", "description", []string{"from template"}),
+					testCheckServerValues("cn=clone,dc=example,dc=com", "telephoneNumber", []string{"123"}),
+					testCheckServerValues("cn=clone,dc=example,dc=com", "seeAlso", []string{}),
+					resource.TestCheckResourceAttr("ldap_object.clone", "cloned_attributes.description.0", "from template"),
+					resource.TestCheckNoResourceAttr("ldap_object.clone", "cloned_attributes.cn"),
+					resource.TestCheckNoResourceAttr("ldap_object.clone", "cloned_attributes.sn"),
+				),
+			},
+			// the template isn't read again
+			{
+				PreConfig: testDeleteEntryExternally(t, template),
+				Config:    config(""),
+				PlanOnly:  true,
+			},
+			// a cloned attribute becomes managed once it's configured
+			{
+				Config: config(`"description" = ["managed"]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckServerValues("cn=clone,dc=example,dc=com", "description", []string{"managed"}),
+					resource.TestCheckNoResourceAttr("ldap_object.clone", "cloned_attributes.description"),
+					resource.TestCheckResourceAttr("ldap_object.clone", "cloned_attributes.telephoneNumber.0", "123"),
+				),
+			},
+		},
+	})
+}
+
+func TestLDAPObjectResourceUnconfiguredAttributes(t *testing.T) {
+	ctx := context.Background()
+	data := &LDAPObjectResourceModel{
+		ObjectClasses: types.ListValueMust(types.StringType, []attr.Value{types.StringValue("person")}),
+		Attributes: types.MapValueMust(types.ListType{ElemType: types.StringType}, map[string]attr.Value{
+			"SN": types.ListValueMust(types.StringType, []attr.Value{types.StringValue("configured")}),
+		}),
+		LocalizedAttributes: types.MapValueMust(types.MapType{ElemType: types.ListType{ElemType: types.StringType}}, map[string]attr.Value{
+			"description": types.MapValueMust(types.ListType{ElemType: types.StringType}, map[string]attr.Value{
+				"en": types.ListValueMust(types.StringType, []attr.Value{types.StringValue("configured")}),
+			}),
+		}),
+		BinaryAttributes:     types.MapNull(types.ListType{ElemType: types.StringType}),
+		SensitiveAttributes:  types.MapNull(types.ListType{ElemType: types.StringType}),
+		PostCreateAttributes: types.MapNull(types.ListType{ElemType: types.StringType}),
+		AttributeAliases:     types.MapNull(types.StringType),
+	}
+	var diagnostics diag.Diagnostics
+	assert.Equal(t, map[string][]string{
+		"objectClass":     {"inetOrgPerson"},
+		"telephoneNumber": {"123"},
+	}, (&LDAPObjectResource{}).unconfiguredAttributes(ctx, map[string][]string{
+		"objectClass":         {"Person", "inetOrgPerson"},
+		"sn":                  {"template"},
+		"description;lang-de": {"template"},
+		"telephoneNumber":     {"123"},
+	}, data, &diagnostics))
+	assert.False(t, diagnostics.HasError())
+}
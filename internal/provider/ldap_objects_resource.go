@@ -0,0 +1,646 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/thoas/go-funk"
+	"sort"
+	"strings"
+	"time"
+)
+
+var _ resource.Resource = &LDAPObjectsResource{}
+var _ resource.ResourceWithConfigure = &LDAPObjectsResource{}
+
+func NewLDAPObjectsResource() resource.Resource {
+	return &LDAPObjectsResource{}
+}
+
+type LDAPObjectsResource struct {
+	conn           *ldap.Conn
+	locks          *dnLocks
+	controlSupport *controlSupport
+}
+
+// Strategies of import_conflict_strategy
+const (
+	importConflictError    = "error"
+	importConflictSkip     = "skip"
+	importConflictTakeOver = "take_over"
+)
+
+type LDAPObjectsResourceModel struct {
+	ID                     types.String `tfsdk:"id"`
+	BaseDN                 types.String `tfsdk:"base_dn"`
+	Objects                types.Map    `tfsdk:"objects"`
+	Controls               types.List   `tfsdk:"controls"`
+	PermissiveModify       types.Bool   `tfsdk:"permissive_modify"`
+	ImportConflictStrategy types.String `tfsdk:"import_conflict_strategy"`
+}
+
+// LDAPObjectsEntryModel describes a single entry of the objects attribute.
+type LDAPObjectsEntryModel struct {
+	ObjectClasses types.List `tfsdk:"object_classes"`
+	Attributes    types.Map  `tfsdk:"attributes"`
+}
+
+// ldapObjectsEntryType is the type of the entries of the objects attribute.
+var ldapObjectsEntryType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"object_classes": types.ListType{ElemType: types.StringType},
+		"attributes":     types.MapType{ElemType: types.ListType{ElemType: types.StringType}},
+	},
+}
+
+func (L *LDAPObjectsResource) Metadata(_ context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_objects"
+}
+
+func (L *LDAPObjectsResource) Schema(_ context.Context, _ resource.SchemaRequest, response *resource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		MarkdownDescription: "Manages many LDAP objects with a single resource. The objects are written one after another, " +
+			"parents before their children, and deleted in reverse order. Objects which can't be written are reported as " +
+			"errors without aborting the changes to the other objects, the objects written successfully are listed in a warning. " +
+			"Objects below a parent which couldn't be created are skipped. If some objects were created when the resource " +
+			"is created, the failed objects are reported as warnings instead, so the resource isn't replaced and the failed " +
+			"objects are created on the next apply",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Resource identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"base_dn": schema.StringAttribute{
+				MarkdownDescription: "If set, the keys of `objects` are RDNs relative to this DN, otherwise they are DNs",
+				Optional:            true,
+				Validators: []validator.String{
+					IsValidDN(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"objects": schema.MapNestedAttribute{
+				MarkdownDescription: "The objects to manage, keyed by their DN or RDN",
+				Required:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"object_classes": schema.ListAttribute{
+							MarkdownDescription: "A list of classes this object implements",
+							Required:            true,
+							ElementType:         types.StringType,
+						},
+						"attributes": schema.MapAttribute{
+							MarkdownDescription: "The definition of an attribute, the name defines the type of the attribute",
+							Optional:            true,
+							ElementType:         types.ListType{ElemType: types.StringType},
+						},
+					},
+				},
+			},
+			"permissive_modify": schema.BoolAttribute{
+				MarkdownDescription: "Whether adding existing values and deleting missing values shouldn't fail modifications. The permissive modify control is sent if the server supports it, e.g. Active Directory and OpenLDAP, otherwise these values are removed from the modification after reading the entry",
+				Optional:            true,
+			},
+			"import_conflict_strategy": schema.StringAttribute{
+				MarkdownDescription: "What to do if an object already exists on the server when it is created: `error` (default) reports it, `skip` leaves the entry untouched and outside of the state, so it is tried again on the next apply, and `take_over` updates the entry to match the configuration and manages it from then on. Applying a large import again with `skip` or `take_over` resumes it after a failure",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(importConflictError, importConflictSkip, importConflictTakeOver),
+				},
+			},
+			"controls": controlsResourceAttribute(),
+		},
+	}
+}
+
+func (L *LDAPObjectsResource) Configure(_ context.Context, request resource.ConfigureRequest, response *resource.ConfigureResponse) {
+	if request.ProviderData == nil {
+		return
+	}
+
+	if client, ok := request.ProviderData.(*ldapClient); !ok {
+		response.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ldapClient, got: %T. Please report this issue to the provider developers.", request.ProviderData),
+		)
+
+		return
+	} else {
+		L.conn = client.conn
+		L.locks = client.locks
+		L.controlSupport = client.controlSupport
+	}
+}
+
+func (L *LDAPObjectsResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	var data *LDAPObjectsResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &data)...)
+	var planObjects map[string]LDAPObjectsEntryModel
+	response.Diagnostics.Append(data.Objects.ElementsAs(ctx, &planObjects, false)...)
+	controls := BuildControls(ctx, data.Controls, &response.Diagnostics)
+	if err := L.controlSupport.check(L.conn, controls); err != nil {
+		addControlSupportError(&response.Diagnostics, err)
+	}
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	created := map[string]LDAPObjectsEntryModel{}
+	var dns []string
+	var written []string
+	var failed []string
+	// failures of single entries are collected separately, see addPartialCreateDiagnostics
+	var entryDiagnostics diag.Diagnostics
+	for _, key := range L.sortedByDepth(data, planObjects, false) {
+		dn := L.dn(data, key)
+		dns = append(dns, dn)
+		if parent, ok := failedRelative(dn, failed, false); ok {
+			addMissingParentError(&entryDiagnostics, dn, parent)
+			failed = append(failed, dn)
+			continue
+		}
+		if added, err := L.createEntry(ctx, data, dn, planObjects[key], controls, &entryDiagnostics); err != nil {
+			addOperationError(&entryDiagnostics, err, "create", dn,
+				"Can not create entry",
+				fmt.Sprintf("Trying to add entry %s returned: %s", dn, err),
+			)
+			failed = append(failed, dn)
+			continue
+		} else if !added {
+			continue
+		}
+		created[key] = planObjects[key]
+		written = append(written, dn)
+	}
+	addPartialCreateDiagnostics(&response.Diagnostics, entryDiagnostics, written)
+
+	if data.BaseDN.IsNull() {
+		data.ID = types.StringValue(strings.Join(dns, ";"))
+	} else {
+		data.ID = data.BaseDN
+	}
+	L.setObjects(ctx, data, created, &response.Diagnostics)
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPObjectsResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	var data *LDAPObjectsResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	var stateObjects map[string]LDAPObjectsEntryModel
+	response.Diagnostics.Append(data.Objects.ElementsAs(ctx, &stateObjects, false)...)
+	controls := BuildControls(ctx, data.Controls, &response.Diagnostics)
+	if err := L.controlSupport.check(L.conn, controls); err != nil {
+		addControlSupportError(&response.Diagnostics, err)
+	}
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	objects := map[string]LDAPObjectsEntryModel{}
+	for _, key := range sortedKeys(stateObjects) {
+		dn := L.dn(data, key)
+		entry, err := L.readEntry(ctx, dn, stateObjects[key], controls, &response.Diagnostics)
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) || errors.Is(err, ErrEntryNotFound) {
+			tflog.Warn(ctx, "Entry was deleted outside of Terraform, removing it from the state", map[string]interface{}{"dn": dn})
+		} else if err != nil {
+			addOperationError(&response.Diagnostics, err, "read", dn,
+				"Can not read entry",
+				err.Error(),
+			)
+			objects[key] = stateObjects[key]
+		} else {
+			objects[key] = entry
+		}
+	}
+
+	L.setObjects(ctx, data, objects, &response.Diagnostics)
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPObjectsResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
+	var stateData *LDAPObjectsResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &stateData)...)
+	var planData *LDAPObjectsResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &planData)...)
+	var stateObjects map[string]LDAPObjectsEntryModel
+	response.Diagnostics.Append(stateData.Objects.ElementsAs(ctx, &stateObjects, false)...)
+	var planObjects map[string]LDAPObjectsEntryModel
+	response.Diagnostics.Append(planData.Objects.ElementsAs(ctx, &planObjects, false)...)
+	controls := BuildControls(ctx, planData.Controls, &response.Diagnostics)
+	if err := L.controlSupport.check(L.conn, controls); err != nil {
+		addControlSupportError(&response.Diagnostics, err)
+	}
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// objects keeps the state of every entry, which is only updated after it was written successfully
+	objects := map[string]LDAPObjectsEntryModel{}
+	for key, entry := range stateObjects {
+		objects[key] = entry
+	}
+
+	var written []string
+	var failed []string
+	for _, key := range L.sortedByDepth(stateData, stateObjects, true) {
+		if _, exists := planObjects[key]; exists {
+			continue
+		}
+		dn := L.dn(stateData, key)
+		if child, ok := failedRelative(dn, failed, true); ok {
+			addRemainingChildError(&response.Diagnostics, dn, child)
+			failed = append(failed, dn)
+			continue
+		}
+		if err := L.deleteEntry(ctx, dn, controls); err != nil {
+			addOperationError(&response.Diagnostics, err, "update", dn,
+				"Can not delete entry",
+				fmt.Sprintf("Trying to delete entry %s returned: %s", dn, err),
+			)
+			failed = append(failed, dn)
+			continue
+		}
+		delete(objects, key)
+		written = append(written, dn)
+	}
+
+	// only entries which couldn't be created are missing for their children, entries which couldn't be modified exist
+	var missing []string
+	for _, key := range L.sortedByDepth(planData, planObjects, false) {
+		dn := L.dn(planData, key)
+		var err error
+		var summary string
+		added, changed := true, true
+		if stateEntry, exists := stateObjects[key]; exists {
+			err = L.modifyEntry(ctx, dn, stateEntry, planObjects[key], controls, planData.PermissiveModify.ValueBool(), &response.Diagnostics)
+			summary = "Can not modify entry"
+			changed = !stateEntry.ObjectClasses.Equal(planObjects[key].ObjectClasses) || !stateEntry.Attributes.Equal(planObjects[key].Attributes)
+		} else if parent, ok := failedRelative(dn, missing, false); ok {
+			addMissingParentError(&response.Diagnostics, dn, parent)
+			missing = append(missing, dn)
+			continue
+		} else {
+			added, err = L.createEntry(ctx, planData, dn, planObjects[key], controls, &response.Diagnostics)
+			summary = "Can not create entry"
+			if err != nil {
+				missing = append(missing, dn)
+			}
+		}
+		if err != nil {
+			addOperationError(&response.Diagnostics, err, "update", dn,
+				summary,
+				fmt.Sprintf("Trying to write entry %s returned: %s", dn, err),
+			)
+			continue
+		} else if !added {
+			continue
+		}
+		objects[key] = planObjects[key]
+		if changed {
+			written = append(written, dn)
+		}
+	}
+	addWrittenEntriesWarning(&response.Diagnostics, "changed", written)
+
+	planData.ID = stateData.ID
+	L.setObjects(ctx, planData, objects, &response.Diagnostics)
+	response.Diagnostics.Append(response.State.Set(ctx, &planData)...)
+}
+
+func (L *LDAPObjectsResource) Delete(ctx context.Context, request resource.DeleteRequest, response *resource.DeleteResponse) {
+	var data *LDAPObjectsResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	var stateObjects map[string]LDAPObjectsEntryModel
+	response.Diagnostics.Append(data.Objects.ElementsAs(ctx, &stateObjects, false)...)
+	controls := BuildControls(ctx, data.Controls, &response.Diagnostics)
+	if err := L.controlSupport.check(L.conn, controls); err != nil {
+		addControlSupportError(&response.Diagnostics, err)
+	}
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	remaining := map[string]LDAPObjectsEntryModel{}
+	var deleted []string
+	var failed []string
+	for _, key := range L.sortedByDepth(data, stateObjects, true) {
+		dn := L.dn(data, key)
+		if child, ok := failedRelative(dn, failed, true); ok {
+			addRemainingChildError(&response.Diagnostics, dn, child)
+			remaining[key] = stateObjects[key]
+			failed = append(failed, dn)
+		} else if err := L.deleteEntry(ctx, dn, controls); err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			addOperationError(&response.Diagnostics, err, "delete", dn,
+				"Can not delete entry",
+				fmt.Sprintf("Trying to delete entry %s returned: %s", dn, err),
+			)
+			remaining[key] = stateObjects[key]
+			failed = append(failed, dn)
+		} else {
+			deleted = append(deleted, dn)
+		}
+	}
+
+	if response.Diagnostics.HasError() {
+		addWrittenEntriesWarning(&response.Diagnostics, "deleted", deleted)
+		// keep the entries which couldn't be deleted
+		L.setObjects(ctx, data, remaining, &response.Diagnostics)
+		response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+	}
+}
+
+// dn returns the DN of the entry with the given key of the objects attribute.
+func (L *LDAPObjectsResource) dn(data *LDAPObjectsResourceModel, key string) string {
+	if data.BaseDN.IsNull() || data.BaseDN.ValueString() == "" {
+		return key
+	}
+	return fmt.Sprintf("%s,%s", key, data.BaseDN.ValueString())
+}
+
+// sortedByDepth returns the keys of the objects ordered by the depth of their DNs, so parents are written before their
+// children. If reverse is set, children come first, like they have to be deleted. Objects of the same depth are
+// ordered by their keys.
+func (L *LDAPObjectsResource) sortedByDepth(data *LDAPObjectsResourceModel, objects map[string]LDAPObjectsEntryModel, reverse bool) []string {
+	keys := sortedKeys(objects)
+	depths := map[string]int{}
+	for _, key := range keys {
+		depths[key] = dnDepth(L.dn(data, key))
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		if reverse {
+			return depths[keys[i]] > depths[keys[j]]
+		}
+		return depths[keys[i]] < depths[keys[j]]
+	})
+	return keys
+}
+
+// dnDepth returns the number of RDNs of a DN. Invalid DNs are counted by their separators, the server rejects them
+// anyway.
+func dnDepth(dn string) int {
+	if parsed, err := ldap.ParseDN(dn); err == nil {
+		return len(parsed.RDNs)
+	}
+	return strings.Count(dn, ",") + 1
+}
+
+// failedRelative returns the first of the failed DNs, which is an ancestor of the DN, or a descendant if descendants is
+// set. Entries below a parent which couldn't be created can't be created either, and entries above a child which
+// couldn't be deleted can't be deleted either.
+func failedRelative(dn string, failed []string, descendants bool) (string, bool) {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return "", false
+	}
+	for _, f := range failed {
+		if parsedFailed, err := ldap.ParseDN(f); err != nil {
+			continue
+		} else if (!descendants && parsedFailed.AncestorOfFold(parsed)) || (descendants && parsed.AncestorOfFold(parsedFailed)) {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+// addMissingParentError reports an entry which wasn't created, because its parent couldn't be created.
+func addMissingParentError(diagnostics *diag.Diagnostics, dn string, parent string) {
+	diagnostics.AddError(
+		"Can not create entry",
+		fmt.Sprintf("The entry %s wasn't created, since its parent %s couldn't be created", dn, parent),
+	)
+}
+
+// addRemainingChildError reports an entry which wasn't deleted, because an entry below it couldn't be deleted.
+func addRemainingChildError(diagnostics *diag.Diagnostics, dn string, child string) {
+	diagnostics.AddError(
+		"Can not delete entry",
+		fmt.Sprintf("The entry %s wasn't deleted, since %s below it couldn't be deleted", dn, child),
+	)
+}
+
+// addWrittenEntriesWarning lists the entries which were written successfully if writing other entries failed, as
+// only these changes are kept in the state.
+func addWrittenEntriesWarning(diagnostics *diag.Diagnostics, action string, dns []string) {
+	if !diagnostics.HasError() || len(dns) == 0 {
+		return
+	}
+	diagnostics.AddWarning(
+		"Objects partially applied",
+		fmt.Sprintf("Although some objects failed, these objects were %s successfully and are kept in the state:\n\n  %s", action, strings.Join(dns, "\n  ")),
+	)
+}
+
+// addPartialCreateDiagnostics adds the diagnostics of the single entries of a create. If some entries were created,
+// their errors are reported as warnings instead: an error would taint the resource and its replacement would delete the
+// created entries again. The failed entries are missing in the state, so they are planned to be created on the next
+// apply.
+func addPartialCreateDiagnostics(diagnostics *diag.Diagnostics, entryDiagnostics diag.Diagnostics, written []string) {
+	addWrittenEntriesWarning(&entryDiagnostics, "created", written)
+	if len(written) == 0 {
+		diagnostics.Append(entryDiagnostics...)
+		return
+	}
+	for _, d := range entryDiagnostics {
+		diagnostics.AddWarning(d.Summary(), d.Detail())
+	}
+}
+
+// setObjects replaces the objects attribute of the model.
+func (L *LDAPObjectsResource) setObjects(ctx context.Context, data *LDAPObjectsResourceModel, objects map[string]LDAPObjectsEntryModel, diagnostics *diag.Diagnostics) {
+	value, d := types.MapValueFrom(ctx, ldapObjectsEntryType, objects)
+	diagnostics.Append(d...)
+	data.Objects = value
+}
+
+// createEntry adds a single entry and resolves a conflict with an existing entry according to
+// import_conflict_strategy. It returns false if the entry was skipped and must not be stored in the state.
+func (L *LDAPObjectsResource) createEntry(ctx context.Context, data *LDAPObjectsResourceModel, dn string, entry LDAPObjectsEntryModel, controls []ldap.Control, diagnostics *diag.Diagnostics) (bool, error) {
+	err := L.addEntry(ctx, dn, entry, controls, diagnostics)
+	if !ldap.IsErrorWithCode(err, ldap.LDAPResultEntryAlreadyExists) {
+		return err == nil, err
+	}
+
+	switch data.ImportConflictStrategy.ValueString() {
+	case importConflictSkip:
+		tflog.Info(ctx, "Entry already exists, skipping it", map[string]interface{}{"dn": dn})
+		diagnostics.AddWarning(
+			"Entry skipped",
+			fmt.Sprintf("The entry %s already exists and was left untouched. It isn't managed by this resource and is tried again on the next apply", dn),
+		)
+		return false, nil
+	case importConflictTakeOver:
+		tflog.Info(ctx, "Entry already exists, taking it over", map[string]interface{}{"dn": dn})
+		existing, err := L.readEntry(ctx, dn, entry, controls, diagnostics)
+		if err != nil {
+			return false, err
+		}
+		if err := L.modifyEntry(ctx, dn, existing, entry, controls, data.PermissiveModify.ValueBool(), diagnostics); err != nil {
+			return false, err
+		}
+		return true, nil
+	default:
+		diagnostics.AddError(
+			"Entry already exists",
+			fmt.Sprintf("The entry %s already exists. Set import_conflict_strategy to skip or take_over to continue with existing entries", dn),
+		)
+		return false, nil
+	}
+}
+
+// addEntry adds a single entry.
+func (L *LDAPObjectsResource) addEntry(ctx context.Context, dn string, entry LDAPObjectsEntryModel, controls []ldap.Control, diagnostics *diag.Diagnostics) error {
+	var objectClasses []string
+	diagnostics.Append(entry.ObjectClasses.ElementsAs(ctx, &objectClasses, false)...)
+	var attributes map[string][]string
+	diagnostics.Append(entry.Attributes.ElementsAs(ctx, &attributes, false)...)
+	if diagnostics.HasError() {
+		return errors.New("error converting data")
+	}
+
+	a := ldap.NewAddRequest(dn, controls)
+	a.Attribute("objectClass", objectClasses)
+	for _, attributeType := range sortedKeys(attributes) {
+		if len(attributes[attributeType]) > 0 {
+			a.Attribute(attributeType, attributes[attributeType])
+		}
+	}
+
+	start := time.Now()
+	defer LogOperation(ctx, "add", dn, start)
+	return WithContext(ctx, func() error {
+		return L.locks.write(ctx, dn, func() error {
+			return L.conn.Add(a)
+		})
+	})
+}
+
+// modifyEntry modifies a single entry to get from the state to the plan. Nothing is sent if the entry didn't change.
+func (L *LDAPObjectsResource) modifyEntry(ctx context.Context, dn string, stateEntry LDAPObjectsEntryModel, planEntry LDAPObjectsEntryModel, controls []ldap.Control, permissive bool, diagnostics *diag.Diagnostics) error {
+	var stateObjectClasses []string
+	diagnostics.Append(stateEntry.ObjectClasses.ElementsAs(ctx, &stateObjectClasses, false)...)
+	var planObjectClasses []string
+	diagnostics.Append(planEntry.ObjectClasses.ElementsAs(ctx, &planObjectClasses, false)...)
+	var stateAttributes map[string][]string
+	diagnostics.Append(stateEntry.Attributes.ElementsAs(ctx, &stateAttributes, false)...)
+	var planAttributes map[string][]string
+	diagnostics.Append(planEntry.Attributes.ElementsAs(ctx, &planAttributes, false)...)
+	if diagnostics.HasError() {
+		return errors.New("error converting data")
+	}
+
+	r := ldap.NewModifyRequest(dn, controls)
+	for _, objectClass := range planObjectClasses {
+		if !funk.ContainsString(stateObjectClasses, objectClass) {
+			r.Add("objectClass", []string{objectClass})
+		}
+	}
+	for _, objectClass := range stateObjectClasses {
+		if !funk.ContainsString(planObjectClasses, objectClass) {
+			r.Delete("objectClass", []string{objectClass})
+		}
+	}
+	for _, attributeType := range sortedKeys(stateAttributes) {
+		if planValues, exists := planAttributes[attributeType]; exists {
+			r.Changes = append(r.Changes, diffValues(attributeType, lookupMatchingRule(attributeType, nil), stateAttributes[attributeType], planValues, false, modifyStrategyIncremental)...)
+		} else {
+			r.Delete(attributeType, []string{})
+		}
+	}
+	for _, attributeType := range sortedKeys(planAttributes) {
+		if _, exists := stateAttributes[attributeType]; !exists && len(planAttributes[attributeType]) > 0 {
+			r.Add(attributeType, planAttributes[attributeType])
+		}
+	}
+
+	if permissive && len(r.Changes) > 0 {
+		if err := PermissiveModify(ctx, L.conn, r, nil); err != nil {
+			return err
+		}
+	}
+
+	if len(r.Changes) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	defer LogOperation(ctx, "modify", dn, start)
+	return WithContext(ctx, func() error {
+		return L.locks.write(ctx, dn, func() error {
+			return L.conn.Modify(r)
+		})
+	})
+}
+
+// deleteEntry deletes a single entry.
+func (L *LDAPObjectsResource) deleteEntry(ctx context.Context, dn string, controls []ldap.Control) error {
+	start := time.Now()
+	defer LogOperation(ctx, "delete", dn, start)
+	return WithContext(ctx, func() error {
+		return L.locks.write(ctx, dn, func() error {
+			return L.conn.Del(ldap.NewDelRequest(dn, controls))
+		})
+	})
+}
+
+// readEntry reads a single entry. Only the attributes managed in the state entry are refreshed, attributes missing on
+// the server are read as empty.
+func (L *LDAPObjectsResource) readEntry(ctx context.Context, dn string, stateEntry LDAPObjectsEntryModel, controls []ldap.Control, diagnostics *diag.Diagnostics) (LDAPObjectsEntryModel, error) {
+	var stateAttributes map[string][]string
+	diagnostics.Append(stateEntry.Attributes.ElementsAs(ctx, &stateAttributes, false)...)
+	if diagnostics.HasError() {
+		return stateEntry, errors.New("error converting data")
+	}
+
+	var entry ldap.Entry
+	start := time.Now()
+	err := WithContext(ctx, func() (err error) {
+		entry, err = GetEntryWithControls(L.conn, dn, controls, append(sortedKeys(stateAttributes), "objectClass")...)
+		return
+	})
+	LogOperation(ctx, "search", dn, start)
+	if err != nil {
+		return stateEntry, err
+	}
+
+	objectClasses, d := types.ListValueFrom(ctx, types.StringType, entry.GetAttributeValues("objectClass"))
+	diagnostics.Append(d...)
+
+	refreshed := LDAPObjectsEntryModel{
+		ObjectClasses: objectClasses,
+		Attributes:    stateEntry.Attributes,
+	}
+	if !stateEntry.Attributes.IsNull() {
+		attributes := map[string][]string{}
+		for attributeType, stateValues := range stateAttributes {
+			attributes[attributeType] = preferStateValues(lookupMatchingRule(attributeType, nil), entry.GetEqualFoldAttributeValues(attributeType), stateValues)
+		}
+		refreshed.Attributes, d = types.MapValueFrom(ctx, types.ListType{ElemType: types.StringType}, attributes)
+		diagnostics.Append(d...)
+	}
+	return refreshed, nil
+}
+
+// sortedKeys returns the keys of a map in ascending order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
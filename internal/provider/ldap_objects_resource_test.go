@@ -0,0 +1,422 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestLDAPObjectsResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create three entries
+			{
+				Config: testObjectsConfig(map[string]string{"one": "first", "two": "second", "three": "third"}),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_objects.test", "objects.%", "3"),
+					testCheckServerValues("cn=one,ou=bulk,dc=example,dc=com", "sn", []string{"first"}),
+					testCheckServerValues("cn=two,ou=bulk,dc=example,dc=com", "sn", []string{"second"}),
+					testCheckServerValues("cn=three,ou=bulk,dc=example,dc=com", "sn", []string{"third"}),
+				),
+			},
+			// Modify one entry
+			{
+				Config: testObjectsConfig(map[string]string{"one": "first", "two": "changed", "three": "third"}),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_objects.test", "objects.cn=two.attributes.sn.0", "changed"),
+					testCheckServerValues("cn=two,ou=bulk,dc=example,dc=com", "sn", []string{"changed"}),
+				),
+			},
+			// Remove one entry from the map
+			{
+				Config: testObjectsConfig(map[string]string{"one": "first", "two": "changed"}),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_objects.test", "objects.%", "2"),
+					testCheckEntryMissing("cn=three,ou=bulk,dc=example,dc=com"),
+					testCheckServerValues("cn=one,ou=bulk,dc=example,dc=com", "sn", []string{"first"}),
+				),
+			},
+		},
+	})
+}
+
+func TestLDAPObjectsResourcePartialApply(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testObjectsConfig(map[string]string{"one": "first", "two": "second"}),
+			},
+			// The invalid entry fails, the other change is still applied
+			{
+				Config:      testObjectsConfig(map[string]string{"one": "changed", "two": "second", "invalid": ""}),
+				ExpectError: regexp.MustCompile("Can not create entry"),
+			},
+			{
+				PreConfig: testPreCheckServer(t, testCheckServerValues("cn=one,ou=bulk,dc=example,dc=com", "sn", []string{"changed"})),
+				Config:    testObjectsConfig(map[string]string{"one": "changed", "two": "second"}),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_objects.test", "objects.%", "2"),
+					testCheckServerValues("cn=one,ou=bulk,dc=example,dc=com", "sn", []string{"changed"}),
+				),
+			},
+		},
+	})
+}
+
+func TestLDAPObjectsResourcePartialCreate(t *testing.T) {
+	var entryUUID string
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The invalid entry is only reported as a warning, so the resource isn't tainted
+			{
+				Config: testObjectsConfig(map[string]string{"one": "first", "invalid": ""}),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_objects.test", "objects.%", "1"),
+					testCheckServerValues("cn=one,ou=bulk,dc=example,dc=com", "sn", []string{"first"}),
+					testCaptureEntryUUID("cn=one,ou=bulk,dc=example,dc=com", &entryUUID),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+			// The created entry isn't replaced
+			{
+				Config: testObjectsConfig(map[string]string{"one": "first"}),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_objects.test", "objects.%", "1"),
+					testCheckEntryUUID("cn=one,ou=bulk,dc=example,dc=com", &entryUUID, true),
+				),
+			},
+		},
+	})
+}
+
+// testObjectsConfig returns a configuration with a person below ou=bulk for every name, using the value as sn. Persons
+// without sn violate the schema.
+func testObjectsConfig(persons map[string]string) string {
+	var objects []string
+	for name, sn := range persons {
+		attributes := fmt.Sprintf(`"cn" = ["%s"]`, name)
+		if sn != "" {
+			attributes += fmt.Sprintf(`, "sn" = ["%s"]`, sn)
+		}
+		objects = append(objects, fmt.Sprintf(`
+		"cn=%s" = {
+			object_classes = ["person"]
+			attributes = { %s }
+		}`, name, attributes))
+	}
+
+	return fmt.Sprintf(`
+resource "ldap_object" "bulk" {
+	dn = "ou=bulk,dc=example,dc=com"
+	object_classes = ["organizationalUnit"]
+	attributes = {
+		"ou" = ["bulk"]
+	}
+}
+
+resource "ldap_objects" "test" {
+	base_dn = ldap_object.bulk.dn
+	objects = {%s
+	}
+}
+`, strings.Join(objects, ""))
+}
+
+func TestLDAPObjectsResourceNested(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The children sort before their parent, but are created after it
+			{
+				Config: testObjectsNestedConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckServerValues("ou=nested,dc=example,dc=com", "ou", []string{"nested"}),
+					testCheckServerValues("ou=inner,ou=nested,dc=example,dc=com", "ou", []string{"inner"}),
+					testCheckServerValues("cn=child,ou=inner,ou=nested,dc=example,dc=com", "sn", []string{"child"}),
+				),
+			},
+		},
+		// Destroying the resource deletes the children before their parent
+		CheckDestroy: testCheckEntryMissing("ou=nested,dc=example,dc=com"),
+	})
+}
+
+const testObjectsNestedConfig = `
+resource "ldap_objects" "test" {
+	objects = {
+		"cn=child,ou=inner,ou=nested,dc=example,dc=com" = {
+			object_classes = ["person"]
+			attributes = { "cn" = ["child"], "sn" = ["child"] }
+		}
+		"ou=inner,ou=nested,dc=example,dc=com" = {
+			object_classes = ["organizationalUnit"]
+			attributes = { "ou" = ["inner"] }
+		}
+		"ou=nested,dc=example,dc=com" = {
+			object_classes = ["organizationalUnit"]
+			attributes = { "ou" = ["nested"] }
+		}
+	}
+}
+`
+
+func TestLDAPObjectsResourceParentFirst(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The child of a parent which can't be created is skipped instead of failing with noSuchObject
+			{
+				Config:      testObjectsParentConfig("doesNotExist"),
+				ExpectError: regexp.MustCompile(`wasn't created, since its(.|\n)*parent`),
+			},
+			// The OU and its child are created in one apply
+			{
+				Config: testObjectsParentConfig("organizationalUnit"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_objects.test", "objects.%", "2"),
+					testCheckServerValues("ou=team,ou=bulk,dc=example,dc=com", "ou", []string{"team"}),
+					testCheckServerValues("cn=child,ou=team,ou=bulk,dc=example,dc=com", "sn", []string{"child"}),
+				),
+			},
+		},
+	})
+}
+
+func testObjectsParentConfig(objectClass string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "bulk" {
+	dn = "ou=bulk,dc=example,dc=com"
+	object_classes = ["organizationalUnit"]
+	attributes = {
+		"ou" = ["bulk"]
+	}
+}
+
+resource "ldap_objects" "test" {
+	base_dn = ldap_object.bulk.dn
+	objects = {
+		"cn=child,ou=team" = {
+			object_classes = ["person"]
+			attributes = { "cn" = ["child"], "sn" = ["child"] }
+		}
+		"ou=team" = {
+			object_classes = [%q]
+			attributes = { "ou" = ["team"] }
+		}
+	}
+}
+`, objectClass)
+}
+
+func TestFailedRelative(t *testing.T) {
+	failed := []string{"ou=team,dc=example,dc=com", "cn=other,dc=example,dc=com"}
+
+	parent, ok := failedRelative("cn=child,OU=Team,dc=example,dc=com", failed, false)
+	assert.True(t, ok)
+	assert.Equal(t, "ou=team,dc=example,dc=com", parent)
+	parent, ok = failedRelative("cn=grandchild,cn=child,ou=team,dc=example,dc=com", failed, false)
+	assert.True(t, ok)
+	assert.Equal(t, "ou=team,dc=example,dc=com", parent)
+	_, ok = failedRelative("ou=team,dc=example,dc=com", failed, false)
+	assert.False(t, ok)
+	_, ok = failedRelative("cn=child,ou=other,dc=example,dc=com", failed, false)
+	assert.False(t, ok)
+
+	child, ok := failedRelative("dc=example,dc=com", failed, true)
+	assert.True(t, ok)
+	assert.Equal(t, "ou=team,dc=example,dc=com", child)
+	_, ok = failedRelative("cn=child,ou=team,dc=example,dc=com", failed, true)
+	assert.False(t, ok)
+	_, ok = failedRelative("invalid", failed, true)
+	assert.False(t, ok)
+}
+
+func TestLDAPObjectsSortedByDepth(t *testing.T) {
+	objects := map[string]LDAPObjectsEntryModel{
+		"cn=b,ou=x":      {},
+		"ou=x":           {},
+		"cn=a,ou=x":      {},
+		"cn=c,ou=y,ou=x": {},
+		"ou=y,ou=x":      {},
+	}
+	L := &LDAPObjectsResource{}
+	data := &LDAPObjectsResourceModel{BaseDN: types.StringValue("dc=example,dc=com")}
+	assert.Equal(t, []string{"ou=x", "cn=a,ou=x", "cn=b,ou=x", "ou=y,ou=x", "cn=c,ou=y,ou=x"}, L.sortedByDepth(data, objects, false))
+	assert.Equal(t, []string{"cn=c,ou=y,ou=x", "cn=a,ou=x", "cn=b,ou=x", "ou=y,ou=x", "ou=x"}, L.sortedByDepth(data, objects, true))
+}
+
+func TestAddWrittenEntriesWarning(t *testing.T) {
+	var diagnostics diag.Diagnostics
+	addWrittenEntriesWarning(&diagnostics, "created", []string{"cn=one,dc=example,dc=com"})
+	assert.Empty(t, diagnostics)
+
+	diagnostics.AddError("Can not create entry", "failed")
+	addWrittenEntriesWarning(&diagnostics, "created", nil)
+	assert.Len(t, diagnostics, 1)
+
+	addWrittenEntriesWarning(&diagnostics, "created", []string{"cn=one,dc=example,dc=com", "cn=two,dc=example,dc=com"})
+	if assert.Len(t, diagnostics.Warnings(), 1) {
+		assert.Contains(t, diagnostics.Warnings()[0].Detail(), "created successfully")
+		assert.Contains(t, diagnostics.Warnings()[0].Detail(), "\n  cn=one,dc=example,dc=com\n  cn=two,dc=example,dc=com")
+	}
+}
+
+func TestAddPartialCreateDiagnostics(t *testing.T) {
+	var failed diag.Diagnostics
+	failed.AddError("Can not create entry", "failed")
+
+	// without created entries the resource fails
+	var diagnostics diag.Diagnostics
+	addPartialCreateDiagnostics(&diagnostics, failed, nil)
+	assert.True(t, diagnostics.HasError())
+
+	diagnostics = nil
+	addPartialCreateDiagnostics(&diagnostics, failed, []string{"cn=one,dc=example,dc=com"})
+	assert.False(t, diagnostics.HasError())
+	if assert.Len(t, diagnostics.Warnings(), 2) {
+		assert.Equal(t, "Can not create entry", diagnostics.Warnings()[0].Summary())
+		assert.Contains(t, diagnostics.Warnings()[1].Detail(), "cn=one,dc=example,dc=com")
+	}
+}
+
+func TestLDAPObjectsResourceControls(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Unknown critical controls are rejected, which shows that the control was sent
+			{
+				Config:      testObjectsControlsConfig(true),
+				ExpectError: regexp.MustCompile("Unavailable Critical Extension"),
+			},
+			// Unknown non-critical controls are ignored by the server
+			{
+				Config: testObjectsControlsConfig(false),
+				Check:  testCheckServerValues("cn=controlled,dc=example,dc=com", "sn", []string{"controlled"}),
+			},
+		},
+	})
+}
+
+func testObjectsControlsConfig(criticality bool) string {
+	return fmt.Sprintf(`
+resource "ldap_objects" "controls" {
+	objects = {
+		"cn=controlled,dc=example,dc=com" = {
+			object_classes = ["person"]
+			attributes = { "cn" = ["controlled"], "sn" = ["controlled"] }
+		}
+	}
+	controls = [
+		{
+			oid = "1.3.6.1.4.1.99999.1"
+			criticality = %t
+		}
+	]
+}
+`, criticality)
+}
+
+func TestLDAPObjectsResourceImportConflictError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The existing entry fails, the other entry is still created and kept in the state
+			{
+				Config: testObjectsConflictConfig("error"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_objects.test", "objects.%", "1"),
+					testCheckServerValues("cn=existing,ou=bulk,dc=example,dc=com", "sn", []string{"existing"}),
+					testCheckServerValues("cn=new,ou=bulk,dc=example,dc=com", "sn", []string{"new"}),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+			// Applying again fails for the existing entry only, the created entry stays untouched
+			{
+				Config:      testObjectsConflictConfig("error"),
+				ExpectError: regexp.MustCompile("Entry already exists"),
+			},
+			{
+				PreConfig: testPreCheckServer(t, resource.ComposeAggregateTestCheckFunc(
+					testCheckServerValues("cn=existing,ou=bulk,dc=example,dc=com", "sn", []string{"existing"}),
+					testCheckServerValues("cn=new,ou=bulk,dc=example,dc=com", "sn", []string{"new"}),
+				)),
+				Config:             testObjectsConflictConfig("skip"),
+				Check:              resource.TestCheckResourceAttr("ldap_objects.test", "objects.%", "1"),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestLDAPObjectsResourceImportConflictSkip(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The existing entry is left untouched and stays outside of the state
+			{
+				Config: testObjectsConflictConfig("skip"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_objects.test", "objects.%", "1"),
+					testCheckServerValues("cn=existing,ou=bulk,dc=example,dc=com", "sn", []string{"existing"}),
+					testCheckServerValues("cn=new,ou=bulk,dc=example,dc=com", "sn", []string{"new"}),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+// testObjectsConflictConfig creates the entry cn=existing with another resource before the bulk resource tries to
+// create it with a different sn.
+func testObjectsConflictConfig(strategy string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "bulk" {
+	dn = "ou=bulk,dc=example,dc=com"
+	object_classes = ["organizationalUnit"]
+	attributes = {
+		"ou" = ["bulk"]
+	}
+}
+
+resource "ldap_object" "existing" {
+	dn = "cn=existing,${ldap_object.bulk.dn}"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["existing"]
+		"sn" = ["existing"]
+	}
+}
+
+resource "ldap_objects" "test" {
+	base_dn = ldap_object.bulk.dn
+	objects = {
+		"cn=existing" = {
+			object_classes = ["person"]
+			attributes = { "cn" = ["existing"], "sn" = ["imported"] }
+		}
+		"cn=new" = {
+			object_classes = ["person"]
+			attributes = { "cn" = ["new"], "sn" = ["new"] }
+		}
+	}
+	import_conflict_strategy = %q
+	depends_on = [ldap_object.existing]
+}
+`, strategy)
+}
@@ -0,0 +1,376 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/thoas/go-funk"
+	"time"
+)
+
+var _ resource.Resource = &LDAPOrganizationalUnitResource{}
+var _ resource.ResourceWithConfigure = &LDAPOrganizationalUnitResource{}
+var _ resource.ResourceWithModifyPlan = &LDAPOrganizationalUnitResource{}
+var _ resource.ResourceWithImportState = &LDAPOrganizationalUnitResource{}
+
+func NewLDAPOrganizationalUnitResource() resource.Resource {
+	return &LDAPOrganizationalUnitResource{}
+}
+
+type LDAPOrganizationalUnitResource struct {
+	conn           *ldap.Conn
+	locks          *dnLocks
+	controlSupport *controlSupport
+}
+
+type LDAPOrganizationalUnitResourceModel struct {
+	ID                 types.String `tfsdk:"id"`
+	DN                 types.String `tfsdk:"dn"`
+	Name               types.String `tfsdk:"name"`
+	ParentDN           types.String `tfsdk:"parent_dn"`
+	Description        types.String `tfsdk:"description"`
+	DeletionProtection types.Bool   `tfsdk:"deletion_protection"`
+	RecursiveDelete    types.Bool   `tfsdk:"recursive_delete"`
+}
+
+func (L *LDAPOrganizationalUnitResource) Configure(_ context.Context, request resource.ConfigureRequest, response *resource.ConfigureResponse) {
+	if request.ProviderData == nil {
+		return
+	}
+
+	if client, ok := request.ProviderData.(*ldapClient); !ok {
+		response.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ldapClient, got: %T. Please report this issue to the provider developers.", request.ProviderData),
+		)
+
+		return
+	} else {
+		L.conn = client.conn
+		L.locks = client.locks
+		L.controlSupport = client.controlSupport
+	}
+}
+
+func (L *LDAPOrganizationalUnitResource) Metadata(_ context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_organizational_unit"
+}
+
+func (L *LDAPOrganizationalUnitResource) Schema(_ context.Context, _ resource.SchemaRequest, response *resource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		MarkdownDescription: "Manages an organizational unit. Changing its name or parent renames respectively moves the " +
+			"organizational unit together with all entries below it",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Resource identifier",
+			},
+			"dn": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "DN of the organizational unit, which is named by its `ou` below `parent_dn`. It is known while planning, so it can be used as `parent_dn` of nested organizational units",
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Name of the organizational unit, stored in `ou`",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"parent_dn": schema.StringAttribute{
+				MarkdownDescription: "DN of the entry the organizational unit is created in",
+				Required:            true,
+				Validators: []validator.String{
+					IsValidDN(),
+				},
+			},
+			"description": schema.StringAttribute{
+				MarkdownDescription: "Description of the organizational unit",
+				Optional:            true,
+			},
+			"deletion_protection": schema.BoolAttribute{
+				MarkdownDescription: "Whether to prevent the organizational unit from being deleted. To delete it, set this to `false` and apply first",
+				Optional:            true,
+			},
+			"recursive_delete": schema.BoolAttribute{
+				MarkdownDescription: "Whether to delete all entries below the organizational unit before deleting it",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// ModifyPlan plans the DN from the name and the parent DN, so it is known to the resources using it. A DN which only
+// differs in its spelling from the DN in the state doesn't cause an update.
+func (L *LDAPOrganizationalUnitResource) ModifyPlan(ctx context.Context, request resource.ModifyPlanRequest, response *resource.ModifyPlanResponse) {
+	var stateData *LDAPOrganizationalUnitResourceModel
+	var planData *LDAPOrganizationalUnitResourceModel
+
+	response.Diagnostics.Append(request.State.Get(ctx, &stateData)...)
+	response.Diagnostics.Append(request.Plan.Get(ctx, &planData)...)
+	if response.Diagnostics.HasError() || planData == nil {
+		return
+	}
+
+	if planData.Name.IsUnknown() || planData.ParentDN.IsUnknown() {
+		planData.DN = types.StringUnknown()
+	} else if dn := organizationalUnitDN(planData); stateData != nil && sameDN(stateData.DN.ValueString(), dn) {
+		planData.DN = stateData.DN
+	} else {
+		planData.DN = types.StringValue(dn)
+	}
+	planData.ID = planData.DN
+	response.Diagnostics.Append(response.Plan.Set(ctx, &planData)...)
+}
+
+func (L *LDAPOrganizationalUnitResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	var data *LDAPOrganizationalUnitResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	data.DN = types.StringValue(organizationalUnitDN(data))
+	data.ID = data.DN
+	a := ldap.NewAddRequest(data.DN.ValueString(), []ldap.Control{})
+	a.Attribute("objectClass", []string{"organizationalUnit"})
+	a.Attribute("ou", []string{data.Name.ValueString()})
+	if !data.Description.IsNull() {
+		a.Attribute("description", []string{data.Description.ValueString()})
+	}
+
+	start := time.Now()
+	err := WithContext(ctx, func() error {
+		return L.locks.write(ctx, a.DN, func() error {
+			return L.conn.Add(a)
+		})
+	})
+	LogOperation(ctx, "add", a.DN, start)
+	if err != nil {
+		addOperationError(&response.Diagnostics, err, "create", a.DN,
+			"Can not create organizational unit",
+			fmt.Sprintf("Trying to add organizational unit %s returned: %s", a.DN, err),
+		)
+		return
+	}
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPOrganizationalUnitResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	var data *LDAPOrganizationalUnitResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	start := time.Now()
+	entry, err := GetEntry(L.conn, data.DN.ValueString(), "description")
+	LogOperation(ctx, "search", data.DN.ValueString(), start)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) || errors.Is(err, ErrEntryNotFound) {
+		tflog.Warn(ctx, "Organizational unit was deleted outside of Terraform, removing it from the state", map[string]interface{}{"dn": data.DN.ValueString()})
+		response.State.RemoveResource(ctx)
+		return
+	} else if err != nil {
+		addOperationError(&response.Diagnostics, err, "read", data.DN.ValueString(),
+			"Can not read organizational unit",
+			err.Error(),
+		)
+		return
+	}
+
+	if description := entry.GetAttributeValues("description"); len(description) > 0 {
+		data.Description = types.StringValue(description[0])
+	} else {
+		data.Description = types.StringNull()
+	}
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPOrganizationalUnitResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
+	var stateData *LDAPOrganizationalUnitResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &stateData)...)
+	var planData *LDAPOrganizationalUnitResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &planData)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	planData.DN = types.StringValue(organizationalUnitDN(planData))
+	if sameDN(stateData.DN.ValueString(), planData.DN.ValueString()) {
+		planData.DN = stateData.DN
+	} else if err := L.move(ctx, stateData, planData); err != nil {
+		addOperationError(&response.Diagnostics, err, "update", stateData.DN.ValueString(),
+			"Can not rename organizational unit",
+			fmt.Sprintf("Trying to rename organizational unit %s to %s returned: %s", stateData.DN.ValueString(), planData.DN.ValueString(), err),
+		)
+		return
+	}
+	planData.ID = planData.DN
+
+	r := ldap.NewModifyRequest(planData.DN.ValueString(), []ldap.Control{})
+	if planData.Description.IsNull() && !stateData.Description.IsNull() {
+		r.Delete("description", []string{})
+	} else if !planData.Description.Equal(stateData.Description) {
+		r.Replace("description", []string{planData.Description.ValueString()})
+	}
+
+	if len(r.Changes) > 0 {
+		start := time.Now()
+		err := WithContext(ctx, func() error {
+			return L.locks.write(ctx, r.DN, func() error {
+				return L.conn.Modify(r)
+			})
+		})
+		LogOperation(ctx, "modify", r.DN, start)
+		if err != nil {
+			// the organizational unit may have been moved already, so the state has to follow it
+			stateData.ID = planData.ID
+			stateData.DN = planData.DN
+			stateData.Name = planData.Name
+			stateData.ParentDN = planData.ParentDN
+			response.Diagnostics.Append(response.State.Set(ctx, &stateData)...)
+			addOperationError(&response.Diagnostics, err, "update", r.DN,
+				"Can not modify organizational unit",
+				fmt.Sprintf("Trying to modify organizational unit %s returned: %s", r.DN, err),
+			)
+			return
+		}
+	}
+	response.Diagnostics.Append(response.State.Set(ctx, &planData)...)
+}
+
+func (L *LDAPOrganizationalUnitResource) Delete(ctx context.Context, request resource.DeleteRequest, response *resource.DeleteResponse) {
+	var data *LDAPOrganizationalUnitResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	if data.DeletionProtection.ValueBool() {
+		addDeletionProtectionError(&response.Diagnostics, data.DN.ValueString())
+		return
+	}
+
+	var controls []ldap.Control
+	if data.RecursiveDelete.ValueBool() {
+		if supported, err := L.controlSupport.supports(L.conn, ControlTypeTreeDelete); err == nil && supported {
+			tflog.Info(ctx, "Deleting subtree using the tree delete control", map[string]interface{}{"dn": data.DN.ValueString()})
+			controls = append(controls, NewControlTreeDelete())
+		} else {
+			tflog.Info(ctx, "Deleting subtree entry by entry", map[string]interface{}{"dn": data.DN.ValueString()})
+			if err := L.deleteChildren(ctx, data.DN.ValueString()); err != nil {
+				addOperationError(&response.Diagnostics, err, "delete", data.DN.ValueString(),
+					"Can not delete children",
+					fmt.Sprintf("Trying to delete the entries below %s returned: %s", data.DN.ValueString(), err),
+				)
+				return
+			}
+		}
+	}
+
+	start := time.Now()
+	err := WithContext(ctx, func() error {
+		return L.locks.write(ctx, data.DN.ValueString(), func() error {
+			return L.conn.Del(ldap.NewDelRequest(data.DN.ValueString(), controls))
+		})
+	})
+	LogOperation(ctx, "delete", data.DN.ValueString(), start)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNotAllowedOnNonLeaf) {
+		detail := fmt.Sprintf("Trying to delete organizational unit returned: %s", err)
+		if children, err := searchChildren(ctx, L.conn, data.DN.ValueString(), ldap.ScopeSingleLevel); err == nil {
+			detail = fmt.Sprintf("The organizational unit has %d children. Delete them first or set recursive_delete to delete them with the organizational unit", len(children))
+		}
+		response.Diagnostics.AddError("Can not delete organizational unit", detail)
+	} else if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		addOperationError(&response.Diagnostics, err, "delete", data.DN.ValueString(),
+			"Can not delete organizational unit",
+			fmt.Sprintf("Trying to delete organizational unit %s returned: %s", data.DN.ValueString(), err),
+		)
+	}
+}
+
+// deleteChildren deletes all entries below the organizational unit, starting with the deepest ones.
+func (L *LDAPOrganizationalUnitResource) deleteChildren(ctx context.Context, dn string) error {
+	children, err := childrenDeepestFirst(ctx, L.conn, dn)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		start := time.Now()
+		err := WithContext(ctx, func() error {
+			return L.locks.write(ctx, child, func() error {
+				return L.conn.Del(ldap.NewDelRequest(child, []ldap.Control{}))
+			})
+		})
+		LogOperation(ctx, "delete", child, start)
+		if err != nil {
+			return fmt.Errorf("can not delete %s: %w", child, err)
+		}
+	}
+	return nil
+}
+
+// ImportState imports an organizational unit by its DN.
+func (L *LDAPOrganizationalUnitResource) ImportState(ctx context.Context, request resource.ImportStateRequest, response *resource.ImportStateResponse) {
+	entry, err := GetEntry(L.conn, request.ID, "objectClass")
+	if err != nil {
+		addOperationError(&response.Diagnostics, err, "import", request.ID,
+			"Can not read organizational unit",
+			err.Error(),
+		)
+		return
+	}
+	if !funk.Contains(entry.GetAttributeValues("objectClass"), func(objectClass string) bool {
+		return equalValues(matchingRuleCaseIgnore, objectClass, "organizationalUnit")
+	}) {
+		response.Diagnostics.AddError(
+			"Not an organizational unit",
+			fmt.Sprintf("The entry %s isn't an organizationalUnit", entry.DN),
+		)
+		return
+	}
+	rdn, parentDN, err := SplitRDN(entry.DN)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Invalid DN",
+			fmt.Sprintf("Can not split the DN %s: %s", entry.DN, err),
+		)
+		return
+	}
+
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("id"), entry.DN)...)
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("dn"), entry.DN)...)
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("name"), rdn.Attributes[0].Value)...)
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("parent_dn"), parentDN)...)
+}
+
+// move renames the organizational unit and moves it to its new parent using a single modify DN operation, which
+// removes the old name from ou.
+func (L *LDAPOrganizationalUnitResource) move(ctx context.Context, stateData *LDAPOrganizationalUnitResourceModel, planData *LDAPOrganizationalUnitResourceModel) error {
+	newSuperior := ""
+	if !sameDN(stateData.ParentDN.ValueString(), planData.ParentDN.ValueString()) {
+		newSuperior = planData.ParentDN.ValueString()
+	}
+	r := ldap.NewModifyDNRequest(stateData.DN.ValueString(), fmt.Sprintf("ou=%s", ldap.EscapeDN(planData.Name.ValueString())), true, newSuperior)
+
+	start := time.Now()
+	defer LogOperation(ctx, "modifydn", r.DN, start)
+	return WithContext(ctx, func() error {
+		return L.locks.write(ctx, r.DN, func() error {
+			return L.conn.ModifyDN(r)
+		})
+	})
+}
+
+// organizationalUnitDN returns the DN of the organizational unit named by its name below its parent.
+func organizationalUnitDN(data *LDAPOrganizationalUnitResourceModel) string {
+	return fmt.Sprintf("ou=%s,%s", ldap.EscapeDN(data.Name.ValueString()), data.ParentDN.ValueString())
+}
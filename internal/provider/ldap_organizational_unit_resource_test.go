@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"testing"
+)
+
+func TestLDAPOrganizationalUnitResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testCheckEntryMissing("ou=staff,dc=example,dc=com"),
+		Steps: []resource.TestStep{
+			{
+				Config: testOrganizationalUnitConfig("engineering", "ldap_organizational_unit.staff.dn", "Engineering"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_organizational_unit.staff", "dn", "ou=staff,dc=example,dc=com"),
+					resource.TestCheckResourceAttr("ldap_organizational_unit.nested", "dn", "ou=engineering,ou=staff,dc=example,dc=com"),
+					testCheckServerValues("ou=engineering,ou=staff,dc=example,dc=com", "description", []string{"Engineering"}),
+				),
+			},
+			// Renaming keeps the entry and only replaces its name
+			{
+				Config: testOrganizationalUnitConfig("development", "ldap_organizational_unit.staff.dn", "Development"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_organizational_unit.nested", "dn", "ou=development,ou=staff,dc=example,dc=com"),
+					testCheckEntryMissing("ou=engineering,ou=staff,dc=example,dc=com"),
+					testCheckServerValues("ou=development,ou=staff,dc=example,dc=com", "ou", []string{"development"}),
+					testCheckServerValues("ou=development,ou=staff,dc=example,dc=com", "description", []string{"Development"}),
+				),
+			},
+			// Changing the parent moves the entry
+			{
+				Config: testOrganizationalUnitConfig("development", `"dc=example,dc=com"`, "Development"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_organizational_unit.nested", "dn", "ou=development,dc=example,dc=com"),
+					testCheckEntryMissing("ou=development,ou=staff,dc=example,dc=com"),
+					testCheckServerValues("ou=development,dc=example,dc=com", "ou", []string{"development"}),
+				),
+			},
+			{
+				Config:            testOrganizationalUnitConfig("development", `"dc=example,dc=com"`, "Development"),
+				ResourceName:      "ldap_organizational_unit.nested",
+				ImportState:       true,
+				ImportStateId:     "ou=development,dc=example,dc=com",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+// testOrganizationalUnitConfig creates the organizational unit staff and another organizational unit with the given
+// name, parent DN expression and description.
+func testOrganizationalUnitConfig(name string, parentDN string, description string) string {
+	return fmt.Sprintf(`
+resource "ldap_organizational_unit" "staff" {
+	name = "staff"
+	parent_dn = "dc=example,dc=com"
+}
+
+resource "ldap_organizational_unit" "nested" {
+	name = %q
+	parent_dn = %s
+	description = %q
+}
+`, name, parentDN, description)
+}
@@ -0,0 +1,278 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"time"
+)
+
+var _ resource.Resource = &LDAPPasswordPolicyStateResource{}
+var _ resource.ResourceWithConfigure = &LDAPPasswordPolicyStateResource{}
+var _ resource.ResourceWithModifyPlan = &LDAPPasswordPolicyStateResource{}
+var _ resource.ResourceWithImportState = &LDAPPasswordPolicyStateResource{}
+
+func NewLDAPPasswordPolicyStateResource() resource.Resource {
+	return &LDAPPasswordPolicyStateResource{}
+}
+
+type LDAPPasswordPolicyStateResource struct {
+	conn  *ldap.Conn
+	locks *dnLocks
+}
+
+type LDAPPasswordPolicyStateResourceModel struct {
+	ID                   types.String `tfsdk:"id"`
+	DN                   types.String `tfsdk:"dn"`
+	Unlock               types.Bool   `tfsdk:"unlock"`
+	ResetFailures        types.Bool   `tfsdk:"reset_failures"`
+	PwdAccountLockedTime types.String `tfsdk:"pwd_account_locked_time"`
+	PwdFailureCount      types.Int64  `tfsdk:"pwd_failure_count"`
+}
+
+// ControlTypePasswordPolicy is the OID of the password policy control, which is advertised by servers enforcing
+// password policies like OpenLDAP with the ppolicy overlay.
+const ControlTypePasswordPolicy = "1.3.6.1.4.1.42.2.27.8.5.1"
+
+// The operational attributes in which the password policy keeps the state of an account.
+const (
+	pwdAccountLockedTime = "pwdAccountLockedTime"
+	pwdFailureTime       = "pwdFailureTime"
+)
+
+func (L *LDAPPasswordPolicyStateResource) Configure(_ context.Context, request resource.ConfigureRequest, response *resource.ConfigureResponse) {
+	if request.ProviderData == nil {
+		return
+	}
+
+	if client, ok := request.ProviderData.(*ldapClient); !ok {
+		response.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ldapClient, got: %T. Please report this issue to the provider developers.", request.ProviderData),
+		)
+
+		return
+	} else {
+		L.conn = client.conn
+		L.locks = client.locks
+	}
+}
+
+func (L *LDAPPasswordPolicyStateResource) Metadata(_ context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_password_policy_state"
+}
+
+func (L *LDAPPasswordPolicyStateResource) Schema(_ context.Context, _ resource.SchemaRequest, response *resource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		MarkdownDescription: "Resets the password policy state of an account, e.g. to unlock it after too many failed binds. " +
+			"The account itself isn't managed by this resource and is left untouched when the resource is destroyed. " +
+			"Requires a server enforcing password policies, like OpenLDAP with the ppolicy overlay",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Resource identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"dn": schema.StringAttribute{
+				MarkdownDescription: "DN of the account",
+				Required:            true,
+				Validators: []validator.String{
+					IsValidDN(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"unlock": schema.BoolAttribute{
+				MarkdownDescription: "Whether to unlock the account by deleting `pwdAccountLockedTime` whenever it is locked",
+				Optional:            true,
+			},
+			"reset_failures": schema.BoolAttribute{
+				MarkdownDescription: "Whether to reset the failure count by deleting `pwdFailureTime` whenever binds have failed",
+				Optional:            true,
+			},
+			"pwd_account_locked_time": schema.StringAttribute{
+				MarkdownDescription: "The time the account was locked at, null if it isn't locked",
+				Computed:            true,
+			},
+			"pwd_failure_count": schema.Int64Attribute{
+				MarkdownDescription: "The number of failed binds recorded in `pwdFailureTime`",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (L *LDAPPasswordPolicyStateResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	var data *LDAPPasswordPolicyStateResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	if supported, err := SupportsControl(L.conn, ControlTypePasswordPolicy); err != nil {
+		response.Diagnostics.AddError(
+			"Can not read root DSE",
+			fmt.Sprintf("Trying to check whether the server supports password policies returned: %s", err),
+		)
+		return
+	} else if !supported {
+		response.Diagnostics.AddError(
+			"Password policies not supported",
+			"The server doesn't advertise the password policy control, password policies are likely not enforced. For OpenLDAP, load the ppolicy overlay",
+		)
+		return
+	}
+
+	data.ID = data.DN
+	if err := L.reset(ctx, data); err != nil {
+		addOperationError(&response.Diagnostics, err, "create", data.DN.ValueString(),
+			"Can not reset password policy state",
+			fmt.Sprintf("Trying to reset the password policy state of %s returned: %s", data.DN.ValueString(), err),
+		)
+		return
+	}
+	if err := L.read(ctx, data); err != nil {
+		addOperationError(&response.Diagnostics, err, "create", data.DN.ValueString(),
+			"Can not read entry",
+			err.Error(),
+		)
+		return
+	}
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPPasswordPolicyStateResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	var data *LDAPPasswordPolicyStateResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	if err := L.read(ctx, data); ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) || errors.Is(err, ErrEntryNotFound) {
+		tflog.Warn(ctx, "Account was deleted outside of Terraform, removing it from the state", map[string]interface{}{"dn": data.DN.ValueString()})
+		response.State.RemoveResource(ctx)
+		return
+	} else if err != nil {
+		addOperationError(&response.Diagnostics, err, "read", data.DN.ValueString(),
+			"Can not read entry",
+			err.Error(),
+		)
+		return
+	}
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPPasswordPolicyStateResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
+	var data *LDAPPasswordPolicyStateResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	if err := L.reset(ctx, data); err != nil {
+		addOperationError(&response.Diagnostics, err, "update", data.DN.ValueString(),
+			"Can not reset password policy state",
+			fmt.Sprintf("Trying to reset the password policy state of %s returned: %s", data.DN.ValueString(), err),
+		)
+		return
+	}
+	// the planned values already reflect the cleared attributes, reading them again could pick up newer failures
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPPasswordPolicyStateResource) Delete(ctx context.Context, request resource.DeleteRequest, _ *resource.DeleteResponse) {
+	var data *LDAPPasswordPolicyStateResourceModel
+	request.State.Get(ctx, &data)
+	tflog.Debug(ctx, "Leaving the password policy state of the account untouched", map[string]interface{}{"dn": data.DN.ValueString()})
+}
+
+func (L *LDAPPasswordPolicyStateResource) ImportState(ctx context.Context, request resource.ImportStateRequest, response *resource.ImportStateResponse) {
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("dn"), request.ID)...)
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("id"), request.ID)...)
+}
+
+// ModifyPlan plans the password policy attributes, which are cleared by the resource, as cleared. This causes an
+// update whenever the account was locked or binds have failed since the last apply.
+func (L *LDAPPasswordPolicyStateResource) ModifyPlan(ctx context.Context, request resource.ModifyPlanRequest, response *resource.ModifyPlanResponse) {
+	var stateData *LDAPPasswordPolicyStateResourceModel
+	var planData *LDAPPasswordPolicyStateResourceModel
+
+	response.Diagnostics.Append(request.State.Get(ctx, &stateData)...)
+	response.Diagnostics.Append(request.Plan.Get(ctx, &planData)...)
+	if response.Diagnostics.HasError() || stateData == nil || planData == nil {
+		return
+	}
+
+	planData.PwdAccountLockedTime = stateData.PwdAccountLockedTime
+	if planData.Unlock.ValueBool() {
+		planData.PwdAccountLockedTime = types.StringNull()
+	}
+	planData.PwdFailureCount = stateData.PwdFailureCount
+	if planData.ResetFailures.ValueBool() {
+		planData.PwdFailureCount = types.Int64Value(0)
+	}
+	response.Diagnostics.Append(response.Plan.Set(ctx, &planData)...)
+}
+
+// reset deletes the password policy attributes of the account, which should be cleared and are present. Since the
+// attributes are usually not user-modifiable, the relax rules control is sent if the server supports it.
+func (L *LDAPPasswordPolicyStateResource) reset(ctx context.Context, data *LDAPPasswordPolicyStateResourceModel) error {
+	start := time.Now()
+	entry, err := GetEntry(L.conn, data.DN.ValueString(), pwdAccountLockedTime, pwdFailureTime)
+	LogOperation(ctx, "search", data.DN.ValueString(), start)
+	if err != nil {
+		return err
+	}
+
+	r := ldap.NewModifyRequest(data.DN.ValueString(), []ldap.Control{})
+	if data.Unlock.ValueBool() && len(entry.GetAttributeValues(pwdAccountLockedTime)) > 0 {
+		r.Delete(pwdAccountLockedTime, []string{})
+	}
+	if data.ResetFailures.ValueBool() && len(entry.GetAttributeValues(pwdFailureTime)) > 0 {
+		r.Delete(pwdFailureTime, []string{})
+	}
+	if len(r.Changes) == 0 {
+		return nil
+	}
+	if supported, err := SupportsControl(L.conn, ControlTypeRelax); err == nil && supported {
+		r.Controls = append(r.Controls, NewControlRelax())
+	}
+
+	start = time.Now()
+	err = WithContext(ctx, func() error {
+		return L.locks.write(ctx, r.DN, func() error {
+			return L.conn.Modify(r)
+		})
+	})
+	LogOperation(ctx, "modify", data.DN.ValueString(), start)
+	return err
+}
+
+// read sets the computed password policy attributes from the account.
+func (L *LDAPPasswordPolicyStateResource) read(ctx context.Context, data *LDAPPasswordPolicyStateResourceModel) error {
+	start := time.Now()
+	entry, err := GetEntry(L.conn, data.DN.ValueString(), pwdAccountLockedTime, pwdFailureTime)
+	LogOperation(ctx, "search", data.DN.ValueString(), start)
+	if err != nil {
+		return err
+	}
+
+	data.PwdAccountLockedTime = types.StringNull()
+	if lockedTime := entry.GetAttributeValue(pwdAccountLockedTime); lockedTime != "" {
+		data.PwdAccountLockedTime = types.StringValue(lockedTime)
+	}
+	data.PwdFailureCount = types.Int64Value(int64(len(entry.GetAttributeValues(pwdFailureTime))))
+	return nil
+}
@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"testing"
+)
+
+func TestLDAPPasswordPolicyStateResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !testServerSupportsControl(ControlTypePasswordPolicy) {
+				t.Skip("server does not enforce password policies")
+			}
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testPasswordPolicyStateConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("ldap_password_policy_state.locked", "pwd_account_locked_time"),
+					resource.TestCheckResourceAttr("ldap_password_policy_state.locked", "pwd_failure_count", "0"),
+				),
+			},
+			// Locking the account outside of Terraform plans to unlock it again
+			{
+				PreConfig:          testLockAccountExternally(t, "cn=locked,dc=example,dc=com"),
+				Config:             testPasswordPolicyStateConfig,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testPasswordPolicyStateConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("ldap_password_policy_state.locked", "pwd_account_locked_time"),
+					testCheckServerValues("cn=locked,dc=example,dc=com", "pwdAccountLockedTime", []string{}),
+					func(_ *terraform.State) error {
+						return testBindAs("cn=locked,dc=example,dc=com", "secret")
+					},
+				),
+			},
+		},
+	})
+}
+
+const testPasswordPolicyStateConfig = `
+resource "ldap_object" "locked" {
+	dn = "cn=locked,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["locked"]
+		"sn" = ["locked"]
+		"userPassword" = ["secret"]
+	}
+	ignore_changes = ["userPassword"]
+}
+
+resource "ldap_password_policy_state" "locked" {
+	dn = ldap_object.locked.dn
+	unlock = true
+	reset_failures = true
+}
+`
+
+// testLockAccountExternally locks the account permanently, like the password policy does after too many failures.
+func testLockAccountExternally(t *testing.T, dn string) func() {
+	return testExternally(t, func(conn *ldap.Conn) error {
+		r := ldap.NewModifyRequest(dn, []ldap.Control{NewControlRelax()})
+		r.Replace(pwdAccountLockedTime, []string{"000001010000Z"})
+		return conn.Modify(r)
+	})
+}
@@ -0,0 +1,259 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"time"
+)
+
+var _ resource.Resource = &LDAPPasswordResource{}
+var _ resource.ResourceWithConfigure = &LDAPPasswordResource{}
+var _ resource.ResourceWithValidateConfig = &LDAPPasswordResource{}
+
+func NewLDAPPasswordResource() resource.Resource {
+	return &LDAPPasswordResource{}
+}
+
+type LDAPPasswordResource struct {
+	conn  *ldap.Conn
+	locks *dnLocks
+}
+
+type LDAPPasswordResourceModel struct {
+	ID                 types.String `tfsdk:"id"`
+	UserDN             types.String `tfsdk:"user_dn"`
+	NewPassword        types.String `tfsdk:"new_password"`
+	OldPassword        types.String `tfsdk:"old_password"`
+	RotationTrigger    types.Map    `tfsdk:"rotation_trigger"`
+	GeneratedPassword  types.String `tfsdk:"generated_password"`
+	PasswordHashScheme types.String `tfsdk:"password_hash_scheme"`
+}
+
+func (L *LDAPPasswordResource) Configure(_ context.Context, request resource.ConfigureRequest, response *resource.ConfigureResponse) {
+	if request.ProviderData == nil {
+		return
+	}
+
+	if client, ok := request.ProviderData.(*ldapClient); !ok {
+		response.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ldapClient, got: %T. Please report this issue to the provider developers.", request.ProviderData),
+		)
+
+		return
+	} else {
+		L.conn = client.conn
+		L.locks = client.locks
+	}
+}
+
+func (L *LDAPPasswordResource) Metadata(_ context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_password"
+}
+
+func (L *LDAPPasswordResource) Schema(_ context.Context, _ resource.SchemaRequest, response *resource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		MarkdownDescription: "Sets the password of an account using the password modify extended operation (RFC 3062), " +
+			"so the server hashes the password and applies its password policy. The password is set again whenever " +
+			"`new_password` or `rotation_trigger` changes. The account itself isn't managed by this resource and " +
+			"keeps its password when the resource is destroyed. Note that `new_password` and `old_password` are " +
+			"stored in the state like any other argument, as this provider doesn't support write-only arguments yet. " +
+			"For servers storing `userPassword` verbatim, the password can be hashed by the provider using `password_hash_scheme`",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Resource identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"user_dn": schema.StringAttribute{
+				MarkdownDescription: "DN of the account",
+				Required:            true,
+				Validators: []validator.String{
+					IsValidDN(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"new_password": schema.StringAttribute{
+				MarkdownDescription: "The new password. If not set, the server generates a password, which is " +
+					"returned in `generated_password`",
+				Optional:  true,
+				Sensitive: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"old_password": schema.StringAttribute{
+				MarkdownDescription: "The current password of the account. Required by most servers if the provider " +
+					"binds as the account itself",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"rotation_trigger": schema.MapAttribute{
+				MarkdownDescription: "Arbitrary values, which set the password again whenever they change",
+				ElementType:         types.StringType,
+				Optional:            true,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+			"password_hash_scheme": schema.StringAttribute{
+				MarkdownDescription: "If set, `new_password` is hashed by the provider and written to `userPassword` instead of using the password modify extended operation, for servers storing it verbatim: `SSHA`, `SSHA512`, `SHA512-CRYPT`, `PBKDF2` or `ARGON2`. If `userPassword` can be read, a password changed outside of Terraform is detected by verifying `new_password` against it and sets the password again",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(passwordHashSchemes...),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"generated_password": schema.StringAttribute{
+				MarkdownDescription: "The password generated by the server if `new_password` isn't set",
+				Computed:            true,
+				Sensitive:           true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (L *LDAPPasswordResource) ValidateConfig(ctx context.Context, request resource.ValidateConfigRequest, response *resource.ValidateConfigResponse) {
+	var data *LDAPPasswordResourceModel
+	response.Diagnostics.Append(request.Config.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.PasswordHashScheme.IsNull() && data.NewPassword.IsNull() {
+		response.Diagnostics.AddAttributeError(
+			path.Root("new_password"),
+			"Missing password",
+			"Only the server can generate passwords, so new_password is required to hash it using password_hash_scheme",
+		)
+	}
+}
+
+func (L *LDAPPasswordResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	var data *LDAPPasswordResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = data.UserDN
+	data.GeneratedPassword = types.StringNull()
+	if !data.PasswordHashScheme.IsNull() {
+		if err := setHashedPassword(ctx, L.conn, L.locks, data.UserDN.ValueString(), data.PasswordHashScheme.ValueString(), data.NewPassword.ValueString()); err != nil {
+			addOperationError(&response.Diagnostics, err, "create", data.UserDN.ValueString(),
+				"Can not set password",
+				fmt.Sprintf("Trying to set the password of %s returned: %s", data.UserDN.ValueString(), err),
+			)
+			return
+		}
+	} else if result, err := L.modify(ctx, data); err != nil {
+		addOperationError(&response.Diagnostics, err, "create", data.UserDN.ValueString(),
+			"Can not set password",
+			fmt.Sprintf("Trying to set the password of %s returned: %s", data.UserDN.ValueString(), err),
+		)
+		return
+	} else if data.NewPassword.IsNull() {
+		if result.GeneratedPassword == "" {
+			response.Diagnostics.AddError(
+				"No password generated",
+				fmt.Sprintf("The server didn't return a generated password for %s. Set new_password instead", data.UserDN.ValueString()),
+			)
+			return
+		}
+		data.GeneratedPassword = types.StringValue(result.GeneratedPassword)
+	}
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPPasswordResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	var data *LDAPPasswordResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// the password can only be verified against hashes written by the provider, otherwise only the account is checked
+	attributeType := "1.1"
+	verifyPassword := !data.PasswordHashScheme.IsNull() && !data.NewPassword.IsNull()
+	if verifyPassword {
+		attributeType = "userPassword"
+	}
+
+	start := time.Now()
+	entry, err := GetEntry(L.conn, data.UserDN.ValueString(), attributeType)
+	LogOperation(ctx, "search", data.UserDN.ValueString(), start)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) || errors.Is(err, ErrEntryNotFound) {
+		tflog.Warn(ctx, "Account was deleted outside of Terraform, removing it from the state", map[string]interface{}{"dn": data.UserDN.ValueString()})
+		response.State.RemoveResource(ctx)
+		return
+	} else if err != nil {
+		addOperationError(&response.Diagnostics, err, "read", data.UserDN.ValueString(),
+			"Can not read entry",
+			err.Error(),
+		)
+		return
+	}
+	if verifyPassword && !passwordMatches(entry.GetAttributeValues("userPassword"), data.NewPassword.ValueString()) {
+		tflog.Warn(ctx, "Password was changed outside of Terraform", map[string]interface{}{"dn": data.UserDN.ValueString()})
+		data.NewPassword = types.StringNull()
+	}
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPPasswordResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
+	var data *LDAPPasswordResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// every argument setting the password requires a replacement, old_password is only needed for the next change
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPPasswordResource) Delete(ctx context.Context, request resource.DeleteRequest, response *resource.DeleteResponse) {
+	var data *LDAPPasswordResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+	tflog.Debug(ctx, "Leaving the password of the account untouched", map[string]interface{}{"dn": data.UserDN.ValueString()})
+}
+
+// modify sends the password modify extended operation for the account.
+func (L *LDAPPasswordResource) modify(ctx context.Context, data *LDAPPasswordResourceModel) (*ldap.PasswordModifyResult, error) {
+	var result *ldap.PasswordModifyResult
+	r := ldap.NewPasswordModifyRequest(data.UserDN.ValueString(), data.OldPassword.ValueString(), data.NewPassword.ValueString())
+
+	start := time.Now()
+	defer LogOperation(ctx, "passwordmodify", data.UserDN.ValueString(), start)
+	err := WithContext(ctx, func() error {
+		return L.locks.write(ctx, data.UserDN.ValueString(), func() error {
+			var err error
+			result, err = L.conn.PasswordModify(r)
+			return err
+		})
+	})
+	return result, err
+}
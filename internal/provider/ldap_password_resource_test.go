@@ -0,0 +1,105 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"regexp"
+	"testing"
+)
+
+func TestLDAPPasswordResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testPasswordConfig(`new_password = "first-secret"`, "1"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("ldap_password.dave", "generated_password"),
+					testCheckBind("cn=dave,dc=example,dc=com", "first-secret"),
+				),
+			},
+			// Changing the trigger sets the password again
+			{
+				PreConfig: testSetPasswordExternally(t, "cn=dave,dc=example,dc=com", "external-secret"),
+				Config:    testPasswordConfig(`new_password = "first-secret"`, "2"),
+				Check:     testCheckBind("cn=dave,dc=example,dc=com", "first-secret"),
+			},
+			// Without a new password, the server generates one
+			{
+				Config: testPasswordConfig("", "2"),
+				Check: func(state *terraform.State) error {
+					password := state.RootModule().Resources["ldap_password.dave"].Primary.Attributes["generated_password"]
+					if password == "" {
+						return fmt.Errorf("no password generated")
+					}
+					return testCheckBind("cn=dave,dc=example,dc=com", password)(state)
+				},
+			},
+		},
+	})
+}
+
+func TestLDAPPasswordResourceHashScheme(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testPasswordConfig(`password_hash_scheme = "SSHA"`, "1"),
+				ExpectError: regexp.MustCompile("Missing password"),
+			},
+			{
+				Config: testPasswordConfig(`new_password = "hashed-secret"
+	password_hash_scheme = "SSHA"`, "1"),
+				Check: testCheckBind("cn=dave,dc=example,dc=com", "hashed-secret"),
+			},
+			// A new salt doesn't cause changes, but a password changed outside of Terraform does
+			{
+				Config: testPasswordConfig(`new_password = "hashed-secret"
+	password_hash_scheme = "SSHA"`, "1"),
+				PlanOnly: true,
+			},
+			{
+				PreConfig: testSetPasswordExternally(t, "cn=dave,dc=example,dc=com", "external-secret"),
+				Config: testPasswordConfig(`new_password = "hashed-secret"
+	password_hash_scheme = "SSHA"`, "1"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+// testPasswordConfig sets the password of dave with the given arguments and rotation trigger.
+func testPasswordConfig(arguments string, trigger string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "dave" {
+	dn = "cn=dave,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["dave"]
+		"sn" = ["dave"]
+	}
+	ignore_changes = ["userPassword"]
+}
+
+resource "ldap_password" "dave" {
+	user_dn = ldap_object.dave.dn
+	%s
+	rotation_trigger = {
+		"rotation" = %q
+	}
+}
+`, arguments, trigger)
+}
+
+// testSetPasswordExternally changes the password of the account outside of Terraform.
+func testSetPasswordExternally(t *testing.T, dn string, password string) func() {
+	return testExternally(t, func(conn *ldap.Conn) error {
+		_, err := conn.PasswordModify(ldap.NewPasswordModifyRequest(dn, "", password))
+		return err
+	})
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"time"
 )
 
 var _ datasource.DataSource = &LDAPSearchDataSource{}
@@ -20,7 +22,8 @@ func NewLDAPSearchDataSource() datasource.DataSource {
 }
 
 type LDAPSearchDataSource struct {
-	conn *ldap.Conn
+	conn   *ldap.Conn
+	client *ldapClient
 }
 
 type LDAPSearchDatasourceModel struct {
@@ -29,7 +32,23 @@ type LDAPSearchDatasourceModel struct {
 	Scope                types.String `tfsdk:"scope"`
 	Filter               types.String `tfsdk:"filter"`
 	Results              types.List   `tfsdk:"results"`
+	Values               types.List   `tfsdk:"values"`
 	AdditionalAttributes types.Set    `tfsdk:"additional_attributes"`
+	Controls             types.List   `tfsdk:"controls"`
+	DontUseCopy          types.Bool   `tfsdk:"dont_use_copy"`
+	DerefAliases         types.String `tfsdk:"deref_aliases"`
+	SizeLimit            types.Int64  `tfsdk:"size_limit"`
+	MaxResults           types.Int64  `tfsdk:"max_results"`
+	PartialResults       types.Bool   `tfsdk:"partial_results"`
+	FollowReferrals      types.Bool   `tfsdk:"follow_referrals"`
+	ReadDurationMs       types.Int64  `tfsdk:"read_duration_ms"`
+}
+
+// LDAPSearchValueModel describes a single attribute value of an entry returned in values.
+type LDAPSearchValueModel struct {
+	DN        types.String `tfsdk:"dn"`
+	Attribute types.String `tfsdk:"attribute"`
+	Value     types.String `tfsdk:"value"`
 }
 
 func (L *LDAPSearchDataSource) Metadata(_ context.Context, request datasource.MetadataRequest, response *datasource.MetadataResponse) {
@@ -47,6 +66,9 @@ func (L *LDAPSearchDataSource) Schema(_ context.Context, _ datasource.SchemaRequ
 			"base_dn": schema.StringAttribute{
 				MarkdownDescription: "Base DN to use to search for LDAP objects",
 				Optional:            true,
+				Validators: []validator.String{
+					IsValidDN(),
+				},
 			},
 			"scope": schema.StringAttribute{
 				MarkdownDescription: "Scope to use to search for LDAP objects",
@@ -58,12 +80,49 @@ func (L *LDAPSearchDataSource) Schema(_ context.Context, _ datasource.SchemaRequ
 			"filter": schema.StringAttribute{
 				MarkdownDescription: "Filter to search for LDAP objects with",
 				Optional:            true,
+				Validators: []validator.String{
+					IsValidFilter(),
+				},
 			},
 			"additional_attributes": schema.SetAttribute{
 				MarkdownDescription: "Any additional attributes to request, such as constructed or operational attributes",
 				Optional:            true,
 				ElementType:         types.StringType,
 			},
+			"dont_use_copy": schema.BoolAttribute{
+				MarkdownDescription: "Whether to send the don't use copy control (RFC 6171), so the server doesn't answer from a possibly outdated copy of the data, but returns a referral or an error instead",
+				Optional:            true,
+			},
+			"deref_aliases": schema.StringAttribute{
+				MarkdownDescription: "How aliases are dereferenced: `never` (default), `searching` below the base DN, `finding` the base DN or `always`",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("never", "searching", "finding", "always"),
+				},
+			},
+			"controls": controlsDataSourceAttribute(),
+			"size_limit": schema.Int64Attribute{
+				MarkdownDescription: "Maximum number of entries to return. The server may enforce a lower limit",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"max_results": schema.Int64Attribute{
+				MarkdownDescription: "Maximum number of entries to read, enforced by the provider. The search is abandoned once more entries are received and the entries read so far are returned together with a warning. Bounds the memory used for searches in large directories",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"partial_results": schema.BoolAttribute{
+				MarkdownDescription: "Whether to return the entries received before a size limit was exceeded together with a warning instead of failing",
+				Optional:            true,
+			},
+			"follow_referrals": schema.BoolAttribute{
+				MarkdownDescription: "Whether to search the servers returned in search continuation references as well. The connections are authenticated as configured by `ldap_referral_bind` of the provider, referrals returned by these servers aren't followed",
+				Optional:            true,
+			},
 			"results": schema.ListAttribute{
 				MarkdownDescription: "List of LDAP objects returned from the search",
 				Computed:            true,
@@ -71,6 +130,30 @@ func (L *LDAPSearchDataSource) Schema(_ context.Context, _ datasource.SchemaRequ
 					ElemType: types.ListType{ElemType: types.StringType},
 				},
 			},
+			"values": schema.ListNestedAttribute{
+				MarkdownDescription: "The values of all entries returned from the search, one element per value, e.g. to build reports",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"dn": schema.StringAttribute{
+							MarkdownDescription: "DN of the entry",
+							Computed:            true,
+						},
+						"attribute": schema.StringAttribute{
+							MarkdownDescription: "Type of the attribute",
+							Computed:            true,
+						},
+						"value": schema.StringAttribute{
+							MarkdownDescription: "Value of the attribute",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"read_duration_ms": schema.Int64Attribute{
+				MarkdownDescription: "Time in milliseconds it took to search the server",
+				Computed:            true,
+			},
 		},
 	}
 }
@@ -80,15 +163,16 @@ func (L *LDAPSearchDataSource) Configure(_ context.Context, request datasource.C
 		return
 	}
 
-	if conn, ok := request.ProviderData.(*ldap.Conn); !ok {
+	if client, ok := request.ProviderData.(*ldapClient); !ok {
 		response.Diagnostics.AddError(
 			"Unexpected Datasource Configure Type",
-			fmt.Sprintf("Expected *ldap.Conn, got: %T. Please report this issue to the provider developers.", request.ProviderData),
+			fmt.Sprintf("Expected *ldapClient, got: %T. Please report this issue to the provider developers.", request.ProviderData),
 		)
 
 		return
 	} else {
-		L.conn = conn
+		L.conn = client.conn
+		L.client = client
 	}
 }
 
@@ -124,18 +208,119 @@ func (L *LDAPSearchDataSource) Read(ctx context.Context, request datasource.Read
 
 	response.State.SetAttribute(ctx, path.Root("id"), fmt.Sprintf("%s/%s/%s", data.BaseDN.ValueString(), data.Scope.ValueString(), filter))
 
-	s := ldap.NewSearchRequest(data.BaseDN.ValueString(), scope, 0, 0, 0, false, filter, append(additionalAttributes, "*"), []ldap.Control{})
+	controls := BuildControls(ctx, data.Controls, &response.Diagnostics)
+	if data.DontUseCopy.ValueBool() {
+		controls = append(controls, NewControlDontUseCopy())
+	}
+	// SearchEntries adds the paged results control
+	if err := L.client.controlSupport.check(L.conn, append(controls, ldap.NewControlPaging(searchPageSize))); err != nil {
+		addControlSupportError(&response.Diagnostics, err)
+	}
+	if response.Diagnostics.HasError() {
+		return
+	}
 
-	if result, err := L.conn.Search(s); err != nil {
-		response.Diagnostics.AddError(
-			"Can not read entry",
-			err.Error(),
+	s := ldap.NewSearchRequest(data.BaseDN.ValueString(), scope, derefAliasesPolicies[data.DerefAliases.ValueString()], int(data.SizeLimit.ValueInt64()), 0, false, filter, append(additionalAttributes, "*"), controls)
+
+	// the entries are converted as they are received instead of keeping the entries themselves, the lists are set
+	// once at the end, since every change of the state copies all of it
+	count := 0
+	results := []map[string][]string{}
+	values := []LDAPSearchValueModel{}
+	consumer := func(entry *ldap.Entry) {
+		result := map[string][]string{}
+		for _, attribute := range entry.Attributes {
+			result[attribute.Name] = attribute.Values
+		}
+		results = append(results, result)
+		values = append(values, flattenEntries([]*ldap.Entry{entry})...)
+		count++
+	}
+
+	maxResults := int(data.MaxResults.ValueInt64())
+	start := time.Now()
+	referrals, truncated, err := SearchEntries(ctx, L.conn, s, maxResults, consumer)
+	response.State.SetAttribute(ctx, path.Root("read_duration_ms"), LogOperation(ctx, "search", data.BaseDN.ValueString(), start).Milliseconds())
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) && data.PartialResults.ValueBool() {
+		response.Diagnostics.AddWarning(
+			"Size limit exceeded",
+			fmt.Sprintf("The search matched more entries than allowed by the size limit, only the first %d entries are returned", count),
 		)
-	} else {
-		for i, entry := range result.Entries {
-			for _, attribute := range entry.Attributes {
-				response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("results").AtListIndex(i).AtMapKey(attribute.Name), attribute.Values)...)
+		err = nil
+	}
+	if err != nil {
+		detail := err.Error()
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+			detail = fmt.Sprintf("%s\n\nSet partial_results to use the entries returned before the size limit was exceeded", detail)
+		}
+		addOperationError(&response.Diagnostics, err, "read", data.BaseDN.ValueString(),
+			"Can not search entries",
+			detail,
+		)
+		return
+	}
+
+	if data.FollowReferrals.ValueBool() {
+		for _, referral := range referrals {
+			if truncated {
+				break
+			}
+			remaining := 0
+			if maxResults > 0 {
+				remaining = maxResults - count
+			}
+			if truncated, err = L.followReferral(ctx, referral, s, remaining, consumer); err != nil {
+				addOperationError(&response.Diagnostics, err, "read", referral,
+					"Can not follow referral",
+					fmt.Sprintf("Searching the referred server %s returned: %s", referral, err),
+				)
+				return
+			}
+		}
+	}
+	if truncated {
+		response.Diagnostics.AddWarning(
+			"Result limit reached",
+			fmt.Sprintf("The search matched more entries than allowed by max_results, only the first %d entries are returned", count),
+		)
+	}
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("results"), results)...)
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("values"), values)...)
+}
+
+// followReferral repeats the search on the server of a search continuation reference, passing the entries to the
+// consumer. The search stops after maxResults entries if it isn't 0.
+func (L *LDAPSearchDataSource) followReferral(ctx context.Context, referral string, s *ldap.SearchRequest, maxResults int, consumer func(entry *ldap.Entry)) (bool, error) {
+	baseDN, err := referralBaseDN(referral, s.BaseDN)
+	if err != nil {
+		return false, err
+	}
+	conn, err := L.client.dialReferral(referral)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	r := ldap.NewSearchRequest(baseDN, s.Scope, s.DerefAliases, s.SizeLimit, s.TimeLimit, s.TypesOnly, s.Filter, s.Attributes, s.Controls)
+	start := time.Now()
+	defer LogOperation(ctx, "search", baseDN, start)
+	_, truncated, err := SearchEntries(ctx, conn, r, maxResults, consumer)
+	return truncated, err
+}
+
+// flattenEntries returns the values of the entries as a list with an element per value.
+func flattenEntries(entries []*ldap.Entry) []LDAPSearchValueModel {
+	values := []LDAPSearchValueModel{}
+	for _, entry := range entries {
+		for _, attribute := range entry.Attributes {
+			for _, value := range attribute.Values {
+				values = append(values, LDAPSearchValueModel{
+					DN:        types.StringValue(entry.DN),
+					Attribute: types.StringValue(attribute.Name),
+					Value:     types.StringValue(value),
+				})
 			}
 		}
 	}
+	return values
 }
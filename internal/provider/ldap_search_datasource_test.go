@@ -1,7 +1,13 @@
 package provider
 
 import (
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"regexp"
 	"testing"
 )
 
@@ -18,6 +24,8 @@ func TestLDAPSearchDatasource(t *testing.T) {
 					resource.TestCheckResourceAttr("data.ldap_search.test", "base_dn", "dc=example,dc=com"),
 					resource.TestCheckResourceAttr("data.ldap_search.test", "results.0.dc.0", "example"),
 					resource.TestCheckResourceAttr("data.ldap_search.test", "results.0.creatorsName.0", "cn=admin,dc=example,dc=com"),
+					resource.TestCheckResourceAttrWith("data.ldap_search.test", "read_duration_ms", testCheckNonNegative),
+					resource.TestCheckResourceAttr("data.ldap_search.test", "values.0.dn", "dc=example,dc=com"),
 				),
 			},
 		},
@@ -29,3 +37,263 @@ data "ldap_search" "test" {
 	base_dn = "dc=example,dc=com"
 	additional_attributes = ["creatorsName"]
 }`
+
+func TestLDAPSearchDatasourcePartialResults(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testSearchDataSourcePartialResults(false),
+				ExpectError: regexp.MustCompile("Size Limit Exceeded"),
+			},
+			{
+				Config: testSearchDataSourcePartialResults(true),
+				Check:  resource.TestCheckResourceAttr("data.ldap_search.limited", "results.#", "2"),
+			},
+		},
+	})
+}
+
+// testSearchDataSourcePartialResults searches for 5 entries with a size limit of 2.
+func testSearchDataSourcePartialResults(partialResults bool) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "limited" {
+	dn = "ou=limited,dc=example,dc=com"
+	object_classes = ["organizationalUnit"]
+	attributes = {
+		"ou" = ["limited"]
+	}
+}
+
+resource "ldap_objects" "limited" {
+	base_dn = ldap_object.limited.dn
+	objects = { for i in range(5) : "cn=entry${i}" => {
+		object_classes = ["person"]
+		attributes = {
+			"cn" = ["entry${i}"]
+			"sn" = ["entry${i}"]
+		}
+	} }
+}
+
+data "ldap_search" "limited" {
+	base_dn = ldap_objects.limited.base_dn
+	scope = "singleLevel"
+	filter = "(objectClass=person)"
+	size_limit = 2
+	partial_results = %t
+}
+`, partialResults)
+}
+
+func TestLDAPSearchDatasourceMaxResults(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testSearchDataSourceMaxResults,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.ldap_search.capped", "results.#", "2"),
+					resource.TestCheckResourceAttr("data.ldap_search.capped", "values.#", "6"),
+				),
+			},
+		},
+	})
+}
+
+// testSearchDataSourceMaxResults reads only 2 of 5 matching entries with 3 values each (objectClass, cn and sn).
+const testSearchDataSourceMaxResults = `
+resource "ldap_object" "capped" {
+	dn = "ou=capped,dc=example,dc=com"
+	object_classes = ["organizationalUnit"]
+	attributes = {
+		"ou" = ["capped"]
+	}
+}
+
+resource "ldap_objects" "capped" {
+	base_dn = ldap_object.capped.dn
+	objects = { for i in range(5) : "cn=entry${i}" => {
+		object_classes = ["person"]
+		attributes = {
+			"cn" = ["entry${i}"]
+			"sn" = ["entry${i}"]
+		}
+	} }
+}
+
+data "ldap_search" "capped" {
+	base_dn = ldap_objects.capped.base_dn
+	scope = "singleLevel"
+	filter = "(objectClass=person)"
+	max_results = 2
+}
+`
+
+func TestLDAPSearchDatasourceDerefAliases(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testSearchDataSourceDerefAliases("never"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.ldap_search.aliases", "results.#", "0"),
+					resource.TestCheckResourceAttr("data.ldap_object.alias", "dn", "cn=alias,ou=aliases,dc=example,dc=com"),
+				),
+			},
+			{
+				Config: testSearchDataSourceDerefAliases("always"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.ldap_search.aliases", "results.#", "1"),
+					resource.TestCheckResourceAttr("data.ldap_search.aliases", "results.0.sn.0", "target"),
+					resource.TestCheckResourceAttr("data.ldap_object.alias", "dn", "cn=target,dc=example,dc=com"),
+				),
+			},
+		},
+	})
+}
+
+// testSearchDataSourceDerefAliases searches for persons below an entry which only contains an alias of a person.
+func testSearchDataSourceDerefAliases(derefAliases string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "target" {
+	dn = "cn=target,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["target"]
+		"sn" = ["target"]
+	}
+}
+
+resource "ldap_object" "aliases" {
+	dn = "ou=aliases,dc=example,dc=com"
+	object_classes = ["organizationalUnit"]
+	attributes = {
+		"ou" = ["aliases"]
+	}
+}
+
+resource "ldap_object" "alias" {
+	dn = "cn=alias,${ldap_object.aliases.dn}"
+	object_classes = ["alias", "extensibleObject"]
+	attributes = {
+		"cn" = ["alias"]
+		"aliasedObjectName" = [ldap_object.target.dn]
+	}
+}
+
+data "ldap_search" "aliases" {
+	base_dn = ldap_object.aliases.dn
+	scope = "singleLevel"
+	filter = "(objectClass=person)"
+	deref_aliases = %[1]q
+	depends_on = [ldap_object.alias]
+}
+
+data "ldap_object" "alias" {
+	dn = ldap_object.alias.dn
+	deref_aliases = %[1]q
+}
+`, derefAliases)
+}
+
+func TestLDAPSearchDatasourceFollowReferrals(t *testing.T) {
+	referral := fmt.Sprintf("%s/ou=referred,dc=example,dc=com", os.Getenv("LDAP_URL"))
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testSearchDataSourceFollowReferrals(false),
+			},
+			{
+				PreConfig: testAddReferralExternally(t, "ou=link,ou=referring,dc=example,dc=com", referral),
+				Config:    testSearchDataSourceFollowReferrals(true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.ldap_search.referring", "results.#", "1"),
+					resource.TestCheckResourceAttr("data.ldap_search.referring", "results.0.cn.0", "referred"),
+				),
+			},
+			{
+				PreConfig: testDeleteReferralExternally(t, "ou=link,ou=referring,dc=example,dc=com"),
+				Config:    testSearchDataSourceFollowReferrals(false),
+			},
+		},
+	})
+}
+
+// testSearchDataSourceFollowReferrals searches ou=referring, which contains a referral to ou=referred.
+func testSearchDataSourceFollowReferrals(search bool) string {
+	config := `
+resource "ldap_object" "referring" {
+	dn = "ou=referring,dc=example,dc=com"
+	object_classes = ["organizationalUnit"]
+}
+
+resource "ldap_object" "referred" {
+	dn = "ou=referred,dc=example,dc=com"
+	object_classes = ["organizationalUnit"]
+}
+
+resource "ldap_object" "referred_person" {
+	dn = "cn=referred,${ldap_object.referred.dn}"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["referred"]
+		"sn" = ["referred"]
+	}
+}
+`
+	if search {
+		config += `
+data "ldap_search" "referring" {
+	base_dn = ldap_object.referring.dn
+	scope = "wholeSubtree"
+	filter = "(objectClass=person)"
+	follow_referrals = true
+}
+`
+	}
+	return config
+}
+
+func testAddReferralExternally(t *testing.T, dn string, referral string) func() {
+	return testExternally(t, func(conn *ldap.Conn) error {
+		r := ldap.NewAddRequest(dn, []ldap.Control{ldap.NewControlManageDsaIT(true)})
+		r.Attribute("objectClass", []string{"referral", "extensibleObject"})
+		r.Attribute("ref", []string{referral})
+		if parsed, err := ldap.ParseDN(dn); err == nil {
+			r.Attribute(parsed.RDNs[0].Attributes[0].Type, []string{parsed.RDNs[0].Attributes[0].Value})
+		}
+		return conn.Add(r)
+	})
+}
+
+func testDeleteReferralExternally(t *testing.T, dn string) func() {
+	return testExternally(t, func(conn *ldap.Conn) error {
+		return conn.Del(ldap.NewDelRequest(dn, []ldap.Control{ldap.NewControlManageDsaIT(true)}))
+	})
+}
+
+func TestFlattenEntries(t *testing.T) {
+	entries := []*ldap.Entry{
+		ldap.NewEntry("cn=alice,dc=example,dc=com", map[string][]string{"cn": {"alice"}, "mail": {"alice@example.com", "a@example.com"}}),
+		ldap.NewEntry("cn=bob,dc=example,dc=com", map[string][]string{"cn": {"bob"}}),
+	}
+
+	values := flattenEntries(entries)
+	assert.Len(t, values, 4)
+	assert.Contains(t, values, LDAPSearchValueModel{
+		DN:        types.StringValue("cn=alice,dc=example,dc=com"),
+		Attribute: types.StringValue("mail"),
+		Value:     types.StringValue("a@example.com"),
+	})
+	assert.Empty(t, flattenEntries(nil))
+}
@@ -0,0 +1,510 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ObjectClassDefinition describes an object class as published in the subschema (RFC 4512, section 4.1.1).
+type ObjectClassDefinition struct {
+	OID       string
+	Names     []string
+	Superiors []string
+	Kind      string
+	Must      []string
+	May       []string
+}
+
+// AttributeTypeDefinition describes an attribute type as published in the subschema (RFC 4512, section 4.1.2).
+type AttributeTypeDefinition struct {
+	OID         string
+	Names       []string
+	Superior    string
+	Equality    string
+	Syntax      string
+	SingleValue bool
+	Operational bool
+	// NoUserModification is set for attribute types which are maintained by the server and can't be written by
+	// clients, unless they send the relax rules control.
+	NoUserModification bool
+}
+
+// Subschema holds the schema definitions published by the server.
+type Subschema struct {
+	ObjectClasses  map[string]ObjectClassDefinition
+	AttributeTypes map[string]AttributeTypeDefinition
+}
+
+// GetSubschema reads the subschema entry advertised by the root DSE.
+func GetSubschema(conn *ldap.Conn) (*Subschema, error) {
+	rootDSE, err := GetEntry(conn, "", "subschemaSubentry")
+	if err != nil {
+		return nil, fmt.Errorf("can not read root DSE: %s", err)
+	}
+
+	subschemaDN := rootDSE.GetAttributeValue("subschemaSubentry")
+	if subschemaDN == "" {
+		return nil, fmt.Errorf("server does not advertise a subschema entry")
+	}
+
+	entry, err := GetEntry(conn, subschemaDN, "objectClasses", "attributeTypes")
+	if err != nil {
+		return nil, fmt.Errorf("can not read subschema entry %s: %s", subschemaDN, err)
+	}
+
+	s := Subschema{
+		ObjectClasses:  map[string]ObjectClassDefinition{},
+		AttributeTypes: map[string]AttributeTypeDefinition{},
+	}
+
+	for _, description := range entry.GetAttributeValues("objectClasses") {
+		if definition, err := ParseObjectClassDefinition(description); err != nil {
+			return nil, err
+		} else {
+			for _, name := range definition.Names {
+				s.ObjectClasses[strings.ToLower(name)] = definition
+			}
+		}
+	}
+
+	for _, description := range entry.GetAttributeValues("attributeTypes") {
+		if definition, err := ParseAttributeTypeDefinition(description); err != nil {
+			return nil, err
+		} else {
+			for _, name := range definition.Names {
+				s.AttributeTypes[strings.ToLower(name)] = definition
+			}
+		}
+	}
+
+	return &s, nil
+}
+
+// AttributeType looks up an attribute type definition by name, ignoring case.
+func (s *Subschema) AttributeType(name string) (AttributeTypeDefinition, bool) {
+	definition, ok := s.AttributeTypes[strings.ToLower(name)]
+	return definition, ok
+}
+
+// equalityMatchingRules maps the equality matching rules of the subschema to the matching rules used to compare
+// values. The IA5 variants are compared like their Directory String counterparts.
+var equalityMatchingRules = map[string]string{
+	"booleanmatch":           matchingRuleBoolean,
+	"caseexactia5match":      matchingRuleCaseExact,
+	"caseexactmatch":         matchingRuleCaseExact,
+	"caseignoreia5match":     matchingRuleCaseIgnore,
+	"caseignorematch":        matchingRuleCaseIgnore,
+	"distinguishednamematch": matchingRuleDistinguishedName,
+	"integermatch":           matchingRuleInteger,
+	"octetstringmatch":       matchingRuleOctetString,
+	"telephonenumbermatch":   matchingRuleTelephoneNumber,
+}
+
+// EqualityMatchingRule returns the equality matching rule of the attribute type, which may be inherited from its
+// superiors. It returns an empty string if the attribute type is unknown or its matching rule isn't supported.
+func (s *Subschema) EqualityMatchingRule(attributeType string) string {
+	for i := 0; attributeType != "" && i < 10; i++ {
+		definition, ok := s.AttributeType(attributeType)
+		if !ok {
+			return ""
+		}
+		if definition.Equality != "" {
+			return equalityMatchingRules[strings.ToLower(definition.Equality)]
+		}
+		attributeType = definition.Superior
+	}
+	return ""
+}
+
+// subschemaCache reads the subschema of the server once and shares it between all resources.
+type subschemaCache struct {
+	once      sync.Once
+	subschema *Subschema
+	err       error
+}
+
+// get returns the subschema, reading it on the first call. An unconfigured cache never returns a subschema.
+func (c *subschemaCache) get(conn *ldap.Conn) (*Subschema, error) {
+	if c == nil {
+		return nil, fmt.Errorf("subschema not available")
+	}
+	c.once.Do(func() {
+		c.subschema, c.err = GetSubschema(conn)
+	})
+	return c.subschema, c.err
+}
+
+// sameAttributeType checks whether both names refer to the same attribute type, e.g. cn and commonName.
+func (s *Subschema) sameAttributeType(a string, b string) bool {
+	if strings.EqualFold(a, b) {
+		return true
+	}
+	definitionA, okA := s.AttributeType(a)
+	definitionB, okB := s.AttributeType(b)
+	return okA && okB && definitionA.OID == definitionB.OID
+}
+
+// containsAttributeType checks whether the list contains the attribute type under any of its names.
+func (s *Subschema) containsAttributeType(list []string, attributeType string) bool {
+	for _, v := range list {
+		if s.sameAttributeType(v, attributeType) {
+			return true
+		}
+	}
+	return false
+}
+
+// ObjectClass looks up an object class definition by name, ignoring case.
+func (s *Subschema) ObjectClass(name string) (ObjectClassDefinition, bool) {
+	definition, ok := s.ObjectClasses[strings.ToLower(name)]
+	return definition, ok
+}
+
+// SimilarObjectClasses returns the names of the object classes, which differ from the given name by at most two
+// characters, ignoring case.
+func (s *Subschema) SimilarObjectClasses(name string) []string {
+	var similar []string
+	for _, definition := range s.ObjectClasses {
+		for _, candidate := range definition.Names {
+			if !containsFold(similar, candidate) && editDistance(strings.ToLower(name), strings.ToLower(candidate)) <= 2 {
+				similar = append(similar, candidate)
+			}
+		}
+	}
+	sort.Strings(similar)
+	return similar
+}
+
+// StructuralConflict returns two structural object classes of the given ones, which are not derived from each other.
+func (s *Subschema) StructuralConflict(objectClasses []string) (string, string, bool) {
+	structural := StructuralObjectClasses(s, objectClasses)
+	for i, a := range structural {
+		for _, b := range structural[i+1:] {
+			if !s.isSubclass(a, b) && !s.isSubclass(b, a) {
+				return a, b, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// isSubclass checks whether the object class is derived from the given superior, directly or indirectly.
+func (s *Subschema) isSubclass(objectClass string, superior string) bool {
+	seen := map[string]bool{}
+	queue := []string{objectClass}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if strings.EqualFold(name, superior) {
+			return true
+		}
+		if seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		if definition, ok := s.ObjectClass(name); ok {
+			queue = append(queue, definition.Superiors...)
+		}
+	}
+	return false
+}
+
+// editDistance returns the Levenshtein distance of two strings.
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+// MissingAttributes returns the attributes required by the given object classes and their superiors, which are not
+// part of the given attribute types. Object classes unknown to the subschema are ignored.
+func (s *Subschema) MissingAttributes(objectClasses []string, attributeTypes []string) []string {
+	var missing []string
+	seen := map[string]bool{}
+	queue := append([]string{}, objectClasses...)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+
+		definition, ok := s.ObjectClass(name)
+		if !ok {
+			continue
+		}
+		queue = append(queue, definition.Superiors...)
+
+		for _, must := range definition.Must {
+			if strings.EqualFold(must, "objectClass") || s.containsAttributeType(attributeTypes, must) || s.containsAttributeType(missing, must) {
+				continue
+			}
+			missing = append(missing, must)
+		}
+	}
+	return missing
+}
+
+// DisallowedAttributes returns the attribute types, which are neither required nor allowed by the given object classes
+// and their superiors. Operational attribute types are never returned. Nothing is returned if one of the object classes
+// is unknown to the subschema or allows any attribute, like extensibleObject.
+func (s *Subschema) DisallowedAttributes(objectClasses []string, attributeTypes []string) []string {
+	var allowed []string
+	seen := map[string]bool{}
+	queue := append([]string{}, objectClasses...)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+
+		definition, ok := s.ObjectClass(name)
+		if !ok || strings.EqualFold(name, "extensibleObject") {
+			return nil
+		}
+		queue = append(queue, definition.Superiors...)
+		allowed = append(allowed, definition.Must...)
+		allowed = append(allowed, definition.May...)
+	}
+
+	var disallowed []string
+	for _, attributeType := range attributeTypes {
+		if strings.EqualFold(attributeType, "objectClass") || s.allowsAttributeType(allowed, attributeType) || containsFold(disallowed, attributeType) {
+			continue
+		}
+		if definition, ok := s.AttributeType(attributeType); ok && definition.Operational {
+			continue
+		}
+		disallowed = append(disallowed, attributeType)
+	}
+	return disallowed
+}
+
+// allowsAttributeType checks whether the attribute type or one of its superiors is part of the allowed attribute
+// types.
+func (s *Subschema) allowsAttributeType(allowed []string, attributeType string) bool {
+	for i := 0; attributeType != "" && i < 10; i++ {
+		if s.containsAttributeType(allowed, attributeType) {
+			return true
+		}
+		definition, ok := s.AttributeType(attributeType)
+		if !ok {
+			return false
+		}
+		attributeType = definition.Superior
+	}
+	return false
+}
+
+// containsFold checks whether the list contains the value, ignoring case.
+func containsFold(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseObjectClassDefinition parses an ObjectClassDescription value.
+func ParseObjectClassDefinition(description string) (ObjectClassDefinition, error) {
+	oid, fields, err := parseSchemaDescription(description)
+	if err != nil {
+		return ObjectClassDefinition{}, err
+	}
+
+	definition := ObjectClassDefinition{
+		OID:       oid,
+		Names:     fields["NAME"],
+		Superiors: fields["SUP"],
+		Kind:      "STRUCTURAL",
+		Must:      fields["MUST"],
+		May:       fields["MAY"],
+	}
+
+	for _, kind := range []string{"ABSTRACT", "AUXILIARY", "STRUCTURAL"} {
+		if _, ok := fields[kind]; ok {
+			definition.Kind = kind
+		}
+	}
+
+	return definition, nil
+}
+
+// ParseAttributeTypeDefinition parses an AttributeTypeDescription value.
+func ParseAttributeTypeDefinition(description string) (AttributeTypeDefinition, error) {
+	oid, fields, err := parseSchemaDescription(description)
+	if err != nil {
+		return AttributeTypeDefinition{}, err
+	}
+
+	definition := AttributeTypeDefinition{
+		OID:   oid,
+		Names: fields["NAME"],
+	}
+	if len(fields["SUP"]) > 0 {
+		definition.Superior = fields["SUP"][0]
+	}
+	if len(fields["EQUALITY"]) > 0 {
+		definition.Equality = fields["EQUALITY"][0]
+	}
+	if len(fields["SYNTAX"]) > 0 {
+		// the syntax may be followed by a length limit like {64}
+		definition.Syntax = strings.SplitN(fields["SYNTAX"][0], "{", 2)[0]
+	}
+	_, definition.SingleValue = fields["SINGLE-VALUE"]
+	// operational attributes are maintained by the server and not governed by object classes
+	definition.Operational = len(fields["USAGE"]) > 0 && fields["USAGE"][0] != "userApplications"
+	_, definition.NoUserModification = fields["NO-USER-MODIFICATION"]
+
+	return definition, nil
+}
+
+// schemaToken is a single token of a schema description. Quoted strings are never keywords.
+type schemaToken struct {
+	value  string
+	quoted bool
+}
+
+// parseSchemaDescription splits a schema description like ( 2.5.6.6 NAME 'person' SUP top STRUCTURAL MUST ( sn $ cn ) )
+// into its numeric OID and a map of keywords to their values. Keywords without a value map to an empty list.
+func parseSchemaDescription(description string) (string, map[string][]string, error) {
+	tokens, err := tokenizeSchemaDescription(description)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(tokens) < 3 || !tokens[0].is("(") || !tokens[len(tokens)-1].is(")") {
+		return "", nil, fmt.Errorf("invalid schema description %q", description)
+	}
+
+	oid := tokens[1].value
+	fields := map[string][]string{}
+	tokens = tokens[2 : len(tokens)-1]
+
+	for len(tokens) > 0 {
+		keyword := tokens[0].value
+		tokens = tokens[1:]
+		values := []string{}
+
+		if len(tokens) > 0 && tokens[0].is("(") {
+			end := -1
+			for i, token := range tokens {
+				if token.is(")") {
+					end = i
+					break
+				}
+			}
+			if end == -1 {
+				return "", nil, fmt.Errorf("unbalanced parentheses in schema description %q", description)
+			}
+			for _, token := range tokens[1:end] {
+				if !token.is("$") {
+					values = append(values, token.value)
+				}
+			}
+			tokens = tokens[end+1:]
+		} else if len(tokens) > 0 && !tokens[0].isKeyword() {
+			values = append(values, tokens[0].value)
+			tokens = tokens[1:]
+		}
+
+		fields[keyword] = values
+	}
+
+	return oid, fields, nil
+}
+
+// tokenizeSchemaDescription splits a schema description into parentheses, dollar signs, quoted strings and words.
+func tokenizeSchemaDescription(description string) ([]schemaToken, error) {
+	var tokens []schemaToken
+
+	for i := 0; i < len(description); {
+		switch c := description[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')' || c == '$':
+			tokens = append(tokens, schemaToken{value: string(c)})
+			i++
+		case c == '\'':
+			end := strings.IndexByte(description[i+1:], '\'')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated quoted string in schema description %q", description)
+			}
+			tokens = append(tokens, schemaToken{value: description[i+1 : i+1+end], quoted: true})
+			i += end + 2
+		default:
+			end := strings.IndexAny(description[i:], " \t\n()$'")
+			if end == -1 {
+				end = len(description) - i
+			}
+			tokens = append(tokens, schemaToken{value: description[i : i+end]})
+			i += end
+		}
+	}
+
+	return tokens, nil
+}
+
+func (t schemaToken) is(value string) bool {
+	return !t.quoted && t.value == value
+}
+
+// isKeyword checks whether a token is a keyword of a schema description rather than a value. Keywords are
+// written in upper case, while unquoted values are OIDs or names of object classes and attribute types.
+func (t schemaToken) isKeyword() bool {
+	return !t.quoted && t.value == strings.ToUpper(t.value) && strings.ToLower(t.value) != t.value
+}
+
+// knownStructuralObjectClasses lists common structural object classes, which are used when the subschema can't be read.
+var knownStructuralObjectClasses = []string{
+	"account", "applicationProcess", "computer", "container", "country", "device", "domain", "group",
+	"groupOfNames", "groupOfUniqueNames", "inetOrgPerson", "locality", "organization", "organizationalPerson",
+	"organizationalRole", "organizationalUnit", "person", "posixGroup", "residentialPerson", "user",
+}
+
+// StructuralObjectClasses filters the given object classes for structural ones. If no subschema is available, a
+// list of well-known structural object classes is used instead.
+func StructuralObjectClasses(subschema *Subschema, objectClasses []string) []string {
+	var structural []string
+	for _, objectClass := range objectClasses {
+		if subschema != nil {
+			if definition, ok := subschema.ObjectClass(objectClass); ok && definition.Kind == "STRUCTURAL" {
+				structural = append(structural, strings.ToLower(objectClass))
+			}
+		} else {
+			for _, known := range knownStructuralObjectClasses {
+				if strings.EqualFold(known, objectClass) {
+					structural = append(structural, strings.ToLower(objectClass))
+				}
+			}
+		}
+	}
+	return structural
+}
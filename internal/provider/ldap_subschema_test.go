@@ -0,0 +1,192 @@
+package provider
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestParseObjectClassDefinition(t *testing.T) {
+	definition, err := ParseObjectClassDefinition("( 2.5.6.6 NAME 'person' DESC 'RFC2256: a person' SUP top STRUCTURAL MUST ( sn $ cn ) MAY ( userPassword $ telephoneNumber $ seeAlso $ description ) )")
+	assert.NoError(t, err)
+	assert.Equal(t, "2.5.6.6", definition.OID)
+	assert.Equal(t, []string{"person"}, definition.Names)
+	assert.Equal(t, []string{"top"}, definition.Superiors)
+	assert.Equal(t, "STRUCTURAL", definition.Kind)
+	assert.Equal(t, []string{"sn", "cn"}, definition.Must)
+	assert.Equal(t, []string{"userPassword", "telephoneNumber", "seeAlso", "description"}, definition.May)
+
+	definition, err = ParseObjectClassDefinition("( 1.3.6.1.4.1.1466.101.120.111 NAME ( 'extensibleObject' 'EXTENSIBLEOBJECT' ) DESC 'RFC4512: extensible object' SUP top AUXILIARY )")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"extensibleObject", "EXTENSIBLEOBJECT"}, definition.Names)
+	assert.Equal(t, "AUXILIARY", definition.Kind)
+	assert.Empty(t, definition.Must)
+
+	definition, err = ParseObjectClassDefinition("( 2.5.6.0 NAME 'top' ABSTRACT MUST objectClass )")
+	assert.NoError(t, err)
+	assert.Equal(t, "ABSTRACT", definition.Kind)
+	assert.Equal(t, []string{"objectClass"}, definition.Must)
+
+	_, err = ParseObjectClassDefinition("( 2.5.6.0 NAME 'top")
+	assert.Error(t, err)
+
+	_, err = ParseObjectClassDefinition("( 2.5.6.0 NAME 'top' MUST ( objectClass )")
+	assert.Error(t, err)
+}
+
+func TestStructuralObjectClasses(t *testing.T) {
+	subschema := &Subschema{
+		ObjectClasses: map[string]ObjectClassDefinition{
+			"person":           {Names: []string{"person"}, Kind: "STRUCTURAL"},
+			"extensibleobject": {Names: []string{"extensibleObject"}, Kind: "AUXILIARY"},
+			"top":              {Names: []string{"top"}, Kind: "ABSTRACT"},
+		},
+	}
+	assert.Equal(t, []string{"person"}, StructuralObjectClasses(subschema, []string{"top", "Person", "extensibleObject"}))
+	assert.Equal(t, []string{"inetorgperson"}, StructuralObjectClasses(nil, []string{"inetOrgPerson", "posixAccount"}))
+}
+
+func TestMissingAttributes(t *testing.T) {
+	subschema := &Subschema{
+		ObjectClasses: map[string]ObjectClassDefinition{
+			"top":                  {Names: []string{"top"}, Kind: "ABSTRACT", Must: []string{"objectClass"}},
+			"person":               {Names: []string{"person"}, Superiors: []string{"top"}, Kind: "STRUCTURAL", Must: []string{"sn", "cn"}},
+			"organizationalperson": {Names: []string{"organizationalPerson"}, Superiors: []string{"person"}, Kind: "STRUCTURAL"},
+			"posixaccount":         {Names: []string{"posixAccount"}, Superiors: []string{"top"}, Kind: "AUXILIARY", Must: []string{"cn", "uid", "uidNumber"}},
+		},
+	}
+
+	assert.Equal(t, []string{"sn"}, subschema.MissingAttributes([]string{"organizationalPerson"}, []string{"objectClass", "CN"}))
+	assert.Equal(t, []string{"uid", "uidNumber"}, subschema.MissingAttributes([]string{"person", "posixAccount"}, []string{"cn", "sn"}))
+	assert.Empty(t, subschema.MissingAttributes([]string{"person", "unknown"}, []string{"cn", "sn"}))
+}
+
+func TestParseAttributeTypeDefinition(t *testing.T) {
+	definition, err := ParseAttributeTypeDefinition("( 2.5.4.3 NAME ( 'cn' 'commonName' ) DESC 'RFC4519: common name(s) for which the entity is known by' SUP name )")
+	assert.NoError(t, err)
+	assert.Equal(t, "2.5.4.3", definition.OID)
+	assert.Equal(t, []string{"cn", "commonName"}, definition.Names)
+	assert.Equal(t, "name", definition.Superior)
+	assert.False(t, definition.SingleValue)
+
+	definition, err = ParseAttributeTypeDefinition("( 1.3.6.1.1.1.1.0 NAME 'uidNumber' DESC 'RFC2307: An integer uniquely identifying a user in an administrative domain' EQUALITY integerMatch ORDERING integerOrderingMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.27 SINGLE-VALUE )")
+	assert.NoError(t, err)
+	assert.Equal(t, "integerMatch", definition.Equality)
+	assert.Equal(t, "1.3.6.1.4.1.1466.115.121.1.27", definition.Syntax)
+	assert.True(t, definition.SingleValue)
+
+	definition, err = ParseAttributeTypeDefinition("( 2.5.4.41 NAME 'name' EQUALITY caseIgnoreMatch SUBSTR caseIgnoreSubstringsMatch SYNTAX 1.3.6.1.4.1.1466.115.121.1.15{32768} )")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.3.6.1.4.1.1466.115.121.1.15", definition.Syntax)
+	assert.False(t, definition.Operational)
+	assert.False(t, definition.NoUserModification)
+
+	definition, err = ParseAttributeTypeDefinition("( 1.3.6.1.1.16.4 NAME 'entryUUID' DESC 'UUID of the entry' EQUALITY UUIDMatch ORDERING UUIDOrderingMatch SYNTAX 1.3.6.1.1.16.1 SINGLE-VALUE NO-USER-MODIFICATION USAGE directoryOperation )")
+	assert.NoError(t, err)
+	assert.True(t, definition.Operational)
+	assert.True(t, definition.NoUserModification)
+}
+
+func testSubschemaWithAttributeTypes() *Subschema {
+	subschema := &Subschema{
+		ObjectClasses: map[string]ObjectClassDefinition{
+			"top":              {Names: []string{"top"}, Kind: "ABSTRACT", Must: []string{"objectClass"}},
+			"person":           {Names: []string{"person"}, Superiors: []string{"top"}, Kind: "STRUCTURAL", Must: []string{"sn", "cn"}, May: []string{"description"}},
+			"extensibleobject": {Names: []string{"extensibleObject"}, Superiors: []string{"top"}, Kind: "AUXILIARY"},
+		},
+		AttributeTypes: map[string]AttributeTypeDefinition{},
+	}
+	for _, definition := range []AttributeTypeDefinition{
+		{OID: "2.5.4.41", Names: []string{"name"}},
+		{OID: "2.5.4.3", Names: []string{"cn", "commonName"}, Superior: "name"},
+		{OID: "2.5.4.4", Names: []string{"sn", "surname"}, Superior: "name"},
+		{OID: "2.5.4.13", Names: []string{"description"}},
+		{OID: "0.9.2342.19200300.100.1.3", Names: []string{"mail", "rfc822Mailbox"}},
+		{OID: "1.3.6.1.1.16.4", Names: []string{"entryUUID"}, Operational: true},
+	} {
+		for _, name := range definition.Names {
+			subschema.AttributeTypes[strings.ToLower(name)] = definition
+		}
+	}
+	return subschema
+}
+
+func TestMissingAttributesAlternativeNames(t *testing.T) {
+	subschema := testSubschemaWithAttributeTypes()
+
+	assert.Empty(t, subschema.MissingAttributes([]string{"person"}, []string{"commonName", "surname"}))
+	assert.Equal(t, []string{"sn"}, subschema.MissingAttributes([]string{"person"}, []string{"commonName"}))
+}
+
+func TestDisallowedAttributes(t *testing.T) {
+	subschema := testSubschemaWithAttributeTypes()
+
+	assert.Empty(t, subschema.DisallowedAttributes([]string{"person"}, []string{"objectClass", "commonName", "sn", "description"}))
+	assert.Equal(t, []string{"mail"}, subschema.DisallowedAttributes([]string{"person"}, []string{"cn", "sn", "mail"}))
+	assert.Empty(t, subschema.DisallowedAttributes([]string{"person"}, []string{"cn", "sn", "entryUUID"}))
+
+	// any attribute is allowed by extensibleObject, unknown object classes can't be checked
+	assert.Empty(t, subschema.DisallowedAttributes([]string{"person", "extensibleObject"}, []string{"cn", "sn", "mail"}))
+	assert.Empty(t, subschema.DisallowedAttributes([]string{"person", "unknown"}, []string{"cn", "sn", "mail"}))
+}
+
+func TestSimilarObjectClasses(t *testing.T) {
+	subschema := &Subschema{
+		ObjectClasses: map[string]ObjectClassDefinition{
+			"inetorgperson":      {Names: []string{"inetOrgPerson"}},
+			"person":             {Names: []string{"person"}},
+			"organizationalunit": {Names: []string{"organizationalUnit"}},
+		},
+	}
+
+	assert.Equal(t, []string{"inetOrgPerson"}, subschema.SimilarObjectClasses("inetOrgPersom"))
+	assert.Equal(t, []string{"person"}, subschema.SimilarObjectClasses("persn"))
+	assert.Empty(t, subschema.SimilarObjectClasses("device"))
+}
+
+func TestStructuralConflict(t *testing.T) {
+	subschema := &Subschema{
+		ObjectClasses: map[string]ObjectClassDefinition{
+			"top":                  {Names: []string{"top"}, Kind: "ABSTRACT"},
+			"person":               {Names: []string{"person"}, Superiors: []string{"top"}, Kind: "STRUCTURAL"},
+			"organizationalperson": {Names: []string{"organizationalPerson"}, Superiors: []string{"person"}, Kind: "STRUCTURAL"},
+			"inetorgperson":        {Names: []string{"inetOrgPerson"}, Superiors: []string{"organizationalPerson"}, Kind: "STRUCTURAL"},
+			"organizationalunit":   {Names: []string{"organizationalUnit"}, Superiors: []string{"top"}, Kind: "STRUCTURAL"},
+			"posixaccount":         {Names: []string{"posixAccount"}, Superiors: []string{"top"}, Kind: "AUXILIARY"},
+		},
+	}
+
+	_, _, conflict := subschema.StructuralConflict([]string{"top", "person", "inetOrgPerson", "posixAccount"})
+	assert.False(t, conflict)
+
+	a, b, conflict := subschema.StructuralConflict([]string{"person", "organizationalUnit"})
+	assert.True(t, conflict)
+	assert.Equal(t, "person", a)
+	assert.Equal(t, "organizationalunit", b)
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("person", "person"))
+	assert.Equal(t, 1, editDistance("inetorgpersom", "inetorgperson"))
+	assert.Equal(t, 2, editDistance("persno", "person"))
+	assert.Equal(t, 3, editDistance("", "top"))
+}
+
+func TestEqualityMatchingRule(t *testing.T) {
+	subschema := &Subschema{
+		AttributeTypes: map[string]AttributeTypeDefinition{
+			"name":        {Names: []string{"name"}, Equality: "caseIgnoreMatch"},
+			"description": {Names: []string{"description"}, Equality: "caseIgnoreMatch"},
+			"info":        {Names: []string{"info"}, Superior: "name"},
+			"mail":        {Names: []string{"mail"}, Equality: "caseIgnoreIA5Match"},
+			"photo":       {Names: []string{"photo"}},
+			"x500uid":     {Names: []string{"x500uid"}, Equality: "uniqueMemberMatch"},
+		},
+	}
+	assert.Equal(t, matchingRuleCaseIgnore, subschema.EqualityMatchingRule("Description"))
+	assert.Equal(t, matchingRuleCaseIgnore, subschema.EqualityMatchingRule("info"), "inherited from the superior")
+	assert.Equal(t, matchingRuleCaseIgnore, subschema.EqualityMatchingRule("mail"))
+	assert.Equal(t, "", subschema.EqualityMatchingRule("photo"))
+	assert.Equal(t, "", subschema.EqualityMatchingRule("x500uid"), "unsupported matching rule")
+	assert.Equal(t, "", subschema.EqualityMatchingRule("unknown"))
+}
@@ -0,0 +1,249 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"time"
+)
+
+var _ datasource.DataSource = &LDAPSyncDataSource{}
+var _ datasource.DataSourceWithConfigure = &LDAPSyncDataSource{}
+
+func NewLDAPSyncDataSource() datasource.DataSource {
+	return &LDAPSyncDataSource{}
+}
+
+type LDAPSyncDataSource struct {
+	conn   *ldap.Conn
+	client *ldapClient
+}
+
+type LDAPSyncDatasourceModel struct {
+	Id         types.String `tfsdk:"id"`
+	BaseDN     types.String `tfsdk:"base_dn"`
+	Scope      types.String `tfsdk:"scope"`
+	Filter     types.String `tfsdk:"filter"`
+	Cookie     types.String `tfsdk:"cookie"`
+	SyncCookie types.String `tfsdk:"sync_cookie"`
+	Changes    types.List   `tfsdk:"changes"`
+}
+
+// LDAPSyncChangeModel describes an entry reported as changed by the content synchronization.
+type LDAPSyncChangeModel struct {
+	DN        types.String `tfsdk:"dn"`
+	EntryUUID types.String `tfsdk:"entry_uuid"`
+	State     types.String `tfsdk:"state"`
+}
+
+// syncStates names the states of the sync state control reported in changes.
+var syncStates = map[ldap.ControlSyncStateState]string{
+	ldap.SyncStateAdd:    "add",
+	ldap.SyncStateModify: "modify",
+	ldap.SyncStateDelete: "delete",
+}
+
+func (L *LDAPSyncDataSource) Metadata(_ context.Context, request datasource.MetadataRequest, response *datasource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_sync"
+}
+
+func (L *LDAPSyncDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, response *datasource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		MarkdownDescription: "Detects changes below a base DN using the content synchronization operation (RFC 4533) in refresh only mode, e.g. provided by the syncprov overlay of OpenLDAP. Without `cookie`, the initial content is synchronized and all entries are returned as added. Passing the `sync_cookie` of a previous read as `cookie` only returns the entries changed since then, so changes are detected without reading all entries again",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Datasource identifier",
+			},
+			"base_dn": schema.StringAttribute{
+				MarkdownDescription: "Base DN of the synchronized entries",
+				Required:            true,
+				Validators: []validator.String{
+					IsValidDN(),
+				},
+			},
+			"scope": schema.StringAttribute{
+				MarkdownDescription: "Scope of the synchronized entries, defaults to `wholeSubtree`",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("baseObject", "singleLevel", "wholeSubtree"),
+				},
+			},
+			"filter": schema.StringAttribute{
+				MarkdownDescription: "Filter of the synchronized entries",
+				Optional:            true,
+				Validators: []validator.String{
+					IsValidFilter(),
+				},
+			},
+			"cookie": schema.StringAttribute{
+				MarkdownDescription: "The `sync_cookie` of a previous read, to only return the changes since then",
+				Optional:            true,
+			},
+			"sync_cookie": schema.StringAttribute{
+				MarkdownDescription: "Base64 encoded cookie returned by the server, describing the synchronized state",
+				Computed:            true,
+			},
+			"changes": schema.ListNestedAttribute{
+				MarkdownDescription: "The entries added, modified or deleted since `cookie`",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"dn": schema.StringAttribute{
+							MarkdownDescription: "DN of the entry. Empty for deleted entries only reported by their UUID",
+							Computed:            true,
+						},
+						"entry_uuid": schema.StringAttribute{
+							MarkdownDescription: "UUID of the entry",
+							Computed:            true,
+						},
+						"state": schema.StringAttribute{
+							MarkdownDescription: "How the entry changed: `add`, `modify` or `delete`",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (L *LDAPSyncDataSource) Configure(_ context.Context, request datasource.ConfigureRequest, response *datasource.ConfigureResponse) {
+	if request.ProviderData == nil {
+		return
+	}
+
+	if client, ok := request.ProviderData.(*ldapClient); !ok {
+		response.Diagnostics.AddError(
+			"Unexpected Datasource Configure Type",
+			fmt.Sprintf("Expected *ldapClient, got: %T. Please report this issue to the provider developers.", request.ProviderData),
+		)
+
+		return
+	} else {
+		L.conn = client.conn
+		L.client = client
+	}
+}
+
+func (L *LDAPSyncDataSource) Read(ctx context.Context, request datasource.ReadRequest, response *datasource.ReadResponse) {
+	var data LDAPSyncDatasourceModel
+	response.Diagnostics.Append(request.Config.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	scope := ldap.ScopeWholeSubtree
+	switch data.Scope.ValueString() {
+	case "baseObject":
+		scope = ldap.ScopeBaseObject
+	case "singleLevel":
+		scope = ldap.ScopeSingleLevel
+	}
+	filter := "(objectClass=*)"
+	if !data.Filter.IsNull() {
+		filter = data.Filter.ValueString()
+	}
+
+	cookie, err := base64.StdEncoding.DecodeString(data.Cookie.ValueString())
+	if err != nil {
+		response.Diagnostics.AddAttributeError(
+			path.Root("cookie"),
+			"Invalid cookie",
+			fmt.Sprintf("The cookie has to be the sync_cookie of a previous read: %s", err),
+		)
+		return
+	}
+	if err := L.client.controlSupport.check(L.conn, []ldap.Control{ldap.NewControlSyncRequest(ldap.SyncRequestModeRefreshOnly, cookie, false)}); err != nil {
+		addControlSupportError(&response.Diagnostics, err)
+		return
+	}
+
+	// only the DNs are needed, the sync state control identifies the changed entries
+	s := ldap.NewSearchRequest(data.BaseDN.ValueString(), scope, ldap.NeverDerefAliases, 0, 0, false, filter, []string{"1.1"}, nil)
+	start := time.Now()
+	changes, syncCookie, err := collectSyncChanges(L.conn.Syncrepl(ctx, s, 0, ldap.SyncRequestModeRefreshOnly, cookie, false))
+	LogOperation(ctx, "sync", data.BaseDN.ValueString(), start)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Can not synchronize content",
+			fmt.Sprintf("The content synchronization of %s returned: %s", data.BaseDN.ValueString(), err),
+		)
+		return
+	}
+	if syncCookie == nil {
+		syncCookie = cookie
+	}
+
+	data.Id = types.StringValue(fmt.Sprintf("%s/%s/%s", data.BaseDN.ValueString(), data.Scope.ValueString(), filter))
+	data.SyncCookie = types.StringValue(base64.StdEncoding.EncodeToString(syncCookie))
+	value, d := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: map[string]attr.Type{
+		"dn":         types.StringType,
+		"entry_uuid": types.StringType,
+		"state":      types.StringType,
+	}}, changes)
+	response.Diagnostics.Append(d...)
+	data.Changes = value
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+// collectSyncChanges reads the results of a content synchronization in refresh only mode. It returns the added,
+// modified and deleted entries together with the last cookie sent by the server, which is nil if none was sent.
+// Entries reported as present are unchanged and skipped.
+func collectSyncChanges(r ldap.Response) ([]LDAPSyncChangeModel, []byte, error) {
+	changes := []LDAPSyncChangeModel{}
+	var cookie []byte
+	for r.Next() {
+		for _, control := range r.Controls() {
+			switch c := control.(type) {
+			case *ldap.ControlSyncState:
+				if c.Cookie != nil {
+					cookie = c.Cookie
+				}
+				if state, changed := syncStates[c.State]; changed && r.Entry() != nil {
+					changes = append(changes, LDAPSyncChangeModel{
+						DN:        types.StringValue(r.Entry().DN),
+						EntryUUID: types.StringValue(c.EntryUUID.String()),
+						State:     types.StringValue(state),
+					})
+				}
+			case *ldap.ControlSyncInfo:
+				switch {
+				case c.NewCookie != nil:
+					cookie = c.NewCookie.Cookie
+				case c.RefreshDelete != nil && c.RefreshDelete.Cookie != nil:
+					cookie = c.RefreshDelete.Cookie
+				case c.RefreshPresent != nil && c.RefreshPresent.Cookie != nil:
+					cookie = c.RefreshPresent.Cookie
+				case c.SyncIdSet != nil:
+					if c.SyncIdSet.Cookie != nil {
+						cookie = c.SyncIdSet.Cookie
+					}
+					// deleted entries may be reported by their UUID only
+					if c.SyncIdSet.RefreshDeletes {
+						for _, entryUUID := range c.SyncIdSet.SyncUUIDs {
+							changes = append(changes, LDAPSyncChangeModel{
+								DN:        types.StringValue(""),
+								EntryUUID: types.StringValue(entryUUID.String()),
+								State:     types.StringValue("delete"),
+							})
+						}
+					}
+				}
+			case *ldap.ControlSyncDone:
+				if c.Cookie != nil {
+					cookie = c.Cookie
+				}
+			}
+		}
+	}
+	return changes, cookie, r.Err()
+}
@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"errors"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLDAPSyncDatasource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !testServerSupportsControl(ldap.ControlTypeSyncRequest) {
+				t.Skip("server does not support the content synchronization operation")
+			}
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "ldap_sync" "initial" {
+	base_dn = "dc=example,dc=com"
+	scope = "baseObject"
+}
+
+data "ldap_sync" "since" {
+	base_dn = "dc=example,dc=com"
+	scope = "baseObject"
+	cookie = data.ldap_sync.initial.sync_cookie
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					// the initial content is returned as added
+					resource.TestCheckResourceAttrSet("data.ldap_sync.initial", "sync_cookie"),
+					resource.TestCheckResourceAttr("data.ldap_sync.initial", "changes.#", "1"),
+					resource.TestCheckResourceAttr("data.ldap_sync.initial", "changes.0.dn", "dc=example,dc=com"),
+					resource.TestCheckResourceAttr("data.ldap_sync.initial", "changes.0.state", "add"),
+					// nothing changed since the initial synchronization
+					resource.TestCheckResourceAttrSet("data.ldap_sync.since", "sync_cookie"),
+					resource.TestCheckResourceAttr("data.ldap_sync.since", "changes.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+// testSyncResponse replays the given results of a content synchronization.
+type testSyncResponse struct {
+	results []*ldap.SearchSingleResult
+	current *ldap.SearchSingleResult
+	err     error
+}
+
+func (r *testSyncResponse) Entry() *ldap.Entry {
+	return r.current.Entry
+}
+
+func (r *testSyncResponse) Referral() string {
+	return r.current.Referral
+}
+
+func (r *testSyncResponse) Controls() []ldap.Control {
+	return r.current.Controls
+}
+
+func (r *testSyncResponse) Err() error {
+	return r.err
+}
+
+func (r *testSyncResponse) Next() bool {
+	if len(r.results) == 0 {
+		return false
+	}
+	r.current, r.results = r.results[0], r.results[1:]
+	return true
+}
+
+func TestCollectSyncChanges(t *testing.T) {
+	added, deleted := uuid.New(), uuid.New()
+	changes, cookie, err := collectSyncChanges(&testSyncResponse{results: []*ldap.SearchSingleResult{
+		{
+			Entry:    ldap.NewEntry("cn=added,dc=example,dc=com", nil),
+			Controls: []ldap.Control{&ldap.ControlSyncState{State: ldap.SyncStateAdd, EntryUUID: added}},
+		},
+		{
+			Entry:    ldap.NewEntry("cn=unchanged,dc=example,dc=com", nil),
+			Controls: []ldap.Control{&ldap.ControlSyncState{State: ldap.SyncStatePresent, EntryUUID: uuid.New()}},
+		},
+		{
+			Controls: []ldap.Control{&ldap.ControlSyncInfo{SyncIdSet: &ldap.ControlSyncInfoSyncIdSet{
+				Cookie:         []byte("first"),
+				RefreshDeletes: true,
+				SyncUUIDs:      []uuid.UUID{deleted},
+			}}},
+		},
+		{
+			Controls: []ldap.Control{&ldap.ControlSyncDone{Cookie: []byte("last")}},
+		},
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("last"), cookie)
+	assert.Equal(t, []LDAPSyncChangeModel{
+		{DN: types.StringValue("cn=added,dc=example,dc=com"), EntryUUID: types.StringValue(added.String()), State: types.StringValue("add")},
+		{DN: types.StringValue(""), EntryUUID: types.StringValue(deleted.String()), State: types.StringValue("delete")},
+	}, changes)
+
+	_, cookie, err = collectSyncChanges(&testSyncResponse{err: errors.New("failed")})
+	assert.Error(t, err)
+	assert.Nil(t, cookie)
+}
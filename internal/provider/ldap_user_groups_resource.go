@@ -0,0 +1,340 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"time"
+)
+
+var _ resource.Resource = &LDAPUserGroupsResource{}
+var _ resource.ResourceWithConfigure = &LDAPUserGroupsResource{}
+
+func NewLDAPUserGroupsResource() resource.Resource {
+	return &LDAPUserGroupsResource{}
+}
+
+type LDAPUserGroupsResource struct {
+	conn  *ldap.Conn
+	locks *dnLocks
+}
+
+type LDAPUserGroupsResourceModel struct {
+	ID       types.String `tfsdk:"id"`
+	UserDN   types.String `tfsdk:"user_dn"`
+	GroupDNs types.Set    `tfsdk:"group_dns"`
+}
+
+// userGroup describes how the user is a member of a group.
+type userGroup struct {
+	memberAttribute string
+	// member is the value of the member attribute referring to the user
+	member string
+	// primary is set for the primary group of an Active Directory user, whose membership is stored in the
+	// primaryGroupID attribute of the user instead of the member attribute of the group
+	primary bool
+}
+
+func (L *LDAPUserGroupsResource) Configure(_ context.Context, request resource.ConfigureRequest, response *resource.ConfigureResponse) {
+	if request.ProviderData == nil {
+		return
+	}
+
+	if client, ok := request.ProviderData.(*ldapClient); !ok {
+		response.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ldapClient, got: %T. Please report this issue to the provider developers.", request.ProviderData),
+		)
+
+		return
+	} else {
+		L.conn = client.conn
+		L.locks = client.locks
+	}
+}
+
+func (L *LDAPUserGroupsResource) Metadata(_ context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_user_groups"
+}
+
+func (L *LDAPUserGroupsResource) Schema(_ context.Context, _ resource.SchemaRequest, response *resource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		MarkdownDescription: "Manages the groups a single user is a member of. The user is added to the listed groups and removed from " +
+			"groups removed from the list. Destroying the resource removes the user from all listed groups. Groups not listed are left " +
+			"untouched. The member attribute is detected per group: `member` for groupOfNames and Active Directory groups, `uniqueMember` for " +
+			"groupOfUniqueNames and `memberUid` with the `uid` of the user for posixGroups. Groups with ambiguous object classes are refused. " +
+			"The primary group of an Active Directory user can't be changed through the member attribute and is skipped",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Resource identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"user_dn": schema.StringAttribute{
+				MarkdownDescription: "DN of the user",
+				Required:            true,
+				Validators: []validator.String{
+					IsValidDN(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"group_dns": schema.SetAttribute{
+				MarkdownDescription: "DNs of the groups the user is a member of",
+				Required:            true,
+				ElementType:         types.StringType,
+			},
+		},
+	}
+}
+
+func (L *LDAPUserGroupsResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	var data *LDAPUserGroupsResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &data)...)
+	var groupDNs []string
+	response.Diagnostics.Append(data.GroupDNs.ElementsAs(ctx, &groupDNs, false)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = data.UserDN
+	L.modifyMemberships(ctx, "create", data.UserDN.ValueString(), groupDNs, nil, &response.Diagnostics)
+	if response.Diagnostics.HasError() {
+		return
+	}
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+// Read checks the membership in each group of the state with a compare operation. Groups which were deleted or don't
+// contain the user anymore are removed from the state.
+func (L *LDAPUserGroupsResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	var data *LDAPUserGroupsResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	var groupDNs []string
+	response.Diagnostics.Append(data.GroupDNs.ElementsAs(ctx, &groupDNs, false)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	userDN := data.UserDN.ValueString()
+	primaryGroupID, err := L.primaryGroupID(ctx, userDN)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) || errors.Is(err, ErrEntryNotFound) {
+		tflog.Warn(ctx, "User was deleted outside of Terraform, removing it from the state", map[string]interface{}{"dn": userDN})
+		response.State.RemoveResource(ctx)
+		return
+	} else if err != nil {
+		addOperationError(&response.Diagnostics, err, "read", userDN,
+			"Can not read user",
+			err.Error(),
+		)
+		return
+	}
+
+	memberOf := []string{}
+	for _, groupDN := range groupDNs {
+		group, err := L.readGroup(ctx, groupDN, userDN, primaryGroupID)
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) || errors.Is(err, ErrEntryNotFound) {
+			tflog.Warn(ctx, "Group was deleted outside of Terraform, removing it from the state", map[string]interface{}{"dn": groupDN})
+			continue
+		} else if err != nil {
+			addOperationError(&response.Diagnostics, err, "read", groupDN,
+				"Can not read group",
+				err.Error(),
+			)
+			return
+		}
+		if group.primary {
+			memberOf = append(memberOf, groupDN)
+			continue
+		}
+
+		var isMember bool
+		start := time.Now()
+		err = WithContext(ctx, func() (err error) {
+			isMember, err = L.conn.Compare(groupDN, group.memberAttribute, group.member)
+			return
+		})
+		LogOperation(ctx, "compare", groupDN, start)
+		if err != nil {
+			addOperationError(&response.Diagnostics, err, "read", groupDN,
+				"Can not compare member",
+				fmt.Sprintf("Trying to check whether %s is a member of group %s returned: %s", userDN, groupDN, err),
+			)
+			return
+		}
+		if isMember {
+			memberOf = append(memberOf, groupDN)
+		} else {
+			tflog.Warn(ctx, "User was removed from the group outside of Terraform", map[string]interface{}{"dn": groupDN, "member": userDN})
+		}
+	}
+
+	set, d := types.SetValueFrom(ctx, types.StringType, memberOf)
+	response.Diagnostics.Append(d...)
+	data.GroupDNs = set
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPUserGroupsResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
+	var data, state *LDAPUserGroupsResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &data)...)
+	response.Diagnostics.Append(request.State.Get(ctx, &state)...)
+	var planGroupDNs, stateGroupDNs []string
+	response.Diagnostics.Append(data.GroupDNs.ElementsAs(ctx, &planGroupDNs, false)...)
+	response.Diagnostics.Append(state.GroupDNs.ElementsAs(ctx, &stateGroupDNs, false)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	L.modifyMemberships(ctx, "update", data.UserDN.ValueString(),
+		subtractValues(matchingRuleDistinguishedName, planGroupDNs, stateGroupDNs),
+		subtractValues(matchingRuleDistinguishedName, stateGroupDNs, planGroupDNs),
+		&response.Diagnostics,
+	)
+	if response.Diagnostics.HasError() {
+		return
+	}
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPUserGroupsResource) Delete(ctx context.Context, request resource.DeleteRequest, response *resource.DeleteResponse) {
+	var data *LDAPUserGroupsResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	var groupDNs []string
+	response.Diagnostics.Append(data.GroupDNs.ElementsAs(ctx, &groupDNs, false)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	L.modifyMemberships(ctx, "delete", data.UserDN.ValueString(), nil, groupDNs, &response.Diagnostics)
+}
+
+// modifyMemberships adds the user to the added groups and removes it from the removed groups. Memberships which
+// already exist or are already gone are tolerated, as well as removed groups which don't exist anymore.
+func (L *LDAPUserGroupsResource) modifyMemberships(ctx context.Context, phase string, userDN string, added []string, removed []string, diagnostics *diag.Diagnostics) {
+	primaryGroupID, err := L.primaryGroupID(ctx, userDN)
+	if err != nil && !(phase == "delete" && (ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) || errors.Is(err, ErrEntryNotFound))) {
+		addOperationError(diagnostics, err, phase, userDN,
+			"Can not read user",
+			err.Error(),
+		)
+		return
+	}
+
+	for _, groupDN := range removed {
+		group, err := L.readGroup(ctx, groupDN, userDN, primaryGroupID)
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) || errors.Is(err, ErrEntryNotFound) {
+			continue
+		} else if err != nil {
+			addOperationError(diagnostics, err, phase, groupDN,
+				"Can not read group",
+				err.Error(),
+			)
+			return
+		}
+		if group.primary {
+			tflog.Info(ctx, "Skipping the removal from the primary group of the user", map[string]interface{}{"dn": groupDN, "member": userDN})
+			continue
+		}
+
+		r := ldap.NewModifyRequest(groupDN, []ldap.Control{})
+		r.Delete(group.memberAttribute, []string{group.member})
+		if err := L.modify(ctx, r); err != nil && !ldap.IsErrorAnyOf(err, ldap.LDAPResultNoSuchObject, ldap.LDAPResultNoSuchAttribute) {
+			addOperationError(diagnostics, err, phase, groupDN,
+				"Can not remove member",
+				fmt.Sprintf("Trying to remove %s from group %s returned: %s", userDN, groupDN, err),
+			)
+			return
+		}
+	}
+
+	for _, groupDN := range added {
+		group, err := L.readGroup(ctx, groupDN, userDN, primaryGroupID)
+		if err != nil {
+			addOperationError(diagnostics, err, phase, groupDN,
+				"Can not read group",
+				err.Error(),
+			)
+			return
+		}
+		if group.primary {
+			tflog.Info(ctx, "Skipping the addition to the primary group of the user", map[string]interface{}{"dn": groupDN, "member": userDN})
+			continue
+		}
+
+		r := ldap.NewModifyRequest(groupDN, []ldap.Control{})
+		r.Add(group.memberAttribute, []string{group.member})
+		if err := L.modify(ctx, r); err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultAttributeOrValueExists) {
+			addOperationError(diagnostics, err, phase, groupDN,
+				"Can not add member",
+				fmt.Sprintf("Trying to add %s to group %s returned: %s", userDN, groupDN, err),
+			)
+			return
+		}
+	}
+}
+
+// primaryGroupID reads the id of the primary group of an Active Directory user. It's empty for other directories.
+func (L *LDAPUserGroupsResource) primaryGroupID(ctx context.Context, userDN string) (string, error) {
+	start := time.Now()
+	entry, err := GetEntry(L.conn, userDN, "primaryGroupID")
+	LogOperation(ctx, "search", userDN, start)
+	if err != nil {
+		return "", err
+	}
+	return entry.GetEqualFoldAttributeValue("primaryGroupID"), nil
+}
+
+// readGroup detects the member attribute of the group, the value referring to the user and whether it's the primary
+// group of the user.
+func (L *LDAPUserGroupsResource) readGroup(ctx context.Context, groupDN string, userDN string, primaryGroupID string) (userGroup, error) {
+	start := time.Now()
+	entry, err := GetEntry(L.conn, groupDN, "objectClass", "primaryGroupToken")
+	LogOperation(ctx, "search", groupDN, start)
+	if err != nil {
+		return userGroup{}, err
+	}
+	memberAttribute, err := detectMemberAttribute(entry.GetAttributeValues("objectClass"))
+	if err != nil {
+		return userGroup{}, err
+	}
+	member, err := memberValue(memberAttribute, userDN)
+	if err != nil {
+		return userGroup{}, err
+	}
+	return userGroup{
+		memberAttribute: memberAttribute,
+		member:          member,
+		primary:         isPrimaryGroup(entry, primaryGroupID),
+	}, nil
+}
+
+// modify modifies the members of a group while holding its lock.
+func (L *LDAPUserGroupsResource) modify(ctx context.Context, r *ldap.ModifyRequest) error {
+	start := time.Now()
+	defer LogOperation(ctx, "modify", r.DN, start)
+	return WithContext(ctx, func() error {
+		return L.locks.write(ctx, r.DN, func() error {
+			return L.conn.Modify(r)
+		})
+	})
+}
+
+// isPrimaryGroup checks whether the group is the primary group of a user with the given primaryGroupID. Active
+// Directory constructs the primaryGroupToken of a group from its relative id, which the primaryGroupID of its members
+// refers to.
+func isPrimaryGroup(group ldap.Entry, primaryGroupID string) bool {
+	return primaryGroupID != "" && group.GetEqualFoldAttributeValue("primaryGroupToken") == primaryGroupID
+}
@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestLDAPUserGroupsResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// groups with different member attributes
+			{
+				Config: testUserGroupsConfig("ldap_object.staff.dn", "ldap_object.vpn.dn"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_user_groups.alice", "group_dns.#", "2"),
+					testCheckServerValues("cn=staff,dc=example,dc=com", "member", []string{"cn=placeholder,dc=example,dc=com", "cn=alice,dc=example,dc=com"}),
+					testCheckServerValues("cn=vpn,dc=example,dc=com", "uniqueMember", []string{"cn=placeholder,dc=example,dc=com", "cn=alice,dc=example,dc=com"}),
+				),
+			},
+			{
+				Config: testUserGroupsConfig("ldap_object.vpn.dn"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckServerValues("cn=staff,dc=example,dc=com", "member", []string{"cn=placeholder,dc=example,dc=com"}),
+					testCheckServerValues("cn=vpn,dc=example,dc=com", "uniqueMember", []string{"cn=placeholder,dc=example,dc=com", "cn=alice,dc=example,dc=com"}),
+				),
+			},
+			// memberships removed outside of Terraform are drift
+			{
+				PreConfig:          testRemoveMemberExternally(t, "cn=vpn,dc=example,dc=com", "uniqueMember", "cn=alice,dc=example,dc=com"),
+				Config:             testUserGroupsConfig("ldap_object.vpn.dn"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testUserGroupsConfig("ldap_object.vpn.dn"),
+				Check:  testCheckServerValues("cn=vpn,dc=example,dc=com", "uniqueMember", []string{"cn=placeholder,dc=example,dc=com", "cn=alice,dc=example,dc=com"}),
+			},
+		},
+	})
+}
+
+// testUserGroupsConfig manages the groups of a user. The groups keep a placeholder member, so they aren't empty after
+// the user was removed.
+func testUserGroupsConfig(groupDNs ...string) string {
+	return fmt.Sprintf(`
+resource "ldap_object" "alice" {
+	dn = "cn=alice,dc=example,dc=com"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["alice"]
+		"sn" = ["alice"]
+	}
+}
+
+resource "ldap_object" "staff" {
+	dn = "cn=staff,dc=example,dc=com"
+	object_classes = ["groupOfNames"]
+	attributes = {
+		"cn" = ["staff"]
+		"member" = ["cn=placeholder,dc=example,dc=com"]
+	}
+	ignore_changes = ["member"]
+}
+
+resource "ldap_object" "vpn" {
+	dn = "cn=vpn,dc=example,dc=com"
+	object_classes = ["groupOfUniqueNames"]
+	attributes = {
+		"cn" = ["vpn"]
+		"uniqueMember" = ["cn=placeholder,dc=example,dc=com"]
+	}
+	ignore_changes = ["uniqueMember"]
+}
+
+resource "ldap_user_groups" "alice" {
+	user_dn = ldap_object.alice.dn
+	group_dns = [%s]
+}
+`, strings.Join(groupDNs, ", "))
+}
+
+func testRemoveMemberExternally(t *testing.T, groupDN string, memberAttribute string, memberDN string) func() {
+	return testExternally(t, func(conn *ldap.Conn) error {
+		r := ldap.NewModifyRequest(groupDN, []ldap.Control{})
+		r.Delete(memberAttribute, []string{memberDN})
+		return conn.Modify(r)
+	})
+}
+
+func TestIsPrimaryGroup(t *testing.T) {
+	group := ldap.NewEntry("cn=Domain Users,cn=Users,dc=example,dc=com", map[string][]string{"primaryGroupToken": {"513"}})
+	assert.True(t, isPrimaryGroup(*group, "513"))
+	assert.False(t, isPrimaryGroup(*group, "512"))
+
+	// directories without primary groups
+	assert.False(t, isPrimaryGroup(*ldap.NewEntry("cn=staff,dc=example,dc=com", nil), ""))
+}
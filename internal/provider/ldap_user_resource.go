@@ -0,0 +1,649 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/thoas/go-funk"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ resource.Resource = &LDAPUserResource{}
+var _ resource.ResourceWithConfigure = &LDAPUserResource{}
+var _ resource.ResourceWithValidateConfig = &LDAPUserResource{}
+var _ resource.ResourceWithModifyPlan = &LDAPUserResource{}
+var _ resource.ResourceWithImportState = &LDAPUserResource{}
+
+func NewLDAPUserResource() resource.Resource {
+	return &LDAPUserResource{}
+}
+
+type LDAPUserResource struct {
+	conn  *ldap.Conn
+	locks *dnLocks
+}
+
+type LDAPUserResourceModel struct {
+	ID                      types.String `tfsdk:"id"`
+	DN                      types.String `tfsdk:"dn"`
+	UID                     types.String `tfsdk:"uid"`
+	CN                      types.String `tfsdk:"cn"`
+	ParentDN                types.String `tfsdk:"parent_dn"`
+	GivenName               types.String `tfsdk:"given_name"`
+	Surname                 types.String `tfsdk:"surname"`
+	Mail                    types.String `tfsdk:"mail"`
+	DisplayName             types.String `tfsdk:"display_name"`
+	Posix                   types.Object `tfsdk:"posix"`
+	AdditionalObjectClasses types.List   `tfsdk:"additional_object_classes"`
+	Attributes              types.Map    `tfsdk:"attributes"`
+	Password                types.String `tfsdk:"password"`
+	PasswordHashScheme      types.String `tfsdk:"password_hash_scheme"`
+}
+
+// LDAPUserPosixModel describes the posix attribute of users.
+type LDAPUserPosixModel struct {
+	UIDNumber     types.Int64  `tfsdk:"uid_number"`
+	GIDNumber     types.Int64  `tfsdk:"gid_number"`
+	HomeDirectory types.String `tfsdk:"home_directory"`
+	LoginShell    types.String `tfsdk:"login_shell"`
+}
+
+// ldapUserPosixType is the type of the posix attribute of users.
+var ldapUserPosixType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"uid_number":     types.Int64Type,
+		"gid_number":     types.Int64Type,
+		"home_directory": types.StringType,
+		"login_shell":    types.StringType,
+	},
+}
+
+// userObjectClasses are the object classes of every user.
+var userObjectClasses = []string{"inetOrgPerson", "organizationalPerson", "person"}
+
+// userAttributeTypes are the attribute types managed by the arguments of the user resource, which can't be set in
+// attributes together with userPassword.
+var userAttributeTypes = []string{"objectClass", "uid", "cn", "givenName", "sn", "mail", "displayName", "uidNumber", "gidNumber", "homeDirectory", "loginShell"}
+
+func (L *LDAPUserResource) Configure(_ context.Context, request resource.ConfigureRequest, response *resource.ConfigureResponse) {
+	if request.ProviderData == nil {
+		return
+	}
+
+	if client, ok := request.ProviderData.(*ldapClient); !ok {
+		response.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *ldapClient, got: %T. Please report this issue to the provider developers.", request.ProviderData),
+		)
+
+		return
+	} else {
+		L.conn = client.conn
+		L.locks = client.locks
+	}
+}
+
+func (L *LDAPUserResource) Metadata(_ context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_user"
+}
+
+func (L *LDAPUserResource) Schema(_ context.Context, _ resource.SchemaRequest, response *resource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		MarkdownDescription: "Manages a user stored as `inetOrgPerson`, which is a `posixAccount` as well if `posix` is set. " +
+			"Changing the name or the parent renames respectively moves the user",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Resource identifier",
+			},
+			"dn": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "DN of the user, which is named by its `uid` below `parent_dn`, or by its `cn` if `uid` isn't set",
+			},
+			"uid": schema.StringAttribute{
+				MarkdownDescription: "User name, which names the user if set",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"cn": schema.StringAttribute{
+				MarkdownDescription: "Common name of the user. Defaults to the given name followed by the surname",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"parent_dn": schema.StringAttribute{
+				MarkdownDescription: "DN of the entry the user is created in",
+				Required:            true,
+				Validators: []validator.String{
+					IsValidDN(),
+				},
+			},
+			"given_name": schema.StringAttribute{
+				MarkdownDescription: "Given name of the user, stored in `givenName`",
+				Optional:            true,
+			},
+			"surname": schema.StringAttribute{
+				MarkdownDescription: "Surname of the user, stored in `sn`",
+				Required:            true,
+			},
+			"mail": schema.StringAttribute{
+				MarkdownDescription: "Mail address of the user",
+				Optional:            true,
+			},
+			"display_name": schema.StringAttribute{
+				MarkdownDescription: "Name of the user shown by applications, stored in `displayName`",
+				Optional:            true,
+			},
+			"posix": schema.SingleNestedAttribute{
+				MarkdownDescription: "If set, the user is a `posixAccount` as well, which requires `uid`",
+				Optional:            true,
+				Attributes: map[string]schema.Attribute{
+					"uid_number": schema.Int64Attribute{
+						MarkdownDescription: "Numeric user id",
+						Required:            true,
+					},
+					"gid_number": schema.Int64Attribute{
+						MarkdownDescription: "Numeric id of the primary group",
+						Required:            true,
+					},
+					"home_directory": schema.StringAttribute{
+						MarkdownDescription: "Home directory of the user. Defaults to `/home/<uid>`",
+						Optional:            true,
+						Computed:            true,
+					},
+					"login_shell": schema.StringAttribute{
+						MarkdownDescription: "Login shell of the user",
+						Optional:            true,
+					},
+				},
+			},
+			"additional_object_classes": schema.ListAttribute{
+				MarkdownDescription: "Object classes of the user in addition to `inetOrgPerson`, `organizationalPerson`, `person` and `posixAccount`",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"attributes": schema.MapAttribute{
+				MarkdownDescription: "Further attributes of the user, e.g. of `additional_object_classes`. The attributes set by the other arguments can't be set here",
+				Optional:            true,
+				ElementType:         types.ListType{ElemType: types.StringType},
+			},
+			"password": schema.StringAttribute{
+				MarkdownDescription: "Password of the user. It is set using the password modify extended operation, so the server hashes it according to its configuration, and never read back. A password changed outside of Terraform isn't detected, unless `password_hash_scheme` is set",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"password_hash_scheme": schema.StringAttribute{
+				MarkdownDescription: "If set, the password is hashed by the provider and written to `userPassword`, for servers storing it verbatim: `SSHA`, `SSHA512`, `SHA512-CRYPT`, `PBKDF2` or `ARGON2`. Every apply setting the password uses a new salt. If `userPassword` can be read, a password changed outside of Terraform is detected by verifying the password against it",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(passwordHashSchemes...),
+				},
+			},
+		},
+	}
+}
+
+func (L *LDAPUserResource) ValidateConfig(ctx context.Context, request resource.ValidateConfigRequest, response *resource.ValidateConfigResponse) {
+	var data *LDAPUserResourceModel
+	response.Diagnostics.Append(request.Config.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.Posix.IsNull() && !data.Posix.IsUnknown() && data.UID.IsNull() {
+		response.Diagnostics.AddAttributeError(
+			path.Root("uid"),
+			"Missing user name",
+			"posixAccount users require a uid",
+		)
+	}
+	for attributeType := range data.Attributes.Elements() {
+		if funk.Contains(append(userAttributeTypes, "userPassword"), func(managed string) bool { return strings.EqualFold(managed, attributeType) }) {
+			response.Diagnostics.AddAttributeError(
+				path.Root("attributes").AtMapKey(attributeType),
+				"Attribute managed by the user",
+				fmt.Sprintf("The attribute %s is set by the other arguments of the user and can't be set in attributes", attributeType),
+			)
+		}
+	}
+}
+
+// ModifyPlan plans the default common name and home directory as well as the DN, so it is known to the resources
+// using it. A DN which only differs in its spelling from the DN in the state doesn't cause an update.
+func (L *LDAPUserResource) ModifyPlan(ctx context.Context, request resource.ModifyPlanRequest, response *resource.ModifyPlanResponse) {
+	var configData *LDAPUserResourceModel
+	var stateData *LDAPUserResourceModel
+	var planData *LDAPUserResourceModel
+
+	response.Diagnostics.Append(request.Config.Get(ctx, &configData)...)
+	response.Diagnostics.Append(request.State.Get(ctx, &stateData)...)
+	response.Diagnostics.Append(request.Plan.Get(ctx, &planData)...)
+	if response.Diagnostics.HasError() || configData == nil || planData == nil {
+		return
+	}
+
+	if configData.CN.IsNull() {
+		planData.CN = defaultUserCN(planData)
+	}
+
+	if !configData.Posix.IsNull() && !configData.Posix.IsUnknown() {
+		var configPosix LDAPUserPosixModel
+		response.Diagnostics.Append(configData.Posix.As(ctx, &configPosix, basetypes.ObjectAsOptions{})...)
+		var planPosix LDAPUserPosixModel
+		response.Diagnostics.Append(planData.Posix.As(ctx, &planPosix, basetypes.ObjectAsOptions{})...)
+		if configPosix.HomeDirectory.IsNull() {
+			planPosix.HomeDirectory = types.StringUnknown()
+			if !planData.UID.IsUnknown() {
+				planPosix.HomeDirectory = types.StringValue("/home/" + planData.UID.ValueString())
+			}
+		}
+		posix, d := types.ObjectValueFrom(ctx, ldapUserPosixType.AttrTypes, planPosix)
+		response.Diagnostics.Append(d...)
+		planData.Posix = posix
+	}
+
+	if planData.UID.IsUnknown() || planData.CN.IsUnknown() || planData.ParentDN.IsUnknown() {
+		planData.DN = types.StringUnknown()
+	} else if dn := userDN(planData); stateData != nil && sameDN(stateData.DN.ValueString(), dn) {
+		planData.DN = stateData.DN
+	} else {
+		planData.DN = types.StringValue(dn)
+	}
+	planData.ID = planData.DN
+	response.Diagnostics.Append(response.Plan.Set(ctx, &planData)...)
+}
+
+func (L *LDAPUserResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	var data *LDAPUserResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &data)...)
+	attributes := L.attributes(ctx, data, &response.Diagnostics)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	data.DN = types.StringValue(userDN(data))
+	data.ID = data.DN
+	a := ldap.NewAddRequest(data.DN.ValueString(), []ldap.Control{})
+	for _, attributeType := range sortedKeys(attributes) {
+		if len(attributes[attributeType]) > 0 {
+			a.Attribute(attributeType, attributes[attributeType])
+		}
+	}
+
+	start := time.Now()
+	err := WithContext(ctx, func() error {
+		return L.locks.write(ctx, a.DN, func() error {
+			return L.conn.Add(a)
+		})
+	})
+	LogOperation(ctx, "add", a.DN, start)
+	if err != nil {
+		addOperationError(&response.Diagnostics, err, "create", a.DN,
+			"Can not create user",
+			fmt.Sprintf("Trying to add user %s returned: %s", a.DN, err),
+		)
+		return
+	}
+
+	if !data.Password.IsNull() {
+		if err := L.setPassword(ctx, data); err != nil {
+			// the user exists, but without the password, which is set again by the next apply
+			data.Password = types.StringNull()
+			response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+			addOperationError(&response.Diagnostics, err, "create", a.DN,
+				"Can not set password",
+				fmt.Sprintf("The user %s was created, but setting its password failed: %s", a.DN, err),
+			)
+			return
+		}
+	}
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPUserResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	var data *LDAPUserResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	var stateAttributes map[string][]string
+	response.Diagnostics.Append(data.Attributes.ElementsAs(ctx, &stateAttributes, false)...)
+	var stateObjectClasses []string
+	response.Diagnostics.Append(data.AdditionalObjectClasses.ElementsAs(ctx, &stateObjectClasses, false)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	attributeTypes := append(sortedKeys(stateAttributes), userAttributeTypes...)
+	verifyPassword := !data.PasswordHashScheme.IsNull() && !data.Password.IsNull()
+	if verifyPassword {
+		attributeTypes = append(attributeTypes, "userPassword")
+	}
+
+	start := time.Now()
+	entry, err := GetEntry(L.conn, data.DN.ValueString(), attributeTypes...)
+	LogOperation(ctx, "search", data.DN.ValueString(), start)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) || errors.Is(err, ErrEntryNotFound) {
+		tflog.Warn(ctx, "User was deleted outside of Terraform, removing it from the state", map[string]interface{}{"dn": data.DN.ValueString()})
+		response.State.RemoveResource(ctx)
+		return
+	} else if err != nil {
+		addOperationError(&response.Diagnostics, err, "read", data.DN.ValueString(),
+			"Can not read user",
+			err.Error(),
+		)
+		return
+	}
+
+	data.UID = userValue(entry.GetAttributeValues("uid"), data.UID)
+	data.CN = userValue(entry.GetAttributeValues("cn"), data.CN)
+	data.GivenName = userValue(entry.GetAttributeValues("givenName"), data.GivenName)
+	data.Surname = userValue(entry.GetAttributeValues("sn"), data.Surname)
+	data.Mail = userValue(entry.GetAttributeValues("mail"), data.Mail)
+	data.DisplayName = userValue(entry.GetAttributeValues("displayName"), data.DisplayName)
+	if verifyPassword && !passwordMatches(entry.GetAttributeValues("userPassword"), data.Password.ValueString()) {
+		tflog.Warn(ctx, "Password was changed outside of Terraform", map[string]interface{}{"dn": data.DN.ValueString()})
+		data.Password = types.StringNull()
+	}
+
+	var objectClasses []string
+	isPosixAccount := false
+	for _, objectClass := range entry.GetAttributeValues("objectClass") {
+		if strings.EqualFold(objectClass, "posixAccount") {
+			isPosixAccount = true
+		} else if !strings.EqualFold(objectClass, "top") && !funk.Contains(userObjectClasses, func(userObjectClass string) bool { return strings.EqualFold(userObjectClass, objectClass) }) {
+			objectClasses = append(objectClasses, objectClass)
+		}
+	}
+	objectClasses = preferStateValues(matchingRuleCaseIgnore, objectClasses, stateObjectClasses)
+	if len(objectClasses) == 0 && data.AdditionalObjectClasses.IsNull() {
+		data.AdditionalObjectClasses = types.ListNull(types.StringType)
+	} else {
+		list, d := types.ListValueFrom(ctx, types.StringType, objectClasses)
+		response.Diagnostics.Append(d...)
+		data.AdditionalObjectClasses = list
+	}
+
+	if isPosixAccount {
+		posix := LDAPUserPosixModel{
+			UIDNumber:     types.Int64Null(),
+			GIDNumber:     types.Int64Null(),
+			HomeDirectory: userValue(entry.GetAttributeValues("homeDirectory"), types.StringNull()),
+			LoginShell:    userValue(entry.GetAttributeValues("loginShell"), types.StringNull()),
+		}
+		if uidNumber, err := strconv.ParseInt(entry.GetAttributeValue("uidNumber"), 10, 64); err == nil {
+			posix.UIDNumber = types.Int64Value(uidNumber)
+		}
+		if gidNumber, err := strconv.ParseInt(entry.GetAttributeValue("gidNumber"), 10, 64); err == nil {
+			posix.GIDNumber = types.Int64Value(gidNumber)
+		}
+		object, d := types.ObjectValueFrom(ctx, ldapUserPosixType.AttrTypes, posix)
+		response.Diagnostics.Append(d...)
+		data.Posix = object
+	} else {
+		data.Posix = types.ObjectNull(ldapUserPosixType.AttrTypes)
+	}
+
+	if !data.Attributes.IsNull() {
+		attributes := map[string][]string{}
+		for attributeType, stateValues := range stateAttributes {
+			attributes[attributeType] = preferStateValues(lookupMatchingRule(attributeType, nil), entry.GetEqualFoldAttributeValues(attributeType), stateValues)
+		}
+		m, d := types.MapValueFrom(ctx, types.ListType{ElemType: types.StringType}, attributes)
+		response.Diagnostics.Append(d...)
+		data.Attributes = m
+	}
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
+
+func (L *LDAPUserResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
+	var stateData *LDAPUserResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &stateData)...)
+	var planData *LDAPUserResourceModel
+	response.Diagnostics.Append(request.Plan.Get(ctx, &planData)...)
+	stateAttributes := L.attributes(ctx, stateData, &response.Diagnostics)
+	planAttributes := L.attributes(ctx, planData, &response.Diagnostics)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	planData.DN = types.StringValue(userDN(planData))
+	if sameDN(stateData.DN.ValueString(), planData.DN.ValueString()) {
+		planData.DN = stateData.DN
+	} else if rdn, err := L.move(ctx, stateData, planData); err != nil {
+		addOperationError(&response.Diagnostics, err, "update", stateData.DN.ValueString(),
+			"Can not rename user",
+			fmt.Sprintf("Trying to rename user %s to %s returned: %s", stateData.DN.ValueString(), planData.DN.ValueString(), err),
+		)
+		return
+	} else {
+		// the modify DN operation added the value of the new RDN and kept the old one, which is deleted below if it
+		// isn't configured anymore
+		for _, ava := range rdn.Attributes {
+			if !containsValue(lookupMatchingRule(ava.Type, nil), stateAttributes[ava.Type], ava.Value) {
+				stateAttributes[ava.Type] = append(stateAttributes[ava.Type], ava.Value)
+			}
+		}
+		stateData.ID = planData.DN
+		stateData.DN = planData.DN
+		stateData.UID = planData.UID
+		stateData.CN = planData.CN
+		stateData.ParentDN = planData.ParentDN
+	}
+	planData.ID = planData.DN
+
+	r := ldap.NewModifyRequest(planData.DN.ValueString(), []ldap.Control{})
+	for _, attributeType := range sortedKeys(stateAttributes) {
+		r.Changes = append(r.Changes, diffValues(attributeType, lookupMatchingRule(attributeType, nil), stateAttributes[attributeType], planAttributes[attributeType], false, modifyStrategyIncremental)...)
+	}
+	for _, attributeType := range sortedKeys(planAttributes) {
+		if _, exists := stateAttributes[attributeType]; !exists && len(planAttributes[attributeType]) > 0 {
+			r.Add(attributeType, planAttributes[attributeType])
+		}
+	}
+	if planData.Password.IsNull() && !stateData.Password.IsNull() {
+		r.Delete("userPassword", []string{})
+	}
+
+	if len(r.Changes) > 0 {
+		start := time.Now()
+		err := WithContext(ctx, func() error {
+			return L.locks.write(ctx, r.DN, func() error {
+				return L.conn.Modify(r)
+			})
+		})
+		LogOperation(ctx, "modify", r.DN, start)
+		if err != nil {
+			// the user may have been renamed already, so the state has to follow it
+			response.Diagnostics.Append(response.State.Set(ctx, &stateData)...)
+			addOperationError(&response.Diagnostics, err, "update", r.DN,
+				"Can not modify user",
+				fmt.Sprintf("Trying to modify user %s returned: %s", r.DN, err),
+			)
+			return
+		}
+	}
+
+	if !planData.Password.IsNull() && (!planData.Password.Equal(stateData.Password) || !planData.PasswordHashScheme.Equal(stateData.PasswordHashScheme)) {
+		if err := L.setPassword(ctx, planData); err != nil {
+			planData.Password = stateData.Password
+			response.Diagnostics.Append(response.State.Set(ctx, &planData)...)
+			addOperationError(&response.Diagnostics, err, "update", r.DN,
+				"Can not set password",
+				fmt.Sprintf("Trying to set the password of user %s returned: %s", r.DN, err),
+			)
+			return
+		}
+	}
+	response.Diagnostics.Append(response.State.Set(ctx, &planData)...)
+}
+
+func (L *LDAPUserResource) Delete(ctx context.Context, request resource.DeleteRequest, response *resource.DeleteResponse) {
+	var data *LDAPUserResourceModel
+	response.Diagnostics.Append(request.State.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	start := time.Now()
+	err := WithContext(ctx, func() error {
+		return L.locks.write(ctx, data.DN.ValueString(), func() error {
+			return L.conn.Del(ldap.NewDelRequest(data.DN.ValueString(), []ldap.Control{}))
+		})
+	})
+	LogOperation(ctx, "delete", data.DN.ValueString(), start)
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		addOperationError(&response.Diagnostics, err, "delete", data.DN.ValueString(),
+			"Can not delete user",
+			fmt.Sprintf("Trying to delete user %s returned: %s", data.DN.ValueString(), err),
+		)
+	}
+}
+
+// ImportState imports a user by its DN. The remaining attributes are read afterwards, except for attributes, which
+// can't be told apart from attributes managed elsewhere, and the password.
+func (L *LDAPUserResource) ImportState(ctx context.Context, request resource.ImportStateRequest, response *resource.ImportStateResponse) {
+	_, parentDN, err := SplitRDN(request.ID)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Invalid DN",
+			fmt.Sprintf("Can not split the DN %s: %s", request.ID, err),
+		)
+		return
+	}
+
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("id"), request.ID)...)
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("dn"), request.ID)...)
+	response.Diagnostics.Append(response.State.SetAttribute(ctx, path.Root("parent_dn"), parentDN)...)
+}
+
+// attributes returns the attributes of the user in the model, including its object classes.
+func (L *LDAPUserResource) attributes(ctx context.Context, data *LDAPUserResourceModel, diagnostics *diag.Diagnostics) map[string][]string {
+	var attributes map[string][]string
+	diagnostics.Append(data.Attributes.ElementsAs(ctx, &attributes, false)...)
+	if attributes == nil {
+		attributes = map[string][]string{}
+	}
+	var additionalObjectClasses []string
+	diagnostics.Append(data.AdditionalObjectClasses.ElementsAs(ctx, &additionalObjectClasses, false)...)
+
+	objectClasses := append([]string{}, userObjectClasses...)
+	for name, value := range map[string]types.String{
+		"uid":         data.UID,
+		"cn":          data.CN,
+		"givenName":   data.GivenName,
+		"sn":          data.Surname,
+		"mail":        data.Mail,
+		"displayName": data.DisplayName,
+	} {
+		attributes[name] = nil
+		if !value.IsNull() {
+			attributes[name] = []string{value.ValueString()}
+		}
+	}
+
+	for _, name := range []string{"uidNumber", "gidNumber", "homeDirectory", "loginShell"} {
+		attributes[name] = nil
+	}
+	if !data.Posix.IsNull() {
+		var posix LDAPUserPosixModel
+		diagnostics.Append(data.Posix.As(ctx, &posix, basetypes.ObjectAsOptions{})...)
+		objectClasses = append(objectClasses, "posixAccount")
+		attributes["uidNumber"] = []string{strconv.FormatInt(posix.UIDNumber.ValueInt64(), 10)}
+		attributes["gidNumber"] = []string{strconv.FormatInt(posix.GIDNumber.ValueInt64(), 10)}
+		attributes["homeDirectory"] = []string{posix.HomeDirectory.ValueString()}
+		if !posix.LoginShell.IsNull() {
+			attributes["loginShell"] = []string{posix.LoginShell.ValueString()}
+		}
+	}
+
+	attributes["objectClass"] = append(objectClasses, additionalObjectClasses...)
+	return attributes
+}
+
+// move renames the user and moves it to its new parent using a single modify DN operation. The value of the old RDN
+// is kept, since its attribute may be required by the object classes of the user, e.g. the cn of a user named by its
+// uid before. It returns the new RDN.
+func (L *LDAPUserResource) move(ctx context.Context, stateData *LDAPUserResourceModel, planData *LDAPUserResourceModel) (*ldap.RelativeDN, error) {
+	rdn, parentDN, err := SplitRDN(planData.DN.ValueString())
+	if err != nil {
+		return nil, err
+	}
+	newSuperior := ""
+	if !sameDN(stateData.ParentDN.ValueString(), parentDN) {
+		newSuperior = parentDN
+	}
+	r := ldap.NewModifyDNRequest(stateData.DN.ValueString(), rdn.String(), false, newSuperior)
+
+	start := time.Now()
+	defer LogOperation(ctx, "modifydn", r.DN, start)
+	return rdn, WithContext(ctx, func() error {
+		return L.locks.write(ctx, r.DN, func() error {
+			return L.conn.ModifyDN(r)
+		})
+	})
+}
+
+// setPassword sets the password of the user using the password modify extended operation, or hashes it if a hash
+// scheme is configured.
+func (L *LDAPUserResource) setPassword(ctx context.Context, data *LDAPUserResourceModel) error {
+	dn, password := data.DN.ValueString(), data.Password.ValueString()
+	if !data.PasswordHashScheme.IsNull() {
+		return setHashedPassword(ctx, L.conn, L.locks, dn, data.PasswordHashScheme.ValueString(), password)
+	}
+
+	start := time.Now()
+	defer LogOperation(ctx, "passwordmodify", dn, start)
+	return WithContext(ctx, func() error {
+		return L.locks.write(ctx, dn, func() error {
+			_, err := L.conn.PasswordModify(ldap.NewPasswordModifyRequest(dn, "", password))
+			return err
+		})
+	})
+}
+
+// defaultUserCN returns the common name of a user without a configured cn, which is the given name followed by the
+// surname.
+func defaultUserCN(data *LDAPUserResourceModel) types.String {
+	if data.GivenName.IsUnknown() || data.Surname.IsUnknown() {
+		return types.StringUnknown()
+	}
+	if data.GivenName.IsNull() {
+		return data.Surname
+	}
+	return types.StringValue(data.GivenName.ValueString() + " " + data.Surname.ValueString())
+}
+
+// userDN returns the DN of the user named by its uid or its common name below its parent.
+func userDN(data *LDAPUserResourceModel) string {
+	if !data.UID.IsNull() {
+		return fmt.Sprintf("uid=%s,%s", ldap.EscapeDN(data.UID.ValueString()), data.ParentDN.ValueString())
+	}
+	return fmt.Sprintf("cn=%s,%s", ldap.EscapeDN(data.CN.ValueString()), data.ParentDN.ValueString())
+}
+
+// userValue returns the value of a single-valued argument of the user read from the given attribute values. The state
+// value is kept if the attribute still contains it, since attributes like cn may have several values.
+func userValue(values []string, state types.String) types.String {
+	if len(values) == 0 {
+		return types.StringNull()
+	}
+	if !state.IsNull() && containsValue(matchingRuleCaseIgnore, values, state.ValueString()) {
+		return state
+	}
+	return types.StringValue(values[0])
+}
@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"regexp"
+	"testing"
+)
+
+func TestLDAPUserResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testCheckEntryMissing("uid=aliddell,dc=example,dc=com"),
+		Steps: []resource.TestStep{
+			{
+				Config: testUserConfig("alice", "alice@example.com", "first-secret", `
+	posix = {
+		uid_number = 10001
+		gid_number = 10001
+	}`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_user.alice", "dn", "uid=alice,dc=example,dc=com"),
+					resource.TestCheckResourceAttr("ldap_user.alice", "cn", "Alice Liddell"),
+					resource.TestCheckResourceAttr("ldap_user.alice", "posix.home_directory", "/home/alice"),
+					testCheckServerValues("uid=alice,dc=example,dc=com", "objectClass", []string{"inetOrgPerson", "organizationalPerson", "person", "posixAccount"}),
+					testCheckServerValues("uid=alice,dc=example,dc=com", "homeDirectory", []string{"/home/alice"}),
+					testCheckServerValues("uid=alice,dc=example,dc=com", "telephoneNumber", []string{"+1 555 0100"}),
+					testCheckBind("uid=alice,dc=example,dc=com", "first-secret"),
+				),
+			},
+			// Renaming keeps the entry, the posix attributes are removed together with the object class
+			{
+				Config: testUserConfig("aliddell", "liddell@example.com", "second-secret", ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ldap_user.alice", "dn", "uid=aliddell,dc=example,dc=com"),
+					testCheckEntryMissing("uid=alice,dc=example,dc=com"),
+					testCheckServerValues("uid=aliddell,dc=example,dc=com", "uid", []string{"aliddell"}),
+					testCheckServerValues("uid=aliddell,dc=example,dc=com", "mail", []string{"liddell@example.com"}),
+					testCheckServerValues("uid=aliddell,dc=example,dc=com", "objectClass", []string{"inetOrgPerson", "organizationalPerson", "person"}),
+					testCheckServerValues("uid=aliddell,dc=example,dc=com", "uidNumber", []string{}),
+					testCheckBind("uid=aliddell,dc=example,dc=com", "second-secret"),
+				),
+			},
+			{
+				Config:                  testUserConfig("aliddell", "liddell@example.com", "second-secret", ""),
+				ResourceName:            "ldap_user.alice",
+				ImportState:             true,
+				ImportStateId:           "uid=aliddell,dc=example,dc=com",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"attributes", "password"},
+			},
+		},
+	})
+}
+
+// testUserConfig creates the user alice with the given uid, mail, password and further arguments.
+func testUserConfig(uid string, mail string, password string, arguments string) string {
+	return fmt.Sprintf(`
+resource "ldap_user" "alice" {
+	uid = %q
+	parent_dn = "dc=example,dc=com"
+	given_name = "Alice"
+	surname = "Liddell"
+	mail = %q
+	password = %q
+	attributes = {
+		"telephoneNumber" = ["+1 555 0100"]
+	}
+%s
+}
+`, uid, mail, password, arguments)
+}
+
+func TestLDAPUserResourceValidation(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "ldap_user" "bob" {
+	parent_dn = "dc=example,dc=com"
+	surname = "Builder"
+	posix = {
+		uid_number = 10002
+		gid_number = 10002
+	}
+}
+`,
+				ExpectError: regexp.MustCompile("Missing user name"),
+			},
+			{
+				Config: `
+resource "ldap_user" "bob" {
+	uid = "bob"
+	parent_dn = "dc=example,dc=com"
+	surname = "Builder"
+	attributes = {
+		"SN" = ["Baumeister"]
+	}
+}
+`,
+				ExpectError: regexp.MustCompile("Attribute managed by the user"),
+			},
+		},
+	})
+}
+
+// testCheckBind checks that binding with the DN and the password succeeds.
+func testCheckBind(dn string, password string) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		conn, err := ldap.DialURL(os.Getenv("LDAP_URL"))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		return conn.Bind(dn, password)
+	}
+}
+
+func TestUserDN(t *testing.T) {
+	data := &LDAPUserResourceModel{
+		UID:       types.StringNull(),
+		ParentDN:  types.StringValue("ou=people,dc=example,dc=com"),
+		GivenName: types.StringValue("Alice"),
+		Surname:   types.StringValue("Liddell, Jr."),
+	}
+	data.CN = defaultUserCN(data)
+	assert.Equal(t, types.StringValue("Alice Liddell, Jr."), data.CN)
+	assert.Equal(t, `cn=Alice Liddell\, Jr.,ou=people,dc=example,dc=com`, userDN(data))
+
+	data.UID = types.StringValue("alice")
+	assert.Equal(t, "uid=alice,ou=people,dc=example,dc=com", userDN(data))
+
+	data.GivenName = types.StringNull()
+	assert.Equal(t, types.StringValue("Liddell, Jr."), defaultUserCN(data))
+	data.GivenName = types.StringUnknown()
+	assert.True(t, defaultUserCN(data).IsUnknown())
+}
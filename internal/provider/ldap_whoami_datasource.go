@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"time"
+)
+
+var _ datasource.DataSource = &LDAPWhoAmIDataSource{}
+var _ datasource.DataSourceWithConfigure = &LDAPWhoAmIDataSource{}
+
+func NewLDAPWhoAmIDataSource() datasource.DataSource {
+	return &LDAPWhoAmIDataSource{}
+}
+
+type LDAPWhoAmIDataSource struct {
+	conn    *ldap.Conn
+	authzID *string
+}
+
+type LDAPWhoAmIDatasourceModel struct {
+	Id       types.String `tfsdk:"id"`
+	AuthzID  types.String `tfsdk:"authz_id"`
+	FromBind types.Bool   `tfsdk:"from_bind"`
+}
+
+func (L *LDAPWhoAmIDataSource) Metadata(_ context.Context, request datasource.MetadataRequest, response *datasource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_whoami"
+}
+
+func (L *LDAPWhoAmIDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, response *datasource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		MarkdownDescription: "Returns the identity the provider is authorized as. If `ldap_authzid_on_bind` is set and the server returned the identity for the bind, it is used, otherwise the \"Who am I?\" extended operation (RFC 4532) is sent",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Datasource identifier",
+			},
+			"authz_id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Authorization identity, e.g. `dn:cn=admin,dc=example,dc=com`. Empty for anonymous connections",
+			},
+			"from_bind": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the identity was returned by the bind in the authorization identity response control (RFC 3829)",
+			},
+		},
+	}
+}
+
+func (L *LDAPWhoAmIDataSource) Configure(_ context.Context, request datasource.ConfigureRequest, response *datasource.ConfigureResponse) {
+	if request.ProviderData == nil {
+		return
+	}
+
+	if client, ok := request.ProviderData.(*ldapClient); !ok {
+		response.Diagnostics.AddError(
+			"Unexpected Datasource Configure Type",
+			fmt.Sprintf("Expected *ldapClient, got: %T. Please report this issue to the provider developers.", request.ProviderData),
+		)
+
+		return
+	} else {
+		L.conn = client.conn
+		L.authzID = client.authzID
+	}
+}
+
+func (L *LDAPWhoAmIDataSource) Read(ctx context.Context, request datasource.ReadRequest, response *datasource.ReadResponse) {
+	var data LDAPWhoAmIDatasourceModel
+	response.Diagnostics.Append(request.Config.Get(ctx, &data)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	if L.authzID != nil {
+		data.AuthzID = types.StringValue(*L.authzID)
+		data.FromBind = types.BoolValue(true)
+	} else {
+		start := time.Now()
+		result, err := L.conn.WhoAmI(nil)
+		LogOperation(ctx, "whoami", "", start)
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Can not determine the authorization identity",
+				fmt.Sprintf("The \"Who am I?\" operation returned: %s", err),
+			)
+			return
+		}
+		data.AuthzID = types.StringValue(result.AuthzID)
+		data.FromBind = types.BoolValue(false)
+	}
+	data.Id = data.AuthzID
+	response.Diagnostics.Append(response.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"os"
+	"testing"
+)
+
+func TestLDAPWhoAmIDatasource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testWhoAmIConfig(false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.ldap_whoami.test", "authz_id", fmt.Sprintf("dn:%s", os.Getenv("LDAP_BIND_DN"))),
+					resource.TestCheckResourceAttr("data.ldap_whoami.test", "from_bind", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestLDAPWhoAmIDatasourceAuthzIDOnBind(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !testServerSupportsControl(ControlTypeAuthzIDRequest) {
+				t.Skip("server does not support the authorization identity request control")
+			}
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// the identity is captured by the bind during the configuration of the provider
+			{
+				Config: testWhoAmIConfig(true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.ldap_whoami.test", "authz_id", fmt.Sprintf("dn:%s", os.Getenv("LDAP_BIND_DN"))),
+					resource.TestCheckResourceAttr("data.ldap_whoami.test", "from_bind", "true"),
+				),
+			},
+		},
+	})
+}
+
+func testWhoAmIConfig(authzIDOnBind bool) string {
+	return fmt.Sprintf(`
+provider "ldap" {
+	ldap_authzid_on_bind = %t
+}
+
+data "ldap_whoami" "test" {}
+`, authzIDOnBind)
+}
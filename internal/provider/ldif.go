@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// LDIFRecord is a content record of an LDIF file (RFC 2849), which describes a single entry.
+type LDIFRecord struct {
+	DN string
+	// Types holds the attribute types in the order of their first appearance, spelled like there.
+	Types      []string
+	Attributes map[string][][]byte
+}
+
+// ldifAttributeDescription matches attribute types given by name or OID, optionally followed by options.
+var ldifAttributeDescription = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9-]*|[0-9]+(\.[0-9]+)*)(;[A-Za-z0-9-]+)*$`)
+
+// ParseLDIFRecord parses LDIF text containing exactly one content record. Folded lines, comments, base64 encoded
+// values and an optional version line are supported, change records and values referenced by URL are not.
+func ParseLDIFRecord(text string) (*LDIFRecord, error) {
+	var lines []string
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(line, " ") {
+			if len(lines) == 0 || lines[len(lines)-1] == "" {
+				return nil, fmt.Errorf("line %d continues a line which doesn't exist", i+1)
+			}
+			lines[len(lines)-1] += line[1:]
+		} else {
+			lines = append(lines, line)
+		}
+	}
+
+	// comments may be folded as well, so they are removed after unfolding
+	var record []string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "#") {
+			continue
+		} else if line == "" && len(record) > 0 && record[len(record)-1] != "" {
+			record = append(record, line)
+		} else if line != "" {
+			if len(record) > 0 && record[len(record)-1] == "" {
+				return nil, fmt.Errorf("only a single record is supported")
+			}
+			record = append(record, line)
+		}
+	}
+	if len(record) > 0 && record[len(record)-1] == "" {
+		record = record[:len(record)-1]
+	}
+
+	if len(record) > 0 && strings.HasPrefix(strings.ToLower(record[0]), "version:") {
+		if version, _ := parseLDIFLine(record[0]); strings.TrimSpace(string(version.value)) != "1" {
+			return nil, fmt.Errorf("unsupported LDIF version %q", version.value)
+		}
+		record = record[1:]
+	}
+	if len(record) == 0 {
+		return nil, fmt.Errorf("the LDIF doesn't contain a record")
+	}
+
+	dn, err := parseLDIFLine(record[0])
+	if err != nil {
+		return nil, err
+	} else if !strings.EqualFold(dn.attributeType, "dn") {
+		return nil, fmt.Errorf("the record has to start with its dn, but starts with %s", dn.attributeType)
+	} else if !utf8.Valid(dn.value) {
+		return nil, fmt.Errorf("the dn isn't valid UTF-8")
+	} else if _, err := ldap.ParseDN(string(dn.value)); err != nil {
+		return nil, fmt.Errorf("invalid dn %q: %s", dn.value, err)
+	}
+
+	r := LDIFRecord{DN: string(dn.value), Attributes: map[string][][]byte{}}
+	for _, line := range record[1:] {
+		attribute, err := parseLDIFLine(line)
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(attribute.attributeType, "changetype") {
+			return nil, fmt.Errorf("change records are not supported, only content records")
+		} else if !ldifAttributeDescription.MatchString(attribute.attributeType) {
+			return nil, fmt.Errorf("invalid attribute type %q", attribute.attributeType)
+		}
+
+		attributeType := attribute.attributeType
+		for _, existing := range r.Types {
+			if strings.EqualFold(existing, attributeType) {
+				attributeType = existing
+			}
+		}
+		if _, exists := r.Attributes[attributeType]; !exists {
+			r.Types = append(r.Types, attributeType)
+		}
+		r.Attributes[attributeType] = append(r.Attributes[attributeType], attribute.value)
+	}
+	return &r, nil
+}
+
+// ldifLine is an attribute type together with a value of an unfolded LDIF line.
+type ldifLine struct {
+	attributeType string
+	value         []byte
+}
+
+// parseLDIFLine splits an unfolded LDIF line into its attribute type and value, decoding base64 values.
+func parseLDIFLine(line string) (ldifLine, error) {
+	separator := strings.Index(line, ":")
+	if separator <= 0 {
+		return ldifLine{}, fmt.Errorf("invalid line %q, expected an attribute type followed by a colon", line)
+	}
+	l := ldifLine{attributeType: line[:separator]}
+	value := line[separator+1:]
+
+	switch {
+	case strings.HasPrefix(value, ":"):
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimLeft(value[1:], " "))
+		if err != nil {
+			return ldifLine{}, fmt.Errorf("invalid base64 value of %s: %s", l.attributeType, err)
+		}
+		l.value = decoded
+	case strings.HasPrefix(value, "<"):
+		return ldifLine{}, fmt.Errorf("the value of %s is referenced by URL, which isn't supported", l.attributeType)
+	default:
+		l.value = []byte(strings.TrimLeft(value, " "))
+	}
+	return l, nil
+}
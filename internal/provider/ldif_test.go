@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseLDIFRecord(t *testing.T) {
+	record, err := ParseLDIFRecord(`version: 1
+# a comment, which is
+  folded
+dn: cn=Doe\, John,ou=people,
+ dc=example,dc=com
+objectClass: person
+objectclass: top
+cn: Doe, John
+sn:: RMO2ZQ==
+description: a description which is fol
+ ded
+jpegPhoto:: /9j/
+telephoneNumber:
+
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, `cn=Doe\, John,ou=people,dc=example,dc=com`, record.DN)
+	assert.Equal(t, []string{"objectClass", "cn", "sn", "description", "jpegPhoto", "telephoneNumber"}, record.Types)
+	assert.Equal(t, map[string][]string{
+		"objectClass":     {"person", "top"},
+		"cn":              {"Doe, John"},
+		"sn":              {"Döe"},
+		"description":     {"a description which is folded"},
+		"jpegPhoto":       {"/9j/"},
+		"telephoneNumber": {""},
+	}, ldifAttributes(record))
+	assert.Equal(t, []byte{0xff, 0xd8, 0xff}, record.Attributes["jpegPhoto"][0])
+
+	for _, invalid := range []string{
+		"",
+		"# only a comment",
+		"cn: test\ndn: cn=test,dc=example,dc=com",
+		"dn: cn=test,,dc=example,dc=com",
+		"dn: cn=test,dc=example,dc=com\nchangetype: delete",
+		"dn: cn=test,dc=example,dc=com\njpegPhoto:< file:///photo.jpg",
+		"dn: cn=test,dc=example,dc=com\nsn:: not base64",
+		"dn: cn=test,dc=example,dc=com\nsn test",
+		"dn: cn=test,dc=example,dc=com\ns_n: test",
+		"dn: cn=one,dc=example,dc=com\nsn: one\n\ndn: cn=two,dc=example,dc=com\nsn: two",
+		" folded: nothing\ndn: cn=test,dc=example,dc=com",
+		"version: 2\ndn: cn=test,dc=example,dc=com",
+	} {
+		_, err := ParseLDIFRecord(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestLDIFChanges(t *testing.T) {
+	record, err := ParseLDIFRecord("dn: cn=test,dc=example,dc=com\nobjectClass: person\ncn: test\nsn: changed\nDescription: b\nDescription: a")
+	assert.NoError(t, err)
+
+	// unchanged attributes are left alone, even if they are spelled or ordered differently
+	assert.Equal(t, []ldap.Change{
+		{Operation: ldap.ReplaceAttribute, Modification: ldap.PartialAttribute{Type: "sn", Vals: []string{"changed"}}},
+		{Operation: ldap.DeleteAttribute, Modification: ldap.PartialAttribute{Type: "telephoneNumber", Vals: []string{}}},
+	}, ldifChanges(record, map[string][]string{
+		"objectClass":     {"Person"},
+		"cn":              {"test"},
+		"sn":              {"test"},
+		"description":     {"a", "b"},
+		"telephoneNumber": {"+1 555 0100"},
+		"mail":            {},
+	}))
+}
@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/thoas/go-funk"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrMemberAttributeUnknown is returned by detectMemberAttribute if the object classes of a group don't determine its
+// member attribute.
+var ErrMemberAttributeUnknown = errors.New("can not detect the member attribute")
+
+// groupMemberAttributes maps the object classes of groups to the attribute storing their members.
+var groupMemberAttributes = map[string]string{
+	"groupofnames":       "member",
+	"groupofuniquenames": "uniqueMember",
+	"posixgroup":         "memberUid",
+	"group":              "member",
+}
+
+// detectMemberAttribute returns the attribute storing the members of a group with the given object classes. Groups
+// whose object classes map to different member attributes, like a groupOfNames which is a posixGroup as well, and
+// groups without any known object class return ErrMemberAttributeUnknown.
+func detectMemberAttribute(objectClasses []string) (string, error) {
+	var attributes []string
+	for _, objectClass := range objectClasses {
+		if attribute, ok := groupMemberAttributes[strings.ToLower(objectClass)]; ok && !funk.ContainsString(attributes, attribute) {
+			attributes = append(attributes, attribute)
+		}
+	}
+	switch len(attributes) {
+	case 1:
+		return attributes[0], nil
+	case 0:
+		return "", fmt.Errorf("%w: none of the object classes %s is a known group object class", ErrMemberAttributeUnknown, strings.Join(objectClasses, ", "))
+	default:
+		sort.Strings(attributes)
+		return "", fmt.Errorf("%w: the object classes %s store members in %s", ErrMemberAttributeUnknown, strings.Join(objectClasses, ", "), strings.Join(attributes, " and "))
+	}
+}
+
+// readMemberAttribute reads the object classes of a group and detects its member attribute.
+func readMemberAttribute(ctx context.Context, conn *ldap.Conn, groupDN string) (string, error) {
+	start := time.Now()
+	entry, err := GetEntry(conn, groupDN, "objectClass")
+	LogOperation(ctx, "search", groupDN, start)
+	if err != nil {
+		return "", err
+	}
+	return detectMemberAttribute(entry.GetAttributeValues("objectClass"))
+}
+
+// addMemberAttributeError reports an error of readMemberAttribute. If the member attribute can't be detected, the
+// error is attached to member_attribute, which has to be set explicitly then.
+func addMemberAttributeError(diagnostics *diag.Diagnostics, err error, phase string, groupDN string) {
+	if errors.Is(err, ErrMemberAttributeUnknown) {
+		diagnostics.AddAttributeError(
+			path.Root("member_attribute"),
+			"Member attribute not detected",
+			fmt.Sprintf("Reading the group %s returned: %s. Set member_attribute explicitly", groupDN, err),
+		)
+		return
+	}
+	addOperationError(diagnostics, err, phase, groupDN,
+		"Can not read group",
+		err.Error(),
+	)
+}
+
+// memberMatchingRule returns the matching rule comparing the values of a member attribute. memberUid stores user
+// names, which are compared case-sensitively, the other member attributes store DNs.
+func memberMatchingRule(memberAttribute string) string {
+	if strings.EqualFold(memberAttribute, "memberUid") {
+		return matchingRuleCaseExact
+	}
+	return matchingRuleDistinguishedName
+}
+
+// memberValue returns the value stored in the member attribute for the member with the given DN. memberUid stores the
+// uid of the member, which is taken from its DN, e.g. alice for uid=alice,ou=people,dc=example,dc=com. Values which
+// aren't DNs are stored as they are.
+func memberValue(memberAttribute string, member string) (string, error) {
+	if !strings.EqualFold(memberAttribute, "memberUid") {
+		return member, nil
+	}
+	dn, err := ldap.ParseDN(member)
+	if err != nil || len(dn.RDNs) == 0 {
+		return member, nil
+	}
+	for _, attribute := range dn.RDNs[0].Attributes {
+		if strings.EqualFold(attribute.Type, "uid") {
+			return attribute.Value, nil
+		}
+	}
+	return "", fmt.Errorf("the group stores user names in memberUid, but the DN %s doesn't start with a uid", member)
+}
@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDetectMemberAttribute(t *testing.T) {
+	for expected, objectClasses := range map[string][]string{
+		"member":       {"top", "groupOfNames"},
+		"uniqueMember": {"top", "GroupOfUniqueNames"},
+		"memberUid":    {"top", "posixGroup"},
+	} {
+		memberAttribute, err := detectMemberAttribute(objectClasses)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, memberAttribute)
+	}
+
+	// Active Directory groups
+	memberAttribute, err := detectMemberAttribute([]string{"top", "group"})
+	assert.NoError(t, err)
+	assert.Equal(t, "member", memberAttribute)
+
+	// ambiguous and unknown object classes
+	_, err = detectMemberAttribute([]string{"groupOfNames", "posixGroup"})
+	assert.ErrorIs(t, err, ErrMemberAttributeUnknown)
+	assert.ErrorContains(t, err, "member and memberUid")
+	_, err = detectMemberAttribute([]string{"top", "organizationalUnit"})
+	assert.ErrorIs(t, err, ErrMemberAttributeUnknown)
+}
+
+func TestMemberValue(t *testing.T) {
+	value, err := memberValue("member", "uid=alice,ou=people,dc=example,dc=com")
+	assert.NoError(t, err)
+	assert.Equal(t, "uid=alice,ou=people,dc=example,dc=com", value)
+
+	value, err = memberValue("memberUid", "UID=alice,ou=people,dc=example,dc=com")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", value)
+
+	value, err = memberValue("memberUid", "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", value)
+
+	_, err = memberValue("memberUid", "cn=alice,ou=people,dc=example,dc=com")
+	assert.Error(t, err)
+
+	assert.Equal(t, matchingRuleCaseExact, memberMatchingRule("memberUid"))
+	assert.Equal(t, matchingRuleDistinguishedName, memberMatchingRule("uniqueMember"))
+}
@@ -0,0 +1,196 @@
+package provider
+
+import (
+	"github.com/go-ldap/ldap/v3"
+	"github.com/thoas/go-funk"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Equality matching rules (RFC 4517, section 4.2) used to compare attribute values.
+const (
+	matchingRuleBoolean           = "booleanMatch"
+	matchingRuleCaseExact         = "caseExactMatch"
+	matchingRuleCaseIgnore        = "caseIgnoreMatch"
+	matchingRuleDistinguishedName = "distinguishedNameMatch"
+	matchingRuleInteger           = "integerMatch"
+	matchingRuleOctetString       = "octetStringMatch"
+	matchingRuleTelephoneNumber   = "telephoneNumberMatch"
+)
+
+// matchingRules lists the matching rules, which can be configured in matching_rules.
+var matchingRules = []string{
+	matchingRuleBoolean,
+	matchingRuleCaseExact,
+	matchingRuleCaseIgnore,
+	matchingRuleDistinguishedName,
+	matchingRuleInteger,
+	matchingRuleOctetString,
+	matchingRuleTelephoneNumber,
+}
+
+// builtinMatchingRules maps well-known attribute types to their equality matching rule. The IA5 variants of the
+// matching rules are compared like their Directory String counterparts. Attribute types not listed here are compared
+// exactly.
+var builtinMatchingRules = map[string]string{
+	"aliasedobjectname":        matchingRuleDistinguishedName,
+	"associatedname":           matchingRuleDistinguishedName,
+	"creatorsname":             matchingRuleDistinguishedName,
+	"distinguishedname":        matchingRuleDistinguishedName,
+	"documentauthor":           matchingRuleDistinguishedName,
+	"manager":                  matchingRuleDistinguishedName,
+	"member":                   matchingRuleDistinguishedName,
+	"memberof":                 matchingRuleDistinguishedName,
+	"modifiersname":            matchingRuleDistinguishedName,
+	"owner":                    matchingRuleDistinguishedName,
+	"roleoccupant":             matchingRuleDistinguishedName,
+	"secretary":                matchingRuleDistinguishedName,
+	"seealso":                  matchingRuleDistinguishedName,
+	"businesscategory":         matchingRuleCaseIgnore,
+	"c":                        matchingRuleCaseIgnore,
+	"cn":                       matchingRuleCaseIgnore,
+	"dc":                       matchingRuleCaseIgnore,
+	"departmentnumber":         matchingRuleCaseIgnore,
+	"displayname":              matchingRuleCaseIgnore,
+	"employeenumber":           matchingRuleCaseIgnore,
+	"employeetype":             matchingRuleCaseIgnore,
+	"givenname":                matchingRuleCaseIgnore,
+	"initials":                 matchingRuleCaseIgnore,
+	"l":                        matchingRuleCaseIgnore,
+	"mail":                     matchingRuleCaseIgnore,
+	"o":                        matchingRuleCaseIgnore,
+	"ou":                       matchingRuleCaseIgnore,
+	"sn":                       matchingRuleCaseIgnore,
+	"st":                       matchingRuleCaseIgnore,
+	"street":                   matchingRuleCaseIgnore,
+	"title":                    matchingRuleCaseIgnore,
+	"uid":                      matchingRuleCaseIgnore,
+	"facsimiletelephonenumber": matchingRuleTelephoneNumber,
+	"homephone":                matchingRuleTelephoneNumber,
+	"mobile":                   matchingRuleTelephoneNumber,
+	"pager":                    matchingRuleTelephoneNumber,
+	"telephonenumber":          matchingRuleTelephoneNumber,
+	"gidnumber":                matchingRuleInteger,
+	"uidnumber":                matchingRuleInteger,
+	"pwdallowuserchange":       matchingRuleBoolean,
+	"pwdlockout":               matchingRuleBoolean,
+	"pwdmustchange":            matchingRuleBoolean,
+	"pwdsafemodify":            matchingRuleBoolean,
+}
+
+// lookupMatchingRule returns the equality matching rule of an attribute description. Configured rules take precedence
+// over the built-in ones, options like language tags are ignored.
+func lookupMatchingRule(attributeDescription string, rules map[string]string) string {
+	attributeType := strings.SplitN(attributeDescription, ";", 2)[0]
+	for configured, rule := range rules {
+		if strings.EqualFold(configured, attributeType) {
+			return rule
+		}
+	}
+	return builtinMatchingRules[strings.ToLower(attributeType)]
+}
+
+// equalValues checks whether two values are equal according to the given matching rule. Values which can't be
+// normalized using the rule, e.g. invalid DNs, have to match exactly.
+func equalValues(rule string, a string, b string) bool {
+	if a == b {
+		return true
+	}
+	switch rule {
+	case matchingRuleDistinguishedName:
+		if dnA, err := ldap.ParseDN(a); err == nil {
+			if dnB, err := ldap.ParseDN(b); err == nil {
+				return dnA.EqualFold(dnB)
+			}
+		}
+	case matchingRuleCaseExact:
+		return normalizeSpaces(a) == normalizeSpaces(b)
+	case matchingRuleCaseIgnore:
+		return strings.EqualFold(normalizeSpaces(a), normalizeSpaces(b))
+	case matchingRuleTelephoneNumber:
+		return strings.EqualFold(removeTelephoneNumberSeparators(a), removeTelephoneNumberSeparators(b))
+	case matchingRuleBoolean:
+		return strings.EqualFold(a, b) && funk.ContainsString([]string{"TRUE", "FALSE"}, strings.ToUpper(a))
+	case matchingRuleInteger:
+		if intA, err := strconv.ParseInt(a, 10, 64); err == nil {
+			if intB, err := strconv.ParseInt(b, 10, 64); err == nil {
+				return intA == intB
+			}
+		}
+	}
+	return false
+}
+
+// normalizeSpaces removes leading and trailing spaces and replaces inner runs of spaces by a single one (RFC 4518,
+// section 2.6.1).
+func normalizeSpaces(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// removeTelephoneNumberSeparators removes spaces and hyphens, which are insignificant for telephone numbers (RFC 4518,
+// section 2.6.3).
+func removeTelephoneNumberSeparators(value string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(value)
+}
+
+// containsValue checks whether the list contains a value equal to the given value.
+func containsValue(rule string, values []string, value string) bool {
+	for _, v := range values {
+		if equalValues(rule, v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// subtractValues returns the values of a, which are not equal to any value of b.
+func subtractValues(rule string, a []string, b []string) []string {
+	var result []string
+	for _, value := range a {
+		if !containsValue(rule, b, value) && !funk.ContainsString(result, value) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// preferStateValues replaces the values returned by the server with the equal values from the state, so the state
+// keeps the form of the values used in the configuration.
+func preferStateValues(rule string, serverValues []string, stateValues []string) []string {
+	values := make([]string, len(serverValues))
+	for i, serverValue := range serverValues {
+		values[i] = serverValue
+		for _, stateValue := range stateValues {
+			if equalValues(rule, serverValue, stateValue) {
+				values[i] = stateValue
+				break
+			}
+		}
+	}
+	return values
+}
+
+// sameDN checks whether both DNs name the same entry, regardless of case and spacing.
+func sameDN(a string, b string) bool {
+	return equalValues(matchingRuleDistinguishedName, a, b)
+}
+
+// normalizeDN returns a representation of the DN which is the same for all spellings of the entry's name, regardless
+// of case, spacing and the order of multi-valued RDNs. Invalid DNs are only lower-cased.
+func normalizeDN(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return strings.ToLower(dn)
+	}
+	rdns := make([]string, len(parsed.RDNs))
+	for i, rdn := range parsed.RDNs {
+		attributes := make([]string, len(rdn.Attributes))
+		for j, attribute := range rdn.Attributes {
+			attributes[j] = strings.ToLower(attribute.Type) + "=" + strings.ToLower(normalizeSpaces(attribute.Value))
+		}
+		sort.Strings(attributes)
+		rdns[i] = strings.Join(attributes, "+")
+	}
+	return strings.Join(rdns, ",")
+}
@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLookupMatchingRule(t *testing.T) {
+	assert.Equal(t, matchingRuleDistinguishedName, lookupMatchingRule("Member", nil))
+	assert.Equal(t, matchingRuleCaseIgnore, lookupMatchingRule("description;lang-en", map[string]string{"Description": matchingRuleCaseIgnore}))
+	assert.Equal(t, matchingRuleCaseExact, lookupMatchingRule("cn", map[string]string{"cn": matchingRuleCaseExact}))
+	assert.Equal(t, "", lookupMatchingRule("memberUid", nil))
+}
+
+func TestEqualValues(t *testing.T) {
+	assert.True(t, equalValues(matchingRuleDistinguishedName, "cn=Admin,dc=example,dc=com", "CN=admin, dc=Example,DC=com"))
+	assert.False(t, equalValues(matchingRuleDistinguishedName, "cn=admin,dc=example,dc=com", "cn=other,dc=example,dc=com"))
+
+	// values which can't be normalized are compared exactly
+	assert.True(t, equalValues(matchingRuleDistinguishedName, "not a dn", "not a dn"))
+	assert.False(t, equalValues(matchingRuleDistinguishedName, "not a dn", "Not a DN"))
+	assert.False(t, equalValues(matchingRuleInteger, "1", "one"))
+
+	assert.True(t, equalValues(matchingRuleCaseIgnore, "John  Doe ", "john doe"))
+	assert.False(t, equalValues(matchingRuleCaseIgnore, "John Doe", "JohnDoe"))
+	assert.True(t, equalValues(matchingRuleCaseExact, " John  Doe", "John Doe"))
+	assert.False(t, equalValues(matchingRuleCaseExact, "John Doe", "john doe"))
+	assert.True(t, equalValues(matchingRuleTelephoneNumber, "+1 555-0100", "+15550100"))
+	assert.True(t, equalValues(matchingRuleBoolean, "true", "TRUE"))
+	assert.False(t, equalValues(matchingRuleBoolean, "yes", "YES"))
+	assert.True(t, equalValues(matchingRuleInteger, "+010", "10"))
+	assert.False(t, equalValues(matchingRuleOctetString, "a", "A"))
+
+	// attributes without matching rule are compared exactly
+	assert.False(t, equalValues("", "admin", "Admin"))
+}
+
+func TestPreferStateValues(t *testing.T) {
+	assert.Equal(t,
+		[]string{"CN=Admin, dc=example,dc=com", "cn=new,dc=example,dc=com"},
+		preferStateValues(matchingRuleDistinguishedName, []string{"cn=admin,dc=example,dc=com", "cn=new,dc=example,dc=com"}, []string{"CN=Admin, dc=example,dc=com"}),
+	)
+	assert.Equal(t,
+		[]string{"admin"},
+		preferStateValues(lookupMatchingRule("memberUid", nil), []string{"admin"}, []string{"Admin"}),
+	)
+}
+
+func TestNormalizeDN(t *testing.T) {
+	assert.Equal(t, normalizeDN("cn=Group,dc=example,dc=com"), normalizeDN("CN=group, DC=Example,dc=com"))
+	assert.Equal(t, normalizeDN("cn=a+sn=b,dc=example,dc=com"), normalizeDN("sn=B+cn=A,dc=example,dc=com"))
+	assert.NotEqual(t, normalizeDN("cn=first,dc=example,dc=com"), normalizeDN("cn=second,dc=example,dc=com"))
+	assert.Equal(t, "not a dn", normalizeDN("Not a DN"))
+}
@@ -0,0 +1,337 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/pbkdf2"
+	"hash"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The schemes supported by password_hash_scheme.
+const (
+	passwordHashSSHA        = "SSHA"
+	passwordHashSSHA512     = "SSHA512"
+	passwordHashSHA512Crypt = "SHA512-CRYPT"
+	passwordHashPBKDF2      = "PBKDF2"
+	passwordHashArgon2      = "ARGON2"
+)
+
+var passwordHashSchemes = []string{passwordHashSSHA, passwordHashSSHA512, passwordHashSHA512Crypt, passwordHashPBKDF2, passwordHashArgon2}
+
+// ErrUnsupportedPasswordScheme is returned by VerifyPassword for hashes of schemes it can't verify.
+var ErrUnsupportedPasswordScheme = errors.New("unsupported password scheme")
+
+// The parameters of newly hashed passwords.
+const (
+	passwordSaltLength     = 16
+	sha512CryptRounds      = 5000
+	pbkdf2Iterations       = 10000
+	argon2Iterations       = 3
+	argon2Memory           = 64 * 1024
+	argon2Parallelism      = 1
+	argon2KeyLength        = 32
+	cryptAlphabet          = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	sha512CryptMaxSaltSize = 16
+)
+
+// cryptEncoding is the base64 alphabet of crypt(3), which encodes the salt of SHA512-CRYPT hashes.
+var cryptEncoding = base64.NewEncoding(cryptAlphabet).WithPadding(base64.NoPadding)
+
+// adaptedEncoding is the base64 variant used by PBKDF2 hashes, which replaces "+" by ".".
+var adaptedEncoding = base64.NewEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789./").WithPadding(base64.NoPadding)
+
+// HashPassword hashes the password with a random salt into a userPassword value of the given scheme.
+func HashPassword(scheme string, password string) (string, error) {
+	salt := make([]byte, passwordSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	switch scheme {
+	case passwordHashSSHA:
+		return "{SSHA}" + base64.StdEncoding.EncodeToString(append(saltedDigest(sha1.New(), password, salt), salt...)), nil
+	case passwordHashSSHA512:
+		return "{SSHA512}" + base64.StdEncoding.EncodeToString(append(saltedDigest(sha512.New(), password, salt), salt...)), nil
+	case passwordHashSHA512Crypt:
+		return "{CRYPT}" + sha512Crypt([]byte(password), []byte(cryptEncoding.EncodeToString(salt)[:sha512CryptMaxSaltSize]), sha512CryptRounds, false), nil
+	case passwordHashPBKDF2:
+		key := pbkdf2.Key([]byte(password), salt, pbkdf2Iterations, sha512.Size, sha512.New)
+		return fmt.Sprintf("{PBKDF2-SHA512}%d$%s$%s", pbkdf2Iterations, adaptedEncoding.EncodeToString(salt), adaptedEncoding.EncodeToString(key)), nil
+	case passwordHashArgon2:
+		key := argon2.IDKey([]byte(password), salt, argon2Iterations, argon2Memory, argon2Parallelism, argon2KeyLength)
+		return fmt.Sprintf("{ARGON2}$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, argon2Memory, argon2Iterations, argon2Parallelism,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	default:
+		return "", fmt.Errorf("unknown password hash scheme %s", scheme)
+	}
+}
+
+// VerifyPassword checks whether the userPassword value is the hash of the password. Values without a scheme are
+// compared verbatim, values of schemes which can't be verified return ErrUnsupportedPasswordScheme.
+func VerifyPassword(value string, password string) (bool, error) {
+	if !strings.HasPrefix(value, "{") || !strings.Contains(value, "}") {
+		return subtle.ConstantTimeCompare([]byte(value), []byte(password)) == 1, nil
+	}
+	end := strings.Index(value, "}")
+	scheme, hashed := strings.ToUpper(value[1:end]), value[end+1:]
+
+	switch scheme {
+	case "SSHA":
+		return verifySaltedDigest(sha1.New(), hashed, password)
+	case "SSHA512":
+		return verifySaltedDigest(sha512.New(), hashed, password)
+	case "CRYPT":
+		return verifySHA512Crypt(hashed, password)
+	case "PBKDF2", "PBKDF2-SHA1":
+		return verifyPBKDF2(sha1.New, hashed, password)
+	case "PBKDF2-SHA256":
+		return verifyPBKDF2(sha256.New, hashed, password)
+	case "PBKDF2-SHA512":
+		return verifyPBKDF2(sha512.New, hashed, password)
+	case "ARGON2":
+		return verifyArgon2(hashed, password)
+	default:
+		return false, fmt.Errorf("%w: %s", ErrUnsupportedPasswordScheme, scheme)
+	}
+}
+
+// passwordMatches checks whether one of the userPassword values is the password. Values which can't be read or
+// verified match, since a change can't be detected for them.
+func passwordMatches(values []string, password string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, value := range values {
+		if ok, err := VerifyPassword(value, password); ok || err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// setHashedPassword replaces userPassword with the password hashed using the given scheme. Unlike the password modify
+// extended operation, this works for servers storing userPassword verbatim.
+func setHashedPassword(ctx context.Context, conn *ldap.Conn, locks *dnLocks, dn string, scheme string, password string) error {
+	hashed, err := HashPassword(scheme, password)
+	if err != nil {
+		return err
+	}
+	r := ldap.NewModifyRequest(dn, []ldap.Control{})
+	r.Replace("userPassword", []string{hashed})
+
+	start := time.Now()
+	defer LogOperation(ctx, "modify", dn, start)
+	return WithContext(ctx, func() error {
+		return locks.write(ctx, dn, func() error {
+			return conn.Modify(r)
+		})
+	})
+}
+
+// saltedDigest returns the digest of the password followed by the salt.
+func saltedDigest(h hash.Hash, password string, salt []byte) []byte {
+	h.Write([]byte(password))
+	h.Write(salt)
+	return h.Sum(nil)
+}
+
+func verifySaltedDigest(h hash.Hash, hashed string, password string) (bool, error) {
+	decoded, err := base64.StdEncoding.DecodeString(hashed)
+	if err != nil {
+		return false, err
+	}
+	if len(decoded) < h.Size() {
+		return false, fmt.Errorf("salted digest is too short")
+	}
+	digest, salt := decoded[:h.Size()], decoded[h.Size():]
+	return subtle.ConstantTimeCompare(digest, saltedDigest(h, password, salt)) == 1, nil
+}
+
+func verifySHA512Crypt(hashed string, password string) (bool, error) {
+	if !strings.HasPrefix(hashed, "$6$") {
+		return false, fmt.Errorf("%w: crypt hashes other than SHA512-CRYPT", ErrUnsupportedPasswordScheme)
+	}
+	parts := strings.Split(hashed[3:], "$")
+	rounds, customRounds := sha512CryptRounds, false
+	if len(parts) == 3 && strings.HasPrefix(parts[0], "rounds=") {
+		var err error
+		if rounds, err = strconv.Atoi(strings.TrimPrefix(parts[0], "rounds=")); err != nil {
+			return false, err
+		}
+		customRounds = true
+		parts = parts[1:]
+	}
+	if len(parts) != 2 {
+		return false, fmt.Errorf("malformed SHA512-CRYPT hash")
+	}
+	return subtle.ConstantTimeCompare([]byte("$6$"+hashed[3:]), []byte(sha512Crypt([]byte(password), []byte(parts[0]), rounds, customRounds))) == 1, nil
+}
+
+func verifyPBKDF2(h func() hash.Hash, hashed string, password string) (bool, error) {
+	parts := strings.Split(hashed, "$")
+	if len(parts) != 3 {
+		return false, fmt.Errorf("malformed PBKDF2 hash")
+	}
+	iterations, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false, err
+	}
+	salt, err := adaptedEncoding.DecodeString(parts[1])
+	if err != nil {
+		return false, err
+	}
+	key, err := adaptedEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(key, pbkdf2.Key([]byte(password), salt, iterations, len(key), h)) == 1, nil
+}
+
+func verifyArgon2(hashed string, password string) (bool, error) {
+	parts := strings.Split(hashed, "$")
+	if len(parts) != 6 || parts[0] != "" {
+		return false, fmt.Errorf("malformed Argon2 hash")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return false, err
+	} else if version != argon2.Version {
+		return false, fmt.Errorf("%w: Argon2 version %d", ErrUnsupportedPasswordScheme, version)
+	}
+	var memory, iterations uint32
+	var parallelism uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &parallelism); err != nil {
+		return false, err
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, err
+	}
+
+	switch parts[1] {
+	case "argon2id":
+		return subtle.ConstantTimeCompare(key, argon2.IDKey([]byte(password), salt, iterations, memory, parallelism, uint32(len(key)))) == 1, nil
+	case "argon2i":
+		return subtle.ConstantTimeCompare(key, argon2.Key([]byte(password), salt, iterations, memory, parallelism, uint32(len(key)))) == 1, nil
+	default:
+		return false, fmt.Errorf("%w: %s", ErrUnsupportedPasswordScheme, parts[1])
+	}
+}
+
+// sha512CryptPermutation is the order in which SHA512-CRYPT encodes the bytes of the final digest.
+var sha512CryptPermutation = [][3]int{
+	{0, 21, 42}, {22, 43, 1}, {44, 2, 23}, {3, 24, 45}, {25, 46, 4}, {47, 5, 26}, {6, 27, 48},
+	{28, 49, 7}, {50, 8, 29}, {9, 30, 51}, {31, 52, 10}, {53, 11, 32}, {12, 33, 54}, {34, 55, 13},
+	{56, 14, 35}, {15, 36, 57}, {37, 58, 16}, {59, 17, 38}, {18, 39, 60}, {40, 61, 19}, {62, 20, 41},
+}
+
+// sha512Crypt implements the SHA512-CRYPT scheme of crypt(3) as specified in https://www.akkadia.org/drepper/SHA-crypt.txt.
+// The rounds are only included in the result if customRounds is set.
+func sha512Crypt(password []byte, salt []byte, rounds int, customRounds bool) string {
+	if len(salt) > sha512CryptMaxSaltSize {
+		salt = salt[:sha512CryptMaxSaltSize]
+	}
+	if rounds < 1000 {
+		rounds = 1000
+	} else if rounds > 999999999 {
+		rounds = 999999999
+	}
+
+	alternate := sha512.New()
+	alternate.Write(password)
+	alternate.Write(salt)
+	alternate.Write(password)
+	alternateSum := alternate.Sum(nil)
+
+	a := sha512.New()
+	a.Write(password)
+	a.Write(salt)
+	for i := len(password); i > 0; i -= sha512.Size {
+		if i > sha512.Size {
+			a.Write(alternateSum)
+		} else {
+			a.Write(alternateSum[:i])
+		}
+	}
+	for i := len(password); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			a.Write(alternateSum)
+		} else {
+			a.Write(password)
+		}
+	}
+	aSum := a.Sum(nil)
+
+	p := sha512.New()
+	for i := 0; i < len(password); i++ {
+		p.Write(password)
+	}
+	pSequence := bytes.Repeat(p.Sum(nil), len(password)/sha512.Size+1)[:len(password)]
+
+	s := sha512.New()
+	for i := 0; i < 16+int(aSum[0]); i++ {
+		s.Write(salt)
+	}
+	sSequence := s.Sum(nil)[:len(salt)]
+
+	c := aSum
+	for i := 0; i < rounds; i++ {
+		h := sha512.New()
+		if i&1 != 0 {
+			h.Write(pSequence)
+		} else {
+			h.Write(c)
+		}
+		if i%3 != 0 {
+			h.Write(sSequence)
+		}
+		if i%7 != 0 {
+			h.Write(pSequence)
+		}
+		if i&1 != 0 {
+			h.Write(c)
+		} else {
+			h.Write(pSequence)
+		}
+		c = h.Sum(nil)
+	}
+
+	var result strings.Builder
+	result.WriteString("$6$")
+	if customRounds {
+		result.WriteString(fmt.Sprintf("rounds=%d$", rounds))
+	}
+	result.Write(salt)
+	result.WriteString("$")
+	for _, indexes := range sha512CryptPermutation {
+		writeCryptBase64(&result, uint(c[indexes[0]])<<16|uint(c[indexes[1]])<<8|uint(c[indexes[2]]), 4)
+	}
+	writeCryptBase64(&result, uint(c[63]), 2)
+	return result.String()
+}
+
+// writeCryptBase64 writes the lowest 6*n bits of the value in the base64 alphabet of crypt(3), least significant
+// bits first.
+func writeCryptBase64(result *strings.Builder, value uint, n int) {
+	for i := 0; i < n; i++ {
+		result.WriteByte(cryptAlphabet[value&0x3f])
+		value >>= 6
+	}
+}
@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestSHA512Crypt(t *testing.T) {
+	// test vectors of the specification and glibc
+	assert.Equal(t, "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1",
+		sha512Crypt([]byte("Hello world!"), []byte("saltstring"), 5000, false))
+	assert.Equal(t, "$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v.",
+		sha512Crypt([]byte("Hello world!"), []byte("saltstringsaltstring"), 10000, true))
+	assert.Equal(t, "$6$rounds=1000$abc$MqEcPZUYRGGcOeq7PhMpfjfu/F0HrVEI0OlZBijWvO8mSG77iNUDP5MqFceKpJTBc8iITVtNyLiNTRNCxv6oh0",
+		sha512Crypt([]byte("secret"), []byte("abc"), 1000, true))
+	assert.Equal(t, "$6$abcdefgh$Xk3qIFrum/O9Dov6VNMoYrWWdSpzcfMaO3jYuLG5e5mC7HqYAELZfnSnb7gsyZezFIYRKPLd3FpcQ2ReT4Il4/",
+		sha512Crypt([]byte(strings.Repeat("x", 130)), []byte("abcdefgh"), 5000, false))
+}
+
+func TestHashPassword(t *testing.T) {
+	for _, scheme := range passwordHashSchemes {
+		hashed, err := HashPassword(scheme, "secret")
+		assert.NoError(t, err, scheme)
+
+		ok, err := VerifyPassword(hashed, "secret")
+		assert.NoError(t, err, scheme)
+		assert.True(t, ok, scheme)
+
+		ok, err = VerifyPassword(hashed, "wrong")
+		assert.NoError(t, err, scheme)
+		assert.False(t, ok, scheme)
+
+		// every hash uses a new salt
+		other, _ := HashPassword(scheme, "secret")
+		assert.NotEqual(t, hashed, other, scheme)
+	}
+
+	_, err := HashPassword("MD5", "secret")
+	assert.Error(t, err)
+}
+
+func TestVerifyPassword(t *testing.T) {
+	// hashes generated by other implementations
+	for _, value := range []string{
+		"{SSHA}uJDd0BIdJ9Z7yDCZNWdgYeb33+cBAgME",
+		"{PBKDF2-SHA256}10000$AAECAwQFBgcICQoLDA0ODw$OImOyGKQvA8WMIN8WwuclUZFDykpCY/RxWOLBTOe8fg",
+		"{CRYPT}$6$rounds=1000$abc$MqEcPZUYRGGcOeq7PhMpfjfu/F0HrVEI0OlZBijWvO8mSG77iNUDP5MqFceKpJTBc8iITVtNyLiNTRNCxv6oh0",
+		"secret",
+	} {
+		ok, err := VerifyPassword(value, "secret")
+		assert.NoError(t, err, value)
+		assert.True(t, ok, value)
+	}
+
+	_, err := VerifyPassword("{MD5}Xr4ilOzQ4PCOq3aQ0qbuaQ==", "secret")
+	assert.True(t, errors.Is(err, ErrUnsupportedPasswordScheme))
+
+	// values which can't be read or verified don't count as changes
+	assert.True(t, passwordMatches(nil, "secret"))
+	assert.True(t, passwordMatches([]string{"{MD5}Xr4ilOzQ4PCOq3aQ0qbuaQ=="}, "secret"))
+	assert.False(t, passwordMatches([]string{"{CRYPT}$6$rounds=1000$abc$MqEcPZUYRGGcOeq7PhMpfjfu/F0HrVEI0OlZBijWvO8mSG77iNUDP5MqFceKpJTBc8iITVtNyLiNTRNCxv6oh0"}, "changed"))
+}
@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/thoas/go-funk"
+)
+
+var _ planmodifier.List = structuralObjectClassModifier{}
+
+// structuralObjectClassModifier requires replacing the object if its structural object classes change, because
+// servers refuse to change them with a modify operation. Auxiliary object classes can be changed in place.
+type structuralObjectClassModifier struct {
+	resource *LDAPObjectResource
+}
+
+func (m structuralObjectClassModifier) Description(_ context.Context) string {
+	return "the object is replaced if its structural object classes change"
+}
+
+func (m structuralObjectClassModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m structuralObjectClassModifier) PlanModifyList(ctx context.Context, request planmodifier.ListRequest, response *planmodifier.ListResponse) {
+	// nothing to replace on create and delete
+	if request.State.Raw.IsNull() || request.Plan.Raw.IsNull() || request.PlanValue.IsUnknown() {
+		return
+	}
+	// unchanged object classes don't need the subschema
+	if request.StateValue.Equal(request.PlanValue) {
+		return
+	}
+
+	var forceNew types.Bool
+	response.Diagnostics.Append(request.Plan.GetAttribute(ctx, path.Root("force_new_on_object_class_change"), &forceNew)...)
+	if !forceNew.IsNull() && !forceNew.ValueBool() {
+		return
+	}
+
+	var stateObjectClasses []string
+	response.Diagnostics.Append(request.StateValue.ElementsAs(ctx, &stateObjectClasses, false)...)
+	var planObjectClasses []string
+	response.Diagnostics.Append(request.PlanValue.ElementsAs(ctx, &planObjectClasses, false)...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	subschema, err := m.resource.subschema.get(m.resource.conn)
+	if err != nil {
+		tflog.Debug(ctx, "Can not read subschema, using well-known structural object classes", map[string]interface{}{"error": err.Error()})
+	}
+
+	stateStructural := StructuralObjectClasses(subschema, stateObjectClasses)
+	planStructural := StructuralObjectClasses(subschema, planObjectClasses)
+	if len(funk.SubtractString(stateStructural, planStructural)) > 0 || len(funk.SubtractString(planStructural, stateStructural)) > 0 {
+		response.RequiresReplace = true
+	}
+}
+
+// RequiresReplaceOnStructuralObjectClassChange returns a plan modifier, which replaces the object if its structural
+// object classes change.
+func RequiresReplaceOnStructuralObjectClassChange(resource *LDAPObjectResource) planmodifier.List {
+	return structuralObjectClassModifier{resource: resource}
+}
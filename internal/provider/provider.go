@@ -3,13 +3,21 @@ package provider
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"github.com/go-ldap/ldap/v3"
+	"github.com/go-ldap/ldap/v3/gssapi"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"net"
+	"net/url"
 	"os"
 	"strings"
 )
@@ -23,15 +31,32 @@ type LDAPProvider struct {
 	// provider is built and ran locally, and "test" when running acceptance
 	// testing.
 	version string
+	// ignoredControls lists controls which are treated as unsupported by the server, so tests can force the
+	// fallbacks of optional controls.
+	ignoredControls []string
 }
 
 // LDAPProviderModel describes the provider data model.
 type LDAPProviderModel struct {
-	LDAPURL               types.String `tfsdk:"ldap_url"`
-	LDAPBindDN            types.String `tfsdk:"ldap_bind_dn"`
-	LDAPBindPassword      types.String `tfsdk:"ldap_bind_password"`
-	LDAPTLSInsecureVerify types.Bool   `tfsdk:"ldap_tls_insecure_verify"`
-	LDAPTLSUseStartTLS    types.Bool   `tfsdk:"ldap_tls_use_starttls"`
+	LDAPURL                  types.String `tfsdk:"ldap_url"`
+	LDAPBindDN               types.String `tfsdk:"ldap_bind_dn"`
+	LDAPBindPassword         types.String `tfsdk:"ldap_bind_password"`
+	LDAPTLSInsecureVerify    types.Bool   `tfsdk:"ldap_tls_insecure_verify"`
+	LDAPTLSUseStartTLS       types.Bool   `tfsdk:"ldap_tls_use_starttls"`
+	LDAPTLSRenegotiation     types.String `tfsdk:"ldap_tls_renegotiation"`
+	LDAPTLSSessionCache      types.Bool   `tfsdk:"ldap_tls_session_cache"`
+	LDAPCACertificate        types.String `tfsdk:"ldap_ca_certificate"`
+	LDAPAllowInsecureBind    types.Bool   `tfsdk:"ldap_allow_insecure_bind"`
+	LDAPCredentialCache      types.String `tfsdk:"ldap_credential_cache"`
+	LDAPKrb5Config           types.String `tfsdk:"ldap_krb5_config"`
+	LDAPServicePrincipal     types.String `tfsdk:"ldap_service_principal"`
+	LDAPReferralBind         types.String `tfsdk:"ldap_referral_bind"`
+	LDAPReferralBindDN       types.String `tfsdk:"ldap_referral_bind_dn"`
+	LDAPReferralBindPassword types.String `tfsdk:"ldap_referral_bind_password"`
+	LDAPSourceAddress        types.String `tfsdk:"ldap_source_address"`
+	LDAPCheckControlSupport  types.Bool   `tfsdk:"ldap_check_control_support"`
+	LDAPAuthzIDOnBind        types.Bool   `tfsdk:"ldap_authzid_on_bind"`
+	LDAPReadOnly             types.Bool   `tfsdk:"ldap_read_only"`
 }
 
 func (p *LDAPProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -46,7 +71,8 @@ func (p *LDAPProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp
 Inspired by [elastic-infra/ldap](https://registry.terraform.io/providers/elastic-infra/ldap/latest), but updated to
 Terraform Framework and including ignoring attributes and a data source.
 
-All provider options can be set by the respective environment variables as well.
+All provider options can be set by the respective environment variables as well. Options configured in the provider
+block take precedence over the environment variables.
 `,
 		Attributes: map[string]schema.Attribute{
 			"ldap_url": schema.StringAttribute{
@@ -69,6 +95,68 @@ All provider options can be set by the respective environment variables as well.
 				MarkdownDescription: "Whether to connect using STARTTLS (`LDAP_TLS_USE_STARTTLS`)",
 				Optional:            true,
 			},
+			"ldap_tls_renegotiation": schema.StringAttribute{
+				MarkdownDescription: "Whether the server may request TLS renegotiation: `never` (default), `once` per connection or `freely` (`LDAP_TLS_RENEGOTIATION`)",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("never", "once", "freely"),
+				},
+			},
+			"ldap_tls_session_cache": schema.BoolAttribute{
+				MarkdownDescription: "Whether to cache TLS sessions, so connections to servers returned in referrals and reconnects can resume them (`LDAP_TLS_SESSION_CACHE`)",
+				Optional:            true,
+			},
+			"ldap_ca_certificate": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded CA certificates used to verify the certificate of the server instead of the system's trusted CAs (`LDAP_CACERT`)",
+				Optional:            true,
+			},
+			"ldap_allow_insecure_bind": schema.BoolAttribute{
+				MarkdownDescription: "Whether to allow sending the bind password over an unencrypted connection, i.e. an `ldap://` URL without STARTTLS (`LDAP_ALLOW_INSECURE_BIND`)",
+				Optional:            true,
+			},
+			"ldap_credential_cache": schema.StringAttribute{
+				MarkdownDescription: "Path to a Kerberos credential cache, e.g. created by `kinit`. If set, a GSSAPI bind is used instead of the bind DN and password (`LDAP_CREDENTIAL_CACHE`)",
+				Optional:            true,
+			},
+			"ldap_krb5_config": schema.StringAttribute{
+				MarkdownDescription: "Path to the Kerberos configuration used for the GSSAPI bind. Defaults to `KRB5_CONFIG` or `/etc/krb5.conf` (`LDAP_KRB5_CONFIG`)",
+				Optional:            true,
+			},
+			"ldap_service_principal": schema.StringAttribute{
+				MarkdownDescription: "Service principal of the LDAP server used for the GSSAPI bind. Defaults to `ldap/<host of ldap_url>` (`LDAP_SERVICE_PRINCIPAL`)",
+				Optional:            true,
+			},
+			"ldap_referral_bind": schema.StringAttribute{
+				MarkdownDescription: "How to authenticate to servers returned in referrals: `same` uses the credentials of the provider, `anonymous` doesn't bind and `explicit` uses `ldap_referral_bind_dn` and `ldap_referral_bind_password`. Defaults to `same` (`LDAP_REFERRAL_BIND`)",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(referralBindSame, referralBindAnonymous, referralBindExplicit),
+				},
+			},
+			"ldap_referral_bind_dn": schema.StringAttribute{
+				MarkdownDescription: "Bind DN used for servers returned in referrals if `ldap_referral_bind` is `explicit` (`LDAP_REFERRAL_BIND_DN`)",
+				Optional:            true,
+			},
+			"ldap_referral_bind_password": schema.StringAttribute{
+				MarkdownDescription: "Bind password used for servers returned in referrals if `ldap_referral_bind` is `explicit` (`LDAP_REFERRAL_BIND_PASSWORD`)",
+				Optional:            true,
+			},
+			"ldap_source_address": schema.StringAttribute{
+				MarkdownDescription: "Local IP address connections to the LDAP server and to servers returned in referrals originate from, e.g. to pass firewall rules on hosts with several addresses (`LDAP_SOURCE_ADDRESS`)",
+				Optional:            true,
+			},
+			"ldap_check_control_support": schema.BoolAttribute{
+				MarkdownDescription: "Whether to check the controls of requests against the `supportedControl` attribute of the root DSE before sending them. Requests with controls the server doesn't advertise fail early instead of being rejected by the server or silently ignored, if they aren't critical. The root DSE is read once (`LDAP_CHECK_CONTROL_SUPPORT`)",
+				Optional:            true,
+			},
+			"ldap_authzid_on_bind": schema.BoolAttribute{
+				MarkdownDescription: "Whether to send the authorization identity request control (RFC 3829) with the bind, so the server returns the identity the provider is authorized as. It is returned by the `ldap_whoami` data source without sending a separate \"Who am I?\" operation. Only used for binds with `ldap_bind_dn` (`LDAP_AUTHZID_ON_BIND`)",
+				Optional:            true,
+			},
+			"ldap_read_only": schema.BoolAttribute{
+				MarkdownDescription: "Whether to refuse all writes, e.g. when the provider is only used for reporting. Creating, updating and destroying resources fails before anything is sent to the server, data sources still work (`LDAP_READ_ONLY`)",
+				Optional:            true,
+			},
 		},
 	}
 }
@@ -87,6 +175,30 @@ func (p *LDAPProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 		ldapTLSUseStartTLS = strings.ToUpper(v) == "TRUE"
 	}
 
+	ldapTLSRenegotiation := os.Getenv("LDAP_TLS_RENEGOTIATION")
+	ldapTLSSessionCache := strings.ToUpper(os.Getenv("LDAP_TLS_SESSION_CACHE")) == "TRUE"
+	ldapCACertificate := os.Getenv("LDAP_CACERT")
+	ldapAllowInsecureBind := strings.ToUpper(os.Getenv("LDAP_ALLOW_INSECURE_BIND")) == "TRUE"
+	ldapCredentialCache := os.Getenv("LDAP_CREDENTIAL_CACHE")
+	ldapKrb5Config := os.Getenv("LDAP_KRB5_CONFIG")
+	if ldapKrb5Config == "" {
+		ldapKrb5Config = os.Getenv("KRB5_CONFIG")
+	}
+	if ldapKrb5Config == "" {
+		ldapKrb5Config = "/etc/krb5.conf"
+	}
+	ldapServicePrincipal := os.Getenv("LDAP_SERVICE_PRINCIPAL")
+	ldapReferralBind := os.Getenv("LDAP_REFERRAL_BIND")
+	if ldapReferralBind == "" {
+		ldapReferralBind = referralBindSame
+	}
+	ldapReferralBindDN := os.Getenv("LDAP_REFERRAL_BIND_DN")
+	ldapReferralBindPassword := os.Getenv("LDAP_REFERRAL_BIND_PASSWORD")
+	ldapSourceAddress := os.Getenv("LDAP_SOURCE_ADDRESS")
+	ldapCheckControlSupport := strings.ToUpper(os.Getenv("LDAP_CHECK_CONTROL_SUPPORT")) == "TRUE"
+	ldapAuthzIDOnBind := strings.ToUpper(os.Getenv("LDAP_AUTHZID_ON_BIND")) == "TRUE"
+	ldapReadOnly := strings.ToUpper(os.Getenv("LDAP_READ_ONLY")) == "TRUE"
+
 	var data LDAPProviderModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
 	if resp.Diagnostics.HasError() {
@@ -113,6 +225,62 @@ func (p *LDAPProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 		ldapTLSUseStartTLS = data.LDAPTLSUseStartTLS.ValueBool()
 	}
 
+	if data.LDAPTLSRenegotiation.ValueString() != "" {
+		ldapTLSRenegotiation = data.LDAPTLSRenegotiation.ValueString()
+	}
+
+	if !data.LDAPTLSSessionCache.IsNull() {
+		ldapTLSSessionCache = data.LDAPTLSSessionCache.ValueBool()
+	}
+
+	if data.LDAPCACertificate.ValueString() != "" {
+		ldapCACertificate = data.LDAPCACertificate.ValueString()
+	}
+
+	if !data.LDAPAllowInsecureBind.IsNull() {
+		ldapAllowInsecureBind = data.LDAPAllowInsecureBind.ValueBool()
+	}
+
+	if data.LDAPCredentialCache.ValueString() != "" {
+		ldapCredentialCache = data.LDAPCredentialCache.ValueString()
+	}
+
+	if data.LDAPKrb5Config.ValueString() != "" {
+		ldapKrb5Config = data.LDAPKrb5Config.ValueString()
+	}
+
+	if data.LDAPServicePrincipal.ValueString() != "" {
+		ldapServicePrincipal = data.LDAPServicePrincipal.ValueString()
+	}
+
+	if data.LDAPReferralBind.ValueString() != "" {
+		ldapReferralBind = data.LDAPReferralBind.ValueString()
+	}
+
+	if data.LDAPReferralBindDN.ValueString() != "" {
+		ldapReferralBindDN = data.LDAPReferralBindDN.ValueString()
+	}
+
+	if data.LDAPReferralBindPassword.ValueString() != "" {
+		ldapReferralBindPassword = data.LDAPReferralBindPassword.ValueString()
+	}
+
+	if data.LDAPSourceAddress.ValueString() != "" {
+		ldapSourceAddress = data.LDAPSourceAddress.ValueString()
+	}
+
+	if !data.LDAPCheckControlSupport.IsNull() {
+		ldapCheckControlSupport = data.LDAPCheckControlSupport.ValueBool()
+	}
+
+	if !data.LDAPAuthzIDOnBind.IsNull() {
+		ldapAuthzIDOnBind = data.LDAPAuthzIDOnBind.ValueBool()
+	}
+
+	if !data.LDAPReadOnly.IsNull() {
+		ldapReadOnly = data.LDAPReadOnly.ValueBool()
+	}
+
 	if ldapUrl == "" {
 		resp.Diagnostics.AddError(
 			"No LDAP url specified",
@@ -121,7 +289,15 @@ func (p *LDAPProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 		return
 	}
 
-	if ldapBindDN == "" {
+	if ldapCredentialCache != "" {
+		if err := checkCredentialCache(ldapCredentialCache); err != nil {
+			resp.Diagnostics.AddError(
+				"Can not read credential cache",
+				fmt.Sprintf("The credential cache configured by ldap_credential_cache can not be read: %s", err),
+			)
+			return
+		}
+	} else if ldapBindDN == "" {
 		resp.Diagnostics.AddError(
 			"No LDAP bind dn specified",
 			"Configure the ldap_bind_dn attribute or LDAP_BIND_DN environment variable for the provider",
@@ -129,7 +305,7 @@ func (p *LDAPProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 		return
 	}
 
-	if ldapBindPassword == "" {
+	if ldapCredentialCache == "" && ldapBindPassword == "" {
 		resp.Diagnostics.AddError(
 			"No LDAP bind password specified",
 			"Configure the ldap_bind_password attribute or LDAP_BIND_PASSWORD environment variable for the provider",
@@ -137,13 +313,41 @@ func (p *LDAPProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 		return
 	}
 
-	var o []ldap.DialOpt
+	if ldapReferralBind == referralBindExplicit && ldapReferralBindDN == "" {
+		resp.Diagnostics.AddError(
+			"No LDAP referral bind dn specified",
+			"Configure the ldap_referral_bind_dn attribute or LDAP_REFERRAL_BIND_DN environment variable for the provider to bind explicitly to referred servers",
+		)
+		return
+	}
 
-	if ldapTLSInsecureVerify {
-		o = append(o, ldap.DialWithTLSConfig(&tls.Config{InsecureSkipVerify: true}))
+	if ldapCredentialCache == "" && !ldapAllowInsecureBind && sendsPasswordUnencrypted(ldapUrl, ldapTLSUseStartTLS, ldapBindPassword) {
+		resp.Diagnostics.AddError(
+			"Insecure bind refused",
+			fmt.Sprintf("Binding to %s would send the bind password unencrypted. Use an ldaps:// URL, enable ldap_tls_use_starttls or set ldap_allow_insecure_bind (LDAP_ALLOW_INSECURE_BIND) to bind anyway", ldapUrl),
+		)
+		return
 	}
 
-	if conn, err := ldap.DialURL(ldapUrl, o...); err != nil {
+	tlsConfig, err := buildTLSConfig(ldapTLSInsecureVerify, ldapCACertificate, ldapTLSRenegotiation, ldapTLSSessionCache)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid TLS configuration",
+			fmt.Sprintf("The TLS configuration of the provider is invalid: %s", err),
+		)
+		return
+	}
+
+	dialer, err := buildDialer(ldapSourceAddress)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid source address",
+			fmt.Sprintf("The source address of the provider is invalid: %s", err),
+		)
+		return
+	}
+
+	if conn, err := ldap.DialURL(ldapUrl, ldap.DialWithDialer(dialer), ldap.DialWithTLSConfig(tlsConfig)); err != nil {
 		resp.Diagnostics.AddError(
 			"Can't connect to LDAP server",
 			fmt.Sprintf("Error connecting to LDAP server: %s", err),
@@ -151,11 +355,7 @@ func (p *LDAPProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 		return
 	} else {
 		if ldapTLSUseStartTLS {
-			c := tls.Config{}
-			if ldapTLSInsecureVerify {
-				c.InsecureSkipVerify = true
-			}
-			if err := conn.StartTLS(&c); err != nil {
+			if err := conn.StartTLS(tlsConfig); err != nil {
 				resp.Diagnostics.AddError(
 					"Can't start TLS",
 					fmt.Sprintf("Error starting TLS: %s", err),
@@ -163,21 +363,154 @@ func (p *LDAPProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 				return
 			}
 		}
-		if err := conn.Bind(ldapBindDN, ldapBindPassword); err != nil {
+		client := &ldapClient{
+			conn:                 conn,
+			locks:                &dnLocks{readOnly: ldapReadOnly},
+			subschema:            &subschemaCache{},
+			controlSupport:       &controlSupport{enabled: ldapCheckControlSupport, ignored: p.ignoredControls},
+			url:                  ldapUrl,
+			dialer:               dialer,
+			tlsConfig:            tlsConfig,
+			tlsUseStartTLS:       ldapTLSUseStartTLS,
+			allowInsecureBind:    ldapAllowInsecureBind,
+			bindDN:               ldapBindDN,
+			bindPassword:         ldapBindPassword,
+			authzIDOnBind:        ldapAuthzIDOnBind,
+			credentialCache:      ldapCredentialCache,
+			krb5Config:           ldapKrb5Config,
+			servicePrincipal:     ldapServicePrincipal,
+			referralBind:         ldapReferralBind,
+			referralBindDN:       ldapReferralBindDN,
+			referralBindPassword: ldapReferralBindPassword,
+		}
+		policy, authzID, err := client.bind(conn, ldapUrl)
+		if err != nil {
 			resp.Diagnostics.AddError(
 				"Can't bind to LDAP server",
 				fmt.Sprintf("Error binding to LDAP server: %s", err),
 			)
 			return
 		}
-		resp.DataSourceData = conn
-		resp.ResourceData = conn
+		for _, warning := range passwordPolicyWarnings(policy) {
+			resp.Diagnostics.AddWarning(
+				"Password policy warning",
+				fmt.Sprintf("Binding as %s succeeded, but %s", ldapBindDN, warning),
+			)
+		}
+		if authzID != nil {
+			tflog.Debug(ctx, "Bound to LDAP server", map[string]interface{}{"authzid": *authzID})
+			client.authzID = authzID
+		}
+		resp.DataSourceData = client
+		resp.ResourceData = client
 	}
 }
 
+// checkCredentialCache checks that the credential cache at the given path is a readable file.
+func checkCredentialCache(path string) error {
+	if info, err := os.Stat(path); err != nil {
+		return err
+	} else if info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// tlsRenegotiationSupport maps the values of ldap_tls_renegotiation to the renegotiation support of TLS clients.
+var tlsRenegotiationSupport = map[string]tls.RenegotiationSupport{
+	"never":  tls.RenegotiateNever,
+	"once":   tls.RenegotiateOnceAsClient,
+	"freely": tls.RenegotiateFreelyAsClient,
+}
+
+// buildTLSConfig returns the TLS configuration used for TLS and STARTTLS connections.
+func buildTLSConfig(insecureVerify bool, caCertificate string, renegotiation string, sessionCache bool) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecureVerify}
+	if caCertificate != "" {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM([]byte(caCertificate)) {
+			return nil, errors.New("the CA certificate configured by ldap_ca_certificate or LDAP_CACERT doesn't contain any PEM encoded certificate")
+		}
+	}
+	if renegotiation != "" {
+		if support, ok := tlsRenegotiationSupport[renegotiation]; !ok {
+			return nil, fmt.Errorf("unknown TLS renegotiation %q, expected never, once or freely", renegotiation)
+		} else {
+			tlsConfig.Renegotiation = support
+		}
+	}
+	if sessionCache {
+		tlsConfig.ClientSessionCache = tls.NewLRUClientSessionCache(0)
+	}
+	return tlsConfig, nil
+}
+
+// buildDialer returns the dialer used to connect to the LDAP server. If a source address is given, connections
+// originate from it.
+func buildDialer(sourceAddress string) (*net.Dialer, error) {
+	dialer := &net.Dialer{Timeout: ldap.DefaultTimeout}
+	if sourceAddress != "" {
+		ip := net.ParseIP(sourceAddress)
+		if ip == nil {
+			return nil, fmt.Errorf("%q configured by ldap_source_address or LDAP_SOURCE_ADDRESS is not an IP address", sourceAddress)
+		}
+		dialer.LocalAddr = &net.TCPAddr{IP: ip}
+	}
+	return dialer, nil
+}
+
+// isEncrypted checks whether a connection to the given URL is encrypted, either by TLS or STARTTLS. Connections to
+// local sockets are considered encrypted, as they don't leave the host.
+func isEncrypted(ldapUrl string, useStartTLS bool) bool {
+	return !strings.HasPrefix(strings.ToLower(ldapUrl), "ldap://") || useStartTLS
+}
+
+// sendsPasswordUnencrypted checks whether a simple bind with the password would send it over an unencrypted
+// connection. Anonymous and unauthenticated binds without a password have nothing to expose.
+func sendsPasswordUnencrypted(ldapUrl string, useStartTLS bool, password string) bool {
+	return password != "" && !isEncrypted(ldapUrl, useStartTLS)
+}
+
+// bindGSSAPI binds to the LDAP server using the Kerberos tickets of the given credential cache. If no service
+// principal is given, ldap/<host> is used.
+func bindGSSAPI(conn *ldap.Conn, ldapUrl string, credentialCache string, krb5Config string, servicePrincipal string) error {
+	if servicePrincipal == "" {
+		if u, err := url.Parse(ldapUrl); err != nil {
+			return err
+		} else {
+			servicePrincipal = fmt.Sprintf("ldap/%s", u.Hostname())
+		}
+	}
+
+	client, err := gssapi.NewClientFromCCache(credentialCache, krb5Config)
+	if err != nil {
+		return fmt.Errorf("can not load credential cache %s: %s", credentialCache, err)
+	}
+	defer client.Close()
+
+	return conn.GSSAPIBind(client, servicePrincipal, "")
+}
+
 func (p *LDAPProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewLDAPObjectResource,
+		NewLDAPObjectsResource,
+		NewLDAPLDIFEntryResource,
+		NewLDAPGroupResource,
+		NewLDAPOrganizationalUnitResource,
+		NewLDAPUserResource,
+		NewLDAPGroupMemberResource,
+		NewLDAPAttributeValueResource,
+		NewLDAPAttributesResource,
+		NewLDAPGroupMembersResource,
+		NewLDAPUserGroupsResource,
+		NewLDAPPasswordPolicyStateResource,
+		NewLDAPPasswordResource,
 	}
 }
 
@@ -185,6 +518,8 @@ func (p *LDAPProvider) DataSources(_ context.Context) []func() datasource.DataSo
 	return []func() datasource.DataSource{
 		NewLDAPObjectDataSource,
 		NewLDAPSearchDataSource,
+		NewLDAPSyncDataSource,
+		NewLDAPWhoAmIDataSource,
 	}
 }
 
@@ -1,9 +1,23 @@
 package provider
 
 import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/stretchr/testify/assert"
+	"net"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
@@ -17,8 +31,389 @@ var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServe
 	"ldap": providerserver.NewProtocol6WithError(New("test")()),
 }
 
+// testIgnoredControlsProviderFactories instantiate a provider which treats the given controls as unsupported by the
+// server.
+func testIgnoredControlsProviderFactories(controls ...string) map[string]func() (tfprotov6.ProviderServer, error) {
+	return map[string]func() (tfprotov6.ProviderServer, error){
+		"ldap": providerserver.NewProtocol6WithError(&LDAPProvider{version: "test", ignoredControls: controls}),
+	}
+}
+
 func testAccPreCheck(t *testing.T) {
 	assert.NotEmpty(t, os.Getenv("LDAP_URL"), "Please set LDAP_URL variable")
 	assert.NotEmpty(t, os.Getenv("LDAP_BIND_DN"), "Please set LDAP_BIND_DN variable")
 	assert.NotEmpty(t, os.Getenv("LDAP_BIND_PASSWORD"), "Please set LDAP_BIND_PASSWORD variable")
 }
+
+func testCheckNonNegative(value string) error {
+	if i, err := strconv.Atoi(value); err != nil {
+		return err
+	} else if i < 0 {
+		return fmt.Errorf("expected a non-negative number, got %d", i)
+	}
+	return nil
+}
+
+func TestCheckCredentialCache(t *testing.T) {
+	dir := t.TempDir()
+	ccache := filepath.Join(dir, "krb5cc_test")
+	assert.NoError(t, os.WriteFile(ccache, []byte{0x05, 0x04}, 0600))
+
+	assert.NoError(t, checkCredentialCache(ccache))
+	assert.Error(t, checkCredentialCache(filepath.Join(dir, "missing")))
+	assert.Error(t, checkCredentialCache(dir))
+}
+
+func TestProviderEnvironmentFallback(t *testing.T) {
+	t.Setenv("LDAP_URL", "ldap://127.0.0.1:1")
+	t.Setenv("LDAP_BIND_DN", "cn=admin,dc=example,dc=com")
+	t.Setenv("LDAP_BIND_PASSWORD", "admin")
+	t.Setenv("LDAP_CACERT", "")
+	t.Setenv("LDAP_CREDENTIAL_CACHE", "")
+	t.Setenv("LDAP_ALLOW_INSECURE_BIND", "true")
+
+	diags := testConfigureProvider(t, map[string]string{})
+	assert.Equal(t, "Can't connect to LDAP server", diags[0].Summary())
+	assert.Contains(t, diags[0].Detail(), "127.0.0.1:1")
+
+	diags = testConfigureProvider(t, map[string]string{"ldap_url": "ldap://127.0.0.1:2"})
+	assert.Contains(t, diags[0].Detail(), "127.0.0.1:2")
+
+	t.Setenv("LDAP_BIND_DN", "")
+	diags = testConfigureProvider(t, map[string]string{})
+	assert.Equal(t, "No LDAP bind dn specified", diags[0].Summary())
+	diags = testConfigureProvider(t, map[string]string{"ldap_bind_dn": "cn=admin,dc=example,dc=com"})
+	assert.Equal(t, "Can't connect to LDAP server", diags[0].Summary())
+
+	t.Setenv("LDAP_CACERT", "not a certificate")
+	diags = testConfigureProvider(t, map[string]string{"ldap_bind_dn": "cn=admin,dc=example,dc=com"})
+	assert.Equal(t, "Invalid TLS configuration", diags[0].Summary())
+	assert.Contains(t, diags[0].Detail(), "LDAP_CACERT")
+}
+
+func TestProviderInsecureBind(t *testing.T) {
+	t.Setenv("LDAP_URL", "ldap://127.0.0.1:1")
+	t.Setenv("LDAP_BIND_DN", "cn=admin,dc=example,dc=com")
+	t.Setenv("LDAP_BIND_PASSWORD", "admin")
+	t.Setenv("LDAP_CACERT", "")
+	t.Setenv("LDAP_CREDENTIAL_CACHE", "")
+	t.Setenv("LDAP_TLS_USE_STARTTLS", "")
+	t.Setenv("LDAP_ALLOW_INSECURE_BIND", "")
+
+	diags := testConfigureProvider(t, map[string]string{})
+	assert.Equal(t, "Insecure bind refused", diags[0].Summary())
+
+	diags = testConfigureProvider(t, map[string]string{"ldap_url": "ldaps://127.0.0.1:1"})
+	assert.Equal(t, "Can't connect to LDAP server", diags[0].Summary())
+
+	t.Setenv("LDAP_ALLOW_INSECURE_BIND", "true")
+	diags = testConfigureProvider(t, map[string]string{})
+	assert.Equal(t, "Can't connect to LDAP server", diags[0].Summary())
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	tlsConfig, err := buildTLSConfig(false, "", "", false)
+	assert.NoError(t, err)
+	assert.Equal(t, tls.RenegotiateNever, tlsConfig.Renegotiation)
+	assert.Nil(t, tlsConfig.ClientSessionCache)
+	assert.Nil(t, tlsConfig.RootCAs)
+
+	tlsConfig, err = buildTLSConfig(true, "", "once", true)
+	assert.NoError(t, err)
+	assert.True(t, tlsConfig.InsecureSkipVerify)
+	assert.Equal(t, tls.RenegotiateOnceAsClient, tlsConfig.Renegotiation)
+	assert.NotNil(t, tlsConfig.ClientSessionCache)
+
+	tlsConfig, err = buildTLSConfig(false, "", "freely", false)
+	assert.NoError(t, err)
+	assert.Equal(t, tls.RenegotiateFreelyAsClient, tlsConfig.Renegotiation)
+
+	_, err = buildTLSConfig(false, "", "sometimes", false)
+	assert.Error(t, err)
+	_, err = buildTLSConfig(false, "not a certificate", "", false)
+	assert.Error(t, err)
+}
+
+func TestProviderTLSRenegotiation(t *testing.T) {
+	t.Setenv("LDAP_URL", "ldaps://127.0.0.1:1")
+	t.Setenv("LDAP_BIND_DN", "cn=admin,dc=example,dc=com")
+	t.Setenv("LDAP_BIND_PASSWORD", "admin")
+	t.Setenv("LDAP_CACERT", "")
+	t.Setenv("LDAP_CREDENTIAL_CACHE", "")
+
+	t.Setenv("LDAP_TLS_RENEGOTIATION", "sometimes")
+	diags := testConfigureProvider(t, map[string]string{})
+	assert.Equal(t, "Invalid TLS configuration", diags[0].Summary())
+
+	t.Setenv("LDAP_TLS_RENEGOTIATION", "once")
+	diags = testConfigureProvider(t, map[string]string{})
+	assert.Equal(t, "Can't connect to LDAP server", diags[0].Summary())
+}
+
+func TestBuildDialer(t *testing.T) {
+	dialer, err := buildDialer("")
+	assert.NoError(t, err)
+	assert.Nil(t, dialer.LocalAddr)
+
+	dialer, err = buildDialer("::1")
+	assert.NoError(t, err)
+	assert.Equal(t, &net.TCPAddr{IP: net.ParseIP("::1")}, dialer.LocalAddr)
+
+	_, err = buildDialer("127.0.0.1:389")
+	assert.Error(t, err)
+	_, err = buildDialer("localhost")
+	assert.Error(t, err)
+}
+
+func TestProviderSourceAddress(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	// the server closes the connection right away, so the bind fails
+	remoteAddr := make(chan net.Addr, 1)
+	go func() {
+		serverConn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		remoteAddr <- serverConn.RemoteAddr()
+		_ = serverConn.Close()
+	}()
+
+	t.Setenv("LDAP_URL", fmt.Sprintf("ldap://%s", listener.Addr()))
+	t.Setenv("LDAP_BIND_DN", "cn=admin,dc=example,dc=com")
+	t.Setenv("LDAP_BIND_PASSWORD", "admin")
+	t.Setenv("LDAP_ALLOW_INSECURE_BIND", "TRUE")
+	t.Setenv("LDAP_CACERT", "")
+	t.Setenv("LDAP_CREDENTIAL_CACHE", "")
+
+	t.Setenv("LDAP_SOURCE_ADDRESS", "not an address")
+	diags := testConfigureProvider(t, map[string]string{})
+	assert.Equal(t, "Invalid source address", diags[0].Summary())
+
+	t.Setenv("LDAP_SOURCE_ADDRESS", "")
+	diags = testConfigureProvider(t, map[string]string{"ldap_source_address": "127.0.0.1"})
+	assert.Equal(t, "Can't bind to LDAP server", diags[0].Summary())
+	select {
+	case addr := <-remoteAddr:
+		assert.Equal(t, "127.0.0.1", addr.(*net.TCPAddr).IP.String())
+	case <-time.After(time.Second):
+		t.Fatal("no connection received")
+	}
+}
+
+func TestIsEncrypted(t *testing.T) {
+	assert.False(t, isEncrypted("ldap://localhost:389", false))
+	assert.True(t, isEncrypted("ldap://localhost:389", true))
+	assert.True(t, isEncrypted("ldaps://localhost:636", false))
+	assert.True(t, isEncrypted("ldapi://%2Fvar%2Frun%2Fslapd%2Fldapi", false))
+}
+
+func TestSendsPasswordUnencrypted(t *testing.T) {
+	assert.True(t, sendsPasswordUnencrypted("ldap://localhost:389", false, "secret"))
+	assert.False(t, sendsPasswordUnencrypted("ldap://localhost:389", true, "secret"))
+	assert.False(t, sendsPasswordUnencrypted("ldaps://localhost:636", false, "secret"))
+	// anonymous binds over plaintext are still accepted
+	assert.False(t, sendsPasswordUnencrypted("ldap://localhost:389", false, ""))
+}
+
+// testConfigureProvider configures the provider with the given string attributes, all other attributes are null.
+func testConfigureProvider(t *testing.T, attributes map[string]string) diag.Diagnostics {
+	ctx := context.Background()
+	p := New("test")()
+
+	schemaResponse := &provider.SchemaResponse{}
+	p.Schema(ctx, provider.SchemaRequest{}, schemaResponse)
+
+	objectType := schemaResponse.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	values := map[string]tftypes.Value{}
+	for name, attributeType := range objectType.AttributeTypes {
+		if value, ok := attributes[name]; ok {
+			values[name] = tftypes.NewValue(attributeType, value)
+		} else {
+			values[name] = tftypes.NewValue(attributeType, nil)
+		}
+	}
+
+	response := &provider.ConfigureResponse{}
+	p.Configure(ctx, provider.ConfigureRequest{
+		Config: tfsdk.Config{
+			Schema: schemaResponse.Schema,
+			Raw:    tftypes.NewValue(objectType, values),
+		},
+	}, response)
+	if !response.Diagnostics.HasError() {
+		t.Fatal("expected the provider configuration to fail")
+	}
+	return response.Diagnostics
+}
+
+func TestProviderCredentialCache(t *testing.T) {
+	dir := t.TempDir()
+	ccache := filepath.Join(dir, "krb5cc_test")
+	if err := os.WriteFile(ccache, []byte("not a credential cache"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testCredentialCacheConfig(filepath.Join(dir, "missing")),
+				ExpectError: regexp.MustCompile("Can not read credential cache"),
+			},
+			{
+				Config:      testCredentialCacheConfig(ccache),
+				ExpectError: regexp.MustCompile("can not load credential cache"),
+			},
+		},
+	})
+}
+
+func testCredentialCacheConfig(ccache string) string {
+	return fmt.Sprintf(`
+provider "ldap" {
+	ldap_credential_cache = %q
+}
+
+data "ldap_object" "test" {
+	dn = "dc=example,dc=com"
+}
+`, ccache)
+}
+
+func TestProviderPasswordPolicyBind(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !testServerSupportsControl(ControlTypePasswordPolicy) || !testServerSupportsControl(ControlTypeRelax) {
+				t.Skip("server does not enforce password policies or support the relax rules control")
+			}
+			t.Cleanup(testDeleteEntryExternally(t, "cn=expired,dc=example,dc=com"))
+			t.Cleanup(testDeleteEntryExternally(t, "cn=expiring,dc=example,dc=com"))
+			testCreateExpiredAccountExternally(t, "cn=expiring,dc=example,dc=com", "cn=expired,dc=example,dc=com")
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testPasswordPolicyBindConfig,
+				ExpectError: regexp.MustCompile(`password policy: password expired`),
+			},
+		},
+	})
+}
+
+const testPasswordPolicyBindConfig = `
+provider "ldap" {
+	ldap_bind_dn = "cn=expired,dc=example,dc=com"
+	ldap_bind_password = "secret"
+}
+
+data "ldap_object" "test" {
+	dn = "dc=example,dc=com"
+}
+`
+
+// testCreateExpiredAccountExternally creates a password policy whose passwords expire after a second without grace
+// logins and an account using it, whose password was changed long ago.
+func testCreateExpiredAccountExternally(t *testing.T, policyDN string, dn string) {
+	testExternally(t, func(conn *ldap.Conn) error {
+		policy := ldap.NewAddRequest(policyDN, []ldap.Control{})
+		policy.Attribute("objectClass", []string{"person", "pwdPolicy"})
+		policy.Attribute("cn", []string{"expiring"})
+		policy.Attribute("sn", []string{"expiring"})
+		policy.Attribute("pwdAttribute", []string{"userPassword"})
+		policy.Attribute("pwdMaxAge", []string{"1"})
+		policy.Attribute("pwdGraceAuthNLimit", []string{"0"})
+		if err := conn.Add(policy); err != nil {
+			return err
+		}
+
+		account := ldap.NewAddRequest(dn, []ldap.Control{})
+		account.Attribute("objectClass", []string{"person"})
+		account.Attribute("cn", []string{"expired"})
+		account.Attribute("sn", []string{"expired"})
+		account.Attribute("userPassword", []string{"secret"})
+		account.Attribute("pwdPolicySubentry", []string{policyDN})
+		if err := conn.Add(account); err != nil {
+			return err
+		}
+
+		changed := ldap.NewModifyRequest(dn, []ldap.Control{NewControlRelax()})
+		changed.Replace("pwdChangedTime", []string{"20000101000000Z"})
+		return conn.Modify(changed)
+	})()
+}
+
+func TestProviderCheckControlSupport(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if testServerSupportsControl(ControlTypeVLV) {
+				t.Skip("server advertises the virtual list view control")
+			}
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// the non-critical control would be ignored by the server
+			{
+				Config:      testCheckControlSupportConfig,
+				ExpectError: regexp.MustCompile(`Control not supported(.|\n)*virtual list view`),
+			},
+		},
+	})
+}
+
+const testCheckControlSupportConfig = `
+provider "ldap" {
+	ldap_check_control_support = true
+}
+
+data "ldap_object" "test" {
+	dn = "dc=example,dc=com"
+	controls = [
+		{
+			oid = "2.16.840.1.113730.3.4.9"
+			value = "MAwCAQACAQCgBAIBAAIBAA=="
+		}
+	]
+}
+`
+
+func TestProviderReadOnly(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// data sources still work, but the resource isn't created
+			{
+				Config:      testReadOnlyConfig,
+				ExpectError: regexp.MustCompile("Provider is configured read_only"),
+			},
+		},
+		CheckDestroy: testCheckEntryMissing("cn=readonly,dc=example,dc=com"),
+	})
+}
+
+const testReadOnlyConfig = `
+provider "ldap" {
+	ldap_read_only = true
+}
+
+data "ldap_object" "test" {
+	dn = "dc=example,dc=com"
+}
+
+resource "ldap_object" "test" {
+	dn = "cn=readonly,${data.ldap_object.test.dn}"
+	object_classes = ["person"]
+	attributes = {
+		"cn" = ["readonly"]
+		"sn" = ["readonly"]
+	}
+}
+`
@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"context"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"time"
+)
+
+// defaultRetryOn lists the result codes retried by default: busy (51), unavailable (52) and other (80).
+var defaultRetryOn = []uint16{ldap.LDAPResultBusy, ldap.LDAPResultUnavailable, ldap.LDAPResultOther}
+
+// retryPolicy describes how often and on which result codes failed write operations are retried.
+type retryPolicy struct {
+	maxAttempts int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	retryOn     []uint16
+}
+
+// noRetry runs operations only once.
+var noRetry = retryPolicy{maxAttempts: 1}
+
+// run runs the operation until it succeeds, fails with a result code which isn't retried or the attempts are
+// exhausted. The backoff starts at minBackoff and doubles with every attempt up to maxBackoff. The operation gets the
+// number of the attempt, so it can tolerate the effects of an earlier attempt, which failed after it was applied.
+func (p retryPolicy) run(ctx context.Context, operation func(attempt int) error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = WithContext(ctx, func() error {
+			return operation(attempt)
+		})
+		if err == nil || attempt >= p.maxAttempts || !ldap.IsErrorAnyOf(err, p.retryOn...) {
+			return err
+		}
+
+		backoff := p.backoff(attempt)
+		tflog.Warn(ctx, "Retrying LDAP operation", map[string]interface{}{
+			"attempt": attempt,
+			"backoff": backoff.String(),
+			"error":   err.Error(),
+		})
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// backoff returns the time to wait after the given attempt failed.
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.minBackoff
+	for i := 1; i < attempt && backoff < p.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.maxBackoff {
+		return p.maxBackoff
+	}
+	return backoff
+}
+
+// addTolerantly adds the entry. If a retried add fails because the entry already exists, it succeeds if the entry
+// matches the add request, since it was likely created by an earlier attempt which failed with a retried result code
+// after the server applied it, e.g. a proxy reporting the backend as unavailable after forwarding the add. Network
+// errors aren't retried, as the shared connection is closed by them.
+func addTolerantly(conn *ldap.Conn, a *ldap.AddRequest, attempt int) error {
+	err := conn.Add(a)
+	if attempt > 1 && ldap.IsErrorWithCode(err, ldap.LDAPResultEntryAlreadyExists) {
+		var attributeTypes []string
+		for _, attribute := range a.Attributes {
+			attributeTypes = append(attributeTypes, attribute.Type)
+		}
+		if entry, readErr := GetEntry(conn, a.DN, attributeTypes...); readErr == nil && entryMatchesAddRequest(entry, a) {
+			return nil
+		}
+	}
+	return err
+}
+
+// deleteTolerantly deletes the entry. If a retried delete fails because the entry doesn't exist anymore, it was
+// deleted by an earlier attempt which failed after the server applied it.
+func deleteTolerantly(conn *ldap.Conn, d *ldap.DelRequest, attempt int) error {
+	err := conn.Del(d)
+	if attempt > 1 && ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		return nil
+	}
+	return err
+}
+
+// unreadableAttributes lists password attributes which servers hash or never return, so their values can't be
+// compared with the values written.
+var unreadableAttributes = []string{"authPassword", "unicodePwd", "userPassword"}
+
+// entryMatchesAddRequest checks whether the entry contains all values of the add request. It is used to verify that
+// an entry which already exists when an add is retried was created by an earlier attempt. Password attributes are
+// skipped, since they aren't returned as written.
+func entryMatchesAddRequest(entry ldap.Entry, a *ldap.AddRequest) bool {
+	for _, attribute := range a.Attributes {
+		if containsFold(unreadableAttributes, attribute.Type) {
+			continue
+		}
+		rule := lookupMatchingRule(attribute.Type, nil)
+		values := entry.GetEqualFoldAttributeValues(attribute.Type)
+		for _, value := range attribute.Vals {
+			if !containsValue(rule, values, value) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,206 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyRun(t *testing.T) {
+	policy := retryPolicy{maxAttempts: 3, minBackoff: time.Millisecond, maxBackoff: time.Millisecond, retryOn: defaultRetryOn}
+
+	var attempts []int
+	err := policy.run(context.Background(), func(attempt int) error {
+		attempts = append(attempts, attempt)
+		if attempt < 2 {
+			return ldap.NewError(ldap.LDAPResultBusy, errors.New("busy"))
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, attempts)
+
+	// result codes which aren't retried fail immediately
+	attempts = nil
+	err = policy.run(context.Background(), func(attempt int) error {
+		attempts = append(attempts, attempt)
+		return ldap.NewError(ldap.LDAPResultInsufficientAccessRights, errors.New("denied"))
+	})
+	assert.True(t, ldap.IsErrorWithCode(err, ldap.LDAPResultInsufficientAccessRights))
+	assert.Equal(t, []int{1}, attempts)
+
+	// the last error is returned once the attempts are exhausted
+	attempts = nil
+	err = policy.run(context.Background(), func(attempt int) error {
+		attempts = append(attempts, attempt)
+		return ldap.NewError(ldap.LDAPResultUnavailable, errors.New("unavailable"))
+	})
+	assert.True(t, ldap.IsErrorWithCode(err, ldap.LDAPResultUnavailable))
+	assert.Equal(t, []int{1, 2, 3}, attempts)
+
+	attempts = nil
+	err = noRetry.run(context.Background(), func(attempt int) error {
+		attempts = append(attempts, attempt)
+		return ldap.NewError(ldap.LDAPResultBusy, errors.New("busy"))
+	})
+	assert.Error(t, err)
+	assert.Equal(t, []int{1}, attempts)
+}
+
+func TestRetryPolicyRunCancelled(t *testing.T) {
+	policy := retryPolicy{maxAttempts: 3, minBackoff: time.Hour, maxBackoff: time.Hour, retryOn: defaultRetryOn}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := policy.run(ctx, func(_ int) error {
+		return ldap.NewError(ldap.LDAPResultBusy, errors.New("busy"))
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := retryPolicy{minBackoff: time.Second, maxBackoff: 5 * time.Second}
+	assert.Equal(t, time.Second, policy.backoff(1))
+	assert.Equal(t, 2*time.Second, policy.backoff(2))
+	assert.Equal(t, 4*time.Second, policy.backoff(3))
+	assert.Equal(t, 5*time.Second, policy.backoff(4))
+	assert.Equal(t, 5*time.Second, policy.backoff(100))
+}
+
+func TestEntryMatchesAddRequest(t *testing.T) {
+	a := ldap.NewAddRequest("cn=test,dc=example,dc=com", nil)
+	a.Attribute("objectClass", []string{"person"})
+	a.Attribute("cn", []string{"test"})
+	a.Attribute("sn", []string{"Test"})
+
+	assert.True(t, entryMatchesAddRequest(*ldap.NewEntry("cn=test,dc=example,dc=com", map[string][]string{
+		"objectClass": {"top", "person"},
+		"cn":          {"TEST"},
+		"sn":          {"test"},
+	}), a))
+	assert.False(t, entryMatchesAddRequest(*ldap.NewEntry("cn=test,dc=example,dc=com", map[string][]string{
+		"objectClass": {"person"},
+		"cn":          {"test"},
+		"sn":          {"other"},
+	}), a))
+
+	// the server hashes the password
+	a.Attribute("userPassword", []string{"secret"})
+	assert.True(t, entryMatchesAddRequest(*ldap.NewEntry("cn=test,dc=example,dc=com", map[string][]string{
+		"objectClass":  {"person"},
+		"cn":           {"test"},
+		"sn":           {"Test"},
+		"userPassword": {"{SSHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g="},
+	}), a))
+}
+
+// testAddServer accepts a single connection and answers its add requests with the given result codes, one per request.
+// Search requests are answered with the given entry.
+func testAddServer(t *testing.T, addResults []uint16, entry *ldap.Entry) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		serverConn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer serverConn.Close()
+		for {
+			packet, err := ber.ReadPacket(serverConn)
+			if err != nil || len(packet.Children) < 2 {
+				return
+			}
+			messageID := packet.Children[0].Value
+			var responses []*ber.Packet
+			switch packet.Children[1].Tag {
+			case ldap.ApplicationAddRequest:
+				if len(addResults) == 0 {
+					return
+				}
+				responses = append(responses, testResult(ldap.ApplicationAddResponse, addResults[0]))
+				addResults = addResults[1:]
+			case ldap.ApplicationSearchRequest:
+				searchEntry := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+				searchEntry.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, "objectName"))
+				attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attributes")
+				for _, attribute := range entry.Attributes {
+					a := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attribute")
+					a.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, attribute.Name, "type"))
+					values := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "vals")
+					for _, value := range attribute.Values {
+						values.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "value"))
+					}
+					a.AppendChild(values)
+					attributes.AppendChild(a)
+				}
+				searchEntry.AppendChild(attributes)
+				responses = append(responses, searchEntry, testResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess))
+			default:
+				return
+			}
+			for _, r := range responses {
+				response := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+				response.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+				response.AppendChild(r)
+				if _, err := serverConn.Write(response.Bytes()); err != nil {
+					return
+				}
+			}
+		}
+	}()
+	return listener
+}
+
+// testResult encodes an LDAPResult with the given application tag and result code.
+func testResult(tag ber.Tag, resultCode uint16) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, uint64(resultCode), "resultCode"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "diagnosticMessage"))
+	return result
+}
+
+func TestAddTolerantlyAfterRetry(t *testing.T) {
+	policy := retryPolicy{maxAttempts: 3, minBackoff: time.Millisecond, maxBackoff: time.Millisecond, retryOn: defaultRetryOn}
+	a := ldap.NewAddRequest("cn=test,dc=example,dc=com", nil)
+	a.Attribute("objectClass", []string{"person"})
+	a.Attribute("cn", []string{"test"})
+	a.Attribute("sn", []string{"Test"})
+
+	for sn, matches := range map[string]bool{"Test": true, "other": false} {
+		// the first attempt was applied although it reported unavailable, so the retry finds the entry
+		listener := testAddServer(t, []uint16{ldap.LDAPResultUnavailable, ldap.LDAPResultEntryAlreadyExists}, ldap.NewEntry(a.DN, map[string][]string{
+			"objectClass": {"top", "person"},
+			"cn":          {"test"},
+			"sn":          {sn},
+		}))
+		conn, err := ldap.DialURL(fmt.Sprintf("ldap://%s", listener.Addr()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var attempts []int
+		err = policy.run(context.Background(), func(attempt int) error {
+			attempts = append(attempts, attempt)
+			return addTolerantly(conn, a, attempt)
+		})
+		assert.Equal(t, []int{1, 2}, attempts)
+		if matches {
+			assert.NoError(t, err)
+		} else {
+			// an entry which differs from the add request was created by someone else
+			assert.True(t, ldap.IsErrorWithCode(err, ldap.LDAPResultEntryAlreadyExists))
+		}
+		_ = conn.Close()
+	}
+}
@@ -1,19 +1,225 @@
 package provider
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"strings"
+	"sync"
+	"time"
 )
 
+// operationCounts counts the LDAP operations issued by the provider by kind. The counts are included in debug logs.
+var operationCounts = map[string]int{}
+var operationCountsMutex sync.Mutex
+
+// ErrEntryNotFound is returned by GetEntry if the search didn't return the entry.
+var ErrEntryNotFound = errors.New("search returned 0 results")
+
 func GetEntry(conn *ldap.Conn, dn string, attrs ...string) (ldap.Entry, error) {
-	s := ldap.NewSearchRequest(dn, ldap.ScopeBaseObject, 0, 0, 0, false, "(&)", attrs, []ldap.Control{})
+	return GetEntryWithControls(conn, dn, []ldap.Control{}, attrs...)
+}
+
+// GetEntryWithControls reads a single entry, sending the given controls with the search request.
+func GetEntryWithControls(conn *ldap.Conn, dn string, controls []ldap.Control, attrs ...string) (ldap.Entry, error) {
+	return GetEntryWithDerefAliases(conn, dn, ldap.NeverDerefAliases, controls, attrs...)
+}
+
+// GetEntryWithDerefAliases reads a single entry like GetEntryWithControls, dereferencing aliases as given.
+func GetEntryWithDerefAliases(conn *ldap.Conn, dn string, derefAliases int, controls []ldap.Control, attrs ...string) (ldap.Entry, error) {
+	s := ldap.NewSearchRequest(dn, ldap.ScopeBaseObject, derefAliases, 0, 0, false, "(&)", attrs, controls)
 
 	if result, err := conn.Search(s); err != nil {
 		return ldap.Entry{}, err
 	} else {
-		if len(result.Entries) != 1 {
+		if len(result.Entries) == 0 {
+			return ldap.Entry{}, ErrEntryNotFound
+		} else if len(result.Entries) != 1 {
 			return ldap.Entry{}, fmt.Errorf("search returned %d results", len(result.Entries))
 		}
 		return *result.Entries[0], nil
 	}
 }
+
+// derefAliasesPolicies maps the values of deref_aliases to the alias dereferencing policies of search requests.
+var derefAliasesPolicies = map[string]int{
+	"never":     ldap.NeverDerefAliases,
+	"searching": ldap.DerefInSearching,
+	"finding":   ldap.DerefFindingBaseObj,
+	"always":    ldap.DerefAlways,
+}
+
+// searchPageSize is the number of entries requested per page by SearchEntries.
+const searchPageSize = 500
+
+// SearchEntries runs the search and passes every entry to the consumer as soon as it is received, so large results
+// don't have to be kept in memory. The entries are requested in pages using the paged results control. If maxResults
+// isn't 0, the search is abandoned after that many entries and truncated is set. The URLs of the search continuation
+// references are returned.
+func SearchEntries(ctx context.Context, conn *ldap.Conn, s *ldap.SearchRequest, maxResults int, consumer func(entry *ldap.Entry)) (referrals []string, truncated bool, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	paging := ldap.NewControlPaging(searchPageSize)
+	r := *s
+	r.Controls = append(append([]ldap.Control{}, s.Controls...), paging)
+	count := 0
+	for {
+		response := conn.SearchAsync(ctx, &r, 0)
+		var cookie []byte
+		for response.Next() {
+			if entry := response.Entry(); entry != nil {
+				if maxResults > 0 && count >= maxResults {
+					// stop the search and drain the response, so its goroutine can finish
+					cancel()
+					for response.Next() {
+					}
+					return referrals, true, nil
+				}
+				consumer(entry)
+				count++
+			}
+			if referral := response.Referral(); referral != "" {
+				referrals = append(referrals, referral)
+			}
+			if control, ok := ldap.FindControl(response.Controls(), ldap.ControlTypePaging).(*ldap.ControlPaging); ok {
+				cookie = control.Cookie
+			}
+		}
+		if err := response.Err(); err != nil {
+			return referrals, false, err
+		}
+		if ctx.Err() != nil {
+			return referrals, false, ctx.Err()
+		}
+		if len(cookie) == 0 {
+			return referrals, false, nil
+		}
+		paging.SetCookie(cookie)
+	}
+}
+
+// WithContext runs a blocking LDAP operation and returns the error of the context if it is done before the operation
+// finished. The operation itself can't be cancelled and will finish in the background.
+func WithContext(ctx context.Context, operation func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- operation()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SplitLanguageTag splits an attribute description like description;lang-en into the attribute type and the language
+// tag (RFC 3866). It returns false if the description doesn't consist of an attribute type and a single language tag.
+func SplitLanguageTag(attributeDescription string) (string, string, bool) {
+	parts := strings.Split(attributeDescription, ";")
+	if len(parts) != 2 || len(parts[1]) <= len("lang-") || !strings.EqualFold(parts[1][:len("lang-")], "lang-") {
+		return "", "", false
+	}
+	return parts[0], parts[1][len("lang-"):], true
+}
+
+// LanguageTaggedType returns the attribute description of the given attribute type with a language tag.
+func LanguageTaggedType(attributeType string, language string) string {
+	return fmt.Sprintf("%s;lang-%s", attributeType, language)
+}
+
+// ParentDN returns the DN of the parent of the given DN, which is empty for a DN with a single RDN.
+func ParentDN(dn string) (string, error) {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return "", err
+	}
+	if len(parsed.RDNs) <= 1 {
+		return "", nil
+	}
+	parent := ldap.DN{RDNs: parsed.RDNs[1:]}
+	return parent.String(), nil
+}
+
+// SplitRDN returns the first RDN of the given DN and the DN of its parent.
+func SplitRDN(dn string) (*ldap.RelativeDN, string, error) {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(parsed.RDNs) == 0 {
+		return nil, "", fmt.Errorf("the DN %q has no RDN", dn)
+	}
+	parent := ldap.DN{RDNs: parsed.RDNs[1:]}
+	return parsed.RDNs[0], parent.String(), nil
+}
+
+// IsNamingContext checks whether the given DN is one of the naming contexts published in the root DSE.
+func IsNamingContext(conn *ldap.Conn, dn string) (bool, error) {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return false, err
+	}
+
+	rootDSE, err := GetEntry(conn, "", "namingContexts")
+	if err != nil {
+		return false, err
+	}
+
+	for _, namingContext := range rootDSE.GetAttributeValues("namingContexts") {
+		if parsedNamingContext, err := ldap.ParseDN(namingContext); err == nil && parsed.EqualFold(parsedNamingContext) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// LogOperation logs the duration of an LDAP operation which started at the given time, together with the number
+// of operations issued so far.
+func LogOperation(ctx context.Context, operation string, dn string, start time.Time) time.Duration {
+	duration := time.Since(start)
+
+	operationCountsMutex.Lock()
+	operationCounts[operation]++
+	counts := make(map[string]interface{}, len(operationCounts))
+	for k, v := range operationCounts {
+		counts[k] = v
+	}
+	operationCountsMutex.Unlock()
+
+	tflog.Debug(ctx, "LDAP operation finished", map[string]interface{}{
+		"operation":        operation,
+		"dn":               dn,
+		"duration_ms":      duration.Milliseconds(),
+		"operation_counts": counts,
+	})
+	return duration
+}
+
+// generalizedTimeLayouts are the layouts of the GeneralizedTime syntax (RFC 4517, section 3.3.13), which allows
+// omitting minutes and seconds and adding a fraction.
+var generalizedTimeLayouts = []string{
+	"20060102150405.999999999Z0700",
+	"20060102150405Z0700",
+	"200601021504Z0700",
+	"2006010215Z0700",
+	"20060102150405.999999999Z07",
+	"20060102150405Z07",
+	"200601021504Z07",
+	"2006010215Z07",
+}
+
+// ParseGeneralizedTime parses a value of the GeneralizedTime syntax like 20230102150405Z.
+func ParseGeneralizedTime(value string) (time.Time, error) {
+	normalized := strings.Replace(value, ",", ".", 1)
+	for _, layout := range generalizedTimeLayouts {
+		if t, err := time.Parse(layout, normalized); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%s is not a valid generalized time", value)
+}
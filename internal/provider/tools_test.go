@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParentDN(t *testing.T) {
+	parentDN, err := ParentDN("cn=bob,ou=people,dc=ex")
+	assert.NoError(t, err)
+	assert.Equal(t, "ou=people,dc=ex", parentDN)
+
+	parentDN, err = ParentDN("cn=Doe\\, John,ou=people,dc=ex")
+	assert.NoError(t, err)
+	assert.Equal(t, "ou=people,dc=ex", parentDN)
+
+	parentDN, err = ParentDN("dc=ex")
+	assert.NoError(t, err)
+	assert.Equal(t, "", parentDN)
+
+	_, err = ParentDN("cn=bob,,dc=ex")
+	assert.Error(t, err)
+}
+
+func TestLogOperation(t *testing.T) {
+	before := operationCounts["compare"]
+	duration := LogOperation(context.Background(), "compare", "cn=test,dc=example,dc=com", time.Now().Add(-time.Second))
+	assert.GreaterOrEqual(t, duration, time.Second)
+	assert.Equal(t, before+1, operationCounts["compare"])
+}
+
+func TestWithContext(t *testing.T) {
+	err := WithContext(context.Background(), func() error {
+		return errors.New("failed")
+	})
+	assert.EqualError(t, err, "failed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = WithContext(ctx, func() error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestSplitLanguageTag(t *testing.T) {
+	attributeType, language, ok := SplitLanguageTag("description;lang-en")
+	assert.True(t, ok)
+	assert.Equal(t, "description", attributeType)
+	assert.Equal(t, "en", language)
+
+	attributeType, language, ok = SplitLanguageTag("cn;LANG-fr-CA")
+	assert.True(t, ok)
+	assert.Equal(t, "cn", attributeType)
+	assert.Equal(t, "fr-CA", language)
+
+	for _, attributeDescription := range []string{"description", "userCertificate;binary", "description;lang-", "description;lang-en;binary"} {
+		_, _, ok = SplitLanguageTag(attributeDescription)
+		assert.False(t, ok, "%s has no single language tag", attributeDescription)
+	}
+
+	assert.Equal(t, "description;lang-en", LanguageTaggedType("description", "en"))
+}
+
+func TestParseGeneralizedTime(t *testing.T) {
+	for value, expected := range map[string]time.Time{
+		"20230102150405Z":     time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC),
+		"20230102150405.5Z":   time.Date(2023, 1, 2, 15, 4, 5, 500000000, time.UTC),
+		"20230102150405,5Z":   time.Date(2023, 1, 2, 15, 4, 5, 500000000, time.UTC),
+		"202301021504Z":       time.Date(2023, 1, 2, 15, 4, 0, 0, time.UTC),
+		"2023010215Z":         time.Date(2023, 1, 2, 15, 0, 0, 0, time.UTC),
+		"20230102150405+0100": time.Date(2023, 1, 2, 14, 4, 5, 0, time.UTC),
+		"20230102150405-05":   time.Date(2023, 1, 2, 20, 4, 5, 0, time.UTC),
+	} {
+		parsed, err := ParseGeneralizedTime(value)
+		if assert.NoError(t, err, value) {
+			assert.True(t, expected.Equal(parsed), "%s parsed as %s", value, parsed)
+		}
+	}
+
+	_, err := ParseGeneralizedTime("2023-01-02T15:04:05Z")
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"strings"
+	"time"
+)
+
+var _ validator.String = filterValidator{}
+var _ validator.String = durationValidator{}
+var _ validator.String = dnValidator{}
+
+// filterValidator validates that a string is a valid LDAP search filter (RFC 4515).
+type filterValidator struct{}
+
+func (v filterValidator) Description(_ context.Context) string {
+	return "value must be a valid LDAP search filter"
+}
+
+func (v filterValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v filterValidator) ValidateString(_ context.Context, request validator.StringRequest, response *validator.StringResponse) {
+	if request.ConfigValue.IsNull() || request.ConfigValue.IsUnknown() {
+		return
+	}
+
+	filter := request.ConfigValue.ValueString()
+	if _, err := ldap.CompileFilter(filter); err != nil {
+		detail := fmt.Sprintf("The filter %q can not be parsed: %s", filter, err)
+		if position := filterErrorPosition(filter); position >= 0 {
+			detail = fmt.Sprintf("%s\n\n%s\n%s^ at position %d", detail, filter, strings.Repeat(" ", position), position+1)
+		}
+		response.Diagnostics.AddAttributeError(request.Path, "Invalid LDAP filter", detail)
+	}
+}
+
+// IsValidFilter returns a validator which ensures that a string is a valid LDAP search filter.
+func IsValidFilter() validator.String {
+	return filterValidator{}
+}
+
+// filterErrorPosition guesses the position of a syntax error in a filter by looking for unbalanced parentheses.
+// Parentheses inside assertion values have to be escaped as \28 and \29, so all literal parentheses are part of
+// the filter structure. It returns -1 if the parentheses are balanced.
+func filterErrorPosition(filter string) int {
+	var open []int
+	for i, c := range filter {
+		switch c {
+		case '(':
+			open = append(open, i)
+		case ')':
+			if len(open) == 0 {
+				return i
+			}
+			open = open[:len(open)-1]
+			if len(open) == 0 && i < len(filter)-1 {
+				// anything after the outermost filter is superfluous
+				return i + 1
+			}
+		}
+	}
+	if len(open) > 0 {
+		return open[len(open)-1]
+	}
+	return -1
+}
+
+// durationValidator validates that a string is a duration like 30s or 5m.
+type durationValidator struct{}
+
+func (v durationValidator) Description(_ context.Context) string {
+	return "value must be a duration like 30s or 5m"
+}
+
+func (v durationValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v durationValidator) ValidateString(_ context.Context, request validator.StringRequest, response *validator.StringResponse) {
+	if request.ConfigValue.IsNull() || request.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if _, err := time.ParseDuration(request.ConfigValue.ValueString()); err != nil {
+		response.Diagnostics.AddAttributeError(request.Path, "Invalid duration", fmt.Sprintf("The duration can not be parsed: %s", err))
+	}
+}
+
+// IsDuration returns a validator which ensures that a string is a valid duration.
+func IsDuration() validator.String {
+	return durationValidator{}
+}
+
+// dnValidator validates that a string is a valid distinguished name (RFC 4514).
+type dnValidator struct{}
+
+func (v dnValidator) Description(_ context.Context) string {
+	return "value must be a valid distinguished name"
+}
+
+func (v dnValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v dnValidator) ValidateString(_ context.Context, request validator.StringRequest, response *validator.StringResponse) {
+	if request.ConfigValue.IsNull() || request.ConfigValue.IsUnknown() {
+		return
+	}
+
+	dn := request.ConfigValue.ValueString()
+	if _, err := ldap.ParseDN(dn); err != nil {
+		detail := fmt.Sprintf("The DN %q can not be parsed: %s", dn, err)
+		if position, component := dnErrorComponent(dn); position >= 0 {
+			detail = fmt.Sprintf("%s\n\n%s\n%s^ invalid RDN %q", detail, dn, strings.Repeat(" ", position), component)
+		}
+		response.Diagnostics.AddAttributeError(request.Path, "Invalid DN", detail)
+	}
+}
+
+// IsValidDN returns a validator which ensures that a string is a valid distinguished name.
+func IsValidDN() validator.String {
+	return dnValidator{}
+}
+
+// dnErrorComponent returns the position and the text of the first RDN of a DN, which can't be parsed on its own.
+// Escaped commas don't separate RDNs. It returns -1 if all RDNs can be parsed.
+func dnErrorComponent(dn string) (int, string) {
+	start := 0
+	escaped := false
+	for i := 0; i <= len(dn); i++ {
+		if i < len(dn) {
+			c := dn[i]
+			switch {
+			case escaped:
+				escaped = false
+				continue
+			case c == '\\':
+				escaped = true
+				continue
+			case c != ',':
+				continue
+			}
+		}
+		component := dn[start:i]
+		if _, err := ldap.ParseDN(component); err != nil || strings.TrimSpace(component) == "" {
+			return start, component
+		}
+		start = i + 1
+	}
+	return -1, ""
+}
@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFilterValidator(t *testing.T) {
+	for _, filter := range []string{
+		"(&)",
+		"(uid=test)",
+		"(uid=)",
+		"(objectClass=*)",
+		"(&(objectClass=person)(|(uid=test)(mail=test@example.com))(!(cn=foo\\28bar\\29)))",
+		"(cn=te*st*)",
+		"(uidNumber>=1000)",
+		"(member:1.2.840.113556.1.4.1941:=cn=group,dc=example,dc=com)",
+	} {
+		response := validateFilter(filter)
+		assert.False(t, response.Diagnostics.HasError(), "filter %s should be valid", filter)
+	}
+
+	for _, filter := range []string{
+		"uid=test",
+		"(uid=test",
+		"((uid=test)",
+		"(uid=test))",
+		"(&(uid=test)(cn=test)",
+		"(uid=test)(cn=test)",
+		"(uid=te\\zzst)",
+	} {
+		response := validateFilter(filter)
+		assert.True(t, response.Diagnostics.HasError(), "filter %s should be invalid", filter)
+	}
+}
+
+func TestFilterErrorPosition(t *testing.T) {
+	assert.Equal(t, -1, filterErrorPosition("(uid=test)"))
+	assert.Equal(t, 0, filterErrorPosition("(uid=test"))
+	assert.Equal(t, 10, filterErrorPosition("(uid=test))"))
+	assert.Equal(t, 0, filterErrorPosition("(&(uid=test)(cn=test)"))
+	assert.Equal(t, 10, filterErrorPosition("(uid=test)(cn=test)"))
+}
+
+func validateFilter(filter string) *validator.StringResponse {
+	response := &validator.StringResponse{}
+	IsValidFilter().ValidateString(context.Background(), validator.StringRequest{
+		Path:        path.Root("filter"),
+		ConfigValue: types.StringValue(filter),
+	}, response)
+	return response
+}
+
+func TestDurationValidator(t *testing.T) {
+	for _, duration := range []string{"30s", "5m", "1h30m"} {
+		response := &validator.StringResponse{}
+		IsDuration().ValidateString(context.Background(), validator.StringRequest{
+			Path:        path.Root("create"),
+			ConfigValue: types.StringValue(duration),
+		}, response)
+		assert.False(t, response.Diagnostics.HasError(), "duration %s should be valid", duration)
+	}
+
+	for _, duration := range []string{"", "5", "five minutes"} {
+		response := &validator.StringResponse{}
+		IsDuration().ValidateString(context.Background(), validator.StringRequest{
+			Path:        path.Root("create"),
+			ConfigValue: types.StringValue(duration),
+		}, response)
+		assert.True(t, response.Diagnostics.HasError(), "duration %s should be invalid", duration)
+	}
+}
+
+func TestDNValidator(t *testing.T) {
+	for _, dn := range []string{
+		"",
+		"dc=example,dc=com",
+		"cn=Doe\\, John,ou=people,dc=example,dc=com",
+		"cn=a\\+b,dc=example,dc=com",
+		"cn=\\41lice,dc=example,dc=com",
+		"cn=alice+uid=alice,dc=example,dc=com",
+	} {
+		response := validateDN(dn)
+		assert.False(t, response.Diagnostics.HasError(), "DN %s should be valid", dn)
+	}
+
+	for _, dn := range []string{
+		"cn=foo,,dc=example,dc=com",
+		"cn=Doe, John,dc=example,dc=com",
+		"cn=foo,",
+		"cn=foo\\zz,dc=example,dc=com",
+	} {
+		response := validateDN(dn)
+		assert.True(t, response.Diagnostics.HasError(), "DN %s should be invalid", dn)
+	}
+}
+
+func TestDNErrorComponent(t *testing.T) {
+	position, component := dnErrorComponent("cn=foo,dc=example,dc=com")
+	assert.Equal(t, -1, position)
+	assert.Equal(t, "", component)
+
+	position, component = dnErrorComponent("cn=foo,,dc=example,dc=com")
+	assert.Equal(t, 7, position)
+	assert.Equal(t, "", component)
+
+	position, component = dnErrorComponent("cn=Doe, John,dc=example,dc=com")
+	assert.Equal(t, 7, position)
+	assert.Equal(t, " John", component)
+
+	position, component = dnErrorComponent("cn=Doe\\, John,dc=ex\\zz,dc=com")
+	assert.Equal(t, 14, position)
+	assert.Equal(t, "dc=ex\\zz", component)
+}
+
+func validateDN(dn string) *validator.StringResponse {
+	response := &validator.StringResponse{}
+	IsValidDN().ValidateString(context.Background(), validator.StringRequest{
+		Path:        path.Root("dn"),
+		ConfigValue: types.StringValue(dn),
+	}, response)
+	return response
+}